@@ -0,0 +1,66 @@
+package cataloghelper
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"io"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatONIX = "onix"
+)
+
+type CatalogReader interface {
+	// Read returns io.EOF once the source is exhausted. Errors wrapping
+	// ErrRecordMalformed only affect the current record, reading can go on.
+	Read() (database.CatalogEntry, error)
+}
+
+type CatalogWriter interface {
+	Write(entry database.CatalogEntry) error
+	Close() error
+}
+
+var ErrCatalogFormatUnsupported = errors.New("catalog format unsupported")
+var ErrRecordMalformed = errors.New("record malformed")
+
+func IsCatalogFormat(format string) bool {
+	return format == FormatCSV || format == FormatONIX
+}
+
+func ContentType(format string) string {
+	if format == FormatONIX {
+		return "application/xml"
+	}
+	return "text/csv"
+}
+
+func FileExtension(format string) string {
+	if format == FormatONIX {
+		return "xml"
+	}
+	return "csv"
+}
+
+func NewCatalogReader(format string, r io.Reader) (CatalogReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatONIX:
+		return newONIXReader(r), nil
+	default:
+		return nil, ErrCatalogFormatUnsupported
+	}
+}
+
+func NewCatalogWriter(format string, w io.Writer) (CatalogWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatONIX:
+		return newONIXWriter(w)
+	default:
+		return nil, ErrCatalogFormatUnsupported
+	}
+}
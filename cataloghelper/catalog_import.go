@@ -0,0 +1,148 @@
+package cataloghelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// How many records are processed between job progress updates.
+const progressInterval = 100
+
+// ErrISBNDuplicated is a row error for a book already listed earlier in the
+// feed. The first listing is the one imported.
+var ErrISBNDuplicated = errors.New("isbn listed earlier in the feed")
+
+type countingReader struct {
+	io.Reader
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+// RunImport streams every record of source into the catalog, keeping the job
+// row up to date as it goes. size is only used to estimate progress and may be
+// zero when unknown. Record level problems are stored as row errors and don't
+// stop the import.
+func RunImport(
+	ctx context.Context,
+	db database.CatalogInterface,
+	job database.ImportJob,
+	source io.Reader,
+	size int64,
+) (database.ImportJob, error) {
+	counter := &countingReader{Reader: source}
+
+	err := importRecords(ctx, db, &job, counter, size)
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	if err != nil {
+		job.Status = database.ImportJobFailed
+		job.Message = err.Error()
+	} else {
+		job.Status = database.ImportJobDone
+		job.Progress = 100
+	}
+
+	// A cancelled import is still recorded as failed.
+	if updateErr := db.UpdateImportJob(context.WithoutCancel(ctx), job); updateErr != nil {
+		log.Error().Err(updateErr).Str("job", job.ID.String()).Msg("Updating finished import job failed")
+	}
+	return job, err
+}
+
+func importRecords(
+	ctx context.Context,
+	db database.CatalogInterface,
+	job *database.ImportJob,
+	counter *countingReader,
+	size int64,
+) error {
+	reader, err := NewCatalogReader(job.Format, counter)
+	if err != nil {
+		return err
+	}
+
+	// The row each ISBN was first seen at, normalized so that ISBN-10 and
+	// ISBN-13 listings of a book match.
+	seen := map[string]int{}
+	for row := 1; ; row++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && !errors.Is(err, ErrRecordMalformed) {
+			return err
+		}
+
+		job.Processed++
+		if err == nil {
+			err = importEntry(ctx, db, entry, job, seen, row)
+		}
+		if err != nil {
+			job.Failed++
+			if err := db.AddImportRowError(ctx, job.ID, database.ImportRowError{
+				Row:     row,
+				ISBN:    entry.ISBN,
+				Message: err.Error(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if job.Processed%progressInterval == 0 {
+			if size > 0 {
+				job.Progress = float32(counter.read) * 100 / float32(size)
+			}
+			if err := db.UpdateImportJob(ctx, *job); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func importEntry(ctx context.Context, db database.CatalogInterface, entry database.CatalogEntry, job *database.ImportJob, seen map[string]int, row int) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	if first, ok := seen[entry.ISBN]; ok {
+		return fmt.Errorf("%w, at row %d", ErrISBNDuplicated, first)
+	}
+	seen[entry.ISBN] = row
+
+	created, err := db.UpsertCatalogEntry(ctx, entry, job.DryRun)
+	if err != nil {
+		return err
+	}
+	if created {
+		job.Created++
+	} else {
+		job.Updated++
+	}
+	return nil
+}
+
+// ExportCatalog writes the whole catalog to w in the given format.
+func ExportCatalog(ctx context.Context, db database.CatalogInterface, format string, w io.Writer) error {
+	writer, err := NewCatalogWriter(format, w)
+	if err != nil {
+		return err
+	}
+	if err := db.ExportCatalog(ctx, writer.Write); err != nil {
+		return err
+	}
+	return writer.Close()
+}
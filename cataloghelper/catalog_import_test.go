@@ -0,0 +1,93 @@
+package cataloghelper
+
+import (
+	"context"
+	"ic-rhadi/e_library/database"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// catalogStub keeps upserted entries by ISBN, the way the catalog table does.
+type catalogStub struct {
+	books     map[string]database.CatalogEntry
+	rowErrors []database.ImportRowError
+	finished  database.ImportJob
+}
+
+func (c *catalogStub) UpsertCatalogEntry(ctx context.Context, entry database.CatalogEntry, dryRun bool) (bool, error) {
+	_, existed := c.books[entry.ISBN]
+	if !dryRun {
+		c.books[entry.ISBN] = entry
+	}
+	return !existed, nil
+}
+
+func (c *catalogStub) ExportCatalog(ctx context.Context, each func(database.CatalogEntry) error) error {
+	return nil
+}
+
+func (c *catalogStub) CreateImportJob(ctx context.Context, format string, dryRun bool, createdBy string) (*database.ImportJob, error) {
+	return nil, nil
+}
+
+func (c *catalogStub) UpdateImportJob(ctx context.Context, job database.ImportJob) error {
+	c.finished = job
+	return nil
+}
+
+func (c *catalogStub) AddImportRowError(ctx context.Context, jobID uuid.UUID, rowError database.ImportRowError) error {
+	c.rowErrors = append(c.rowErrors, rowError)
+	return nil
+}
+
+func (c *catalogStub) GetImportJob(ctx context.Context, jobID uuid.UUID) (*database.ImportJob, error) {
+	return nil, nil
+}
+
+func TestRunImport(t *testing.T) {
+	feed := "isbn,title,author,page_count\n" +
+		"9780306406157,First,Ada Writer,\n" +
+		"978-0-306-40615-7,First again,Ada Writer,\n" +
+		"9781861972712,Second,Bo Writer,many\n" +
+		"9780141439518,,Jane Austen,\n" +
+		"0306406152,First as ISBN-10,Ada Writer,\n" +
+		"9780141439518,Pride and Prejudice,Jane Austen,\n"
+
+	for _, dryRun := range []bool{false, true} {
+		db := &catalogStub{books: map[string]database.CatalogEntry{}}
+		job := database.ImportJob{ID: uuid.New(), Format: FormatCSV, DryRun: dryRun}
+
+		result, err := RunImport(context.Background(), db, job, strings.NewReader(feed), int64(len(feed)))
+		require.Nil(t, err)
+		assert.Equal(t, database.ImportJobDone, result.Status)
+		assert.Equal(t, result, db.finished, "the finished job wasn't stored")
+		assert.Equal(t, []int{6, 2, 0, 4}, []int{result.Processed, result.Created, result.Updated, result.Failed},
+			"the rows weren't counted right, with dry run %v", dryRun)
+
+		var rows []int
+		for _, rowError := range db.rowErrors {
+			rows = append(rows, rowError.Row)
+		}
+		assert.Equal(t, []int{2, 3, 4, 5}, rows, "the failed rows weren't reported, with dry run %v", dryRun)
+		assert.Equal(t, "isbn listed earlier in the feed, at row 1", db.rowErrors[0].Message)
+		assert.Equal(t, "isbn listed earlier in the feed, at row 1", db.rowErrors[3].Message, "an ISBN-10 listing of the same book wasn't caught")
+		if !dryRun {
+			assert.Equal(t, "First", db.books["9780306406157"].Title, "the first listing of a book should've been imported")
+		}
+	}
+}
+
+func TestRunImportUnsupportedFeed(t *testing.T) {
+	db := &catalogStub{books: map[string]database.CatalogEntry{}}
+	job := database.ImportJob{ID: uuid.New(), Format: FormatONIX}
+
+	feed := `<ONIXMessage><Product></Product></ONIXMessage>`
+	result, err := RunImport(context.Background(), db, job, strings.NewReader(feed), 0)
+	assert.Equal(t, ErrONIXReleaseUnsupported, err)
+	assert.Equal(t, database.ImportJobFailed, result.Status)
+	assert.Equal(t, ErrONIXReleaseUnsupported.Error(), db.finished.Message, "the job should've been failed with the reason")
+}
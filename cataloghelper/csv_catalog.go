@@ -0,0 +1,139 @@
+package cataloghelper
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvImportColumns = []string{
+	"isbn", "title", "author", "summary", "cover_url",
+	"publisher", "publication_date", "language", "page_count", "edition", "format",
+}
+var csvExportColumns = append(csvImportColumns, "readers", "rating", "rating_count")
+
+// Publication dates are written as ISO 8601 calendar dates.
+const csvDateLayout = "2006-01-02"
+
+var ErrCSVHeaderMissingColumn = errors.New("csv header is missing a required column")
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header failed: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"isbn", "title", "author"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrCSVHeaderMissingColumn, required)
+		}
+	}
+
+	return &csvReader{reader, columns}, nil
+}
+
+func (c *csvReader) Read() (database.CatalogEntry, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return database.CatalogEntry{}, fmt.Errorf("%w: %v", ErrRecordMalformed, parseErr.Err)
+		}
+		return database.CatalogEntry{}, err
+	}
+
+	field := func(name string) string {
+		i, ok := c.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	entry := database.CatalogEntry{
+		ISBN:      field("isbn"),
+		Title:     field("title"),
+		Author:    field("author"),
+		Summary:   field("summary"),
+		Cover:     field("cover_url"),
+		Publisher: field("publisher"),
+		Language:  field("language"),
+		Edition:   field("edition"),
+		Format:    field("format"),
+	}
+	if date := field("publication_date"); date != "" {
+		publishedOn, err := time.Parse(csvDateLayout, date)
+		if err != nil {
+			return entry, fmt.Errorf("%w: publication_date %q isn't a YYYY-MM-DD date", ErrRecordMalformed, date)
+		}
+		entry.PublishedOn = &publishedOn
+	}
+	if pages := field("page_count"); pages != "" {
+		pageCount, err := strconv.Atoi(pages)
+		if err != nil {
+			return entry, fmt.Errorf("%w: page_count %q isn't a number", ErrRecordMalformed, pages)
+		}
+		entry.PageCount = pageCount
+	}
+	return entry, nil
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvExportColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer}, nil
+}
+
+func (c *csvWriter) Write(entry database.CatalogEntry) error {
+	var publishedOn, pageCount string
+	if entry.PublishedOn != nil {
+		publishedOn = entry.PublishedOn.Format(csvDateLayout)
+	}
+	if entry.PageCount > 0 {
+		pageCount = strconv.Itoa(entry.PageCount)
+	}
+	return c.writer.Write([]string{
+		entry.ISBN,
+		entry.Title,
+		entry.Author,
+		entry.Summary,
+		entry.Cover,
+		entry.Publisher,
+		publishedOn,
+		entry.Language,
+		pageCount,
+		entry.Edition,
+		entry.Format,
+		strconv.Itoa(entry.Readers),
+		strconv.FormatFloat(float64(entry.Rating), 'f', 2, 32),
+		strconv.Itoa(entry.RatingCount),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
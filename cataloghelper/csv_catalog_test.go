@@ -0,0 +1,148 @@
+package cataloghelper
+
+import (
+	"bytes"
+	"errors"
+	"ic-rhadi/e_library/database"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll reads every record of a feed, keeping the record level errors.
+func readAll(t *testing.T, reader CatalogReader) ([]database.CatalogEntry, []error) {
+	var entries []database.CatalogEntry
+	var errs []error
+	for {
+		entry, err := reader.Read()
+		if err == io.EOF {
+			return entries, errs
+		}
+		if err != nil && !errors.Is(err, ErrRecordMalformed) {
+			require.Nil(t, err, "unexpected error reading the feed")
+		}
+		entries = append(entries, entry)
+		errs = append(errs, err)
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		feed   string
+		expErr error
+	}{
+		{"required columns", "isbn,title,author\n", nil},
+		{"any case and spacing", " ISBN , Title,AUTHOR,summary\n", nil},
+		{"missing author", "isbn,title,summary\n", ErrCSVHeaderMissingColumn},
+		{"missing isbn", "title,author\n", ErrCSVHeaderMissingColumn},
+		{"empty feed", "", io.EOF},
+	}
+
+	for _, test := range tests {
+		_, err := NewCatalogReader(FormatCSV, strings.NewReader(test.feed))
+		if test.expErr == nil {
+			assert.Nil(t, err, "%s: unexpected error reading the header", test.name)
+		} else {
+			assert.ErrorIs(t, err, test.expErr, "%s: unexpected error reading the header", test.name)
+		}
+	}
+}
+
+func TestCSVRecords(t *testing.T) {
+	publishedOn := time.Date(1992, time.May, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		row       string
+		expEntry  database.CatalogEntry
+		malformed bool
+	}{
+		{
+			name: "every column",
+			row:  `9780306406157,"Signal, Noise",Ada Writer,About it,https://example.com/c.jpg,Pub,1992-05-01,en,320,2nd,paperback`,
+			expEntry: database.CatalogEntry{
+				ISBN: "9780306406157", Title: "Signal, Noise", Author: "Ada Writer", Summary: "About it",
+				Cover: "https://example.com/c.jpg", Publisher: "Pub", PublishedOn: &publishedOn,
+				Language: "en", PageCount: 320, Edition: "2nd", Format: "paperback",
+			},
+		},
+		{
+			name:     "short row",
+			row:      "9780306406157, Title ,Ada Writer",
+			expEntry: database.CatalogEntry{ISBN: "9780306406157", Title: "Title", Author: "Ada Writer"},
+		},
+		{
+			name:      "malformed date",
+			row:       "9780306406157,Title,Ada Writer,,,,01/05/1992",
+			malformed: true,
+		},
+		{
+			name:      "malformed page count",
+			row:       "9780306406157,Title,Ada Writer,,,,,,many",
+			malformed: true,
+		},
+		{
+			name:      "unbalanced quote",
+			row:       `9780306406157,"Title,Ada Writer`,
+			malformed: true,
+		},
+	}
+
+	for _, test := range tests {
+		feed := strings.Join(csvImportColumns, ",") + "\n" + test.row + "\n"
+		reader, err := NewCatalogReader(FormatCSV, strings.NewReader(feed))
+		require.Nil(t, err)
+
+		entry, err := reader.Read()
+		if test.malformed {
+			assert.ErrorIs(t, err, ErrRecordMalformed, "%s: the record should've been malformed", test.name)
+			continue
+		}
+		if assert.Nil(t, err, "%s: unexpected error reading the record", test.name) {
+			assert.Equal(t, test.expEntry, entry, "%s: the record wasn't read right", test.name)
+		}
+	}
+}
+
+func TestCSVMalformedRecordSkipped(t *testing.T) {
+	feed := "isbn,title,author,page_count\n" +
+		"9780306406157,First,Ada Writer,many\n" +
+		"9781861972712,Second,Ada Writer,12\n"
+	reader, err := NewCatalogReader(FormatCSV, strings.NewReader(feed))
+	require.Nil(t, err)
+
+	entries, errs := readAll(t, reader)
+	require.Len(t, entries, 2, "reading didn't go on after a malformed record")
+	assert.ErrorIs(t, errs[0], ErrRecordMalformed)
+	assert.Nil(t, errs[1])
+	assert.Equal(t, 12, entries[1].PageCount)
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	publishedOn := time.Date(1992, time.May, 1, 0, 0, 0, 0, time.UTC)
+	entry := database.CatalogEntry{
+		ISBN: "9780306406157", Title: "Signal, \"Noise\"", Author: "Ada Writer", Summary: "Line one\nline two",
+		Publisher: "Pub", PublishedOn: &publishedOn, Language: "en", PageCount: 320, Format: "ebook",
+		Readers: 3, Rating: 4.5, RatingCount: 2,
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewCatalogWriter(FormatCSV, &buf)
+	require.Nil(t, err)
+	require.Nil(t, writer.Write(entry))
+	require.Nil(t, writer.Close())
+	assert.True(t, strings.HasSuffix(strings.SplitN(buf.String(), "\n", 2)[0], "readers,rating,rating_count"))
+
+	reader, err := NewCatalogReader(FormatCSV, &buf)
+	require.Nil(t, err)
+	got, err := reader.Read()
+	require.Nil(t, err)
+
+	// Reader counts and ratings are exported, but never imported.
+	entry.Readers, entry.Rating, entry.RatingCount = 0, 0, 0
+	assert.Equal(t, entry, got, "an exported record didn't import back the same")
+}
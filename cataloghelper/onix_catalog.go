@@ -0,0 +1,481 @@
+package cataloghelper
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Code list values from ONIX for Books 3.0. Only the reference tag names are
+// supported, short tag feeds have to be converted first.
+const (
+	onixIDTypeISBN10       = "02"
+	onixIDTypeISBN13       = "15"
+	onixTitleTypeDistinct  = "01"
+	onixTextTypeDesc       = "03"
+	onixResourceFrontCover = "01"
+	onixResourceFormLink   = "02"
+	onixLanguageRoleText   = "01"
+	onixExtentTypeMain     = "00"
+	onixExtentUnitPages    = "03"
+	onixPublishingRolePub  = "01"
+	onixDateRolePublished  = "01"
+	onixDateFormat         = "20060102"
+)
+
+// ProductForm codes (list 150) for each of our formats. Reading maps every
+// code in a family onto the format, writing uses the first one listed.
+var onixProductForms = map[string][]string{
+	database.FormatHardcover: {"BB"},
+	database.FormatPaperback: {"BC"},
+	database.FormatEbook:     {"ED", "EA"},
+	database.FormatAudiobook: {"AJ", "AN"},
+}
+
+// ContributorRole codes (list 17) for each credit role. Other authorship
+// codes (A01 to A99) are read as authors, the remaining roles are skipped.
+var onixContributorRoles = map[string]string{
+	"A01": database.CreditAuthor,
+	"A12": database.CreditIllustrator,
+	"B06": database.CreditTranslator,
+	"B01": database.CreditEditor,
+}
+
+// ONIX language codes are ISO 639-2/B, which only differs from the
+// terminology codes x/text/language produces for these languages.
+var onixBibliographicLanguages = map[string]string{
+	"sqi": "alb", "hye": "arm", "eus": "baq", "mya": "bur", "zho": "chi",
+	"ces": "cze", "nld": "dut", "fra": "fre", "kat": "geo", "deu": "ger",
+	"ell": "gre", "isl": "ice", "mkd": "mac", "mri": "mao", "msa": "may",
+	"fas": "per", "ron": "rum", "slk": "slo", "bod": "tib", "cym": "wel",
+}
+
+type onixProduct struct {
+	XMLName            xml.Name                `xml:"Product"`
+	RecordReference    string                  `xml:"RecordReference"`
+	NotificationType   string                  `xml:"NotificationType"`
+	ProductIdentifiers []onixProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  onixDescriptiveDetail   `xml:"DescriptiveDetail"`
+	CollateralDetail   *onixCollateralDetail   `xml:"CollateralDetail,omitempty"`
+	PublishingDetail   *onixPublishingDetail   `xml:"PublishingDetail,omitempty"`
+}
+
+type onixProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+type onixDescriptiveDetail struct {
+	ProductComposition string            `xml:"ProductComposition"`
+	ProductForm        string            `xml:"ProductForm"`
+	TitleDetails       []onixTitleDetail `xml:"TitleDetail"`
+	Contributors       []onixContributor `xml:"Contributor"`
+	EditionNumber      int               `xml:"EditionNumber,omitempty"`
+	EditionStatement   string            `xml:"EditionStatement,omitempty"`
+	Languages          []onixLanguage    `xml:"Language"`
+	Extents            []onixExtent      `xml:"Extent"`
+}
+
+type onixLanguage struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"`
+}
+
+type onixExtent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue int    `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+type onixTitleDetail struct {
+	TitleType     string             `xml:"TitleType"`
+	TitleElements []onixTitleElement `xml:"TitleElement"`
+}
+
+type onixTitleElement struct {
+	TitleElementLevel string `xml:"TitleElementLevel"`
+	TitlePrefix       string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPref  string `xml:"TitleWithoutPrefix,omitempty"`
+	TitleText         string `xml:"TitleText,omitempty"`
+	Subtitle          string `xml:"Subtitle,omitempty"`
+}
+
+type onixContributor struct {
+	SequenceNumber  int    `xml:"SequenceNumber,omitempty"`
+	ContributorRole string `xml:"ContributorRole"`
+	PersonName      string `xml:"PersonName,omitempty"`
+	CorporateName   string `xml:"CorporateName,omitempty"`
+}
+
+type onixCollateralDetail struct {
+	TextContents        []onixTextContent        `xml:"TextContent"`
+	SupportingResources []onixSupportingResource `xml:"SupportingResource"`
+}
+
+type onixTextContent struct {
+	TextType        string `xml:"TextType"`
+	ContentAudience string `xml:"ContentAudience"`
+	Text            string `xml:"Text"`
+}
+
+type onixSupportingResource struct {
+	ResourceContentType string                `xml:"ResourceContentType"`
+	ContentAudience     string                `xml:"ContentAudience"`
+	ResourceMode        string                `xml:"ResourceMode"`
+	ResourceVersions    []onixResourceVersion `xml:"ResourceVersion"`
+}
+
+type onixResourceVersion struct {
+	ResourceForm string `xml:"ResourceForm"`
+	ResourceLink string `xml:"ResourceLink"`
+}
+
+type onixPublishingDetail struct {
+	Publishers      []onixPublisher      `xml:"Publisher"`
+	PublishingDates []onixPublishingDate `xml:"PublishingDate"`
+}
+
+type onixPublisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+type onixPublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               string `xml:"Date"`
+}
+
+// ErrONIXReleaseUnsupported is returned for ONIX 2.1 feeds, whose products
+// are laid out differently, and for short tag feeds.
+var ErrONIXReleaseUnsupported = errors.New("only ONIX 3.0 feeds with reference tags are supported")
+
+type onixReader struct {
+	decoder *xml.Decoder
+}
+
+func newONIXReader(r io.Reader) *onixReader {
+	return &onixReader{xml.NewDecoder(r)}
+}
+
+// Read decodes one <Product> at a time, so a feed never has to fit in memory.
+func (o *onixReader) Read() (database.CatalogEntry, error) {
+	for {
+		token, err := o.decoder.Token()
+		if err != nil {
+			return database.CatalogEntry{}, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "ONIXMessage":
+			if !isONIX3(start) {
+				return database.CatalogEntry{}, ErrONIXReleaseUnsupported
+			}
+			continue
+		case "ONIXmessage":
+			return database.CatalogEntry{}, ErrONIXReleaseUnsupported
+		case "Product":
+		default:
+			continue
+		}
+
+		var product onixProduct
+		if err := o.decoder.DecodeElement(&product, &start); err != nil {
+			return database.CatalogEntry{}, err
+		}
+		return product.toEntry()
+	}
+}
+
+// isONIX3 tells a 3.x message, which has to name its release, from a 2.1 one.
+func isONIX3(message xml.StartElement) bool {
+	for _, attr := range message.Attr {
+		if attr.Name.Local == "release" {
+			return strings.HasPrefix(attr.Value, "3.")
+		}
+	}
+	return false
+}
+
+func (p onixProduct) toEntry() (database.CatalogEntry, error) {
+	entry := database.CatalogEntry{}
+
+	for _, id := range p.ProductIdentifiers {
+		if id.ProductIDType == onixIDTypeISBN13 || (id.ProductIDType == onixIDTypeISBN10 && entry.ISBN == "") {
+			entry.ISBN = strings.TrimSpace(id.IDValue)
+		}
+	}
+	if entry.ISBN == "" {
+		return entry, fmt.Errorf("%w: product %q has no isbn", ErrRecordMalformed, p.RecordReference)
+	}
+
+	for _, title := range p.DescriptiveDetail.TitleDetails {
+		if title.TitleType != onixTitleTypeDistinct || len(title.TitleElements) == 0 {
+			continue
+		}
+		element := title.TitleElements[0]
+		entry.Title = element.TitleText
+		if entry.Title == "" {
+			entry.Title = strings.TrimSpace(element.TitlePrefix + " " + element.TitleWithoutPref)
+		}
+		if element.Subtitle != "" {
+			entry.Title += ": " + element.Subtitle
+		}
+		break
+	}
+
+	contributors := p.DescriptiveDetail.Contributors
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return contributors[i].SequenceNumber < contributors[j].SequenceNumber
+	})
+	var authors []string
+	for _, c := range contributors {
+		name := strings.TrimSpace(c.PersonName)
+		if name == "" {
+			name = strings.TrimSpace(c.CorporateName)
+		}
+		role, ok := onixContributorRoles[c.ContributorRole]
+		if !ok && strings.HasPrefix(c.ContributorRole, "A") {
+			role, ok = database.CreditAuthor, true
+		}
+		if !ok || name == "" {
+			continue
+		}
+
+		entry.Authors = append(entry.Authors, database.BookAuthor{Name: name, Role: role})
+		// Only authorship goes into the display string, translators and
+		// the like are listed in the credits alone.
+		if strings.HasPrefix(c.ContributorRole, "A") && role != database.CreditIllustrator {
+			authors = append(authors, name)
+		}
+	}
+	entry.Author = strings.Join(authors, ", ")
+
+	detail := p.DescriptiveDetail
+	for format, forms := range onixProductForms {
+		for _, form := range forms {
+			if detail.ProductForm == form {
+				entry.Format = format
+			}
+		}
+	}
+	entry.Edition = strings.TrimSpace(detail.EditionStatement)
+	if entry.Edition == "" && detail.EditionNumber > 0 {
+		entry.Edition = strconv.Itoa(detail.EditionNumber)
+	}
+	for _, lang := range detail.Languages {
+		if lang.LanguageRole == onixLanguageRoleText {
+			entry.Language = strings.TrimSpace(lang.LanguageCode)
+			break
+		}
+	}
+	for _, extent := range detail.Extents {
+		if extent.ExtentType == onixExtentTypeMain && extent.ExtentUnit == onixExtentUnitPages {
+			entry.PageCount = extent.ExtentValue
+			break
+		}
+	}
+
+	if p.CollateralDetail != nil {
+		for _, text := range p.CollateralDetail.TextContents {
+			if text.TextType == onixTextTypeDesc {
+				entry.Summary = strings.TrimSpace(text.Text)
+				break
+			}
+		}
+		for _, resource := range p.CollateralDetail.SupportingResources {
+			if resource.ResourceContentType != onixResourceFrontCover {
+				continue
+			}
+			for _, version := range resource.ResourceVersions {
+				if version.ResourceForm == onixResourceFormLink {
+					entry.Cover = strings.TrimSpace(version.ResourceLink)
+					break
+				}
+			}
+		}
+	}
+
+	if p.PublishingDetail != nil {
+		for _, publisher := range p.PublishingDetail.Publishers {
+			if publisher.PublishingRole == onixPublishingRolePub {
+				entry.Publisher = strings.TrimSpace(publisher.PublisherName)
+				break
+			}
+		}
+		for _, date := range p.PublishingDetail.PublishingDates {
+			if date.PublishingDateRole != onixDateRolePublished {
+				continue
+			}
+			publishedOn, err := time.Parse(onixDateFormat, strings.TrimSpace(date.Date))
+			if err != nil {
+				return entry, fmt.Errorf("%w: product %q has a malformed publication date", ErrRecordMalformed, p.RecordReference)
+			}
+			entry.PublishedOn = &publishedOn
+			break
+		}
+	}
+
+	return entry, nil
+}
+
+type onixWriter struct {
+	w       io.Writer
+	encoder *xml.Encoder
+}
+
+func newONIXWriter(w io.Writer) (*onixWriter, error) {
+	header := xml.Header +
+		`<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">` + "\n" +
+		"  <Header>\n" +
+		"    <Sender><SenderName>eLibrary</SenderName></Sender>\n" +
+		"    <SentDateTime>" + time.Now().UTC().Format("20060102T1504Z") + "</SentDateTime>\n" +
+		"  </Header>\n"
+	if _, err := io.WriteString(w, header); err != nil {
+		return nil, err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("  ", "  ")
+	return &onixWriter{w, encoder}, nil
+}
+
+// Write emits one <Product>. ONIX has no element for reader counts or ratings,
+// those are only part of the CSV export.
+func (o *onixWriter) Write(entry database.CatalogEntry) error {
+	productForm := "00"
+	if forms, ok := onixProductForms[entry.Format]; ok {
+		productForm = forms[0]
+	}
+
+	product := onixProduct{
+		RecordReference:  entry.ISBN,
+		NotificationType: "03",
+		ProductIdentifiers: []onixProductIdentifier{
+			{ProductIDType: onixIDTypeISBN13, IDValue: entry.ISBN},
+		},
+		DescriptiveDetail: onixDescriptiveDetail{
+			ProductComposition: "00",
+			ProductForm:        productForm,
+			TitleDetails: []onixTitleDetail{{
+				TitleType: onixTitleTypeDistinct,
+				TitleElements: []onixTitleElement{{
+					TitleElementLevel: "01",
+					TitleText:         entry.Title,
+				}},
+			}},
+			Contributors:     onixContributors(entry),
+			EditionStatement: entry.Edition,
+		},
+	}
+	if entry.Language != "" {
+		if tag, err := language.Parse(entry.Language); err == nil {
+			base, _ := tag.Base()
+			code := base.ISO3()
+			if bibliographic, ok := onixBibliographicLanguages[code]; ok {
+				code = bibliographic
+			}
+			product.DescriptiveDetail.Languages = []onixLanguage{{
+				LanguageRole: onixLanguageRoleText,
+				LanguageCode: code,
+			}}
+		}
+	}
+	if entry.PageCount > 0 {
+		product.DescriptiveDetail.Extents = []onixExtent{{
+			ExtentType:  onixExtentTypeMain,
+			ExtentValue: entry.PageCount,
+			ExtentUnit:  onixExtentUnitPages,
+		}}
+	}
+
+	if entry.Summary != "" || entry.Cover != "" {
+		collateral := &onixCollateralDetail{}
+		if entry.Summary != "" {
+			collateral.TextContents = []onixTextContent{{
+				TextType:        onixTextTypeDesc,
+				ContentAudience: "00",
+				Text:            entry.Summary,
+			}}
+		}
+		if entry.Cover != "" {
+			collateral.SupportingResources = []onixSupportingResource{{
+				ResourceContentType: onixResourceFrontCover,
+				ContentAudience:     "00",
+				ResourceMode:        "03",
+				ResourceVersions: []onixResourceVersion{{
+					ResourceForm: onixResourceFormLink,
+					ResourceLink: entry.Cover,
+				}},
+			}}
+		}
+		product.CollateralDetail = collateral
+	}
+
+	if entry.Publisher != "" || entry.PublishedOn != nil {
+		publishing := &onixPublishingDetail{}
+		if entry.Publisher != "" {
+			publishing.Publishers = []onixPublisher{{
+				PublishingRole: onixPublishingRolePub,
+				PublisherName:  entry.Publisher,
+			}}
+		}
+		if entry.PublishedOn != nil {
+			publishing.PublishingDates = []onixPublishingDate{{
+				PublishingDateRole: onixDateRolePublished,
+				Date:               entry.PublishedOn.Format(onixDateFormat),
+			}}
+		}
+		product.PublishingDetail = publishing
+	}
+
+	return o.encoder.Encode(product)
+}
+
+// onixContributors lists the entry's credits, or its author string as a single
+// author when it has none.
+func onixContributors(entry database.CatalogEntry) []onixContributor {
+	if len(entry.Authors) == 0 {
+		return []onixContributor{{
+			SequenceNumber:  1,
+			ContributorRole: "A01",
+			PersonName:      entry.Author,
+		}}
+	}
+
+	contributors := make([]onixContributor, 0, len(entry.Authors))
+	for i, credit := range entry.Authors {
+		code := "A01"
+		for c, role := range onixContributorRoles {
+			if role == credit.Role {
+				code = c
+				break
+			}
+		}
+		contributors = append(contributors, onixContributor{
+			SequenceNumber:  i + 1,
+			ContributorRole: code,
+			PersonName:      credit.Name,
+		})
+	}
+	return contributors
+}
+
+func (o *onixWriter) Close() error {
+	if err := o.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(o.w, "\n</ONIXMessage>\n")
+	return err
+}
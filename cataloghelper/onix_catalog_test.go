@@ -0,0 +1,197 @@
+package cataloghelper
+
+import (
+	"bytes"
+	"ic-rhadi/e_library/database"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onixFeed wraps products in an ONIX 3.0 message.
+func onixFeed(products ...string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">
+  <Header><Sender><SenderName>Test</SenderName></Sender></Header>
+` + strings.Join(products, "\n") + `
+</ONIXMessage>`
+}
+
+const onixFullProduct = `<Product>
+  <RecordReference>ref-1</RecordReference>
+  <ProductIdentifier><ProductIDType>02</ProductIDType><IDValue>0306406152</IDValue></ProductIdentifier>
+  <ProductIdentifier><ProductIDType>15</ProductIDType><IDValue>9780306406157</IDValue></ProductIdentifier>
+  <DescriptiveDetail>
+    <ProductComposition>00</ProductComposition>
+    <ProductForm>EA</ProductForm>
+    <TitleDetail><TitleType>01</TitleType><TitleElement>
+      <TitleElementLevel>01</TitleElementLevel>
+      <TitlePrefix>The</TitlePrefix><TitleWithoutPrefix>Signal</TitleWithoutPrefix>
+      <Subtitle>And the Noise</Subtitle>
+    </TitleElement></TitleDetail>
+    <Contributor><SequenceNumber>3</SequenceNumber><ContributorRole>B06</ContributorRole><PersonName>Tran Slator</PersonName></Contributor>
+    <Contributor><SequenceNumber>2</SequenceNumber><ContributorRole>A02</ContributorRole><PersonName>Co Writer</PersonName></Contributor>
+    <Contributor><SequenceNumber>1</SequenceNumber><ContributorRole>A01</ContributorRole><PersonName>Ada Writer</PersonName></Contributor>
+    <Contributor><SequenceNumber>4</SequenceNumber><ContributorRole>Z99</ContributorRole><PersonName>Some One</PersonName></Contributor>
+    <EditionNumber>2</EditionNumber>
+    <Language><LanguageRole>01</LanguageRole><LanguageCode>eng</LanguageCode></Language>
+    <Extent><ExtentType>00</ExtentType><ExtentValue>320</ExtentValue><ExtentUnit>03</ExtentUnit></Extent>
+  </DescriptiveDetail>
+  <CollateralDetail>
+    <TextContent><TextType>03</TextType><ContentAudience>00</ContentAudience><Text> About it </Text></TextContent>
+    <SupportingResource>
+      <ResourceContentType>01</ResourceContentType><ContentAudience>00</ContentAudience><ResourceMode>03</ResourceMode>
+      <ResourceVersion><ResourceForm>02</ResourceForm><ResourceLink>https://example.com/c.jpg</ResourceLink></ResourceVersion>
+    </SupportingResource>
+  </CollateralDetail>
+  <PublishingDetail>
+    <Publisher><PublishingRole>01</PublishingRole><PublisherName>Pub</PublisherName></Publisher>
+    <PublishingDate><PublishingDateRole>01</PublishingDateRole><Date>19920501</Date></PublishingDate>
+  </PublishingDetail>
+</Product>`
+
+func TestONIXProduct(t *testing.T) {
+	reader, err := NewCatalogReader(FormatONIX, strings.NewReader(onixFeed(onixFullProduct)))
+	require.Nil(t, err)
+
+	entry, err := reader.Read()
+	require.Nil(t, err)
+	publishedOn := time.Date(1992, time.May, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, database.CatalogEntry{
+		ISBN:        "9780306406157",
+		Title:       "The Signal: And the Noise",
+		Author:      "Ada Writer, Co Writer",
+		Summary:     "About it",
+		Cover:       "https://example.com/c.jpg",
+		Publisher:   "Pub",
+		PublishedOn: &publishedOn,
+		Language:    "eng",
+		PageCount:   320,
+		Edition:     "2",
+		Format:      database.FormatEbook,
+		Authors: database.BookAuthors{
+			{Name: "Ada Writer", Role: database.CreditAuthor},
+			{Name: "Co Writer", Role: database.CreditAuthor},
+			{Name: "Tran Slator", Role: database.CreditTranslator},
+		},
+	}, entry, "the product wasn't read right")
+}
+
+func TestONIXMalformedProducts(t *testing.T) {
+	tests := []struct {
+		name    string
+		product string
+		expISBN string
+		expErr  error
+	}{
+		{
+			name:    "isbn-10 only",
+			product: `<Product><ProductIdentifier><ProductIDType>02</ProductIDType><IDValue>0306406152</IDValue></ProductIdentifier></Product>`,
+			expISBN: "0306406152",
+		},
+		{
+			name:    "proprietary id only",
+			product: `<Product><RecordReference>r</RecordReference><ProductIdentifier><ProductIDType>01</ProductIDType><IDValue>X1</IDValue></ProductIdentifier></Product>`,
+			expErr:  ErrRecordMalformed,
+		},
+		{
+			name: "malformed date",
+			product: `<Product><ProductIdentifier><ProductIDType>15</ProductIDType><IDValue>9780306406157</IDValue></ProductIdentifier>
+				<PublishingDetail><PublishingDate><PublishingDateRole>01</PublishingDateRole><Date>1992-05-01</Date></PublishingDate></PublishingDetail></Product>`,
+			expISBN: "9780306406157",
+			expErr:  ErrRecordMalformed,
+		},
+	}
+
+	for _, test := range tests {
+		reader, err := NewCatalogReader(FormatONIX, strings.NewReader(onixFeed(test.product)))
+		require.Nil(t, err)
+
+		entry, err := reader.Read()
+		if test.expErr == nil {
+			assert.Nil(t, err, "%s: unexpected error reading the product", test.name)
+		} else {
+			assert.ErrorIs(t, err, test.expErr, "%s: unexpected error reading the product", test.name)
+		}
+		assert.Equal(t, test.expISBN, entry.ISBN, "%s: the isbn wasn't read right", test.name)
+	}
+}
+
+func TestONIXReleases(t *testing.T) {
+	tests := []struct {
+		name   string
+		feed   string
+		expErr error
+	}{
+		{"3.0 reference tags", onixFeed(onixFullProduct), nil},
+		{
+			name: "2.1 reference tags",
+			feed: `<ONIXMessage xmlns="http://www.editeur.org/onix/2.1/reference"><Product>
+				<ProductIdentifier><ProductIDType>15</ProductIDType><IDValue>9780306406157</IDValue></ProductIdentifier>
+				<Title><TitleType>01</TitleType><TitleText>Signal</TitleText></Title>
+				<Contributor><ContributorRole>A01</ContributorRole><PersonName>Ada Writer</PersonName></Contributor>
+				</Product></ONIXMessage>`,
+			expErr: ErrONIXReleaseUnsupported,
+		},
+		{
+			name:   "3.0 short tags",
+			feed:   `<ONIXmessage release="3.0"><product><a001>ref</a001></product></ONIXmessage>`,
+			expErr: ErrONIXReleaseUnsupported,
+		},
+	}
+
+	for _, test := range tests {
+		reader, err := NewCatalogReader(FormatONIX, strings.NewReader(test.feed))
+		require.Nil(t, err)
+
+		_, err = reader.Read()
+		assert.Equal(t, test.expErr, err, "%s: unexpected error reading the feed", test.name)
+	}
+}
+
+func TestONIXBrokenXML(t *testing.T) {
+	feed := onixFeed(`<Product><ProductIdentifier><ProductIDType>15</ProductIDType>`)
+	reader, err := NewCatalogReader(FormatONIX, strings.NewReader(feed))
+	require.Nil(t, err)
+
+	_, err = reader.Read()
+	assert.NotNil(t, err, "a broken feed should've failed")
+	assert.NotErrorIs(t, err, ErrRecordMalformed, "a broken feed can't be read on, so it mustn't be a record error")
+}
+
+func TestONIXRoundTrip(t *testing.T) {
+	publishedOn := time.Date(1992, time.May, 1, 0, 0, 0, 0, time.UTC)
+	entries := []database.CatalogEntry{
+		{
+			ISBN: "9780306406157", Title: "Signal & Noise", Author: "Ada Writer", Summary: "About <it>",
+			Cover: "https://example.com/c.jpg", Publisher: "Pub", PublishedOn: &publishedOn,
+			PageCount: 320, Edition: "2nd", Format: database.FormatHardcover,
+			Authors: database.BookAuthors{
+				{Name: "Ada Writer", Role: database.CreditAuthor},
+				{Name: "Ill Ustrator", Role: database.CreditIllustrator},
+			},
+		},
+		{ISBN: "9781861972712", Title: "Second", Author: "Bo Writer"},
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewCatalogWriter(FormatONIX, &buf)
+	require.Nil(t, err)
+	for _, entry := range entries {
+		require.Nil(t, writer.Write(entry))
+	}
+	require.Nil(t, writer.Close())
+
+	reader, err := NewCatalogReader(FormatONIX, &buf)
+	require.Nil(t, err)
+	got, errs := readAll(t, reader)
+	require.Len(t, got, 2)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	// Without credits, the author string is written as the single author.
+	entries[1].Authors = database.BookAuthors{{Name: "Bo Writer", Role: database.CreditAuthor}}
+	assert.Equal(t, entries, got, "exported products didn't import back the same")
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"os/user"
+
+	"github.com/rs/zerolog/log"
+)
+
+// cliActor names the operator in the audit log, as there's no account
+// signed in.
+func cliActor() string {
+	if u, err := user.Current(); err == nil {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
+
+// recordAudit appends an event acted by the operator to the audit log.
+// Failing to record it is logged rather than failing the command, which has
+// already taken effect.
+func recordAudit(ctx context.Context, audit database.AuditInterface, action string, payload any) {
+	event := database.AuditEvent{
+		Action:    action,
+		Actor:     cliActor(),
+		UserAgent: "elibctl",
+	}
+	if payload != nil {
+		js, err := json.Marshal(payload)
+		if err != nil {
+			log.Error().Err(err).Str("action", action).Msg("Encoding audit event payload failed")
+		}
+		event.Payload = js
+	}
+
+	if err := audit.RecordAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		log.Error().Err(err).Str("action", action).Msg("Recording audit event failed")
+	}
+}
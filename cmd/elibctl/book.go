@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"ic-rhadi/e_library/cataloghelper"
+	"ic-rhadi/e_library/database"
+	"io"
+	"os"
+	"strings"
+)
+
+type importRowErrorResult struct {
+	Row     int    `json:"row"`
+	ISBN    string `json:"isbn,omitempty"`
+	Message string `json:"message"`
+}
+
+type importResult struct {
+	Job       string                 `json:"job_id"`
+	Status    string                 `json:"status"`
+	DryRun    bool                   `json:"dry_run"`
+	Processed int                    `json:"processed"`
+	Created   int                    `json:"created"`
+	Updated   int                    `json:"updated"`
+	Failed    int                    `json:"failed"`
+	Message   string                 `json:"message,omitempty"`
+	Errors    []importRowErrorResult `json:"errors,omitempty"`
+}
+
+func (i importResult) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "job %s: %s, %d rows processed, %d created, %d updated, %d failed",
+		i.Job, i.Status, i.Processed, i.Created, i.Updated, i.Failed)
+	for _, rowErr := range i.Errors {
+		fmt.Fprintf(&b, "\nrow %d (%s): %s", rowErr.Row, rowErr.ISBN, rowErr.Message)
+	}
+	return b.String()
+}
+
+type exportResult struct {
+	File   string `json:"file"`
+	Format string `json:"format"`
+}
+
+func (e exportResult) text() string {
+	return fmt.Sprintf("catalog written to %s as %s", e.File, e.Format)
+}
+
+// catalogImportAudit is the payload of the audit event of an import, like the
+// API's.
+type catalogImportAudit struct {
+	Job    string `json:"job_id"`
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+	Size   int64  `json:"size"`
+}
+
+func runBookImport(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("book import", flag.ContinueOnError)
+	format := flags.String("format", cataloghelper.FormatCSV, "feed format, csv or onix")
+	dryRun := flags.Bool("dry-run", false, "validate the feed without saving anything")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return nil, err
+	}
+	if !cataloghelper.IsCatalogFormat(*format) {
+		return nil, errUsage
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	res, err := importCatalog(ctx, db, *format, *dryRun, file, stat.Size())
+	if res.Job == "" {
+		return nil, err
+	}
+	return res, err
+}
+
+// importCatalog runs an import tracked as a job, just like the ones started
+// from the admin API.
+func importCatalog(ctx context.Context, db database.DB, format string, dryRun bool, source io.Reader, size int64) (importResult, error) {
+	job, err := db.CreateImportJob(ctx, format, dryRun, cliActor())
+	if err != nil {
+		return importResult{}, err
+	}
+	recordAudit(ctx, db, database.AuditCatalogImportStarted, catalogImportAudit{job.ID.String(), format, dryRun, size})
+
+	finished, importErr := cataloghelper.RunImport(ctx, db, *job, source, size)
+	res := importResult{
+		Job:       finished.ID.String(),
+		Status:    finished.Status,
+		DryRun:    finished.DryRun,
+		Processed: finished.Processed,
+		Created:   finished.Created,
+		Updated:   finished.Updated,
+		Failed:    finished.Failed,
+		Message:   finished.Message,
+	}
+	if finished.Failed > 0 {
+		if details, err := db.GetImportJob(ctx, job.ID); err == nil {
+			for _, rowErr := range details.Errors {
+				res.Errors = append(res.Errors, importRowErrorResult{rowErr.Row, rowErr.ISBN, rowErr.Message})
+			}
+		}
+	}
+	return res, importErr
+}
+
+func runBookExport(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("book export", flag.ContinueOnError)
+	format := flags.String("format", cataloghelper.FormatCSV, "output format, csv or onix")
+	output := flags.String("o", "", "file to write, instead of stdout")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return nil, err
+	}
+	if !cataloghelper.IsCatalogFormat(*format) {
+		return nil, errUsage
+	}
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Written to stdout, the catalog is the output.
+	if *output == "" {
+		return nil, cataloghelper.ExportCatalog(ctx, db, *format, os.Stdout)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return nil, err
+	}
+	if err := cataloghelper.ExportCatalog(ctx, db, *format, file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return exportResult{*output, *format}, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The jobs the server runs on tickers, which cleanup run can run once.
+const (
+	jobExpired         = "expired"
+	jobPopularity      = "popularity"
+	jobRecommendations = "recommendations"
+)
+
+type cleanupResult struct {
+	Jobs            []string `json:"jobs"`
+	SessionsDeleted int64    `json:"sessions_deleted"`
+	AccountsDeleted int64    `json:"accounts_deleted"`
+}
+
+func (c cleanupResult) text() string {
+	return fmt.Sprintf("ran %s: %d expired sessions and %d never activated accounts deleted",
+		strings.Join(c.Jobs, ", "), c.SessionsDeleted, c.AccountsDeleted)
+}
+
+func runCleanup(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("cleanup run", flag.ContinueOnError)
+	only := flags.String("only", "", "run a single job: expired, popularity or recommendations")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return nil, err
+	}
+	jobs := []string{jobExpired, jobPopularity, jobRecommendations}
+	switch *only {
+	case "":
+	case jobExpired, jobPopularity, jobRecommendations:
+		jobs = []string{*only}
+	default:
+		return nil, errUsage
+	}
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	res := cleanupResult{Jobs: []string{}}
+	for _, job := range jobs {
+		switch job {
+		case jobExpired:
+			deleted, err := db.CleanupExpired(ctx, time.Now())
+			res.SessionsDeleted, res.AccountsDeleted = deleted.Sessions, deleted.Accounts
+			if err != nil {
+				return res, err
+			}
+		case jobPopularity:
+			if err := db.RefreshPopularity(ctx); err != nil {
+				return res, fmt.Errorf("refreshing book popularity: %w", err)
+			}
+		case jobRecommendations:
+			if err := db.RefreshBookNeighbors(ctx); err != nil {
+				return res, fmt.Errorf("refreshing book neighbors: %w", err)
+			}
+		}
+		res.Jobs = append(res.Jobs, job)
+	}
+	return res, nil
+}
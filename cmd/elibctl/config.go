@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"ic-rhadi/e_library/confighelper"
+	"strings"
+)
+
+type configResult struct {
+	settings []confighelper.Setting
+	problems confighelper.ValidationError
+}
+
+// text writes the settings the way an env file would, the problems with
+// them as comments after.
+func (c configResult) text() string {
+	lines := make([]string, 0, len(c.settings)+len(c.problems))
+	for _, s := range c.settings {
+		lines = append(lines, s.Key+"="+s.Value)
+	}
+	for _, fe := range c.problems {
+		lines = append(lines, "# "+fe.Key+" "+fe.Problem)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type configProblem struct {
+	Key     string `json:"key"`
+	Problem string `json:"problem"`
+}
+
+// MarshalJSON writes the settings by key, and the problems under errors,
+// which no key is named.
+func (c configResult) MarshalJSON() ([]byte, error) {
+	values := make(map[string]any, len(c.settings)+1)
+	for _, s := range c.settings {
+		values[s.Key] = s.Value
+	}
+	if len(c.problems) > 0 {
+		problems := make([]configProblem, len(c.problems))
+		for i, fe := range c.problems {
+			problems[i] = configProblem{fe.Key, fe.Problem}
+		}
+		values["errors"] = problems
+	}
+	return json.Marshal(values)
+}
+
+// runConfigPrint prints the configuration as loaded, defaults and all, to
+// check what the server will run with. An invalid configuration is printed
+// as far as it could be read, with its problems, and fails the command.
+func runConfigPrint(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := flags.Bool("redacted", false, "hide the secrets")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return nil, err
+	}
+
+	res := configResult{settings: env.conf.Settings(), problems: env.problems}
+	if *redacted {
+		for i, s := range res.settings {
+			res.settings[i] = s.Redacted()
+		}
+	}
+	if len(env.problems) > 0 {
+		return res, env.problems
+	}
+	return res, nil
+}
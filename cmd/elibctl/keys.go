@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// signingKeyBytes is the size of a generated session signing key, the 512
+// bits HS256 hashes blocks of.
+const signingKeyBytes = 64
+
+type keysResult struct {
+	Secret     string `json:"secret,omitempty"`
+	EnvFile    string `json:"env_file,omitempty"`
+	SecretFile string `json:"secret_file,omitempty"`
+	Revoked    int64  `json:"revoked"`
+}
+
+func (k keysResult) text() string {
+	where := "JWTSECRET=" + strconv.Quote(k.Secret)
+	switch {
+	case k.SecretFile != "":
+		where = "JWTSECRET written to " + k.SecretFile
+	case k.EnvFile != "":
+		where = "JWTSECRET written to " + k.EnvFile
+	}
+	return fmt.Sprintf("%s\n%d sessions revoked, restart the server to sign with the new key", where, k.Revoked)
+}
+
+type keysRotatedAudit struct {
+	Revoked int64 `json:"revoked"`
+}
+
+var errKeysEnvFileShadowed = errors.New("JWTSECRET is read from JWTSECRET_FILE, which the new key is written to; drop -env-file")
+
+// runKeysRotate replaces the key sessions are signed with. Tokens signed with
+// the old one stop verifying once the server restarts with the new one, so
+// the refresh tokens are revoked first: a key written without them being
+// revoked would leave sessions that fail instead of asking to sign in again.
+// A JWTSECRET_FILE takes precedence over JWTSECRET, so the key goes there
+// when it's set.
+func runKeysRotate(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("keys rotate", flag.ContinueOnError)
+	envFile := flags.String("env-file", "", "env file to write the new JWTSECRET to, instead of printing it")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return nil, err
+	}
+
+	secretFile := env.conf.SecretFiles["JWTSECRET"]
+	if secretFile != "" && *envFile != "" {
+		return nil, errKeysEnvFileShadowed
+	}
+	// The file is checked before revoking anything, so a typo doesn't sign
+	// everyone out for a key that can't be saved.
+	for _, path := range []string{secretFile, *envFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
+		}
+	}
+
+	key := make([]byte, signingKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	secret := base64.StdEncoding.EncodeToString(key)
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	res := keysResult{}
+	res.Revoked, err = db.RevokeAllSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, db, database.AuditSigningKeyRotated, keysRotatedAudit{res.Revoked})
+
+	switch {
+	case secretFile != "":
+		err = writeSecretFile(secretFile, secret)
+		res.SecretFile = secretFile
+	case *envFile != "":
+		err = setEnvKey(*envFile, "JWTSECRET", secret)
+		res.EnvFile = *envFile
+	default:
+		res.Secret = secret
+	}
+	if err != nil {
+		// The sessions are gone already, the key mustn't be lost with them.
+		res.Secret, res.SecretFile, res.EnvFile = secret, "", ""
+		return res, err
+	}
+	return res, nil
+}
+
+// writeSecretFile replaces the content of the secret file at path with value,
+// keeping its permissions.
+func writeSecretFile(path string, value string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(value+"\n"), stat.Mode().Perm())
+}
+
+// setEnvKey sets key to value in the env file at path, replacing the line
+// that set it or adding one.
+func setEnvKey(path string, key string, value string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	line := key + "=" + strconv.Quote(value)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	found := false
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), key+"=") {
+			lines[i] = line
+			found = true
+		}
+	}
+	if !found {
+		lines = append(lines, line)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), stat.Mode().Perm())
+}
@@ -0,0 +1,194 @@
+// Command elibctl runs the operational tasks of the e-library against the
+// database the server is configured with.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"ic-rhadi/e_library/confighelper"
+	"ic-rhadi/e_library/database"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// command is an elibctl subcommand. run parses its own flags out of args and
+// returns what to print, or nil when it wrote its output itself or failed
+// before doing anything.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, env *environment, args []string) (result, error)
+}
+
+var commands = []command{
+	{"migrate", "", "run the newest schema file", runMigrate},
+	{"user create", "[-name name] [-role user|support|admin] [-activate=false] <email>, reading the password from stdin", "create an account", runUserCreate},
+	{"user activate", "<email>", "activate an account", runUserActivate},
+	{"user set-role", "-role user|support|admin <email>", "change an account's role", runUserSetRole},
+	{"user revoke-sessions", "<email>", "sign an account out of every device", runUserRevokeSessions},
+	{"book import", "[-format csv|onix] [-dry-run] <file>", "import a catalog feed", runBookImport},
+	{"book export", "[-format csv|onix] [-o file]", "export the catalog, to stdout by default", runBookExport},
+	{"cleanup run", "[-only expired|popularity|recommendations]", "run the server's background jobs once", runCleanup},
+	{"keys rotate", "[-env-file file]", "replace the session signing key and sign everyone out", runKeysRotate},
+	{"seed", "[-password password]", "add demo books and accounts", runSeed},
+	{"config print", "[-redacted]", "print the configuration, defaults included", runConfigPrint},
+}
+
+// errUsage is returned by commands called with the wrong arguments.
+var errUsage = errors.New("wrong arguments")
+
+// environment is what the commands share: the configuration and, once a
+// command asks for it, the database.
+type environment struct {
+	conf     confighelper.Config
+	problems confighelper.ValidationError
+	db       database.DB
+}
+
+// connect opens the database. Statements are only prepared when prepare is
+// set, since preparing fails until the schema has been migrated.
+func (e *environment) connect(ctx context.Context, prepare bool) (database.DB, error) {
+	db, err := database.StartDB(ctx, e.conf.Database.DSN(), e.conf.Database.Options())
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the database: %w", err)
+	}
+	e.db = db
+	if prepare {
+		if err := db.PrepareStatements(ctx); err != nil {
+			return nil, fmt.Errorf("preparing statements, has `elibctl migrate` been run? %w", err)
+		}
+	}
+	return db, nil
+}
+
+func (e *environment) close() {
+	if e.db != nil {
+		e.db.CloseDB()
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	// Results go to stdout, so they can be piped; the logs stay out of them.
+	log.Logger = zerolog.New(zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+		w.Out = os.Stderr
+	})).With().Timestamp().Logger()
+
+	flags := flag.NewFlagSet("elibctl", flag.ContinueOnError)
+	jsonOut := flags.Bool("json", false, "print results as JSON, for scripts")
+	flags.Usage = printUsage
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, cmdArgs, found := findCommand(flags.Args())
+	if !found {
+		printUsage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// config print runs with an invalid configuration too, to show it along
+	// with what's wrong with it.
+	conf, err := confighelper.Load(ctx)
+	var problems confighelper.ValidationError
+	if err != nil && !(errors.As(err, &problems) && cmd.name == "config print") {
+		return fail(*jsonOut, fmt.Errorf("loading configuration: %w", err))
+	}
+	env := &environment{conf: conf, problems: problems}
+	defer env.close()
+
+	res, err := cmd.run(ctx, env, cmdArgs)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "usage: elibctl %s %s\n", cmd.name, cmd.usage)
+		return 2
+	}
+	if res == nil {
+		if err != nil {
+			return fail(*jsonOut, err)
+		}
+		return 0
+	}
+	// A command failing part way may still have something to show, like the
+	// rows an import got through.
+	if printErr := printResult(*jsonOut, res); printErr != nil && err == nil {
+		err = printErr
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Command failed")
+		return 1
+	}
+	return 0
+}
+
+// findCommand matches the longest command name args start with.
+func findCommand(args []string) (command, []string, bool) {
+	for words := min(2, len(args)); words > 0; words-- {
+		name := strings.Join(args[:words], " ")
+		for _, cmd := range commands {
+			if cmd.name == name {
+				return cmd, args[words:], true
+			}
+		}
+	}
+	return command{}, nil, false
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: elibctl [-json] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// result is what a command prints: its JSON encoding with -json, its text
+// otherwise.
+type result interface {
+	text() string
+}
+
+func printResult(jsonOut bool, res result) error {
+	if jsonOut {
+		return json.NewEncoder(os.Stdout).Encode(res)
+	}
+	_, err := fmt.Println(res.text())
+	return err
+}
+
+// fail reports err, on stdout as well with -json so scripts don't have to
+// read the logs, and returns the exit code.
+func fail(jsonOut bool, err error) int {
+	log.Error().Err(err).Msg("Command failed")
+	if jsonOut {
+		json.NewEncoder(os.Stdout).Encode(map[string]string{"error": err.Error()})
+	}
+	return 1
+}
+
+// parseFlags parses args into flags, reporting errUsage unless exactly
+// positional arguments are left.
+func parseFlags(flags *flag.FlagSet, args []string, positional int) error {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != positional {
+		return errUsage
+	}
+	return nil
+}
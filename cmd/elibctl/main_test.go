@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/cataloghelper"
+	"ic-rhadi/e_library/confighelper"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCommand(t *testing.T) {
+	cmd, args, found := findCommand([]string{"user", "set-role", "-role", "admin", "someone@example.com"})
+	if assert.True(t, found, "a two word command wasn't found") {
+		assert.Equal(t, "user set-role", cmd.name)
+		assert.Equal(t, []string{"-role", "admin", "someone@example.com"}, args, "the command's arguments weren't kept")
+	}
+
+	cmd, args, found = findCommand([]string{"seed", "-password", "x"})
+	if assert.True(t, found, "a one word command wasn't found") {
+		assert.Equal(t, "seed", cmd.name)
+		assert.Equal(t, []string{"-password", "x"}, args)
+	}
+
+	for _, args := range [][]string{{}, {"user"}, {"user", "delete"}, {"book"}} {
+		_, _, found := findCommand(args)
+		assert.False(t, found, "%v shouldn't have been a command", args)
+	}
+}
+
+func TestSetEnvKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	require.Nil(t, os.WriteFile(path, []byte("PORT=3000\nJWTSECRET=\"old\"\nPG_DB=librarydb"), 0o600))
+
+	require.Nil(t, setEnvKey(path, "JWTSECRET", "new+key/=="))
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "PORT=3000\nJWTSECRET=\"new+key/==\"\nPG_DB=librarydb\n", string(content), "the key wasn't replaced in place")
+
+	require.Nil(t, setEnvKey(path, "SMTP_HOST", "localhost"))
+	content, err = os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "PORT=3000\nJWTSECRET=\"new+key/==\"\nPG_DB=librarydb\nSMTP_HOST=\"localhost\"\n", string(content), "a missing key wasn't added")
+
+	stat, err := os.Stat(path)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o600), stat.Mode().Perm(), "the env file's permissions weren't kept")
+}
+
+func TestWriteSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt")
+	require.Nil(t, os.WriteFile(path, []byte("old\n"), 0o640))
+
+	require.Nil(t, writeSecretFile(path, "new+key/=="))
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "new+key/==\n", string(content), "the secret file wasn't replaced")
+
+	stat, err := os.Stat(path)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o640), stat.Mode().Perm(), "the secret file's permissions weren't kept")
+}
+
+// TestShadowedKeysRotate checks nothing is revoked for a key that would be
+// written where the server doesn't read it.
+func TestShadowedKeysRotate(t *testing.T) {
+	dir := t.TempDir()
+	env := &environment{conf: confighelper.Config{SecretFiles: map[string]string{"JWTSECRET": filepath.Join(dir, "jwt")}}}
+
+	_, err := runKeysRotate(context.Background(), env, []string{"-env-file", filepath.Join(dir, ".env")})
+	assert.Equal(t, errKeysEnvFileShadowed, err)
+	assert.Nil(t, env.db, "the database was connected to for a key that can't be saved")
+
+	env = &environment{}
+	_, err = runKeysRotate(context.Background(), env, []string{"-env-file", filepath.Join(dir, ".env")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Nil(t, env.db, "the database was connected to for a missing env file")
+}
+
+func TestInvalidConfigPrint(t *testing.T) {
+	env := &environment{
+		conf: confighelper.Config{JWTSecret: "short", Port: 3000},
+		problems: confighelper.ValidationError{
+			{Key: "JWTSECRET", Problem: "must be set, and at least 32 bytes long"},
+			{Key: "PG_PORT", Problem: "must be a whole number"},
+		},
+	}
+
+	res, err := runConfigPrint(context.Background(), env, []string{"-redacted"})
+	assert.Equal(t, env.problems, err, "an invalid configuration didn't fail the command")
+	require.NotNil(t, res, "an invalid configuration wasn't printed")
+	text := res.text()
+	assert.Contains(t, text, "JWTSECRET=[redacted]\n", "a secret wasn't redacted")
+	assert.Contains(t, text, "PORT=3000\n", "a valid setting wasn't printed")
+	assert.True(t, strings.HasSuffix(text, "\n# JWTSECRET must be set, and at least 32 bytes long\n# PG_PORT must be a whole number"), "the problems weren't printed after the settings:\n%s", text)
+
+	encoded, err := json.Marshal(res)
+	require.Nil(t, err)
+	var values map[string]any
+	require.Nil(t, json.Unmarshal(encoded, &values))
+	assert.Equal(t, "[redacted]", values["JWTSECRET"], "a secret wasn't redacted in JSON")
+	assert.Equal(t, []any{
+		map[string]any{"key": "JWTSECRET", "problem": "must be set, and at least 32 bytes long"},
+		map[string]any{"key": "PG_PORT", "problem": "must be a whole number"},
+	}, values["errors"], "the problems weren't printed in JSON")
+}
+
+// TestSeedCatalog keeps the demo books importable.
+func TestSeedCatalog(t *testing.T) {
+	reader, err := cataloghelper.NewCatalogReader(cataloghelper.FormatCSV, bytes.NewReader(seedCatalog))
+	require.Nil(t, err)
+
+	books := 0
+	for {
+		entry, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err, "a demo book couldn't be read")
+		assert.Nil(t, entry.Validate(), "demo book %q isn't valid", entry.Title)
+		books++
+	}
+	assert.NotZero(t, books, "there are no demo books")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+type migrateResult struct {
+	Version int `json:"version"`
+}
+
+func (m migrateResult) text() string {
+	return fmt.Sprintf("schema version %d applied", m.Version)
+}
+
+func runMigrate(ctx context.Context, env *environment, args []string) (result, error) {
+	if err := parseFlags(flag.NewFlagSet("migrate", flag.ContinueOnError), args, 0); err != nil {
+		return nil, err
+	}
+
+	db, err := env.connect(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	version, err := db.Migrate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return migrateResult{version}, nil
+}
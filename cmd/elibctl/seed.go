@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"ic-rhadi/e_library/cataloghelper"
+	"ic-rhadi/e_library/database"
+)
+
+// seedCatalog are public domain books to try the library with.
+//
+//go:embed seed_catalog.csv
+var seedCatalog []byte
+
+// seedAccounts are one account of each role.
+var seedAccounts = []struct {
+	email string
+	name  string
+	role  string
+}{
+	{"admin@example.com", "Demo Admin", database.RoleAdmin},
+	{"support@example.com", "Demo Support", database.RoleSupport},
+	{"reader@example.com", "Demo Reader", database.RoleUser},
+}
+
+type seedResult struct {
+	BooksCreated    int      `json:"books_created"`
+	BooksUpdated    int      `json:"books_updated"`
+	AccountsCreated []string `json:"accounts_created"`
+}
+
+func (s seedResult) text() string {
+	return fmt.Sprintf("%d books created, %d updated, %d accounts created %v",
+		s.BooksCreated, s.BooksUpdated, len(s.AccountsCreated), s.AccountsCreated)
+}
+
+// runSeed fills the library with demo data. Running it again updates the
+// books and leaves the existing accounts alone.
+func runSeed(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	password := flags.String("password", "Demo-pass1", "password of the demo accounts")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return nil, err
+	}
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := importCatalog(ctx, db, cataloghelper.FormatCSV, false, bytes.NewReader(seedCatalog), int64(len(seedCatalog)))
+	if err != nil {
+		return nil, err
+	}
+	res := seedResult{BooksCreated: imported.Created, BooksUpdated: imported.Updated, AccountsCreated: []string{}}
+
+	for _, account := range seedAccounts {
+		_, err := createAccount(ctx, env, db, account.email, account.name, *password, account.role, true)
+		if errors.Is(err, database.ErrAccountExisted) {
+			continue
+		}
+		if err != nil {
+			return res, err
+		}
+		res.AccountsCreated = append(res.AccountsCreated, account.email)
+	}
+	return res, nil
+}
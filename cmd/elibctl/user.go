@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"io"
+	"os"
+	"strings"
+)
+
+type userResult struct {
+	Email           string `json:"email"`
+	Name            string `json:"name,omitempty"`
+	Role            string `json:"role,omitempty"`
+	PreviousRole    string `json:"previous_role,omitempty"`
+	Activated       bool   `json:"activated"`
+	ActivationToken string `json:"activation_token,omitempty"`
+}
+
+func (u userResult) text() string {
+	state := "not activated, activation token " + u.ActivationToken
+	if u.Activated {
+		state = "activated"
+	}
+	if u.PreviousRole != "" {
+		return fmt.Sprintf("%s: role %s, was %s", u.Email, u.Role, u.PreviousRole)
+	}
+	if u.Role == "" {
+		return fmt.Sprintf("%s: %s", u.Email, state)
+	}
+	return fmt.Sprintf("%s: %s, %s", u.Email, u.Role, state)
+}
+
+type revokedSessionsResult struct {
+	Email   string `json:"email"`
+	Revoked int64  `json:"revoked"`
+}
+
+func (r revokedSessionsResult) text() string {
+	return fmt.Sprintf("%s: %d sessions revoked", r.Email, r.Revoked)
+}
+
+// The payloads of the audit events elibctl records about accounts.
+type accountAudit struct {
+	Account string `json:"account"`
+	Method  string `json:"method,omitempty"`
+}
+
+type roleAudit struct {
+	Account string `json:"account"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type revokedSessionsAudit struct {
+	Account string `json:"account"`
+	Revoked int64  `json:"revoked"`
+}
+
+var errPasswordMissing = errors.New("password missing from stdin")
+var errAccountAlreadyActivated = errors.New("account has already been activated")
+
+func runUserCreate(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("user create", flag.ContinueOnError)
+	name := flags.String("name", "", "display name, the email's local part by default")
+	role := flags.String("role", database.RoleUser, "user, support or admin")
+	activate := flags.Bool("activate", true, "activate the account right away")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return nil, err
+	}
+	email := flags.Arg(0)
+	if *name == "" {
+		*name, _, _ = strings.Cut(email, "@")
+	}
+
+	password, err := readPassword(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	return createAccount(ctx, env, db, email, *name, password, *role, *activate)
+}
+
+// createAccount registers an account with a password the way the API does,
+// then gives it role and activates it when asked.
+func createAccount(
+	ctx context.Context,
+	env *environment,
+	db database.DB,
+	email string,
+	name string,
+	password string,
+	role string,
+	activate bool,
+) (userResult, error) {
+	res := userResult{Email: email, Name: name, Role: database.RoleUser}
+
+	token, _, err := db.Register(ctx, email, password, name, env.conf.LoginLengths.ActivationLength)
+	if err != nil {
+		return res, err
+	}
+	recordAudit(ctx, db, database.AuditAccountRegistered, accountAudit{Account: email, Method: "cli"})
+
+	if role != database.RoleUser {
+		if err := db.SetAccountRole(ctx, email, role); err != nil {
+			return res, err
+		}
+		recordAudit(ctx, db, database.AuditRoleChanged, roleAudit{email, database.RoleUser, role})
+		res.Role = role
+	}
+
+	if !activate {
+		res.ActivationToken = token
+		return res, nil
+	}
+	if err := db.ActivateAccount(ctx, email); err != nil {
+		return res, err
+	}
+	recordAudit(ctx, db, database.AuditAccountActivated, accountAudit{Account: email})
+	res.Activated = true
+	return res, nil
+}
+
+// readPassword reads the first line of r, prompting for it when r is a
+// terminal.
+func readPassword(r *os.File) (string, error) {
+	if stat, err := r.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "password: ")
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errPasswordMissing
+	}
+	return password, nil
+}
+
+func runUserActivate(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("user activate", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1); err != nil {
+		return nil, err
+	}
+	email := flags.Arg(0)
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	activated, _, _, err := db.GetActivationData(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if activated {
+		return nil, errAccountAlreadyActivated
+	}
+	if err := db.ActivateAccount(ctx, email); err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, db, database.AuditAccountActivated, accountAudit{Account: email})
+	return userResult{Email: email, Activated: true}, nil
+}
+
+func runUserSetRole(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("user set-role", flag.ContinueOnError)
+	role := flags.String("role", "", "user, support or admin")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return nil, err
+	}
+	if *role == "" {
+		return nil, errUsage
+	}
+	email := flags.Arg(0)
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := db.GetAccountRole(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.SetAccountRole(ctx, email, *role); err != nil {
+		return nil, err
+	}
+	if previous != *role {
+		recordAudit(ctx, db, database.AuditRoleChanged, roleAudit{email, previous, *role})
+	}
+	return userResult{Email: email, Role: *role, PreviousRole: previous}, nil
+}
+
+func runUserRevokeSessions(ctx context.Context, env *environment, args []string) (result, error) {
+	flags := flag.NewFlagSet("user revoke-sessions", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1); err != nil {
+		return nil, err
+	}
+	email := flags.Arg(0)
+
+	db, err := env.connect(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.GetAccount(ctx, email); err != nil {
+		return nil, err
+	}
+	revoked, err := db.RevokeAccountSessions(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, db, database.AuditSessionsRevoked, revokedSessionsAudit{email, revoked})
+	return revokedSessionsResult{email, revoked}, nil
+}
@@ -0,0 +1,276 @@
+package confighelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/sethvargo/go-envconfig"
+)
+
+// Config is what the server and elibctl are configured with. Every key has a
+// default or is checked by Validate, so a loaded Config can be used as is.
+type Config struct {
+	JWTSecret    string       `env:"JWTSECRET"`
+	Database     Database     `env:""`
+	Port         int          `env:"PORT,default=3000"`
+	MetricsAddr  string       `env:"METRICS_ADDR,default=:9090"`
+	Server       Server       `env:""`
+	Readiness    Readiness    `env:""`
+	LoginLengths LoginLengths `env:""`
+	LoginLimits  LoginLimits  `env:""`
+	Rankings     Rankings     `env:""`
+	Catalog      Catalog      `env:""`
+	OPDS         OPDS         `env:""`
+
+	// SecretFiles are the paths the secrets were read from, by key.
+	SecretFiles map[string]string
+}
+
+// Database is where to connect, either a DATABASE_URL or the PG_ keys, with
+// the TLS and pool settings added to both and to the replicas.
+type Database struct {
+	URL             string        `env:"DATABASE_URL"`
+	ReplicaURLs     []string      `env:"DATABASE_REPLICA_URLS"`
+	MaxReplicaLag   time.Duration `env:"DB_REPLICA_MAX_LAG,default=5s"`
+	Host            string        `env:"PG_HOST,default=localhost"`
+	Port            int           `env:"PG_PORT,default=5432"`
+	User            string        `env:"PG_USER"`
+	Pass            string        `env:"PG_PASS"`
+	Name            string        `env:"PG_DB"`
+	SSLMode         string        `env:"PG_SSLMODE"`
+	SSLRootCert     string        `env:"PG_SSLROOTCERT"`
+	SSLCert         string        `env:"PG_SSLCERT"`
+	SSLKey          string        `env:"PG_SSLKEY"`
+	MaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS,default=25"`
+	MaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS,default=10"`
+	ConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME,default=30m"`
+	ConnMaxIdleTime time.Duration `env:"DB_CONN_MAX_IDLE_TIME,default=5m"`
+	ConnectTimeout  time.Duration `env:"DB_CONNECT_TIMEOUT,default=1m"`
+}
+
+type Server struct {
+	ReadTimeout     time.Duration `env:"SERVER_READ_TIMEOUT,default=1m"`
+	WriteTimeout    time.Duration `env:"SERVER_WRITE_TIMEOUT,default=2m"`
+	IdleTimeout     time.Duration `env:"SERVER_IDLE_TIMEOUT,default=2m"`
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT,default=30s"`
+	DrainDelay      time.Duration `env:"SERVER_DRAIN_DELAY,default=5s"`
+}
+
+type Readiness struct {
+	CheckTimeout  time.Duration `env:"READINESS_CHECK_TIMEOUT,default=2s"`
+	CacheDuration time.Duration `env:"READINESS_CACHE_DURATION,default=5s"`
+}
+
+type LoginLengths struct {
+	TokenLength           time.Duration `env:"TOKEN_DURATION,default=10m"`
+	SessionLength         time.Duration `env:"SESSION_DURATION,default=48h"`
+	ActivationLength      time.Duration `env:"ACTIVATION_DURATION,default=10m"`
+	DatabaseCleanupLength time.Duration `env:"DB_CLEANUP_DURATION,default=24h"`
+	ImpersonationLength   time.Duration `env:"IMPERSONATION_DURATION,default=15m"`
+}
+
+// LoginLimits refuse the password sign ins of an account or an address for
+// the rest of FailureWindow once MaxFailures of them failed within it.
+type LoginLimits struct {
+	MaxFailures   int           `env:"LOGIN_MAX_FAILURES,default=10"`
+	FailureWindow time.Duration `env:"LOGIN_FAILURE_WINDOW,default=15m"`
+}
+
+type Rankings struct {
+	NewArrivalWindow            time.Duration `env:"NEW_ARRIVAL_WINDOW,default=720h"`
+	PopularityRefreshLength     time.Duration `env:"POPULARITY_REFRESH_DURATION,default=1h"`
+	RecommendationRefreshLength time.Duration `env:"RECOMMENDATION_REFRESH_DURATION,default=6h"`
+}
+
+type Catalog struct {
+	ImportMaxSize int `env:"CATALOG_IMPORT_MAX_SIZE,default=104857600"`
+}
+
+type OPDS struct {
+	AcquisitionURL  string `env:"OPDS_ACQUISITION_URL"`
+	AcquisitionType string `env:"OPDS_ACQUISITION_TYPE,default=application/epub+zip"`
+	// CredentialCacheLength is how long credentials that signed in are
+	// trusted without checking the password again.
+	CredentialCacheLength time.Duration `env:"OPDS_CREDENTIAL_CACHE_DURATION,default=1m"`
+}
+
+// secretKeys can be read from the file their _FILE key names instead, like
+// JWTSECRET_FILE=/run/secrets/jwt, and are hidden by Redacted.
+var secretKeys = []string{"JWTSECRET", "DATABASE_URL", "DATABASE_REPLICA_URLS", "PG_PASS"}
+
+// configFileKey names the optional YAML or TOML file read under the
+// environment.
+const configFileKey = "CONFIG_FILE"
+
+// Load reads .env into the environment when there's one, without overriding
+// what's already set, then the Config. The environment takes precedence over
+// the CONFIG_FILE, which takes precedence over the defaults. With a
+// ValidationError the Config is still returned, as far as it could be read.
+func Load(ctx context.Context) (Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("loading .env file: %w", err)
+	}
+	return load(ctx, envconfig.OsLookuper())
+}
+
+func load(ctx context.Context, env envconfig.Lookuper) (Config, error) {
+	var conf Config
+
+	src := source{env: env, file: map[string]string{}, secrets: map[string]string{}, secretFiles: map[string]string{}}
+	var problems ValidationError
+	if path, ok := env.Lookup(configFileKey); ok && path != "" {
+		file, err := readConfigFile(path)
+		if err != nil {
+			return conf, err
+		}
+		src.file = file
+		problems = append(problems, checkFileKeys(file)...)
+	}
+	problems = append(problems, src.readSecretFiles()...)
+	problems = append(problems, checkValues(src)...)
+	// The rest is still read, the keys in error left to their defaults, so
+	// the configuration can be shown along with what's wrong with it.
+	for _, fe := range problems {
+		src.skipped = append(src.skipped, fe.Key)
+	}
+
+	if err := envconfig.ProcessWith(ctx, &conf, src); err != nil {
+		return conf, err
+	}
+	conf.SecretFiles = src.secretFiles
+	if len(problems) > 0 {
+		return conf, problems
+	}
+	if err := conf.Validate(); err != nil {
+		return conf, err
+	}
+	return conf, nil
+}
+
+// source looks keys up in the secret files first, then the environment, then
+// the config file. Empty values count as unset, so they get the default, and
+// so do the skipped keys.
+type source struct {
+	env         envconfig.Lookuper
+	file        map[string]string
+	secrets     map[string]string
+	secretFiles map[string]string
+	skipped     []string
+}
+
+func (s source) Lookup(key string) (string, bool) {
+	if slices.Contains(s.skipped, key) {
+		return "", false
+	}
+	if v, ok := s.secrets[key]; ok {
+		return v, true
+	}
+	if v, ok := s.env.Lookup(key); ok && v != "" {
+		return v, true
+	}
+	if v, ok := s.file[key]; ok && v != "" {
+		return v, true
+	}
+	return "", false
+}
+
+// readSecretFiles reads the secrets whose _FILE key is set.
+func (s source) readSecretFiles() ValidationError {
+	var problems ValidationError
+	for _, key := range secretKeys {
+		path, ok := s.Lookup(key + "_FILE")
+		if !ok {
+			continue
+		}
+		if _, ok := s.Lookup(key); ok {
+			problems = append(problems, FieldError{key + "_FILE", "can't be set along with " + key})
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, FieldError{key + "_FILE", err.Error()})
+			continue
+		}
+		s.secrets[key] = trimNewline(string(content))
+		s.secretFiles[key] = path
+	}
+	return problems
+}
+
+// FieldError is a problem with the value of a configuration key.
+type FieldError struct {
+	Key     string
+	Problem string
+}
+
+// ValidationError lists every problem found with the configuration, so they
+// can all be fixed at once.
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	msg := fmt.Sprintf("%d configuration problems:", len(v))
+	for _, fe := range v {
+		msg += fmt.Sprintf(" %s %s;", fe.Key, fe.Problem)
+	}
+	return msg[:len(msg)-1]
+}
+
+func (v *ValidationError) check(ok bool, key string, problem string) {
+	if !ok {
+		*v = append(*v, FieldError{key, problem})
+	}
+}
+
+// Validate checks the values are in range and fit together.
+func (c Config) Validate() error {
+	var v ValidationError
+	v.check(len(c.JWTSecret) >= 32, "JWTSECRET", "must be set, and at least 32 bytes long")
+	c.Database.validate(&v)
+	v.check(c.Port > 0 && c.Port < 65536, "PORT", "must be a port between 1 and 65535")
+	_, metricsPort, err := net.SplitHostPort(c.MetricsAddr)
+	v.check(err == nil && metricsPort != strconv.Itoa(c.Port), "METRICS_ADDR", "must be a host:port, on another port than PORT")
+
+	v.check(c.Server.ReadTimeout > 0, "SERVER_READ_TIMEOUT", "must be positive")
+	v.check(c.Server.WriteTimeout > 0, "SERVER_WRITE_TIMEOUT", "must be positive")
+	v.check(c.Server.IdleTimeout > 0, "SERVER_IDLE_TIMEOUT", "must be positive")
+	v.check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT", "must be positive")
+	v.check(c.Server.DrainDelay >= 0, "SERVER_DRAIN_DELAY", "can't be negative")
+	v.check(c.Readiness.CheckTimeout > 0, "READINESS_CHECK_TIMEOUT", "must be positive")
+	v.check(c.Readiness.CacheDuration >= 0, "READINESS_CACHE_DURATION", "can't be negative")
+
+	v.check(c.LoginLengths.TokenLength > 0, "TOKEN_DURATION", "must be positive")
+	v.check(c.LoginLengths.SessionLength >= c.LoginLengths.TokenLength, "SESSION_DURATION", "can't be shorter than TOKEN_DURATION")
+	v.check(c.LoginLengths.ActivationLength > 0, "ACTIVATION_DURATION", "must be positive")
+	v.check(c.LoginLengths.DatabaseCleanupLength >= time.Minute, "DB_CLEANUP_DURATION", "must be at least 1m")
+	v.check(c.LoginLengths.ImpersonationLength > 0 && c.LoginLengths.ImpersonationLength <= time.Hour, "IMPERSONATION_DURATION", "must be positive and at most 1h")
+
+	v.check(c.LoginLimits.MaxFailures > 0, "LOGIN_MAX_FAILURES", "must be positive")
+	v.check(c.LoginLimits.FailureWindow > 0, "LOGIN_FAILURE_WINDOW", "must be positive")
+
+	v.check(c.Rankings.NewArrivalWindow > 0, "NEW_ARRIVAL_WINDOW", "must be positive")
+	v.check(c.Rankings.PopularityRefreshLength >= time.Minute, "POPULARITY_REFRESH_DURATION", "must be at least 1m")
+	v.check(c.Rankings.RecommendationRefreshLength >= time.Minute, "RECOMMENDATION_REFRESH_DURATION", "must be at least 1m")
+
+	v.check(c.Catalog.ImportMaxSize > 0, "CATALOG_IMPORT_MAX_SIZE", "must be positive")
+
+	if c.OPDS.AcquisitionURL != "" {
+		u, err := url.Parse(c.OPDS.AcquisitionURL)
+		v.check(err == nil && u.IsAbs(), "OPDS_ACQUISITION_URL", "must be an absolute URL")
+	}
+	v.check(c.OPDS.AcquisitionType != "", "OPDS_ACQUISITION_TYPE", "must be set")
+	v.check(c.OPDS.CredentialCacheLength >= 0 && c.OPDS.CredentialCacheLength <= 10*time.Minute, "OPDS_CREDENTIAL_CACHE_DURATION", "can't be negative or over 10m")
+
+	if len(v) > 0 {
+		return v
+	}
+	return nil
+}
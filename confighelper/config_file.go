@@ -0,0 +1,144 @@
+package confighelper
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readConfigFile reads a YAML or TOML file into the keys it sets. Nested
+// tables are joined to their keys with underscores, so
+//
+//	server:
+//	  read_timeout: 1m
+//
+// sets SERVER_READ_TIMEOUT, just like the environment would.
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	values := map[string]string{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var tree map[string]any
+		if err := yaml.Unmarshal(content, &tree); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+		if err := flatten("", tree, values); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	case ".toml":
+		if err := parseTOML(content, values); err != nil {
+			return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]any, values map[string]string) error {
+	for k, v := range tree {
+		key := strings.ToUpper(prefix + k)
+		switch v := v.(type) {
+		case map[string]any:
+			if err := flatten(key+"_", v, values); err != nil {
+				return err
+			}
+		case []any:
+			return fmt.Errorf("%s: lists aren't supported", key)
+		case nil:
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// parseTOML reads the part of TOML configuration needs: tables, and keys set
+// to strings, numbers or booleans.
+func parseTOML(content []byte, values map[string]string) error {
+	prefix := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			table, ok := strings.CutSuffix(stripTOMLComment(line), "]")
+			if !ok || strings.HasPrefix(table, "[[") {
+				return fmt.Errorf("line %d: only [tables] are supported", n)
+			}
+			prefix = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(table[1:]), ".", "_")) + "_"
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("line %d: expected key = value", n)
+		}
+		key = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(key), ".", "_"))
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		values[prefix+key] = value
+	}
+	return scanner.Err()
+}
+
+func parseTOMLValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return strconv.Unquote(raw[:end+1])
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return raw[1 : end+1], nil
+	}
+
+	value := strings.TrimSpace(stripTOMLComment(raw))
+	if value == "" || strings.ContainsAny(value, `[{"'`) {
+		return "", fmt.Errorf("only strings, numbers and booleans are supported")
+	}
+	return strings.ReplaceAll(value, "_", ""), nil
+}
+
+// closingQuote finds the quote ending the basic string raw starts with.
+func closingQuote(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func stripTOMLComment(s string) string {
+	before, _, _ := strings.Cut(s, "#")
+	return strings.TrimSpace(before)
+}
+
+// trimNewline drops the line ending secret files are usually saved with.
+func trimNewline(s string) string {
+	return strings.TrimRight(s, "\r\n")
+}
@@ -0,0 +1,168 @@
+package confighelper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-envconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// minimalEnv is the least the configuration needs, the rest has defaults.
+func minimalEnv() map[string]string {
+	return map[string]string{
+		"JWTSECRET": testSecret,
+		"PG_USER":   "admin",
+		"PG_DB":     "librarydb",
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestDefaults(t *testing.T) {
+	env := minimalEnv()
+	env["TOKEN_DURATION"] = ""
+
+	conf, err := load(context.Background(), envconfig.MapLookuper(env))
+	require.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, conf.LoginLengths.TokenLength, "an empty key didn't get its default")
+	assert.Equal(t, 24*time.Hour, conf.LoginLengths.DatabaseCleanupLength)
+	assert.Equal(t, 3000, conf.Port)
+	assert.Equal(t, ":9090", conf.MetricsAddr)
+	assert.Equal(t, 5432, conf.Database.Port)
+	assert.Equal(t, "application/epub+zip", conf.OPDS.AcquisitionType)
+}
+
+func TestAggregatedErrors(t *testing.T) {
+	env := map[string]string{
+		"PG_PORT":             "5432x",
+		"TOKEN_DURATION":      "10",
+		"DB_CLEANUP_DURATION": "0s",
+	}
+	conf, err := load(context.Background(), envconfig.MapLookuper(env))
+	assert.Equal(t, ValidationError{
+		{"PG_PORT", "must be a whole number"},
+		{"TOKEN_DURATION", "must be a duration like 90s, 15m or 48h"},
+	}, err, "every malformed value should've been reported")
+	assert.Equal(t, 5432, conf.Database.Port, "a malformed value didn't fall back to its default")
+	assert.Equal(t, time.Duration(0), conf.LoginLengths.DatabaseCleanupLength, "the well formed values weren't read along with the malformed ones")
+
+	delete(env, "PG_PORT")
+	env["TOKEN_DURATION"] = "72h"
+	_, err = load(context.Background(), envconfig.MapLookuper(env))
+	assert.Equal(t, ValidationError{
+		{"JWTSECRET", "must be set, and at least 32 bytes long"},
+		{"PG_USER", "must be set, or DATABASE_URL"},
+		{"PG_DB", "must be set, or DATABASE_URL"},
+		{"SESSION_DURATION", "can't be shorter than TOKEN_DURATION"},
+		{"DB_CLEANUP_DURATION", "must be at least 1m"},
+	}, err, "every invalid value should've been reported")
+	assert.Contains(t, err.Error(), "5 configuration problems: JWTSECRET must be set")
+}
+
+func TestMetricsAddr(t *testing.T) {
+	env := minimalEnv()
+	env["METRICS_ADDR"] = "127.0.0.1:9100"
+	conf, err := load(context.Background(), envconfig.MapLookuper(env))
+	require.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:9100", conf.MetricsAddr)
+
+	for _, addr := range []string{"9100", ":3000"} {
+		env["METRICS_ADDR"] = addr
+		_, err = load(context.Background(), envconfig.MapLookuper(env))
+		assert.Equal(t, ValidationError{{"METRICS_ADDR", "must be a host:port, on another port than PORT"}}, err, "METRICS_ADDR %q should've been refused", addr)
+	}
+}
+
+func TestYAMLFile(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+port: 8080
+pg:
+  host: db.internal
+  db: fromfile
+server:
+  read_timeout: 30s
+token_duration: 5m
+`)
+	env := minimalEnv()
+	env[configFileKey] = path
+
+	conf, err := load(context.Background(), envconfig.MapLookuper(env))
+	require.Nil(t, err)
+	assert.Equal(t, 8080, conf.Port)
+	assert.Equal(t, "db.internal", conf.Database.Host, "a nested key wasn't read")
+	assert.Equal(t, 30*time.Second, conf.Server.ReadTimeout)
+	assert.Equal(t, 5*time.Minute, conf.LoginLengths.TokenLength)
+	assert.Equal(t, "librarydb", conf.Database.Name, "the environment didn't override the file")
+}
+
+func TestTOMLFile(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+# the API
+port = 8_080
+token_duration = "5m" # short lived
+
+[pg]
+host = 'db.internal'
+
+[server]
+read_timeout = "30s"
+`)
+	env := minimalEnv()
+	env[configFileKey] = path
+
+	conf, err := load(context.Background(), envconfig.MapLookuper(env))
+	require.Nil(t, err)
+	assert.Equal(t, 8080, conf.Port)
+	assert.Equal(t, "db.internal", conf.Database.Host)
+	assert.Equal(t, 30*time.Second, conf.Server.ReadTimeout)
+	assert.Equal(t, 5*time.Minute, conf.LoginLengths.TokenLength)
+}
+
+func TestUnknownFileKey(t *testing.T) {
+	env := minimalEnv()
+	env[configFileKey] = writeFile(t, "config.yaml", "token_durration: 5m\n")
+
+	_, err := load(context.Background(), envconfig.MapLookuper(env))
+	assert.Equal(t, ValidationError{{"TOKEN_DURRATION", "isn't a configuration key"}}, err)
+}
+
+func TestSecretFile(t *testing.T) {
+	env := minimalEnv()
+	delete(env, "JWTSECRET")
+	env["JWTSECRET_FILE"] = writeFile(t, "jwt", testSecret+"\n")
+
+	conf, err := load(context.Background(), envconfig.MapLookuper(env))
+	require.Nil(t, err)
+	assert.Equal(t, testSecret, conf.JWTSecret, "the secret wasn't read from its file")
+	assert.Equal(t, env["JWTSECRET_FILE"], conf.SecretFiles["JWTSECRET"], "the secret's file wasn't kept")
+
+	env["JWTSECRET"] = testSecret
+	_, err = load(context.Background(), envconfig.MapLookuper(env))
+	assert.Equal(t, ValidationError{{"JWTSECRET_FILE", "can't be set along with JWTSECRET"}}, err)
+}
+
+func TestRedactedSettings(t *testing.T) {
+	conf, err := load(context.Background(), envconfig.MapLookuper(minimalEnv()))
+	require.Nil(t, err)
+
+	settings := map[string]string{}
+	for _, s := range conf.Settings() {
+		settings[s.Key] = s.Redacted().Value
+	}
+	assert.Equal(t, "[redacted]", settings["JWTSECRET"], "the secret wasn't redacted")
+	assert.Equal(t, "", settings["PG_PASS"], "an unset secret should stay empty")
+	assert.Equal(t, "admin", settings["PG_USER"])
+	assert.Equal(t, "10m0s", settings["TOKEN_DURATION"])
+	assert.Len(t, settings, len(keys()), "a key is missing from the settings")
+}
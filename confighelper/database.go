@@ -0,0 +1,146 @@
+package confighelper
+
+import (
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// sslModes are the modes lib/pq supports.
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+func (d Database) validate(v *ValidationError) {
+	if d.URL != "" {
+		// The parse error isn't reported, it quotes the URL and its password.
+		u, err := url.Parse(d.URL)
+		v.check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"), "DATABASE_URL", "must be a postgres:// URL")
+		if err == nil {
+			if mode := u.Query().Get("sslmode"); mode != "" {
+				v.check(slices.Contains(sslModes, mode), "DATABASE_URL", "has an unsupported sslmode, use one of "+strings.Join(sslModes, ", "))
+			}
+		}
+	} else {
+		v.check(d.Host != "", "PG_HOST", "must be set")
+		v.check(d.Port > 0 && d.Port < 65536, "PG_PORT", "must be a port between 1 and 65535")
+		v.check(d.User != "", "PG_USER", "must be set, or DATABASE_URL")
+		v.check(d.Name != "", "PG_DB", "must be set, or DATABASE_URL")
+	}
+
+	if d.SSLMode != "" {
+		v.check(slices.Contains(sslModes, d.SSLMode), "PG_SSLMODE", "must be one of "+strings.Join(sslModes, ", "))
+	}
+	for _, file := range []struct{ key, path string }{
+		{"PG_SSLROOTCERT", d.SSLRootCert},
+		{"PG_SSLCERT", d.SSLCert},
+		{"PG_SSLKEY", d.SSLKey},
+	} {
+		if file.path != "" {
+			_, err := os.Stat(file.path)
+			v.check(err == nil, file.key, "must name a readable file")
+		}
+	}
+	v.check((d.SSLCert == "") == (d.SSLKey == ""), "PG_SSLKEY", "must be set along with PG_SSLCERT")
+
+	for _, replica := range d.ReplicaURLs {
+		u, err := url.Parse(replica)
+		v.check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"), "DATABASE_REPLICA_URLS", "must be comma separated postgres:// URLs")
+	}
+	v.check(d.MaxReplicaLag > 0, "DB_REPLICA_MAX_LAG", "must be positive")
+
+	v.check(d.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS", "can't be negative")
+	v.check(d.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS", "can't be negative")
+	v.check(d.MaxOpenConns == 0 || d.MaxIdleConns <= d.MaxOpenConns, "DB_MAX_IDLE_CONNS", "can't be more than DB_MAX_OPEN_CONNS")
+	v.check(d.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME", "can't be negative")
+	v.check(d.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME", "can't be negative")
+	v.check(d.ConnectTimeout >= 0, "DB_CONNECT_TIMEOUT", "can't be negative")
+}
+
+// DSN is the connection string of the database. A DATABASE_URL is used as
+// is, with the PG_SSL keys it doesn't set added. Otherwise it's built from
+// the PG_ keys, with TLS off unless PG_SSLMODE says otherwise.
+func (d Database) DSN() string {
+	if d.URL != "" {
+		return d.withTLS(d.URL)
+	}
+
+	params := append([][2]string{
+		{"host", d.Host},
+		{"port", strconv.Itoa(d.Port)},
+		{"user", d.User},
+		{"password", d.Pass},
+		{"dbname", d.Name},
+	}, d.tls()...)
+	if d.SSLMode == "" {
+		params = append(params, [2]string{"sslmode", "disable"})
+	}
+	var dsn []string
+	for _, kv := range params {
+		if kv[1] != "" {
+			dsn = append(dsn, kv[0]+"="+quoteDSNValue(kv[1]))
+		}
+	}
+	return strings.Join(dsn, " ")
+}
+
+// ReplicaDSNs are the connection strings of the replicas, with the PG_SSL
+// keys they don't set added.
+func (d Database) ReplicaDSNs() []string {
+	var dsns []string
+	for _, replica := range d.ReplicaURLs {
+		dsns = append(dsns, d.withTLS(replica))
+	}
+	return dsns
+}
+
+func (d Database) tls() [][2]string {
+	return [][2]string{
+		{"sslmode", d.SSLMode},
+		{"sslrootcert", d.SSLRootCert},
+		{"sslcert", d.SSLCert},
+		{"sslkey", d.SSLKey},
+	}
+}
+
+// withTLS adds the PG_SSL keys the URL doesn't set to its query.
+func (d Database) withTLS(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	for _, kv := range d.tls() {
+		if kv[1] != "" && !q.Has(kv[0]) {
+			q.Set(kv[0], kv[1])
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// quoteDSNValue quotes a key/value connection string value when it has
+// spaces, quotes or backslashes.
+func quoteDSNValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`+"\t\n") {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, `'`, `\'`))
+}
+
+// Options are the pool settings, how long to wait for the database and the
+// replicas to read from.
+func (d Database) Options() database.ConnectOptions {
+	return database.ConnectOptions{
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+		ConnMaxIdleTime: d.ConnMaxIdleTime,
+		RetryTimeout:    d.ConnectTimeout,
+		ReplicaDSNs:     d.ReplicaDSNs(),
+		MaxReplicaLag:   d.MaxReplicaLag,
+	}
+}
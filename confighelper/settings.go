@@ -0,0 +1,110 @@
+package confighelper
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// eachKey calls visit with every field of v that's read from a key, with the
+// key, nested structs included.
+func eachKey(v reflect.Value, visit func(key string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			eachKey(field, visit)
+			continue
+		}
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("env"), ",")
+		if key != "" {
+			visit(key, field)
+		}
+	}
+}
+
+// keys are every key the Config reads, in order.
+func keys() []string {
+	var ks []string
+	eachKey(reflect.ValueOf(Config{}), func(key string, _ reflect.Value) {
+		ks = append(ks, key)
+	})
+	return ks
+}
+
+// checkValues reports the values that don't parse as their field's type. The
+// config loader stops at the first one, these are all reported together.
+func checkValues(src source) ValidationError {
+	var problems ValidationError
+	eachKey(reflect.ValueOf(Config{}), func(key string, field reflect.Value) {
+		value, ok := src.Lookup(key)
+		if !ok {
+			return
+		}
+		switch {
+		case field.Type() == durationType:
+			_, err := time.ParseDuration(value)
+			problems.check(err == nil, key, "must be a duration like 90s, 15m or 48h")
+		case field.Kind() == reflect.Int:
+			_, err := strconv.Atoi(value)
+			problems.check(err == nil, key, "must be a whole number")
+		}
+	})
+	return problems
+}
+
+// checkFileKeys reports the keys of a config file the Config doesn't read,
+// which are likely typos.
+func checkFileKeys(file map[string]string) ValidationError {
+	known := keys()
+	for _, key := range secretKeys {
+		known = append(known, key+"_FILE")
+	}
+
+	var problems ValidationError
+	for key := range file {
+		problems.check(slices.Contains(known, key), key, "isn't a configuration key")
+	}
+	slices.SortFunc(problems, func(a, b FieldError) int { return strings.Compare(a.Key, b.Key) })
+	return problems
+}
+
+// Setting is a configuration key with its value, written the way the
+// environment would set it.
+type Setting struct {
+	Key    string
+	Value  string
+	Secret bool
+}
+
+// Settings lists every key of c with its value, in the order of Config.
+func (c Config) Settings() []Setting {
+	var settings []Setting
+	eachKey(reflect.ValueOf(c), func(key string, field reflect.Value) {
+		s := Setting{Key: key, Secret: slices.Contains(secretKeys, key)}
+		switch {
+		case field.Type() == durationType:
+			s.Value = time.Duration(field.Int()).String()
+		case field.Kind() == reflect.Int:
+			s.Value = strconv.FormatInt(field.Int(), 10)
+		case field.Kind() == reflect.Slice:
+			s.Value = strings.Join(field.Interface().([]string), ",")
+		default:
+			s.Value = field.String()
+		}
+		settings = append(settings, s)
+	})
+	return settings
+}
+
+// Redacted hides the value of a secret setting, keeping whether it's set.
+func (s Setting) Redacted() Setting {
+	if s.Secret && s.Value != "" {
+		s.Value = "[redacted]"
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+package coverhelper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+type coverSize struct {
+	Name  string
+	Width int
+}
+
+// Widths of the generated thumbnails. Images narrower than a size are never
+// upscaled, that variant keeps the original width instead.
+var coverSizes = []coverSize{
+	{"small", 160},
+	{"medium", 320},
+	{"large", 640},
+}
+
+var acceptedCoverTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+const maxCoverPixels = 40_000_000
+
+var ErrCoverTypeUnsupported = errors.New("cover image type unsupported")
+var ErrCoverDimensionTooLarge = errors.New("cover image dimension is larger than allowed")
+
+func CoverSizes() []string {
+	var sizes []string
+	for _, size := range coverSizes {
+		sizes = append(sizes, size.Name)
+	}
+	return sizes
+}
+
+func IsCoverSize(size string) bool {
+	for _, s := range coverSizes {
+		if s.Name == size {
+			return true
+		}
+	}
+	return false
+}
+
+func IsCoverFormat(format string) bool {
+	return format == FormatJPEG || format == FormatWebP
+}
+
+func ContentType(format string) string {
+	return "image/" + format
+}
+
+func variantName(size string, format string) string {
+	return size + "." + format
+}
+
+func processCover(data []byte) (map[string][]byte, error) {
+	if !acceptedCoverTypes[http.DetectContentType(data)] {
+		return nil, ErrCoverTypeUnsupported
+	}
+
+	// The header is checked before decoding so an image claiming huge
+	// dimensions can't make us allocate the whole thing.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCoverTypeUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrCoverTypeUnsupported
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return nil, ErrCoverDimensionTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCoverTypeUnsupported
+	}
+
+	variants := make(map[string][]byte)
+	for _, size := range coverSizes {
+		img := resize(src, size.Width)
+
+		var jpegBuf bytes.Buffer
+		if err := jpeg.Encode(&jpegBuf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("encoding %s jpeg failed: %w", size.Name, err)
+		}
+		variants[variantName(size.Name, FormatJPEG)] = jpegBuf.Bytes()
+
+		var webpBuf bytes.Buffer
+		if err := nativewebp.Encode(&webpBuf, img, nil); err != nil {
+			return nil, fmt.Errorf("encoding %s webp failed: %w", size.Name, err)
+		}
+		variants[variantName(size.Name, FormatWebP)] = webpBuf.Bytes()
+	}
+	return variants, nil
+}
+
+func resize(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	// JPEG has no alpha channel, so transparent covers get a white backdrop
+	// instead of turning black.
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
@@ -0,0 +1,79 @@
+package coverhelper
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.Nil(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestCoverDimensionLimit(t *testing.T) {
+	// 8000x5000 is maxCoverPixels and 53x754717 one pixel more. Over the
+	// limit the header is enough, the image is refused before being decoded.
+	variants, err := processCover(encodePNG(t, image.NewGray(image.Rect(0, 0, 8000, 5000))))
+	require.Nil(t, err, "an image of exactly maxCoverPixels was refused")
+	assert.Len(t, variants, len(coverSizes)*2, "an image of exactly maxCoverPixels didn't get every variant")
+
+	_, err = processCover(encodePNG(t, image.NewGray(image.Rect(0, 0, 53, 754717))))
+	assert.Equal(t, ErrCoverDimensionTooLarge, err, "an image one pixel over maxCoverPixels wasn't refused")
+}
+
+func TestCoverTypes(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	img.Set(1, 1, color.RGBA{200, 30, 30, 255})
+
+	var jpegBuf, gifBuf, webpBuf bytes.Buffer
+	require.Nil(t, jpeg.Encode(&jpegBuf, img, nil))
+	require.Nil(t, gif.Encode(&gifBuf, img, nil))
+	require.Nil(t, nativewebp.Encode(&webpBuf, img, nil))
+	pngData := encodePNG(t, img)
+
+	accepted := map[string][]byte{
+		"jpeg": jpegBuf.Bytes(),
+		"png":  pngData,
+		"gif":  gifBuf.Bytes(),
+		"webp": webpBuf.Bytes(),
+	}
+	for name, data := range accepted {
+		variants, err := processCover(data)
+		if assert.Nil(t, err, "a %s cover was refused", name) {
+			assert.Len(t, variants, len(coverSizes)*2, "a %s cover didn't get every variant", name)
+		}
+	}
+
+	refused := map[string][]byte{
+		"bmp":       append([]byte("BM"), make([]byte, 64)...),
+		"text":      []byte("not an image"),
+		"truncated": pngData[:len(pngData)/2],
+		"empty":     nil,
+	}
+	for name, data := range refused {
+		_, err := processCover(data)
+		assert.Equal(t, ErrCoverTypeUnsupported, err, "a %s cover wasn't refused", name)
+	}
+}
+
+func TestCoverNotUpscaled(t *testing.T) {
+	variants, err := processCover(encodePNG(t, image.NewRGBA(image.Rect(0, 0, 200, 100))))
+	require.Nil(t, err)
+
+	for _, size := range coverSizes {
+		img, err := jpeg.Decode(bytes.NewReader(variants[variantName(size.Name, FormatJPEG)]))
+		require.Nil(t, err, "the %s variant isn't a valid jpeg", size.Name)
+		assert.Equal(t, min(size.Width, 200), img.Bounds().Dx(), "the %s variant has the wrong width", size.Name)
+		assert.Equal(t, min(size.Width, 200)/2, img.Bounds().Dy(), "the %s variant didn't keep the aspect ratio", size.Name)
+	}
+}
@@ -0,0 +1,116 @@
+package coverhelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sethvargo/go-envconfig"
+)
+
+type CoverStorage interface {
+	StoreCover(ctx context.Context, image io.Reader) (coverID uuid.UUID, err error)
+	OpenCover(ctx context.Context, coverID uuid.UUID, size string, format string) (cover io.ReadSeekCloser, modTime time.Time, err error)
+	DeleteCover(ctx context.Context, coverID uuid.UUID) error
+}
+
+func NewCoverStorage(ctx context.Context) (CoverStorage, error) {
+	var storage coverStorageImpl
+
+	if err := envconfig.Process(ctx, &storage); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(storage.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("cover directory creation failed: %w", err)
+	}
+
+	return storage, nil
+}
+
+type coverStorageImpl struct {
+	Directory string `env:"COVER_DIR, default=covers"`
+	MaxSize   int64  `env:"COVER_MAX_SIZE, default=5242880"`
+}
+
+var ErrCoverNotFound = errors.New("cover not found")
+var ErrCoverTooLarge = errors.New("cover image is larger than allowed")
+
+func (s coverStorageImpl) StoreCover(ctx context.Context, image io.Reader) (coverID uuid.UUID, err error) {
+	data, err := io.ReadAll(io.LimitReader(image, s.MaxSize+1))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if int64(len(data)) > s.MaxSize {
+		return uuid.Nil, ErrCoverTooLarge
+	}
+
+	variants, err := processCover(data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	coverID, err = uuid.NewRandom()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	dir := filepath.Join(s.Directory, coverID.String())
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return uuid.Nil, err
+	}
+	for name, content := range variants {
+		if err := writeFileAtomic(filepath.Join(dir, name), content); err != nil {
+			os.RemoveAll(dir)
+			return uuid.Nil, err
+		}
+	}
+
+	return coverID, nil
+}
+
+func (s coverStorageImpl) OpenCover(ctx context.Context, coverID uuid.UUID, size string, format string) (io.ReadSeekCloser, time.Time, error) {
+	if !IsCoverSize(size) || !IsCoverFormat(format) {
+		return nil, time.Time{}, ErrCoverNotFound
+	}
+
+	f, err := os.Open(filepath.Join(s.Directory, coverID.String(), variantName(size, format)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, time.Time{}, ErrCoverNotFound
+		}
+		return nil, time.Time{}, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, err
+	}
+	return f, stat.ModTime(), nil
+}
+
+func (s coverStorageImpl) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	return os.RemoveAll(filepath.Join(s.Directory, coverID.String()))
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,33 @@
+package coverhelper
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverMaxSize(t *testing.T) {
+	data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 20, 30)))
+
+	storage := coverStorageImpl{Directory: t.TempDir(), MaxSize: int64(len(data))}
+	coverID, err := storage.StoreCover(context.Background(), bytes.NewReader(data))
+	require.Nil(t, err, "a cover of exactly COVER_MAX_SIZE was refused")
+
+	cover, _, err := storage.OpenCover(context.Background(), coverID, "small", FormatWebP)
+	require.Nil(t, err, "a stored cover couldn't be opened")
+	content, err := io.ReadAll(cover)
+	cover.Close()
+	require.Nil(t, err)
+	assert.NotEmpty(t, content, "a stored cover is empty")
+
+	storage.MaxSize--
+	coverID, err = storage.StoreCover(context.Background(), bytes.NewReader(data))
+	assert.Equal(t, ErrCoverTooLarge, err, "a cover one byte over COVER_MAX_SIZE wasn't refused")
+	assert.Equal(t, uuid.Nil, coverID)
+}
@@ -0,0 +1,360 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// AdminUserInterface is what support staff look up and change on accounts.
+type AdminUserInterface interface {
+	SearchAccounts(ctx context.Context, query string, limit int, offset int) ([]Account, error)
+	GetAccount(ctx context.Context, email string) (*Account, error)
+	GetAccountSessions(ctx context.Context, email string, currTime time.Time) ([]AccountSession, error)
+	GetAccountLoans(ctx context.Context, email string, limit int, offset int) ([]ReadingBook, error)
+	SetAccountStatus(ctx context.Context, email string, status string, reason string) (*Account, error)
+	RevokeAccountSessions(ctx context.Context, email string) (revoked int64, err error)
+	SetAccountRole(ctx context.Context, email string, role string) error
+	RevokeAllSessions(ctx context.Context) (revoked int64, err error)
+}
+
+// Account is an account as support staff see it, without its credentials.
+// Sessions counts the token families still alive.
+type Account struct {
+	Email           string
+	Name            string
+	Role            string
+	Activated       bool
+	HasPassword     bool
+	HasGoogle       bool
+	Status          string
+	StatusReason    string
+	StatusChangedAt *time.Time
+	Sessions        int
+}
+
+// AccountSession is a signed in device: a refresh token family from the
+// login that started it to its latest refresh.
+type AccountSession struct {
+	TokenFamily   string
+	StartedAt     time.Time
+	LastRefreshAt time.Time
+	ExpiresIn     time.Time
+}
+
+const accountColumnsStr = `
+		a.email,
+		a.name,
+		a.role,
+		a.activated,
+		a.password IS NOT NULL,
+		a.g_id IS NOT NULL,
+		a.status,
+		a.status_reason,
+		a.status_changed_at,
+		(
+			SELECT
+				count(DISTINCT s.token_family)
+			FROM
+				user_session s
+			WHERE
+				s.user_id = a.email
+				AND NOT s.exhausted
+				AND s.expires_in > now()
+		)`
+
+var searchAccountsStmt = dbStatement{
+	nil, `
+	SELECT` + accountColumnsStr + `
+	FROM
+		user_account a
+	WHERE
+		$1 = ''
+		OR a.email ILIKE '%' || $1 || '%'
+		OR a.name ILIKE '%' || $1 || '%'
+	ORDER BY
+		a.email
+	LIMIT
+		$2 OFFSET $3;`,
+}
+var getAccountStmt = dbStatement{
+	nil, `
+	SELECT` + accountColumnsStr + `
+	FROM
+		user_account a
+	WHERE
+		a.email = $1;`,
+}
+
+// getAccountSessionsStmt groups the refresh tokens by family, leaving out
+// the families that were used up or have expired.
+var getAccountSessionsStmt = dbStatement{
+	nil, `
+	SELECT
+		token_family,
+		min(created_at),
+		max(created_at),
+		max(expires_in)
+	FROM
+		user_session
+	WHERE
+		user_id = $1
+	GROUP BY
+		token_family
+	HAVING
+		bool_or(NOT exhausted)
+		AND max(expires_in) > $2
+	ORDER BY
+		max(created_at) DESC;`,
+}
+
+var getAccountLoansStmt = dbStatement{
+	nil, `
+	SELECT` + bookColumnsStr + `,` + readingProgressColumnsStr + `
+	FROM
+		book b
+		LEFT JOIN rating_avg AS av ON b.id = av.id
+		JOIN reading_progress rp ON rp.book_id = b.id
+	WHERE
+		rp.account_id = $1
+	ORDER BY
+		rp.updated_at DESC
+	LIMIT
+		$2 OFFSET $3;`,
+}
+
+var setAccountStatusStmt = dbStatement{
+	nil, `
+	UPDATE user_account
+	SET
+		status = $2,
+		status_reason = $3,
+		status_changed_at = now()
+	WHERE
+		email = $1;`,
+}
+var revokeAccountSessionsStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		user_session
+	WHERE
+		user_id = $1
+		AND NOT exhausted
+		AND expires_in > now();`,
+}
+var setAccountRoleStmt = dbStatement{
+	nil, `
+	UPDATE user_account
+	SET
+		role = $2
+	WHERE
+		email = $1;`,
+}
+var revokeAllSessionsStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		user_session
+	WHERE
+		NOT exhausted
+		AND expires_in > now();`,
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"searchAccounts":        &searchAccountsStmt,
+		"getAccount":            &getAccountStmt,
+		"getAccountSessions":    &getAccountSessionsStmt,
+		"getAccountLoans":       &getAccountLoansStmt,
+		"setAccountStatus":      &setAccountStatusStmt,
+		"revokeAccountSessions": &revokeAccountSessionsStmt,
+		"setAccountRole":        &setAccountRoleStmt,
+		"revokeAllSessions":     &revokeAllSessionsStmt,
+	})
+}
+
+var ErrAccountStatusInvalid = errors.New("status must be active, disabled or banned")
+var ErrAccountStatusReasonMissing = errors.New("disabling or banning an account needs a reason")
+var ErrAccountStatusReasonInvalid = errors.New("reason must be at most 1000 characters")
+var ErrAccountRoleInvalid = errors.New("role must be user, support or admin")
+
+func accountColumns(account *Account) []any {
+	return []any{
+		&account.Email,
+		&account.Name,
+		&account.Role,
+		&account.Activated,
+		&account.HasPassword,
+		&account.HasGoogle,
+		&account.Status,
+		&account.StatusReason,
+		&account.StatusChangedAt,
+		&account.Sessions,
+	}
+}
+
+// SearchAccounts lists the accounts whose email or name has query in it,
+// or every account when it's empty.
+func (db DBInstance) SearchAccounts(ctx context.Context, query string, limit int, offset int) ([]Account, error) {
+	rows, err := searchAccountsStmt.QueryContext(ctx, strings.TrimSpace(query), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []Account{}
+	for rows.Next() {
+		var account Account
+		if err := rows.Scan(accountColumns(&account)...); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (db DBInstance) GetAccount(ctx context.Context, email string) (*Account, error) {
+	var account Account
+	if err := getAccountStmt.QueryRowContext(ctx, email).Scan(accountColumns(&account)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (db DBInstance) GetAccountSessions(ctx context.Context, email string, currTime time.Time) ([]AccountSession, error) {
+	rows, err := getAccountSessionsStmt.QueryContext(ctx, email, currTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []AccountSession{}
+	for rows.Next() {
+		var session AccountSession
+		if err := rows.Scan(&session.TokenFamily, &session.StartedAt, &session.LastRefreshAt, &session.ExpiresIn); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetAccountLoans lists every book the account started, finished or not, the
+// last one read first.
+func (db DBInstance) GetAccountLoans(ctx context.Context, email string, limit int, offset int) ([]ReadingBook, error) {
+	rows, err := getAccountLoansStmt.QueryContext(ctx, email, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := []ReadingBook{}
+	for rows.Next() {
+		book := ReadingBook{}
+		if err := rows.Scan(append(bookColumns(&book.Book), readingProgressColumns(&book.Progress)...)...); err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// SetAccountStatus disables, bans or reactivates an account. Disabling or
+// banning it also signs it out everywhere.
+func (db DBInstance) SetAccountStatus(ctx context.Context, email string, status string, reason string) (*Account, error) {
+	reason = strings.TrimSpace(reason)
+	switch status {
+	case AccountActive:
+	case AccountDisabled, AccountBanned:
+		if reason == "" {
+			return nil, ErrAccountStatusReasonMissing
+		}
+	default:
+		return nil, ErrAccountStatusInvalid
+	}
+	if len([]rune(reason)) > 1000 {
+		return nil, ErrAccountStatusReasonInvalid
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := setAccountStatusStmt.Tx(ctx, tx).ExecContext(ctx, email, status, reason)
+	if err != nil {
+		return nil, err
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if updated == 0 {
+		return nil, ErrAccountNotFound
+	}
+	if status != AccountActive {
+		if _, err := revokeAccountSessionsStmt.Tx(ctx, tx).ExecContext(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+
+	var account Account
+	if err := getAccountStmt.Tx(ctx, tx).QueryRowContext(ctx, email).Scan(accountColumns(&account)...); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// RevokeAccountSessions signs the account out of every device. Their session
+// tokens keep working until they expire, but can't be refreshed.
+func (db DBInstance) RevokeAccountSessions(ctx context.Context, email string) (revoked int64, err error) {
+	result, err := revokeAccountSessionsStmt.ExecContext(ctx, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (db DBInstance) SetAccountRole(ctx context.Context, email string, role string) error {
+	switch role {
+	case RoleUser, RoleSupport, RoleAdmin:
+	default:
+		return ErrAccountRoleInvalid
+	}
+
+	result, err := setAccountRoleStmt.ExecContext(ctx, email, role)
+	if err != nil {
+		return err
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return err
+	} else if updated == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+// RevokeAllSessions signs every account out, for when the key signing the
+// tokens is replaced.
+func (db DBInstance) RevokeAllSessions(ctx context.Context) (revoked int64, err error) {
+	result, err := revokeAllSessionsStmt.ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
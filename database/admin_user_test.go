@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var accountColumnNames = []string{"email", "name", "role", "activated", "has_password", "has_google", "status", "status_reason", "status_changed_at", "sessions"}
+
+func TestSuccessfulSetAccountStatus(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	mock.ExpectPrepare("UPDATE user_account")
+	mock.ExpectPrepare("DELETE FROM")
+	mock.ExpectPrepare("SELECT")
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_account").
+		WithArgs(expEmail, AccountBanned, "spam reviews").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM").
+		WithArgs(expEmail).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("SELECT").
+		WithArgs(expEmail).
+		WillReturnRows(sqlmock.NewRows(accountColumnNames).
+			AddRow(expEmail, "Someone", RoleUser, true, true, false, AccountBanned, "spam reviews", nil, 0))
+	mock.ExpectCommit()
+
+	require.Nil(t, setAccountStatusStmt.Prepare(ctx, d))
+	require.Nil(t, revokeAccountSessionsStmt.Prepare(ctx, d))
+	require.Nil(t, getAccountStmt.Prepare(ctx, d))
+
+	account, err := db.SetAccountStatus(ctx, expEmail, AccountBanned, "  spam reviews ")
+	if assert.Nil(t, err, "unexpected error banning an account") {
+		assert.Equal(t, AccountBanned, account.Status, "the account should've been banned")
+		assert.Equal(t, 0, account.Sessions, "the account should've been signed out")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidSetAccountStatus(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	for _, tc := range []struct {
+		status string
+		reason string
+		expErr error
+	}{
+		{"suspended", "spam", ErrAccountStatusInvalid},
+		{AccountDisabled, " ", ErrAccountStatusReasonMissing},
+	} {
+		_, err := db.SetAccountStatus(ctx, expEmail, tc.status, tc.reason)
+		assert.Equal(t, tc.expErr, err, "setting the status %q should've been refused", tc.status)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
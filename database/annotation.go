@@ -0,0 +1,381 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AnnotationBookmark  = "bookmark"
+	AnnotationHighlight = "highlight"
+	AnnotationNote      = "note"
+)
+
+const (
+	AnnotationPrivate = "private"
+	AnnotationShared  = "shared"
+)
+
+// AnnotationColors are the highlight colors readers can pick from.
+var AnnotationColors = []string{"yellow", "green", "blue", "pink", "purple"}
+
+const annotationColumnsStr = `
+		a.id,
+		a.account_id,
+		u.name,
+		a.book_id,
+		a.kind,
+		a.cfi_range,
+		COALESCE(a.quote, ''),
+		COALESCE(a.color, ''),
+		COALESCE(a.note, ''),
+		a.privacy,
+		a.created_at,
+		a.updated_at`
+
+// annotationVisibleStr keeps the annotations the account in $1 may read: its
+// own, and the shared ones of the accounts it follows.
+const annotationVisibleStr = `
+		(
+			a.account_id = $1
+			OR (
+				a.privacy = 'shared'
+				AND EXISTS (
+					SELECT
+						1
+					FROM
+						account_follow f
+					WHERE
+						f.follower_id = $1
+						AND f.followee_id = a.account_id
+				)
+			)
+		)`
+
+var createAnnotationStmt = dbStatement{
+	nil, `
+	INSERT INTO annotation (
+		id, account_id, book_id, kind, cfi_range, quote, color, note, privacy
+	)
+	VALUES
+		($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9);`,
+}
+
+var getAnnotationStmt = dbStatement{
+	nil, `
+	SELECT` + annotationColumnsStr + `
+	FROM
+		annotation a
+		JOIN user_account u ON u.email = a.account_id
+	WHERE
+		a.id = $2
+		AND` + annotationVisibleStr + `;`,
+}
+
+// listBookAnnotationsStmt pages through a book's annotations in the order
+// they were made. $3 and $4 are the creation time and id of the last
+// annotation of the previous page, both null for the first one.
+var listBookAnnotationsStmt = dbStatement{
+	nil, `
+	SELECT` + annotationColumnsStr + `
+	FROM
+		annotation a
+		JOIN user_account u ON u.email = a.account_id
+	WHERE
+		a.book_id = $2
+		AND` + annotationVisibleStr + `
+		AND (
+			$3::timestamptz IS NULL
+			OR (a.created_at, a.id) > ($3::timestamptz, $4::uuid)
+		)
+	ORDER BY
+		a.created_at ASC,
+		a.id ASC
+	LIMIT
+		$5;`,
+}
+
+var exportBookAnnotationsStmt = dbStatement{
+	nil, `
+	SELECT` + annotationColumnsStr + `
+	FROM
+		annotation a
+		JOIN user_account u ON u.email = a.account_id
+	WHERE
+		a.account_id = $1
+		AND a.book_id = $2
+	ORDER BY
+		a.created_at ASC,
+		a.id ASC;`,
+}
+
+var updateAnnotationStmt = dbStatement{
+	nil, `
+	UPDATE
+		annotation
+	SET
+		color = NULLIF($3, ''),
+		note = NULLIF($4, ''),
+		privacy = $5,
+		updated_at = now()
+	WHERE
+		account_id = $1
+		AND id = $2;`,
+}
+
+var deleteAnnotationStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		annotation
+	WHERE
+		account_id = $1
+		AND id = $2;`,
+}
+
+type AnnotationInterface interface {
+	CreateAnnotation(ctx context.Context, annotation Annotation) (*Annotation, error)
+	GetAnnotation(ctx context.Context, annotationID uuid.UUID, accountID string) (*Annotation, error)
+	ListBookAnnotations(ctx context.Context, bookID uuid.UUID, after *AnnotationCursor, limit int, accountID string) ([]Annotation, error)
+	ExportBookAnnotations(ctx context.Context, bookID uuid.UUID, accountID string) ([]Annotation, error)
+	UpdateAnnotation(ctx context.Context, annotation Annotation) (*Annotation, error)
+	DeleteAnnotation(ctx context.Context, annotationID uuid.UUID, accountID string) error
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"createAnnotation":      &createAnnotationStmt,
+		"getAnnotation":         &getAnnotationStmt,
+		"listBookAnnotations":   &listBookAnnotationsStmt,
+		"exportBookAnnotations": &exportBookAnnotationsStmt,
+		"updateAnnotation":      &updateAnnotationStmt,
+		"deleteAnnotation":      &deleteAnnotationStmt,
+	})
+}
+
+// Annotation is a bookmark, highlight or note anchored to a CFI range of a
+// book. Quote is the text the range covers, as the reader's device saw it.
+type Annotation struct {
+	ID        uuid.UUID
+	AccountID string
+	OwnerName string
+	BookID    uuid.UUID
+	Kind      string
+	CFIRange  string
+	Quote     string
+	Color     string
+	Note      string
+	Privacy   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AnnotationCursor marks where a page of annotations stopped.
+type AnnotationCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+var ErrAnnotationNotFound = errors.New("annotation not found")
+var ErrAnnotationKindInvalid = errors.New("kind must be one of bookmark, highlight or note")
+var ErrAnnotationRangeInvalid = errors.New("cfi range must be an epubcfi")
+var ErrAnnotationColorInvalid = errors.New("color must be one of yellow, green, blue, pink or purple")
+var ErrAnnotationNoteInvalid = errors.New("notes can't be longer than 10000 characters")
+var ErrAnnotationNoteMissing = errors.New("note missing")
+var ErrAnnotationPrivacyInvalid = errors.New("privacy must be either private or shared")
+
+const maxAnnotationNote = 10000
+
+// Validate trims the annotation's fields in place and checks them. Privacy
+// defaults to private.
+func (a *Annotation) Validate() error {
+	switch a.Kind {
+	case AnnotationBookmark, AnnotationHighlight, AnnotationNote:
+	default:
+		return ErrAnnotationKindInvalid
+	}
+	a.CFIRange = strings.TrimSpace(a.CFIRange)
+	if !strings.HasPrefix(a.CFIRange, "epubcfi(") || !strings.HasSuffix(a.CFIRange, ")") {
+		return ErrAnnotationRangeInvalid
+	}
+	return a.validateEditable()
+}
+
+// validateEditable checks the fields an annotation's owner can change later.
+func (a *Annotation) validateEditable() error {
+	a.Color = strings.ToLower(strings.TrimSpace(a.Color))
+	if a.Color != "" {
+		valid := false
+		for _, color := range AnnotationColors {
+			valid = valid || a.Color == color
+		}
+		if !valid {
+			return ErrAnnotationColorInvalid
+		}
+	}
+	a.Note = strings.TrimSpace(a.Note)
+	if len([]rune(a.Note)) > maxAnnotationNote {
+		return ErrAnnotationNoteInvalid
+	}
+	if a.Kind == AnnotationNote && a.Note == "" {
+		return ErrAnnotationNoteMissing
+	}
+	switch a.Privacy {
+	case "":
+		a.Privacy = AnnotationPrivate
+	case AnnotationPrivate, AnnotationShared:
+	default:
+		return ErrAnnotationPrivacyInvalid
+	}
+	return nil
+}
+
+func annotationColumns(annotation *Annotation) []any {
+	return []any{
+		&annotation.ID,
+		&annotation.AccountID,
+		&annotation.OwnerName,
+		&annotation.BookID,
+		&annotation.Kind,
+		&annotation.CFIRange,
+		&annotation.Quote,
+		&annotation.Color,
+		&annotation.Note,
+		&annotation.Privacy,
+		&annotation.CreatedAt,
+		&annotation.UpdatedAt,
+	}
+}
+
+func scanAnnotations(rows *sql.Rows) ([]Annotation, error) {
+	defer rows.Close()
+
+	var annotations []Annotation
+	for rows.Next() {
+		annotation := Annotation{}
+		if err := rows.Scan(annotationColumns(&annotation)...); err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+func (db DBInstance) CreateAnnotation(ctx context.Context, annotation Annotation) (*Annotation, error) {
+	if err := annotation.Validate(); err != nil {
+		return nil, err
+	}
+
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	annotation.ID = randomUUID
+
+	if _, err := createAnnotationStmt.ExecContext(ctx,
+		annotation.ID,
+		annotation.AccountID,
+		annotation.BookID,
+		annotation.Kind,
+		annotation.CFIRange,
+		annotation.Quote,
+		annotation.Color,
+		annotation.Note,
+		annotation.Privacy,
+	); err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	return db.GetAnnotation(ctx, annotation.ID, annotation.AccountID)
+}
+
+func (db DBInstance) GetAnnotation(ctx context.Context, annotationID uuid.UUID, accountID string) (*Annotation, error) {
+	annotation := Annotation{}
+	if err := getAnnotationStmt.QueryRowContext(ctx, accountID, annotationID).
+		Scan(annotationColumns(&annotation)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAnnotationNotFound
+		}
+		return nil, err
+	}
+	return &annotation, nil
+}
+
+func (db DBInstance) ListBookAnnotations(ctx context.Context, bookID uuid.UUID, after *AnnotationCursor, limit int, accountID string) ([]Annotation, error) {
+	if limit <= 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+
+	var afterTime sql.NullTime
+	var afterID uuid.NullUUID
+	if after != nil {
+		afterTime = sql.NullTime{Time: after.CreatedAt, Valid: true}
+		afterID = uuid.NullUUID{UUID: after.ID, Valid: true}
+	}
+
+	rows, err := listBookAnnotationsStmt.QueryContext(ctx, accountID, bookID, afterTime, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanAnnotations(rows)
+}
+
+func (db DBInstance) ExportBookAnnotations(ctx context.Context, bookID uuid.UUID, accountID string) ([]Annotation, error) {
+	rows, err := exportBookAnnotationsStmt.QueryContext(ctx, accountID, bookID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAnnotations(rows)
+}
+
+// UpdateAnnotation sets the color, note and privacy of one of the account's
+// annotations. Kind must be the stored one, it decides whether a note is
+// required.
+func (db DBInstance) UpdateAnnotation(ctx context.Context, annotation Annotation) (*Annotation, error) {
+	if err := annotation.validateEditable(); err != nil {
+		return nil, err
+	}
+
+	result, err := updateAnnotationStmt.ExecContext(ctx,
+		annotation.AccountID,
+		annotation.ID,
+		annotation.Color,
+		annotation.Note,
+		annotation.Privacy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrAnnotationNotFound
+	}
+	return db.GetAnnotation(ctx, annotation.ID, annotation.AccountID)
+}
+
+func (db DBInstance) DeleteAnnotation(ctx context.Context, annotationID uuid.UUID, accountID string) error {
+	result, err := deleteAnnotationStmt.ExecContext(ctx, accountID, annotationID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAnnotationNotFound
+	}
+	return nil
+}
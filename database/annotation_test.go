@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAnnotation(t *testing.T) {
+	const cfi = "epubcfi(/6/4!/4/2,/1:0,/1:20)"
+	tests := []struct {
+		annotation Annotation
+		expErr     error
+	}{
+		{Annotation{Kind: AnnotationHighlight, CFIRange: cfi, Color: " Yellow "}, nil},
+		{Annotation{Kind: AnnotationBookmark, CFIRange: cfi, Privacy: AnnotationShared}, nil},
+		{Annotation{Kind: "underline", CFIRange: cfi}, ErrAnnotationKindInvalid},
+		{Annotation{Kind: AnnotationBookmark, CFIRange: "/6/4"}, ErrAnnotationRangeInvalid},
+		{Annotation{Kind: AnnotationHighlight, CFIRange: cfi, Color: "red"}, ErrAnnotationColorInvalid},
+		{Annotation{Kind: AnnotationNote, CFIRange: cfi, Note: " "}, ErrAnnotationNoteMissing},
+		{Annotation{Kind: AnnotationNote, CFIRange: cfi, Note: strings.Repeat("a", 10001)}, ErrAnnotationNoteInvalid},
+		{Annotation{Kind: AnnotationBookmark, CFIRange: cfi, Privacy: "public"}, ErrAnnotationPrivacyInvalid},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expErr, test.annotation.Validate(), "unexpected error validating %+v", test.annotation)
+	}
+
+	annotation := Annotation{Kind: AnnotationHighlight, CFIRange: cfi, Color: " Yellow "}
+	if assert.Nil(t, annotation.Validate()) {
+		assert.Equal(t, "yellow", annotation.Color, "validation should've normalized the color")
+		assert.Equal(t, AnnotationPrivate, annotation.Privacy, "annotations should've defaulted to private")
+	}
+}
+
+func TestCursorListBookAnnotations(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	bookID := uuid.New()
+	after := AnnotationCursor{CreatedAt: time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC), ID: uuid.New()}
+	expAnnotation := Annotation{
+		ID:        uuid.New(),
+		AccountID: expEmail,
+		OwnerName: "Reza",
+		BookID:    bookID,
+		Kind:      AnnotationBookmark,
+		CFIRange:  "epubcfi(/6/4)",
+		Privacy:   AnnotationPrivate,
+		CreatedAt: after.CreatedAt.Add(time.Minute),
+		UpdatedAt: after.CreatedAt.Add(time.Minute),
+	}
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(expEmail, bookID, after.CreatedAt, after.ID, 51).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "account_id", "name", "book_id", "kind", "cfi_range", "quote", "color", "note", "privacy", "created_at", "updated_at",
+		}).AddRow(
+			expAnnotation.ID, expAnnotation.AccountID, expAnnotation.OwnerName, expAnnotation.BookID, expAnnotation.Kind,
+			expAnnotation.CFIRange, "", "", "", expAnnotation.Privacy, expAnnotation.CreatedAt, expAnnotation.UpdatedAt,
+		)).
+		RowsWillBeClosed()
+
+	err = listBookAnnotationsStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	annotations, err := db.ListBookAnnotations(ctx, bookID, &after, 51, expEmail)
+	if assert.Nil(t, err, "unexpected error in a successful annotation list test") {
+		assert.Equal(t, []Annotation{expAnnotation}, annotations, "function should've returned the page after the cursor")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
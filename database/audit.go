@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type AuditInterface interface {
+	RecordAuditEvent(ctx context.Context, event AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter AuditFilter, limit int, offset int) ([]AuditEvent, error)
+	ExportAuditEvents(ctx context.Context, filter AuditFilter, each func(AuditEvent) error) error
+	VerifyAuditChain(ctx context.Context) (AuditVerification, error)
+}
+
+// The audited actions.
+const (
+	AuditLoginSucceeded        = "login.succeeded"
+	AuditLoginFailed           = "login.failed"
+	AuditAccountRegistered     = "account.registered"
+	AuditAccountActivated      = "account.activated"
+	AuditRefreshTokenReused    = "refresh_token.reused"
+	AuditSessionFamilyRevoked  = "session.family_revoked"
+	AuditPasswordChanged       = "account.password_changed"
+	AuditRoleChanged           = "account.role_changed"
+	AuditActivationResent      = "account.activation_resent"
+	AuditAccountStatusChanged  = "account.status_changed"
+	AuditSessionsRevoked       = "session.revoked_all"
+	AuditAccountImpersonated   = "account.impersonated"
+	AuditSigningKeyRotated     = "session.signing_key_rotated"
+	AuditBookCoverUploaded     = "catalog.cover_uploaded"
+	AuditBookClassificationSet = "catalog.classification_set"
+	AuditGenreCreated          = "catalog.genre_created"
+	AuditCatalogImportStarted  = "catalog.import_started"
+	AuditReviewModerated       = "review.moderated"
+)
+
+// AuditActions lists every audited action, for filters to check against.
+var AuditActions = []string{
+	AuditLoginSucceeded,
+	AuditLoginFailed,
+	AuditAccountRegistered,
+	AuditAccountActivated,
+	AuditRefreshTokenReused,
+	AuditSessionFamilyRevoked,
+	AuditPasswordChanged,
+	AuditRoleChanged,
+	AuditActivationResent,
+	AuditAccountStatusChanged,
+	AuditSessionsRevoked,
+	AuditAccountImpersonated,
+	AuditSigningKeyRotated,
+	AuditBookCoverUploaded,
+	AuditBookClassificationSet,
+	AuditGenreCreated,
+	AuditCatalogImportStarted,
+	AuditReviewModerated,
+}
+
+// AuditEvent is a row of the audit log. Actor is the email of the account
+// acting, or the one a failed login was for. RecordAuditEvent fills in ID,
+// OccurredAt and the hashes.
+type AuditEvent struct {
+	ID         int64
+	OccurredAt time.Time
+	Action     string
+	Actor      string
+	IP         string
+	UserAgent  string
+	RequestID  string
+	Payload    json.RawMessage
+	PrevHash   string
+	Hash       string
+}
+
+// AuditFilter narrows the listed events. Zero fields don't filter.
+type AuditFilter struct {
+	Actor  string
+	Action string
+	From   time.Time
+	Until  time.Time
+}
+
+// AuditVerification is the outcome of walking the hash chain. BrokenAt is
+// the id of the first event whose hashes don't match, 0 when none.
+type AuditVerification struct {
+	Checked  int64
+	BrokenAt int64
+}
+
+// auditChainLock serializes appends, each needing the hash of the last one.
+const auditChainLock = 0x61756469
+
+var lockAuditChainStmt = dbStatement{
+	nil, `
+	SELECT pg_advisory_xact_lock($1)`,
+}
+var getLastAuditHashStmt = dbStatement{
+	nil, `
+	SELECT
+		hash
+	FROM
+		audit_event
+	ORDER BY
+		id DESC
+	LIMIT
+		1`,
+}
+var addAuditEventStmt = dbStatement{
+	nil, `
+	INSERT INTO
+		audit_event (occurred_at, action, actor, ip, user_agent, request_id, payload, prev_hash, hash)
+	VALUES
+		($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+}
+
+const auditEventColumns = `
+		id, occurred_at, action, actor, ip, user_agent, request_id, payload::text, prev_hash, hash`
+
+const auditFilterStr = `
+	WHERE
+		($1 = '' OR actor = $1)
+		AND ($2 = '' OR action = $2)
+		AND ($3::timestamptz IS NULL OR occurred_at >= $3)
+		AND ($4::timestamptz IS NULL OR occurred_at < $4)`
+
+var listAuditEventsStmt = dbStatement{
+	nil, `
+	SELECT` + auditEventColumns + `
+	FROM
+		audit_event` + auditFilterStr + `
+	ORDER BY
+		id DESC
+	LIMIT
+		$5 OFFSET $6`,
+}
+var exportAuditEventsStmt = dbStatement{
+	nil, `
+	SELECT` + auditEventColumns + `
+	FROM
+		audit_event` + auditFilterStr + `
+	ORDER BY
+		id ASC`,
+}
+var walkAuditChainStmt = dbStatement{
+	nil, `
+	SELECT` + auditEventColumns + `
+	FROM
+		audit_event
+	ORDER BY
+		id ASC`,
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"lockAuditChain":    &lockAuditChainStmt,
+		"getLastAuditHash":  &getLastAuditHashStmt,
+		"addAuditEvent":     &addAuditEventStmt,
+		"listAuditEvents":   &listAuditEventsStmt,
+		"exportAuditEvents": &exportAuditEventsStmt,
+		"walkAuditChain":    &walkAuditChainStmt,
+	})
+}
+
+// auditHash chains event to the event before it, hashing every field that
+// was recorded.
+func auditHash(prevHash string, event AuditEvent) string {
+	fields, _ := json.Marshal([]string{
+		prevHash,
+		event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		event.Action,
+		event.Actor,
+		event.IP,
+		event.UserAgent,
+		event.RequestID,
+		string(event.Payload),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+func (db DBInstance) RecordAuditEvent(ctx context.Context, event AuditEvent) error {
+	// Postgres keeps microseconds, the hash has to be of what's stored.
+	event.OccurredAt = time.Now().UTC().Truncate(time.Microsecond)
+	if len(event.Payload) == 0 {
+		event.Payload = json.RawMessage("{}")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockAuditChainStmt.Tx(ctx, tx).ExecContext(ctx, auditChainLock); err != nil {
+		return err
+	}
+	var prevHash string
+	if err := getLastAuditHashStmt.Tx(ctx, tx).QueryRowContext(ctx).Scan(&prevHash); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	hash := auditHash(prevHash, event)
+	if _, err := addAuditEventStmt.Tx(ctx, tx).ExecContext(ctx,
+		event.OccurredAt,
+		event.Action,
+		event.Actor,
+		event.IP,
+		event.UserAgent,
+		event.RequestID,
+		string(event.Payload),
+		prevHash,
+		hash,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func auditFilterArgs(filter AuditFilter) []any {
+	var from, until sql.NullTime
+	if !filter.From.IsZero() {
+		from = sql.NullTime{Time: filter.From, Valid: true}
+	}
+	if !filter.Until.IsZero() {
+		until = sql.NullTime{Time: filter.Until, Valid: true}
+	}
+	return []any{filter.Actor, filter.Action, from, until}
+}
+
+func scanAuditEvents(rows *sql.Rows, each func(AuditEvent) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		var event AuditEvent
+		var payload string
+		if err := rows.Scan(
+			&event.ID,
+			&event.OccurredAt,
+			&event.Action,
+			&event.Actor,
+			&event.IP,
+			&event.UserAgent,
+			&event.RequestID,
+			&payload,
+			&event.PrevHash,
+			&event.Hash,
+		); err != nil {
+			return err
+		}
+		event.Payload = json.RawMessage(payload)
+		// prev_hash used to be a char(64), which reads the empty hash of the
+		// first event back as spaces.
+		event.PrevHash = strings.TrimRight(event.PrevHash, " ")
+		if err := each(event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListAuditEvents lists the events matching filter, the newest first.
+func (db DBInstance) ListAuditEvents(ctx context.Context, filter AuditFilter, limit int, offset int) ([]AuditEvent, error) {
+	rows, err := listAuditEventsStmt.QueryContext(ctx, append(auditFilterArgs(filter), limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []AuditEvent{}
+	err = scanAuditEvents(rows, func(event AuditEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	return events, err
+}
+
+// ExportAuditEvents calls each with every event matching filter, the oldest
+// first.
+func (db DBInstance) ExportAuditEvents(ctx context.Context, filter AuditFilter, each func(AuditEvent) error) error {
+	rows, err := exportAuditEventsStmt.QueryContext(ctx, auditFilterArgs(filter)...)
+	if err != nil {
+		return err
+	}
+	return scanAuditEvents(rows, each)
+}
+
+// VerifyAuditChain walks the whole log, checking that each event links to
+// the one before it and that its hash still matches its fields.
+func (db DBInstance) VerifyAuditChain(ctx context.Context) (AuditVerification, error) {
+	rows, err := walkAuditChainStmt.QueryContext(ctx)
+	if err != nil {
+		return AuditVerification{}, err
+	}
+
+	var verification AuditVerification
+	prevHash := ""
+	err = scanAuditEvents(rows, func(event AuditEvent) error {
+		if verification.BrokenAt != 0 {
+			return nil
+		}
+		verification.Checked++
+		if event.PrevHash != prevHash || auditHash(prevHash, event) != event.Hash {
+			verification.BrokenAt = event.ID
+		}
+		prevHash = event.Hash
+		return nil
+	})
+	return verification, err
+}
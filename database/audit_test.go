@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var auditColumns = []string{"id", "occurred_at", "action", "actor", "ip", "user_agent", "request_id", "payload", "prev_hash", "hash"}
+
+// chainedAuditEvents are events the way RecordAuditEvent would've chained them.
+func chainedAuditEvents(n int) []AuditEvent {
+	events := make([]AuditEvent, n)
+	prevHash := ""
+	for i := range events {
+		events[i] = AuditEvent{
+			ID:         int64(i + 1),
+			OccurredAt: time.Date(2026, time.October, 19, 8, 30, i, 123456000, time.UTC),
+			Action:     AuditLoginSucceeded,
+			Actor:      expEmail,
+			IP:         "192.0.2.1",
+			UserAgent:  "Go-http-client/1.1",
+			Payload:    json.RawMessage(`{"method":"password"}`),
+			PrevHash:   prevHash,
+		}
+		events[i].Hash = auditHash(prevHash, events[i])
+		prevHash = events[i].Hash
+	}
+	return events
+}
+
+func auditRows(events []AuditEvent) *sqlmock.Rows {
+	rows := sqlmock.NewRows(auditColumns)
+	for _, e := range events {
+		rows.AddRow(e.ID, e.OccurredAt, e.Action, e.Actor, e.IP, e.UserAgent, e.RequestID, string(e.Payload), e.PrevHash, e.Hash)
+	}
+	return rows
+}
+
+func TestRecordAuditEvent(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	prevHash := chainedAuditEvents(1)[0].Hash
+	event := AuditEvent{Action: AuditGenreCreated, Actor: expEmail, IP: "192.0.2.1", RequestID: "host/abc-000001"}
+
+	mock.ExpectPrepare("pg_advisory_xact_lock")
+	mock.ExpectPrepare("SELECT")
+	mock.ExpectPrepare("INSERT INTO")
+	mock.ExpectBegin()
+	mock.ExpectExec("pg_advisory_xact_lock").
+		WithArgs(auditChainLock).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"hash"}).AddRow(prevHash))
+	var occurredAt time.Time
+	var hash string
+	mock.ExpectExec("INSERT INTO").
+		WithArgs(
+			argCapture(func(v driver.Value) { occurredAt = v.(time.Time) }),
+			event.Action, event.Actor, event.IP, event.UserAgent, event.RequestID, "{}", prevHash,
+			argCapture(func(v driver.Value) { hash = v.(string) }),
+		).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	require.Nil(t, lockAuditChainStmt.Prepare(ctx, d))
+	require.Nil(t, getLastAuditHashStmt.Prepare(ctx, d))
+	require.Nil(t, addAuditEventStmt.Prepare(ctx, d))
+
+	if assert.Nil(t, db.RecordAuditEvent(ctx, event), "unexpected error recording an audit event") {
+		event.OccurredAt = occurredAt
+		event.Payload = json.RawMessage("{}")
+		assert.Equal(t, auditHash(prevHash, event), hash, "the event should've been chained to the last one")
+		assert.Equal(t, occurredAt, occurredAt.Truncate(time.Microsecond), "the event's time should've been kept to what postgres stores")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	ctx := context.Background()
+
+	intact := chainedAuditEvents(4)
+	tampered := chainedAuditEvents(4)
+	tampered[2].Actor = "someone@else.com"
+	removed := append(chainedAuditEvents(4)[:1], chainedAuditEvents(4)[2:]...)
+	// What Postgres returned for the first event while prev_hash was a
+	// char(64).
+	padded := chainedAuditEvents(4)
+	padded[0].PrevHash = strings.Repeat(" ", 64)
+
+	tests := []struct {
+		name   string
+		events []AuditEvent
+		exp    AuditVerification
+	}{
+		{"intact", intact, AuditVerification{Checked: 4}},
+		{"tampered", tampered, AuditVerification{Checked: 3, BrokenAt: 3}},
+		{"removed", removed, AuditVerification{Checked: 2, BrokenAt: 3}},
+		{"padded", padded, AuditVerification{Checked: 4}},
+	}
+
+	for _, test := range tests {
+		d, mock, err := sqlmock.New()
+		require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+
+		mock.ExpectPrepare("SELECT").
+			ExpectQuery().
+			WillReturnRows(auditRows(test.events)).
+			RowsWillBeClosed()
+		require.Nil(t, walkAuditChainStmt.Prepare(ctx, d))
+
+		verification, err := DBInstance{DB: d}.VerifyAuditChain(ctx)
+		if assert.Nil(t, err, "unexpected error verifying the %s audit log", test.name) {
+			assert.Equal(t, test.exp, verification, "unexpected verification of the %s audit log", test.name)
+		}
+		assert.Nil(t, mock.ExpectationsWereMet())
+		d.Close()
+	}
+}
+
+// argCapture matches any argument, handing it to capture.
+type argCapture func(v driver.Value)
+
+func (c argCapture) Match(v driver.Value) bool {
+	c(v)
+	return true
+}
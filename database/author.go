@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CreditAuthor      = "author"
+	CreditIllustrator = "illustrator"
+	CreditTranslator  = "translator"
+	CreditEditor      = "editor"
+)
+
+// bookAuthorsStr aggregates a book's credits, in billing order, as a JSON
+// array scanned into BookAuthors.
+const bookAuthorsStr = `
+		COALESCE(
+			(
+				SELECT
+					json_agg(
+						json_build_object('id', a.id, 'name', a.name, 'role', ba.role)
+						ORDER BY ba.position, ba.role
+					)
+				FROM
+					book_author ba
+					JOIN author a ON a.id = ba.author_id
+				WHERE
+					ba.book_id = b.id
+			),
+			'[]'
+		)`
+
+var searchAuthorsStmt = dbStatement{
+	nil, `
+	SELECT
+		a.id,
+		a.name,
+		count(DISTINCT ba.book_id)
+	FROM
+		author a
+		LEFT JOIN book_author ba ON a.id = ba.author_id
+	WHERE
+		a.name ILIKE '%' || $1 || '%'
+	GROUP BY
+		a.id
+	ORDER BY
+		a.name ASC,
+		a.id ASC
+	LIMIT
+		$2 OFFSET $3;`,
+}
+
+var getAuthorStmt = dbStatement{
+	nil, `
+	SELECT
+		a.id,
+		a.name,
+		count(DISTINCT ba.book_id)
+	FROM
+		author a
+		LEFT JOIN book_author ba ON a.id = ba.author_id
+	WHERE
+		a.id = $1
+	GROUP BY
+		a.id;`,
+}
+
+var getAuthorBooksStmt = dbStatement{
+	nil, fmt.Sprintf(bookSelectStr, `
+	WHERE
+		b.id IN (
+			SELECT
+				book_id
+			FROM
+				book_author
+			WHERE
+				author_id = $2
+		)
+	ORDER BY
+		b.publication_date ASC NULLS LAST,
+		b.title ASC`),
+}
+
+var deleteBookCreditsStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		book_author
+	WHERE
+		book_id = $1;`,
+}
+
+// addBookCreditStmt links a book to the first author with a matching name,
+// creating the author when there is none.
+var addBookCreditStmt = dbStatement{
+	nil, `
+	WITH found AS (
+		SELECT
+			id
+		FROM
+			author
+		WHERE
+			lower(name) = lower($2)
+		ORDER BY
+			id ASC
+		LIMIT
+			1
+	), created AS (
+		INSERT INTO author (
+			id, name
+		)
+		SELECT
+			$1, $2
+		WHERE
+			NOT EXISTS (SELECT 1 FROM found)
+		RETURNING
+			id
+	)
+	INSERT INTO book_author (
+		book_id, author_id, role, position
+	)
+	SELECT
+		$3, id, $4, $5
+	FROM
+		(
+			SELECT id FROM found
+			UNION ALL
+			SELECT id FROM created
+		) AS credited
+	ON CONFLICT DO NOTHING;`,
+}
+
+type AuthorInterface interface {
+	SearchAuthors(ctx context.Context, limit int, offset int, query string) ([]Author, error)
+	GetAuthor(ctx context.Context, authorID uuid.UUID) (*Author, error)
+	GetAuthorBooks(ctx context.Context, authorID uuid.UUID, accountID string) ([]Book, error)
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"searchAuthors":     &searchAuthorsStmt,
+		"getAuthor":         &getAuthorStmt,
+		"getAuthorBooks":    &getAuthorBooksStmt,
+		"deleteBookCredits": &deleteBookCreditsStmt,
+		"addBookCredit":     &addBookCreditStmt,
+	})
+}
+
+type Author struct {
+	ID        uuid.UUID
+	Name      string
+	BookCount int
+}
+
+// BookAuthor is one credit on a book. ID is left empty for credits that are
+// only known by name, like the ones read from an import feed.
+type BookAuthor struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Role string    `json:"role"`
+}
+
+type BookAuthors []BookAuthor
+
+func (b *BookAuthors) Scan(value any) error {
+	return scanJSONList(value, b)
+}
+
+var ErrAuthorNotFound = errors.New("author not found")
+
+// authorSeparator matches what joins names in the legacy author strings.
+// listSeparator also takes commas, which only join names in a list closed by
+// "and" or "&" and without semicolons: a bare comma is more likely an inverted
+// name like "Tolkien, J. R. R.", and semicolons list inverted names. The
+// schema file splits them with the same patterns.
+var (
+	authorSeparator   = regexp.MustCompile(`\s*(?:;|&|\s+and\s+)\s*`)
+	listSeparator     = regexp.MustCompile(`\s*(?:,?\s+and\s+|,|;|&)\s*`)
+	authorConjunction = regexp.MustCompile(`&|\s+and\s+`)
+)
+
+// SplitAuthorNames splits a display string like "A, B and C" into the names
+// it credits.
+func SplitAuthorNames(author string) []string {
+	author = strings.TrimSpace(author)
+	separator := authorSeparator
+	if !strings.Contains(author, ";") && authorConjunction.MatchString(author) {
+		separator = listSeparator
+	}
+	var names []string
+	for _, name := range separator.Split(author, -1) {
+		if name = strings.Trim(name, ", "); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (db DBInstance) SearchAuthors(ctx context.Context, limit int, offset int, query string) ([]Author, error) {
+	if limit <= 0 || offset < 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+
+	rows, err := searchAuthorsStmt.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []Author
+	for rows.Next() {
+		author := Author{}
+		if err := rows.Scan(&author.ID, &author.Name, &author.BookCount); err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+func (db DBInstance) GetAuthor(ctx context.Context, authorID uuid.UUID) (*Author, error) {
+	author := Author{}
+	if err := getAuthorStmt.QueryRowContext(ctx, authorID).
+		Scan(&author.ID, &author.Name, &author.BookCount); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAuthorNotFound
+		}
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (db DBInstance) GetAuthorBooks(ctx context.Context, authorID uuid.UUID, accountID string) ([]Book, error) {
+	rows, err := getAuthorBooksStmt.QueryContext(ctx, accountID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// setBookCredits replaces every credit of a book inside tx. Credits without a
+// role are counted as authorship.
+func setBookCredits(ctx context.Context, tx *sql.Tx, bookID uuid.UUID, credits []BookAuthor) error {
+	if _, err := deleteBookCreditsStmt.Tx(ctx, tx).ExecContext(ctx, bookID); err != nil {
+		return err
+	}
+
+	addCredit := addBookCreditStmt.Tx(ctx, tx)
+	for position, credit := range credits {
+		if credit.Role == "" {
+			credit.Role = CreditAuthor
+		}
+		randomUUID, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		if _, err := addCredit.ExecContext(ctx, randomUUID, credit.Name, bookID, credit.Role, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAuthorNames(t *testing.T) {
+	tests := []struct {
+		author   string
+		expNames []string
+	}{
+		{"Hayashi Naotaka", []string{"Hayashi Naotaka"}},
+		{"Hayashi Naotaka, Chiyomaru Shikura and Ono Fuyumi", []string{"Hayashi Naotaka", "Chiyomaru Shikura", "Ono Fuyumi"}},
+		{"Hayashi Naotaka, Chiyomaru Shikura, and Ono Fuyumi", []string{"Hayashi Naotaka", "Chiyomaru Shikura", "Ono Fuyumi"}},
+		{"Tolkien, J. R. R.", []string{"Tolkien, J. R. R."}},
+		{"Tolkien, J. R. R.; Lewis, C. S.", []string{"Tolkien, J. R. R.", "Lewis, C. S."}},
+		{"A and B & C; D", []string{"A", "B", "C", "D"}},
+		{"Anderson Sandy", []string{"Anderson Sandy"}},
+		{" , ", nil},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expNames, SplitAuthorNames(test.author), "unexpected names splitting %q", test.author)
+	}
+}
+
+func TestScanBookAuthors(t *testing.T) {
+	authorID := uuid.New()
+
+	var authors BookAuthors
+	err := authors.Scan([]byte(`[{"id": "` + authorID.String() + `", "name": "MC2", "role": "translator"}]`))
+	if assert.Nil(t, err, "unexpected error scanning book authors") {
+		assert.Equal(t, BookAuthors{{ID: authorID, Name: "MC2", Role: CreditTranslator}}, authors)
+	}
+
+	err = authors.Scan("[]")
+	if assert.Nil(t, err, "unexpected error scanning no book authors") {
+		assert.Nil(t, authors, "an empty credit list should've been scanned as nil")
+	}
+}
+
+func TestSuccessfulSearchAuthors(t *testing.T) {
+	ctx := context.Background()
+
+	expAuthors := []Author{
+		{ID: uuid.New(), Name: "Hayashi Naotaka", BookCount: 3},
+		{ID: uuid.New(), Name: "Hayashi Naotaka", BookCount: 1},
+	}
+
+	rows := sqlmock.NewRows([]string{"a.id", "a.name", "count"})
+	for _, a := range expAuthors {
+		rows.AddRow(a.ID, a.Name, a.BookCount)
+	}
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs("Hayashi", 20, 0).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	err = searchAuthorsStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	authors, err := db.SearchAuthors(ctx, 20, 0, "Hayashi")
+	if assert.Nil(t, err, "unexpected error in a successful author search test") {
+		assert.Equal(t, expAuthors, authors, "function should've returned every matching author")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestNotFoundGetAuthor(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	authorID := uuid.New()
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(authorID).
+		WillReturnRows(sqlmock.NewRows([]string{"a.id", "a.name", "count"}))
+
+	err = getAuthorStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	author, err := db.GetAuthor(ctx, authorID)
+	assert.Nil(t, author, "function should've returned no author")
+	assert.Equal(t, ErrAuthorNotFound, err, "function should've reported the author as missing")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -2,19 +2,34 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-const getNewBooksStr = `
-	SELECT
+// bookReadersStr counts the accounts that started reading a book.
+const bookReadersStr = `
+		(
+			SELECT
+				count(*)
+			FROM
+				reading_progress rp
+			WHERE
+				rp.book_id = b.id
+		)`
+
+// bookColumnsStr lists the columns read by bookColumns, from book b joined
+// with rating_avg av. The first parameter is always the account whose
+// favorites are checked.
+const bookColumnsStr = `
 		b.id,
 		b.title,
 		b.cover_image,
-		b.author,
-		b.readers_count,
+		b.cover_id,
+		b.author,` + bookReadersStr + `,
 		av.rating,
 		EXISTS (
 			SELECT
@@ -24,68 +39,86 @@ const getNewBooksStr = `
 			WHERE
 				f.user_id = $1
 				AND f.book_id = b.id
-		) AS is_favorited
+		) AS is_favorited,
+		COALESCE(b.isbn, ''),
+		COALESCE(b.publisher, ''),
+		b.publication_date,
+		COALESCE(b.language, ''),
+		COALESCE(b.page_count, 0),
+		COALESCE(b.edition, ''),
+		COALESCE(b.format, ''),` + bookAuthorsStr + `,` + bookGenresStr + `,` + bookTagsStr
+
+// bookSelectStr selects the columns read by scanBooks. The verb takes the
+// rest of the query, from further joins on.
+const bookSelectStr = `
+	SELECT` + bookColumnsStr + `
 	FROM
 		book b
-		LEFT JOIN rating_avg AS av ON b.id = av.id 
+		LEFT JOIN rating_avg AS av ON b.id = av.id
+	%s;`
+
+// New arrivals are books acquired within a window, given in seconds by the
+// parameter the verb is filled with.
+const getNewBooksStr = `
 	WHERE
-		b.is_new
+		b.acquired_at >= now() - $%d * interval '1 second'%s
 	ORDER BY
-		b.title ASC%s;`
+		b.acquired_at DESC,
+		b.title ASC%s`
 
 var getNewBooks = dbStatement{
 	nil,
-	fmt.Sprintf(getNewBooksStr, ""),
+	fmt.Sprintf(bookSelectStr, fmt.Sprintf(getNewBooksStr, 2, "", "")),
 }
 var getNewBooksPaginated = dbStatement{
 	nil,
-	fmt.Sprintf(getNewBooksStr, `
-			LIMIT
-				$2 OFFSET $3`),
+	fmt.Sprintf(bookSelectStr, fmt.Sprintf(getNewBooksStr, 5, genreFilterStr(4), `
+	LIMIT
+		$2 OFFSET $3`)),
 }
 
+// Popular books are ranked by their score in book_popularity for the window
+// named by the parameter the verb is filled with.
 const getPopularBooksStr = `
-	SELECT
-		b.id,
-		b.title,
-		b.cover_image,
-		b.author,
-		b.readers_count,
-		av.rating,
-		EXISTS (
-			SELECT
-				1
-			FROM
-				fav_book f
-			WHERE
-				f.user_id = $1
-				AND f.book_id = b.id
-		) AS is_favorited
-	FROM
-		book b
-		LEFT JOIN rating_avg AS av ON b.id = av.id 
+		JOIN book_popularity p ON p.book_id = b.id AND p.time_window = $%d
 	WHERE
-		b.is_popular
+		p.score > 0%s
 	ORDER BY
-		b.title ASC%s;`
+		p.score DESC,
+		b.title ASC%s`
 
 var getPopularBooks = dbStatement{
-	nil, fmt.Sprintf(getPopularBooksStr, ""),
+	nil, fmt.Sprintf(bookSelectStr, fmt.Sprintf(getPopularBooksStr, 2, "", "")),
 }
 var getPopularBooksPaginated = dbStatement{
-	nil, fmt.Sprintf(getPopularBooksStr, `
+	nil, fmt.Sprintf(bookSelectStr, fmt.Sprintf(getPopularBooksStr, 5, genreFilterStr(4), `
 	LIMIT
-		$2 OFFSET $3`),
+		$2 OFFSET $3`)),
 }
 
 var searchBooks = dbStatement{
+	nil, fmt.Sprintf(bookSelectStr, `
+	WHERE
+		(
+			b.title ILIKE '%' || $2 || '%'
+			OR b.author ILIKE '%' || $2 || '%'
+			OR b.isbn = $5
+		)`+genreFilterStr(6)+`
+	ORDER BY
+		b.title ASC
+	LIMIT
+		$3 OFFSET $4`),
+}
+
+var getBookStmt = dbStatement{
 	nil, `
 	SELECT
 		b.id,
 		b.title,
 		b.cover_image,
+		b.cover_id,
 		b.author,
-		b.readers_count,
+		b.summary,` + bookReadersStr + `,
 		av.rating,
 		EXISTS (
 			SELECT
@@ -95,67 +128,96 @@ var searchBooks = dbStatement{
 			WHERE
 				f.user_id = $1
 				AND f.book_id = b.id
-		) AS is_favorited
+		) AS is_favorited,
+		COALESCE(b.isbn, ''),
+		COALESCE(b.publisher, ''),
+		b.publication_date,
+		COALESCE(b.language, ''),
+		COALESCE(b.page_count, 0),
+		COALESCE(b.edition, ''),
+		COALESCE(b.format, ''),` + bookAuthorsStr + `,` + bookGenresStr + `,` + bookTagsStr + `,` + bookShelvesStr + `
 	FROM
 		book b
 		LEFT JOIN rating_avg AS av ON b.id = av.id
 	WHERE
-		b.title ILIKE '%' || $2 || '%'
-		OR b.author ILIKE '%' || $2 || '%'
-	ORDER BY
-		b.title ASC
-	LIMIT
-		$3 OFFSET $4;`,
+		b.id = $2;`,
 }
 
 type BookInterface interface {
-	SearchBooks(ctx context.Context, limit int, offset int, query string, accountID string) ([]Book, error)
-	GetNewBooks(ctx context.Context, accountID string) ([]Book, error)
-	GetNewBooksPaginated(ctx context.Context, limit int, offset int, accountID string) ([]Book, error)
-	GetPopularBooks(ctx context.Context, accountID string) ([]Book, error)
-	GetPopularBooksPaginated(ctx context.Context, limit int, offset int, accountID string) ([]Book, error)
+	SearchBooks(ctx context.Context, limit int, offset int, query string, genreID uuid.NullUUID, accountID string) ([]Book, error)
+	GetNewBooks(ctx context.Context, newWindow time.Duration, accountID string) ([]Book, error)
+	GetNewBooksPaginated(ctx context.Context, limit int, offset int, newWindow time.Duration, genreID uuid.NullUUID, accountID string) ([]Book, error)
+	GetPopularBooks(ctx context.Context, window string, accountID string) ([]Book, error)
+	GetPopularBooksPaginated(ctx context.Context, limit int, offset int, window string, genreID uuid.NullUUID, accountID string) ([]Book, error)
+	GetBook(ctx context.Context, bookID uuid.UUID, accountID string) (*Book, error)
+	RecordBookView(ctx context.Context, bookID uuid.UUID, accountID string) error
 }
 
 func init() {
-	prepareStatements = append(prepareStatements,
-		&getNewBooks,
-		&getNewBooksPaginated,
-		&getPopularBooks,
-		&getPopularBooksPaginated,
-		&searchBooks,
-	)
+	registerStatements(map[string]*dbStatement{
+		"getNewBooks":              &getNewBooks,
+		"getNewBooksPaginated":     &getNewBooksPaginated,
+		"getPopularBooks":          &getPopularBooks,
+		"getPopularBooksPaginated": &getPopularBooksPaginated,
+		"searchBooks":              &searchBooks,
+		"getBook":                  &getBookStmt,
+	})
 }
 
 type Book struct {
-	ID      uuid.UUID
-	Title   string
-	Author  string
-	Cover   URL
-	Summary string
-	Readers int
-	Rating  float32
-	IsFav   bool
+	ID          uuid.UUID
+	Title       string
+	Author      string
+	Cover       URL
+	CoverID     uuid.NullUUID
+	Summary     string
+	Readers     int
+	Rating      float32
+	IsFav       bool
+	ISBN        string
+	Publisher   string
+	PublishedOn *time.Time
+	Language    string
+	PageCount   int
+	Edition     string
+	Format      string
+	Authors     BookAuthors
+	Genres      BookGenres
+	Tags        BookTags
+	Shelves     BookShelves
 }
 
-func (db DBInstance) GetNewBooks(ctx context.Context, accountID string) ([]Book, error) {
-	var books []Book
-	rows, err := getNewBooks.Statement.QueryContext(ctx, accountID)
-	if err != nil {
-		return nil, err
+// bookColumns returns the scan destinations for the columns of bookColumnsStr.
+func bookColumns(book *Book) []any {
+	return []any{
+		&book.ID,
+		&book.Title,
+		&book.Cover,
+		&book.CoverID,
+		&book.Author,
+		&book.Readers,
+		&book.Rating,
+		&book.IsFav,
+		&book.ISBN,
+		&book.Publisher,
+		&book.PublishedOn,
+		&book.Language,
+		&book.PageCount,
+		&book.Edition,
+		&book.Format,
+		&book.Authors,
+		&book.Genres,
+		&book.Tags,
 	}
+}
+
+func scanBooks(rows *sql.Rows) ([]Book, error) {
+	var books []Book
 	defer rows.Close()
 
 	for rows.Next() {
 		book := Book{}
-		if err := rows.Scan(
-			&book.ID,
-			&book.Title,
-			&book.Cover,
-			&book.Author,
-			&book.Readers,
-			&book.Rating,
-			&book.IsFav,
-		); err != nil {
+		if err := rows.Scan(bookColumns(&book)...); err != nil {
 			return nil, err
 		}
 		books = append(books, book)
@@ -167,128 +229,105 @@ func (db DBInstance) GetNewBooks(ctx context.Context, accountID string) ([]Book,
 	return books, nil
 }
 
-func (db DBInstance) GetNewBooksPaginated(ctx context.Context, limit int, offset int, accountID string) ([]Book, error) {
-	var books []Book
+func (db DBInstance) GetNewBooks(ctx context.Context, newWindow time.Duration, accountID string) ([]Book, error) {
+	return readCatalog(ctx, db, accountID, &getNewBooks, func(stmt catalogQuerier) ([]Book, error) {
+		rows, err := stmt.QueryContext(ctx, accountID, newWindow.Seconds())
+		if err != nil {
+			return nil, err
+		}
+		return scanBooks(rows)
+	})
+}
+
+func (db DBInstance) GetNewBooksPaginated(ctx context.Context, limit int, offset int, newWindow time.Duration, genreID uuid.NullUUID, accountID string) ([]Book, error) {
 	if limit <= 0 || offset < 0 {
 		return nil, errors.New("function parameters outside the bounds")
 	}
-	rows, err := getNewBooksPaginated.Statement.QueryContext(ctx, accountID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		book := Book{}
-		if err := rows.Scan(
-			&book.ID,
-			&book.Title,
-			&book.Cover,
-			&book.Author,
-			&book.Readers,
-			&book.Rating,
-			&book.IsFav,
-		); err != nil {
+	return readCatalog(ctx, db, accountID, &getNewBooksPaginated, func(stmt catalogQuerier) ([]Book, error) {
+		rows, err := stmt.QueryContext(ctx, accountID, limit, offset, genreID, newWindow.Seconds())
+		if err != nil {
 			return nil, err
 		}
-		books = append(books, book)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return books, nil
+		return scanBooks(rows)
+	})
 }
 
-func (db DBInstance) GetPopularBooks(ctx context.Context, accountID string) ([]Book, error) {
-	var books []Book
-	rows, err := getPopularBooks.Statement.QueryContext(ctx, accountID)
-	if err != nil {
-		return nil, err
+func (db DBInstance) GetPopularBooks(ctx context.Context, window string, accountID string) ([]Book, error) {
+	if !IsPopularityWindow(window) {
+		return nil, ErrPopularityWindowUnknown
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		book := Book{}
-		if err := rows.Scan(
-			&book.ID,
-			&book.Title,
-			&book.Cover,
-			&book.Author,
-			&book.Readers,
-			&book.Rating,
-			&book.IsFav,
-		); err != nil {
+	return readCatalog(ctx, db, accountID, &getPopularBooks, func(stmt catalogQuerier) ([]Book, error) {
+		rows, err := stmt.QueryContext(ctx, accountID, window)
+		if err != nil {
 			return nil, err
 		}
-		books = append(books, book)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return books, nil
+		return scanBooks(rows)
+	})
 }
 
-func (db DBInstance) GetPopularBooksPaginated(ctx context.Context, limit int, offset int, accountID string) ([]Book, error) {
-	var books []Book
+func (db DBInstance) GetPopularBooksPaginated(ctx context.Context, limit int, offset int, window string, genreID uuid.NullUUID, accountID string) ([]Book, error) {
 	if limit <= 0 || offset < 0 {
 		return nil, errors.New("function parameters outside the bounds")
 	}
-	rows, err := getPopularBooksPaginated.Statement.QueryContext(ctx, accountID, limit, offset)
-	if err != nil {
-		return nil, err
+	if !IsPopularityWindow(window) {
+		return nil, ErrPopularityWindowUnknown
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		book := Book{}
-		if err := rows.Scan(
-			&book.ID,
-			&book.Title,
-			&book.Cover,
-			&book.Author,
-			&book.Readers,
-			&book.Rating,
-			&book.IsFav,
-		); err != nil {
+	return readCatalog(ctx, db, accountID, &getPopularBooksPaginated, func(stmt catalogQuerier) ([]Book, error) {
+		rows, err := stmt.QueryContext(ctx, accountID, limit, offset, genreID, window)
+		if err != nil {
 			return nil, err
 		}
-		books = append(books, book)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return books, nil
+		return scanBooks(rows)
+	})
 }
 
-func (db DBInstance) SearchBooks(ctx context.Context, limit int, offset int, query string, accountID string) ([]Book, error) {
-	var books []Book
-	rows, err := searchBooks.Statement.QueryContext(ctx, accountID, query, limit, offset)
-	if err != nil {
-		return nil, err
+func (db DBInstance) SearchBooks(ctx context.Context, limit int, offset int, query string, genreID uuid.NullUUID, accountID string) ([]Book, error) {
+	// Searching by ISBN has to match however the query was typed, so it's
+	// normalized the same way as stored ISBNs.
+	var isbn sql.NullString
+	if normalized, err := NormalizeISBN(query); err == nil {
+		isbn = sql.NullString{String: normalized, Valid: true}
 	}
-	defer rows.Close()
 
-	for rows.Next() {
+	return readCatalog(ctx, db, accountID, &searchBooks, func(stmt catalogQuerier) ([]Book, error) {
+		rows, err := stmt.QueryContext(ctx, accountID, query, limit, offset, isbn, genreID)
+		if err != nil {
+			return nil, err
+		}
+		return scanBooks(rows)
+	})
+}
+
+func (db DBInstance) GetBook(ctx context.Context, bookID uuid.UUID, accountID string) (*Book, error) {
+	return readCatalog(ctx, db, accountID, &getBookStmt, func(stmt catalogQuerier) (*Book, error) {
 		book := Book{}
-		if err := rows.Scan(
+		if err := stmt.QueryRowContext(ctx, accountID, bookID).Scan(
 			&book.ID,
 			&book.Title,
 			&book.Cover,
+			&book.CoverID,
 			&book.Author,
+			&book.Summary,
 			&book.Readers,
 			&book.Rating,
 			&book.IsFav,
+			&book.ISBN,
+			&book.Publisher,
+			&book.PublishedOn,
+			&book.Language,
+			&book.PageCount,
+			&book.Edition,
+			&book.Format,
+			&book.Authors,
+			&book.Genres,
+			&book.Tags,
+			&book.Shelves,
 		); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrBookNotFound
+			}
 			return nil, err
 		}
-		books = append(books, book)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return books, nil
+		return &book, nil
+	})
 }
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type BookCoverInterface interface {
+	SetBookCover(ctx context.Context, bookID uuid.UUID, coverID uuid.UUID) (oldCoverID uuid.NullUUID, err error)
+}
+
+var setBookCoverStmt = dbStatement{
+	nil, `
+	UPDATE book b
+	SET
+		cover_id = $2
+	FROM
+		book old
+	WHERE
+		b.id = $1
+		AND old.id = b.id
+	RETURNING
+		old.cover_id;`,
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"setBookCover": &setBookCoverStmt,
+	})
+}
+
+var ErrBookNotFound = errors.New("book not found")
+
+func (db DBInstance) SetBookCover(ctx context.Context, bookID uuid.UUID, coverID uuid.UUID) (oldCoverID uuid.NullUUID, err error) {
+	if err := setBookCoverStmt.QueryRowContext(ctx, bookID, coverID).Scan(&oldCoverID); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.NullUUID{}, ErrBookNotFound
+		}
+		return uuid.NullUUID{}, err
+	}
+	return
+}
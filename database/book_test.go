@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
@@ -35,19 +37,41 @@ func TestSuccessfulNewBooks(t *testing.T) {
 			"b.id",
 			"b.title",
 			"b.cover_image",
+			"b.cover_id",
 			"b.author",
 			"b.readers_count",
 			"av.rating",
-			"is_favorited"})
+			"is_favorited",
+			"isbn",
+			"publisher",
+			"publication_date",
+			"language",
+			"page_count",
+			"edition",
+			"format",
+			"authors",
+			"genres",
+			"tags"})
 	for _, b := range expBooks {
 		rows.AddRow(
 			b.ID,
 			b.Title,
 			b.Cover.String(),
+			nil,
 			b.Author,
 			b.Readers,
 			b.Rating,
 			b.IsFav,
+			b.ISBN,
+			b.Publisher,
+			b.PublishedOn,
+			b.Language,
+			b.PageCount,
+			b.Edition,
+			b.Format,
+			"[]",
+			"[]",
+			"[]",
 		)
 	}
 
@@ -55,18 +79,18 @@ func TestSuccessfulNewBooks(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	mock.ExpectPrepare("SELECT").
 		ExpectQuery().
-		WithArgs(expEmail).
+		WithArgs(expEmail, float64(30*24*60*60)).
 		WillReturnRows(rows).
 		RowsWillBeClosed()
 
 	err = getNewBooks.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
-	books, err := db.GetNewBooks(ctx, expEmail)
+	books, err := db.GetNewBooks(ctx, 30*24*time.Hour, expEmail)
 	if assert.Nil(t, err, "unexpected error in a successful get new books test") {
 		assert.Equal(t, expBooks, books, "function should've returned a list of new books")
 	}
@@ -97,19 +121,41 @@ func TestSuccessfulNewBooksPaginated(t *testing.T) {
 			"b.id",
 			"b.title",
 			"b.cover_image",
+			"b.cover_id",
 			"b.author",
 			"b.readers_count",
 			"av.rating",
-			"is_favorited"})
+			"is_favorited",
+			"isbn",
+			"publisher",
+			"publication_date",
+			"language",
+			"page_count",
+			"edition",
+			"format",
+			"authors",
+			"genres",
+			"tags"})
 	for _, b := range expBooks {
 		rows.AddRow(
 			b.ID,
 			b.Title,
 			b.Cover.String(),
+			nil,
 			b.Author,
 			b.Readers,
 			b.Rating,
 			b.IsFav,
+			b.ISBN,
+			b.Publisher,
+			b.PublishedOn,
+			b.Language,
+			b.PageCount,
+			b.Edition,
+			b.Format,
+			"[]",
+			"[]",
+			"[]",
 		)
 	}
 
@@ -117,18 +163,18 @@ func TestSuccessfulNewBooksPaginated(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	mock.ExpectPrepare("SELECT").
 		ExpectQuery().
-		WithArgs(expEmail, 8, 0).
+		WithArgs(expEmail, 8, 0, nil, float64(30*24*60*60)).
 		WillReturnRows(rows).
 		RowsWillBeClosed()
 
 	err = getNewBooksPaginated.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
-	books, err := db.GetNewBooksPaginated(ctx, 8, 0, expEmail)
+	books, err := db.GetNewBooksPaginated(ctx, 8, 0, 30*24*time.Hour, uuid.NullUUID{}, expEmail)
 	if assert.Nil(t, err, "unexpected error in a successful get new books test") {
 		assert.Equal(t, expBooks, books, "function should've returned a list of new books")
 	}
@@ -159,19 +205,41 @@ func TestSuccessfulPopularBooks(t *testing.T) {
 			"b.id",
 			"b.title",
 			"b.cover_image",
+			"b.cover_id",
 			"b.author",
 			"b.readers_count",
 			"av.rating",
-			"is_favorited"})
+			"is_favorited",
+			"isbn",
+			"publisher",
+			"publication_date",
+			"language",
+			"page_count",
+			"edition",
+			"format",
+			"authors",
+			"genres",
+			"tags"})
 	for _, b := range expBooks {
 		rows.AddRow(
 			b.ID,
 			b.Title,
 			b.Cover.String(),
+			nil,
 			b.Author,
 			b.Readers,
 			b.Rating,
 			b.IsFav,
+			b.ISBN,
+			b.Publisher,
+			b.PublishedOn,
+			b.Language,
+			b.PageCount,
+			b.Edition,
+			b.Format,
+			"[]",
+			"[]",
+			"[]",
 		)
 	}
 
@@ -179,18 +247,18 @@ func TestSuccessfulPopularBooks(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	mock.ExpectPrepare("SELECT").
 		ExpectQuery().
-		WithArgs(expEmail).
+		WithArgs(expEmail, PopularityWindow30d).
 		WillReturnRows(rows).
 		RowsWillBeClosed()
 
 	err = getPopularBooks.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
-	books, err := db.GetPopularBooks(ctx, expEmail)
+	books, err := db.GetPopularBooks(ctx, PopularityWindow30d, expEmail)
 	if assert.Nil(t, err, "unexpected error in a successful get popular books test") {
 		assert.Equal(t, expBooks, books, "function should've returned a list of popular books")
 	}
@@ -221,19 +289,41 @@ func TestSuccessfulPopularBooksPaginated(t *testing.T) {
 			"b.id",
 			"b.title",
 			"b.cover_image",
+			"b.cover_id",
 			"b.author",
 			"b.readers_count",
 			"av.rating",
-			"is_favorited"})
+			"is_favorited",
+			"isbn",
+			"publisher",
+			"publication_date",
+			"language",
+			"page_count",
+			"edition",
+			"format",
+			"authors",
+			"genres",
+			"tags"})
 	for _, b := range expBooks {
 		rows.AddRow(
 			b.ID,
 			b.Title,
 			b.Cover.String(),
+			nil,
 			b.Author,
 			b.Readers,
 			b.Rating,
 			b.IsFav,
+			b.ISBN,
+			b.Publisher,
+			b.PublishedOn,
+			b.Language,
+			b.PageCount,
+			b.Edition,
+			b.Format,
+			"[]",
+			"[]",
+			"[]",
 		)
 	}
 
@@ -241,18 +331,18 @@ func TestSuccessfulPopularBooksPaginated(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	mock.ExpectPrepare("SELECT").
 		ExpectQuery().
-		WithArgs(expEmail, 8, 0).
+		WithArgs(expEmail, 8, 0, nil, PopularityWindow7d).
 		WillReturnRows(rows).
 		RowsWillBeClosed()
 
 	err = getPopularBooksPaginated.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
-	books, err := db.GetPopularBooksPaginated(ctx, 8, 0, expEmail)
+	books, err := db.GetPopularBooksPaginated(ctx, 8, 0, PopularityWindow7d, uuid.NullUUID{}, expEmail)
 	if assert.Nil(t, err, "unexpected error in a successful get popular books test") {
 		assert.Equal(t, expBooks, books, "function should've returned a list of popular books")
 	}
@@ -266,7 +356,7 @@ func TestSuccessfulSearchBooks(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	expBook := Book{
 		ID:      uuid.MustParse("d42c75ef-5f76-4f26-8b8a-71fecd99b4f5"),
@@ -289,19 +379,41 @@ func TestSuccessfulSearchBooks(t *testing.T) {
 			"b.id",
 			"b.title",
 			"b.cover_image",
+			"b.cover_id",
 			"b.author",
 			"b.readers_count",
 			"av.rating",
-			"is_favorited"})
+			"is_favorited",
+			"isbn",
+			"publisher",
+			"publication_date",
+			"language",
+			"page_count",
+			"edition",
+			"format",
+			"authors",
+			"genres",
+			"tags"})
 	for _, b := range expBooks {
 		rows.AddRow(
 			b.ID,
 			b.Title,
 			b.Cover.String(),
+			nil,
 			b.Author,
 			b.Readers,
 			b.Rating,
 			b.IsFav,
+			b.ISBN,
+			b.Publisher,
+			b.PublishedOn,
+			b.Language,
+			b.PageCount,
+			b.Edition,
+			b.Format,
+			"[]",
+			"[]",
+			"[]",
 		)
 	}
 
@@ -309,16 +421,162 @@ func TestSuccessfulSearchBooks(t *testing.T) {
 
 	mock.ExpectPrepare("SELECT").
 		ExpectQuery().
-		WithArgs(expEmail, expQuery, 20, 0).
+		WithArgs(expEmail, expQuery, 20, 0, nil, nil).
 		WillReturnRows(rows).
 		RowsWillBeClosed()
 
 	err = searchBooks.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
-	books, err := db.SearchBooks(ctx, 20, 0, expQuery, expEmail)
+	books, err := db.SearchBooks(ctx, 20, 0, expQuery, uuid.NullUUID{}, expEmail)
 	if assert.Nil(t, err, "unexpected error in a successful search books test") {
 		assert.Equal(t, expBooks, books, "function should've returned a list of searched-for books")
 	}
 	assert.Nil(t, mock.ExpectationsWereMet())
 }
+
+func TestSuccessfulGetBook(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	publishedOn := time.Date(1997, 12, 24, 0, 0, 0, 0, time.UTC)
+	expBook := Book{
+		ID:          uuid.MustParse("d42c75ef-5f76-4f26-8b8a-71fecd99b4f5"),
+		Title:       "E",
+		Author:      "MC2",
+		Cover:       URLMustParse("https://example.com"),
+		Summary:     "Energy",
+		Readers:     10,
+		Rating:      3.5,
+		IsFav:       true,
+		ISBN:        "9784088725093",
+		Publisher:   "Shueisha",
+		PublishedOn: &publishedOn,
+		Language:    "ja",
+		PageCount:   216,
+		Edition:     "1st",
+		Format:      FormatPaperback,
+		Authors: BookAuthors{
+			{ID: uuid.MustParse("0c1e2d3f-4a5b-4c6d-8e7f-8091a2b3c4d5"), Name: "MC2", Role: CreditAuthor},
+		},
+		Genres: BookGenres{
+			{ID: uuid.MustParse("1d2e3f40-5a6b-4c7d-8e9f-a0b1c2d3e4f5"), Name: "Manga"},
+		},
+		Tags: BookTags{"shonen", "sports"},
+		Shelves: BookShelves{
+			{ID: uuid.MustParse("2e3f4051-6b7c-4d8e-9fa0-b1c2d3e4f5a6"), Name: "Favorites", Kind: ShelfFavorites},
+		},
+	}
+
+	rows := sqlmock.
+		NewRows([]string{
+			"b.id",
+			"b.title",
+			"b.cover_image",
+			"b.cover_id",
+			"b.author",
+			"b.summary",
+			"b.readers_count",
+			"av.rating",
+			"is_favorited",
+			"isbn",
+			"publisher",
+			"publication_date",
+			"language",
+			"page_count",
+			"edition",
+			"format",
+			"authors",
+			"genres",
+			"tags",
+			"shelves"}).
+		AddRow(
+			expBook.ID,
+			expBook.Title,
+			expBook.Cover.String(),
+			nil,
+			expBook.Author,
+			expBook.Summary,
+			expBook.Readers,
+			expBook.Rating,
+			expBook.IsFav,
+			expBook.ISBN,
+			expBook.Publisher,
+			publishedOn,
+			expBook.Language,
+			expBook.PageCount,
+			expBook.Edition,
+			expBook.Format,
+			`[{"id": "0c1e2d3f-4a5b-4c6d-8e7f-8091a2b3c4d5", "name": "MC2", "role": "author"}]`,
+			`[{"id": "1d2e3f40-5a6b-4c7d-8e9f-a0b1c2d3e4f5", "name": "Manga"}]`,
+			`["shonen", "sports"]`,
+			`[{"id": "2e3f4051-6b7c-4d8e-9fa0-b1c2d3e4f5a6", "name": "Favorites", "kind": "favorites"}]`,
+		)
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(expEmail, expBook.ID).
+		WillReturnRows(rows)
+
+	err = getBookStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	book, err := db.GetBook(ctx, expBook.ID, expEmail)
+	if assert.Nil(t, err, "unexpected error in a successful get book test") {
+		assert.Equal(t, expBook, *book, "function should've returned the book's details")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestNotFoundGetBook(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	bookID := uuid.New()
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(expEmail, bookID).
+		WillReturnError(sql.ErrNoRows)
+
+	err = getBookStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	_, err = db.GetBook(ctx, bookID, expEmail)
+	assert.Equal(t, ErrBookNotFound, err, "function should've reported the book as missing")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestISBNSearchBooks(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	expQuery := "4-08-872509-3"
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(expEmail, expQuery, 20, 0, "9784088725093", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"b.id"})).
+		RowsWillBeClosed()
+
+	err = searchBooks.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	_, err = db.SearchBooks(ctx, 20, 0, expQuery, uuid.NullUUID{}, expEmail)
+	assert.Nil(t, err, "unexpected error in an isbn search books test")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
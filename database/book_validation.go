@@ -0,0 +1,79 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	FormatHardcover = "hardcover"
+	FormatPaperback = "paperback"
+	FormatEbook     = "ebook"
+	FormatAudiobook = "audiobook"
+)
+
+var bookFormats = map[string]bool{
+	FormatHardcover: true,
+	FormatPaperback: true,
+	FormatEbook:     true,
+	FormatAudiobook: true,
+}
+
+var ErrBookTitleMissing = errors.New("title missing")
+var ErrBookAuthorMissing = errors.New("author missing")
+var ErrBookLanguageInvalid = errors.New("language is not a valid BCP 47 tag")
+var ErrBookPageCountInvalid = errors.New("page count can't be negative")
+var ErrBookFormatInvalid = errors.New("format must be hardcover, paperback, ebook or audiobook")
+var ErrBookPublicationDateInvalid = errors.New("publication date is too far in the future")
+
+// NormalizeLanguage canonicalizes a BCP 47 tag, so "JA-jp" and "jpn" are both
+// stored as their shortest form, "ja-JP" and "ja".
+func NormalizeLanguage(tag string) (string, error) {
+	t, err := language.Parse(strings.TrimSpace(tag))
+	if err != nil {
+		return "", ErrBookLanguageInvalid
+	}
+	return t.String(), nil
+}
+
+// Validate normalizes the entry's ISBN and language in place and checks the
+// rest of its fields, returning the first problem found.
+func (e *CatalogEntry) Validate() error {
+	isbn, err := NormalizeISBN(e.ISBN)
+	if err != nil {
+		return err
+	}
+	e.ISBN = isbn
+
+	e.Title = strings.TrimSpace(e.Title)
+	if e.Title == "" {
+		return ErrBookTitleMissing
+	}
+	e.Author = strings.TrimSpace(e.Author)
+	if e.Author == "" {
+		return ErrBookAuthorMissing
+	}
+
+	if e.Language != "" {
+		lang, err := NormalizeLanguage(e.Language)
+		if err != nil {
+			return err
+		}
+		e.Language = lang
+	}
+	if e.PageCount < 0 {
+		return ErrBookPageCountInvalid
+	}
+	e.Format = strings.ToLower(strings.TrimSpace(e.Format))
+	if e.Format != "" && !bookFormats[e.Format] {
+		return ErrBookFormatInvalid
+	}
+	// Preorders may be announced ahead of release, but not by years.
+	if e.PublishedOn != nil && e.PublishedOn.After(time.Now().AddDate(2, 0, 0)) {
+		return ErrBookPublicationDateInvalid
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCatalogEntry(t *testing.T) {
+	tooLate := time.Now().AddDate(3, 0, 0)
+	valid := CatalogEntry{ISBN: "4-08-872509-X", Title: " E ", Author: "MC2"}
+
+	tests := []struct {
+		modify func(e *CatalogEntry)
+		expErr error
+	}{
+		{func(e *CatalogEntry) {}, ErrISBNChecksum},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2" }, nil},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2"; e.Title = " " }, ErrBookTitleMissing},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2"; e.Author = "" }, ErrBookAuthorMissing},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2"; e.Language = "not a tag" }, ErrBookLanguageInvalid},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2"; e.PageCount = -1 }, ErrBookPageCountInvalid},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2"; e.Format = "scroll" }, ErrBookFormatInvalid},
+		{func(e *CatalogEntry) { e.ISBN = "0-306-40615-2"; e.PublishedOn = &tooLate }, ErrBookPublicationDateInvalid},
+	}
+
+	for i, test := range tests {
+		entry := valid
+		test.modify(&entry)
+		assert.Equal(t, test.expErr, entry.Validate(), "unexpected error validating entry %d", i)
+	}
+}
+
+func TestValidateNormalizesCatalogEntry(t *testing.T) {
+	entry := CatalogEntry{
+		ISBN:     "0-306-40615-2",
+		Title:    " E ",
+		Author:   "MC2",
+		Language: "JPN",
+		Format:   " Paperback",
+	}
+	if assert.Nil(t, entry.Validate(), "unexpected error validating a valid entry") {
+		assert.Equal(t, "9780306406157", entry.ISBN, "Validate didn't normalize the isbn")
+		assert.Equal(t, "E", entry.Title, "Validate didn't trim the title")
+		assert.Equal(t, "ja", entry.Language, "Validate didn't canonicalize the language")
+		assert.Equal(t, FormatPaperback, entry.Format, "Validate didn't normalize the format")
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	lang, err := NormalizeLanguage("pt-br")
+	assert.Nil(t, err)
+	assert.Equal(t, "pt-BR", lang)
+}
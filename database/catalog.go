@@ -0,0 +1,394 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CatalogInterface interface {
+	UpsertCatalogEntry(ctx context.Context, entry CatalogEntry, dryRun bool) (created bool, err error)
+	ExportCatalog(ctx context.Context, each func(CatalogEntry) error) error
+	CreateImportJob(ctx context.Context, format string, dryRun bool, createdBy string) (*ImportJob, error)
+	UpdateImportJob(ctx context.Context, job ImportJob) error
+	AddImportRowError(ctx context.Context, jobID uuid.UUID, rowError ImportRowError) error
+	GetImportJob(ctx context.Context, jobID uuid.UUID) (*ImportJob, error)
+}
+
+var upsertCatalogEntryStmt = dbStatement{
+	nil, `
+	INSERT INTO book (
+		id, isbn, title, author, cover_image, summary,
+		publisher, publication_date, language, page_count, edition, format
+	)
+	VALUES
+		(
+			$1, $2, $3, $4, $5, $6,
+			NULLIF($7, ''), $8, NULLIF($9, ''), NULLIF($10, 0), NULLIF($11, ''), NULLIF($12, '')
+		)
+	ON CONFLICT (isbn) DO UPDATE
+	SET
+		title = EXCLUDED.title,
+		author = EXCLUDED.author,
+		cover_image = EXCLUDED.cover_image,
+		summary = EXCLUDED.summary,
+		publisher = EXCLUDED.publisher,
+		publication_date = EXCLUDED.publication_date,
+		language = EXCLUDED.language,
+		page_count = EXCLUDED.page_count,
+		edition = EXCLUDED.edition,
+		format = EXCLUDED.format
+	RETURNING
+		id,
+		(xmax = 0) AS created;`,
+}
+
+var exportCatalogStmt = dbStatement{
+	nil, fmt.Sprintf(`
+	SELECT
+		COALESCE(b.isbn, ''),
+		b.title,
+		b.author,
+		b.cover_image,
+		b.summary,
+		COALESCE(b.publisher, ''),
+		b.publication_date,
+		COALESCE(b.language, ''),
+		COALESCE(b.page_count, 0),
+		COALESCE(b.edition, ''),
+		COALESCE(b.format, ''),`+bookReadersStr+`,
+		av.rating,
+		(
+			SELECT
+				count(*)
+			FROM
+				rate_book r
+			WHERE
+				r.book_id = b.id
+				AND r.review_status <> 'hidden'
+		) AS rating_count,%s
+	FROM
+		book b
+		LEFT JOIN rating_avg AS av ON b.id = av.id
+	ORDER BY
+		b.title ASC;`, bookAuthorsStr),
+}
+
+var createImportJobStmt = dbStatement{
+	nil, `
+	INSERT INTO import_job (
+		id, format, dry_run, created_by
+	)
+	VALUES
+		($1, $2, $3, $4)
+	RETURNING
+		status, created_at;`,
+}
+
+var updateImportJobStmt = dbStatement{
+	nil, `
+	UPDATE import_job
+	SET
+		status = $2,
+		progress = $3,
+		processed_rows = $4,
+		created_rows = $5,
+		updated_rows = $6,
+		failed_rows = $7,
+		message = $8,
+		finished_at = $9
+	WHERE
+		id = $1;`,
+}
+
+var addImportRowErrorStmt = dbStatement{
+	nil, `
+	INSERT INTO import_job_error (
+		job_id, row_number, isbn, message
+	)
+	VALUES
+		($1, $2, $3, $4);`,
+}
+
+var getImportJobStmt = dbStatement{
+	nil, `
+	SELECT
+		format,
+		dry_run,
+		status,
+		progress,
+		processed_rows,
+		created_rows,
+		updated_rows,
+		failed_rows,
+		COALESCE(message, ''),
+		created_by,
+		created_at,
+		finished_at
+	FROM
+		import_job
+	WHERE
+		id = $1;`,
+}
+
+var getImportRowErrorsStmt = dbStatement{
+	nil, `
+	SELECT
+		row_number,
+		isbn,
+		message
+	FROM
+		import_job_error
+	WHERE
+		job_id = $1
+	ORDER BY
+		row_number ASC
+	LIMIT
+		1000;`,
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"upsertCatalogEntry": &upsertCatalogEntryStmt,
+		"exportCatalog":      &exportCatalogStmt,
+		"createImportJob":    &createImportJobStmt,
+		"updateImportJob":    &updateImportJobStmt,
+		"addImportRowError":  &addImportRowErrorStmt,
+		"getImportJob":       &getImportJobStmt,
+		"getImportRowErrors": &getImportRowErrorsStmt,
+	})
+}
+
+type CatalogEntry struct {
+	ISBN        string
+	Title       string
+	Author      string
+	Cover       string
+	Summary     string
+	Publisher   string
+	PublishedOn *time.Time
+	Language    string
+	PageCount   int
+	Edition     string
+	Format      string
+	Readers     int
+	Rating      float32
+	RatingCount int
+	// Authors credits contributors by role. When empty, Author is split
+	// into authorship credits instead.
+	Authors BookAuthors
+}
+
+const (
+	ImportJobRunning = "running"
+	ImportJobDone    = "done"
+	ImportJobFailed  = "failed"
+)
+
+type ImportJob struct {
+	ID         uuid.UUID
+	Format     string
+	DryRun     bool
+	Status     string
+	Progress   float32
+	Processed  int
+	Created    int
+	Updated    int
+	Failed     int
+	Message    string
+	CreatedBy  string
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+	Errors     []ImportRowError
+}
+
+type ImportRowError struct {
+	Row     int
+	ISBN    string
+	Message string
+}
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// UpsertCatalogEntry inserts a book or updates the one sharing its ISBN, and
+// replaces its author credits. On a dry run the statements still run, so
+// constraint violations are reported, but its transaction is rolled back.
+func (db DBInstance) UpsertCatalogEntry(ctx context.Context, entry CatalogEntry, dryRun bool) (created bool, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return false, err
+	}
+
+	var bookID uuid.UUID
+	if err := upsertCatalogEntryStmt.Tx(ctx, tx).
+		QueryRowContext(ctx,
+			randomUUID,
+			entry.ISBN,
+			entry.Title,
+			entry.Author,
+			entry.Cover,
+			entry.Summary,
+			entry.Publisher,
+			entry.PublishedOn,
+			entry.Language,
+			entry.PageCount,
+			entry.Edition,
+			entry.Format,
+		).Scan(&bookID, &created); err != nil {
+		return false, err
+	}
+
+	credits := entry.Authors
+	if len(credits) == 0 {
+		for _, name := range SplitAuthorNames(entry.Author) {
+			credits = append(credits, BookAuthor{Name: name, Role: CreditAuthor})
+		}
+	}
+	if err := setBookCredits(ctx, tx, bookID, credits); err != nil {
+		return false, err
+	}
+
+	if dryRun {
+		return created, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return
+}
+
+func (db DBInstance) ExportCatalog(ctx context.Context, each func(CatalogEntry) error) error {
+	rows, err := exportCatalogStmt.QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := CatalogEntry{}
+		if err := rows.Scan(
+			&entry.ISBN,
+			&entry.Title,
+			&entry.Author,
+			&entry.Cover,
+			&entry.Summary,
+			&entry.Publisher,
+			&entry.PublishedOn,
+			&entry.Language,
+			&entry.PageCount,
+			&entry.Edition,
+			&entry.Format,
+			&entry.Readers,
+			&entry.Rating,
+			&entry.RatingCount,
+			&entry.Authors,
+		); err != nil {
+			return err
+		}
+		if err := each(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (db DBInstance) CreateImportJob(ctx context.Context, format string, dryRun bool, createdBy string) (*ImportJob, error) {
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	job := ImportJob{
+		ID:        randomUUID,
+		Format:    format,
+		DryRun:    dryRun,
+		CreatedBy: createdBy,
+	}
+	if err := createImportJobStmt.
+		QueryRowContext(ctx, job.ID, format, dryRun, createdBy).
+		Scan(&job.Status, &job.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (db DBInstance) UpdateImportJob(ctx context.Context, job ImportJob) error {
+	var message sql.NullString
+	if job.Message != "" {
+		message = sql.NullString{String: job.Message, Valid: true}
+	}
+
+	_, err := updateImportJobStmt.ExecContext(ctx,
+		job.ID,
+		job.Status,
+		job.Progress,
+		job.Processed,
+		job.Created,
+		job.Updated,
+		job.Failed,
+		message,
+		job.FinishedAt,
+	)
+	return err
+}
+
+func (db DBInstance) AddImportRowError(ctx context.Context, jobID uuid.UUID, rowError ImportRowError) error {
+	_, err := addImportRowErrorStmt.ExecContext(ctx, jobID, rowError.Row, rowError.ISBN, rowError.Message)
+	return err
+}
+
+func (db DBInstance) GetImportJob(ctx context.Context, jobID uuid.UUID) (*ImportJob, error) {
+	job := ImportJob{ID: jobID}
+	var finishedAt sql.NullTime
+	if err := getImportJobStmt.QueryRowContext(ctx, jobID).Scan(
+		&job.Format,
+		&job.DryRun,
+		&job.Status,
+		&job.Progress,
+		&job.Processed,
+		&job.Created,
+		&job.Updated,
+		&job.Failed,
+		&job.Message,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&finishedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, err
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	rows, err := getImportRowErrorsStmt.QueryContext(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rowError := ImportRowError{}
+		if err := rows.Scan(&rowError.Row, &rowError.ISBN, &rowError.Message); err != nil {
+			return nil, err
+		}
+		job.Errors = append(job.Errors, rowError)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
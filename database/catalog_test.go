@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var expEntry = CatalogEntry{
+	ISBN:      "9784088725093",
+	Title:     "E",
+	Author:    "MC2",
+	Cover:     "https://example.com",
+	Summary:   "",
+	Publisher: "Shueisha",
+	Language:  "ja",
+	PageCount: 216,
+	Format:    FormatPaperback,
+}
+
+func TestSuccessfulUpsertCatalogEntry(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	bookID := uuid.New()
+	test1 := mock.ExpectPrepare("INSERT")
+	test2 := mock.ExpectPrepare("DELETE")
+	test3 := mock.ExpectPrepare("WITH")
+	mock.ExpectBegin()
+	test1.ExpectQuery().
+		WithArgs(&testUUID{}, expEntry.ISBN, expEntry.Title, expEntry.Author, expEntry.Cover, expEntry.Summary,
+			expEntry.Publisher, nil, expEntry.Language, expEntry.PageCount, expEntry.Edition, expEntry.Format).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(bookID, true))
+	test2.ExpectExec().
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	test3.ExpectExec().
+		WithArgs(&testUUID{}, expEntry.Author, bookID, CreditAuthor, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	for _, stmt := range []*dbStatement{&upsertCatalogEntryStmt, &deleteBookCreditsStmt, &addBookCreditStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	created, err := db.UpsertCatalogEntry(ctx, expEntry, false)
+	if assert.Nil(t, err, "unexpected error in a successful catalog upsert test") {
+		assert.True(t, created, "function should've reported the book as created")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDryRunUpsertCatalogEntry(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	bookID := uuid.New()
+	test1 := mock.ExpectPrepare("INSERT")
+	test2 := mock.ExpectPrepare("DELETE")
+	test3 := mock.ExpectPrepare("WITH")
+	mock.ExpectBegin()
+	test1.ExpectQuery().
+		WithArgs(&testUUID{}, expEntry.ISBN, expEntry.Title, expEntry.Author, expEntry.Cover, expEntry.Summary,
+			expEntry.Publisher, nil, expEntry.Language, expEntry.PageCount, expEntry.Edition, expEntry.Format).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).AddRow(bookID, false))
+	test2.ExpectExec().
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	test3.ExpectExec().
+		WithArgs(&testUUID{}, expEntry.Author, bookID, CreditAuthor, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	for _, stmt := range []*dbStatement{&upsertCatalogEntryStmt, &deleteBookCreditsStmt, &addBookCreditStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	created, err := db.UpsertCatalogEntry(ctx, expEntry, true)
+	if assert.Nil(t, err, "unexpected error in a dry run catalog upsert test") {
+		assert.False(t, created, "function should've reported the book as updated")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet(), "a dry run upsert should've been rolled back")
+}
+
+func TestSuccessfulExportCatalog(t *testing.T) {
+	ctx := context.Background()
+
+	expEntries := []CatalogEntry{expEntry, expEntry}
+	expEntries[1].Readers = 4
+	expEntries[1].Rating = 3.5
+	expEntries[1].RatingCount = 2
+
+	rows := sqlmock.NewRows([]string{
+		"isbn", "title", "author", "cover_image", "summary",
+		"publisher", "publication_date", "language", "page_count", "edition", "format",
+		"readers_count", "rating", "rating_count", "authors",
+	})
+	for _, e := range expEntries {
+		rows.AddRow(e.ISBN, e.Title, e.Author, e.Cover, e.Summary,
+			e.Publisher, nil, e.Language, e.PageCount, e.Edition, e.Format,
+			e.Readers, e.Rating, e.RatingCount, "[]")
+	}
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	err = exportCatalogStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	var entries []CatalogEntry
+	err = db.ExportCatalog(ctx, func(e CatalogEntry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if assert.Nil(t, err, "unexpected error in a successful catalog export test") {
+		assert.Equal(t, expEntries, entries, "function should've streamed every catalog entry")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
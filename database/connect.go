@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// ConnectOptions tunes the connection pool, and how long StartDB waits for
+// the database to accept connections.
+type ConnectOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// RetryTimeout is how long to keep retrying while the database boots,
+	// zero tries once.
+	RetryTimeout time.Duration
+	// ReplicaDSNs are the read replicas the catalog is read from, while they
+	// aren't lagging more than MaxReplicaLag.
+	ReplicaDSNs   []string
+	MaxReplicaLag time.Duration
+}
+
+// connectBackoff is the wait before the second attempt, doubled after every
+// failed one up to maxConnectBackoff.
+var (
+	connectBackoff    = 500 * time.Millisecond
+	maxConnectBackoff = 10 * time.Second
+)
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// waitForDB pings db until it answers, backing off between attempts, for up
+// to timeout.
+func waitForDB(ctx context.Context, db pinger, dsn string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	wait := connectBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil || !retryableConnectError(err) || time.Now().Add(wait).After(deadline) {
+			return err
+		}
+		log.Warn().Err(err).Str("dsn", RedactDSN(dsn)).Int("attempt", attempt).Dur("retry_in", wait).
+			Msg("Database isn't accepting connections yet")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, maxConnectBackoff)
+	}
+}
+
+// retryableConnectError tells whether the database may still be starting up.
+// Errors from the server itself, like a wrong password, won't go away by
+// retrying, except for the one sent while it's booting.
+func retryableConnectError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "57P03" // cannot_connect_now
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// dsnPassword matches the password of a key/value connection string, quoted
+// or not.
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// RedactDSN hides the password of a postgres:// URL or key/value connection
+// string, so it can be logged.
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		if q.Has("password") {
+			q.Set("password", "xxxxx")
+			u.RawQuery = q.Encode()
+		}
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}xxxxx")
+}
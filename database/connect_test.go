@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactDSN(t *testing.T) {
+	assert.Equal(t, "postgres://admin:xxxxx@db:5432/librarydb?sslmode=require",
+		RedactDSN("postgres://admin:secret@db:5432/librarydb?sslmode=require"))
+	assert.Equal(t, "postgres://db/librarydb?password=xxxxx&user=admin",
+		RedactDSN("postgres://db/librarydb?user=admin&password=secret"))
+	assert.Equal(t, "host=db user=admin password=xxxxx dbname=librarydb",
+		RedactDSN("host=db user=admin password=secret dbname=librarydb"))
+	assert.Equal(t, "host=db password=xxxxx dbname=librarydb",
+		RedactDSN(`host=db password='it\'s a secret' dbname=librarydb`))
+}
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+func fastBackoff(t *testing.T) {
+	backoff, maxBackoff := connectBackoff, maxConnectBackoff
+	connectBackoff, maxConnectBackoff = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() { connectBackoff, maxConnectBackoff = backoff, maxBackoff })
+}
+
+func TestWaitForDB(t *testing.T) {
+	fastBackoff(t)
+	booting := []error{syscall.ECONNREFUSED, &pq.Error{Code: "57P03"}}
+	pings := 0
+	db := pingerFunc(func(ctx context.Context) error {
+		pings++
+		if pings <= len(booting) {
+			return booting[pings-1]
+		}
+		return nil
+	})
+
+	assert.Nil(t, waitForDB(context.Background(), db, "", time.Second))
+	assert.Equal(t, 3, pings, "the database wasn't waited for")
+}
+
+func TestWaitForDBGivesUp(t *testing.T) {
+	fastBackoff(t)
+
+	pings := 0
+	wrongPassword := &pq.Error{Code: "28P01"}
+	err := waitForDB(context.Background(), pingerFunc(func(ctx context.Context) error {
+		pings++
+		return wrongPassword
+	}), "", time.Second)
+	assert.Equal(t, wrongPassword, err)
+	assert.Equal(t, 1, pings, "a wrong password was retried")
+
+	pings = 0
+	err = waitForDB(context.Background(), pingerFunc(func(ctx context.Context) error {
+		pings++
+		return syscall.ECONNREFUSED
+	}), "", 0)
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+	assert.Equal(t, 1, pings, "a zero timeout should try once")
+}
@@ -3,6 +3,10 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/metricshelper"
+	"ic-rhadi/e_library/tracinghelper"
 	"io/fs"
 	"io/ioutil"
 	"path/filepath"
@@ -20,41 +24,89 @@ type DB interface {
 	UserAccountInterface
 	UserSessionInterface
 	BookInterface
+	BookCoverInterface
+	AuthorInterface
+	GenreInterface
+	CatalogInterface
+	RecommendationInterface
+	ReadingInterface
+	AnnotationInterface
+	ShelfInterface
+	ReviewInterface
+	HealthInterface
+	AuditInterface
+	AdminUserInterface
 	InitDB(ctx context.Context) error
-	SetConcurrentRoutineJobs(timerLength time.Duration) chan<- bool
+	Migrate(ctx context.Context) (version int, err error)
+	PrepareStatements(ctx context.Context) error
+	CleanupExpired(ctx context.Context, currTime time.Time) (CleanupResult, error)
+	RefreshPopularity(ctx context.Context) error
+	RefreshBookNeighbors(ctx context.Context) error
+	RunExpiredCleanup(ctx context.Context, interval time.Duration) error
+	RunPopularityRefresh(ctx context.Context, interval time.Duration) error
+	RunRecommendationRefresh(ctx context.Context, interval time.Duration) error
 	CloseDB()
 }
 
+// DBInstance is the primary's pool, with the optional read replicas book
+// listings are read from.
 type DBInstance struct {
 	*sql.DB
+	replicas *replicaSet
 }
 
 var prepareStatements []*dbStatement
 
-func StartDB(dbInfo string) (DB, error) {
-	db, err := sql.Open("postgres", dbInfo)
+// StartDB opens the pool to the database at dsn, a postgres:// URL or a
+// key/value connection string, and waits for it to accept connections.
+func StartDB(ctx context.Context, dsn string, opts ConnectOptions) (DB, error) {
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	loggerAdapter := zerologadapter.New(log.Logger)
-	db = sqldblogger.OpenDriver(dbInfo, db.Driver(), loggerAdapter, sqldblogger.WithSQLQueryAsMessage(true))
-	err = db.Ping()
+	db = sqldblogger.OpenDriver(dsn, db.Driver(), tracedLogAdapter{}, sqldblogger.WithSQLQueryAsMessage(true))
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	if err := waitForDB(ctx, db, dsn, opts.RetryTimeout); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	replicas, err := openReplicas(opts.ReplicaDSNs, opts)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	log.Info().Str("dsn", dbInfo).Msg("Database connected")
-	return DBInstance{db}, nil
+	if err := metricshelper.RegisterDBStats(db); err != nil {
+		log.Warn().Err(err).Msg("Database pool metrics couldn't be registered")
+	}
+
+	log.Info().Str("dsn", RedactDSN(dsn)).Msg("Database connected")
+	return DBInstance{db, replicas}, nil
 }
 
-func (db DBInstance) InitDB(ctx context.Context) error {
-	files, err := ioutil.ReadDir(filepath.Join(".", "database", "sql_versions"))
+// tracedLogAdapter logs queries with the ids of the trace and span they ran
+// in.
+type tracedLogAdapter struct{}
+
+func (tracedLogAdapter) Log(ctx context.Context, level sqldblogger.Level, msg string, data map[string]interface{}) {
+	zerologadapter.New(tracinghelper.Logger(ctx)).Log(ctx, level, msg, data)
+}
+
+// sqlVersionsDir holds the schema files; only the newest one is run.
+var sqlVersionsDir = filepath.Join(".", "database", "sql_versions")
+
+// latestSQLVersion finds the schema file with the highest version number.
+func latestSQLVersion() (version int, name string, err error) {
+	files, err := ioutil.ReadDir(sqlVersionsDir)
 	if err != nil {
-		return err
+		return 0, "", err
 	}
 
-	var latestVer = 0
 	var latestSQLFile fs.FileInfo
 	for _, file := range files {
 		ver := strings.SplitN(file.Name(), "_", 2)
@@ -62,25 +114,51 @@ func (db DBInstance) InitDB(ctx context.Context) error {
 
 		if err != nil {
 			log.Printf("error getting version on file \"%s\": %v.\n", file.Name(), err)
-			return err
-		} else if latestVer <= currVer {
-			latestVer = currVer
+			return 0, "", err
+		} else if version <= currVer {
+			version = currVer
 			latestSQLFile = file
 		}
 
 	}
+	if latestSQLFile == nil {
+		return 0, "", fmt.Errorf("no schema file in %s", sqlVersionsDir)
+	}
+	return version, latestSQLFile.Name(), nil
+}
 
-	c, err := ioutil.ReadFile(filepath.Join(".", "database", "sql_versions", latestSQLFile.Name()))
-	if err != nil {
-		log.Error().Err(err).Str("file", latestSQLFile.Name()).Msg("error reading sql file")
+// InitDB migrates the schema and prepares the statements.
+func (db DBInstance) InitDB(ctx context.Context) error {
+	if _, err := db.Migrate(ctx); err != nil {
 		return err
 	}
+	return db.PrepareStatements(ctx)
+}
+
+// Migrate runs the newest schema file, returning its version.
+func (db DBInstance) Migrate(ctx context.Context) (version int, err error) {
+	latestVer, latestSQLFile, err := latestSQLVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	c, err := ioutil.ReadFile(filepath.Join(sqlVersionsDir, latestSQLFile))
+	if err != nil {
+		log.Error().Err(err).Str("file", latestSQLFile).Msg("error reading sql file")
+		return 0, err
+	}
 	sql := string(c)
 	if _, err := db.ExecContext(ctx, sql); err != nil {
 		log.Error().Err(err).Str("sql", sql).Msg("error running initializing sql file")
-		return err
+		return 0, err
 	}
+	appliedSQLVersion.Store(int64(latestVer))
+	return latestVer, nil
+}
 
+// PrepareStatements prepares every registered statement, which fails unless
+// the schema has been migrated.
+func (db DBInstance) PrepareStatements(ctx context.Context) error {
 	for _, stmt := range prepareStatements {
 		if err := stmt.Prepare(ctx, db.DB); err != nil {
 			log.Error().Err(err).Str("query", stmt.Query).Msg("error preparing statements")
@@ -90,29 +168,58 @@ func (db DBInstance) InitDB(ctx context.Context) error {
 	return nil
 }
 
-func (db DBInstance) SetConcurrentRoutineJobs(timerLength time.Duration) chan<- bool {
-	ticker := time.NewTicker(timerLength)
-	quit := make(chan bool)
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				deleted, err := db.DeleteExpiredSession(context.Background(), time.Now())
-				if err != nil {
-					log.Error().Err(err).Msg("Deleting expired sessions returned an error")
-				}
-				log.Info().Int64("deleted rows", deleted).Msg("Expired sessions deleted")
-				deleted, err = db.DeleteExpiredAccount(context.Background(), time.Now())
-				if err != nil {
-					log.Error().Err(err).Msg("Deleting expired sessions returned an error")
-				}
-				log.Info().Int64("deleted rows", deleted).Msg("Expired sessions deleted")
-			case <-quit:
-				return
-			}
+// CleanupResult counts the rows CleanupExpired deleted.
+type CleanupResult struct {
+	Sessions int64
+	Accounts int64
+}
+
+// CleanupExpired deletes the sessions and never activated accounts that
+// expired by currTime. A failing step doesn't keep the other from running.
+func (db DBInstance) CleanupExpired(ctx context.Context, currTime time.Time) (CleanupResult, error) {
+	var result CleanupResult
+	sessions, sessionsErr := db.DeleteExpiredSession(ctx, currTime)
+	if sessionsErr != nil {
+		sessionsErr = fmt.Errorf("deleting expired sessions: %w", sessionsErr)
+	} else {
+		result.Sessions = sessions
+	}
+	accounts, accountsErr := db.DeleteExpiredAccount(ctx, currTime)
+	if accountsErr != nil {
+		accountsErr = fmt.Errorf("deleting expired accounts: %w", accountsErr)
+	} else {
+		result.Accounts = accounts
+	}
+	return result, errors.Join(sessionsErr, accountsErr)
+}
+
+// RunExpiredCleanup runs CleanupExpired every interval until ctx is
+// cancelled.
+func (db DBInstance) RunExpiredCleanup(ctx context.Context, interval time.Duration) error {
+	return runEvery(ctx, interval, func(ctx context.Context) {
+		result, err := db.CleanupExpired(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("Deleting expired rows returned an error")
 		}
-	}()
-	return quit
+		log.Info().Int64("sessions", result.Sessions).Int64("accounts", result.Accounts).Msg("Expired rows deleted")
+		metricshelper.CleanupDeletedRows.WithLabelValues("sessions").Add(float64(result.Sessions))
+		metricshelper.CleanupDeletedRows.WithLabelValues("accounts").Add(float64(result.Accounts))
+	})
+}
+
+// runEvery calls job every interval until ctx is cancelled. A job running at
+// that moment gets the cancelled ctx and is waited for.
+func runEvery(ctx context.Context, interval time.Duration, job func(ctx context.Context)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			job(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
 }
 
 func (db DBInstance) CloseDB() {
@@ -121,5 +228,6 @@ func (db DBInstance) CloseDB() {
 			log.Error().Err(err).Msg("error closing db")
 		}
 	}
+	db.replicas.close()
 	db.Close()
 }
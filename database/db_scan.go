@@ -0,0 +1,52 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/lib/pq"
+)
+
+// scanJSONList scans a JSON array column into dest, a pointer to a slice. An
+// empty array is scanned as a nil slice.
+func scanJSONList(value any, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("destination pointer is nil")
+	}
+
+	var data []byte
+	switch value := value.(type) {
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	case nil:
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		return nil
+	default:
+		return fmt.Errorf("unsupported Scan, storing driver.Value type %T into type %T", value, dest)
+	}
+
+	list := reflect.New(v.Elem().Type())
+	if err := json.Unmarshal(data, list.Interface()); err != nil {
+		return err
+	}
+	if list.Elem().Len() == 0 {
+		list.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+	v.Elem().Set(list.Elem())
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -3,13 +3,32 @@ package database
 import (
 	"context"
 	"database/sql"
+	"ic-rhadi/e_library/metricshelper"
+	"ic-rhadi/e_library/tracinghelper"
+	"time"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = tracinghelper.Tracer("database")
+
 type dbStatement struct {
 	Statement *sql.Stmt
 	Query     string
 }
 
+// statementNames labels the duration metrics of each registered statement.
+var statementNames = map[*dbStatement]string{}
+
+// registerStatements names stmts and adds them to the ones InitDB prepares.
+func registerStatements(stmts map[string]*dbStatement) {
+	for name, stmt := range stmts {
+		statementNames[stmt] = name
+		prepareStatements = append(prepareStatements, stmt)
+	}
+}
+
 func (stmt *dbStatement) Prepare(ctx context.Context, db *sql.DB) (err error) {
 	(*stmt).Statement, err = db.PrepareContext(ctx, stmt.Query)
 	return
@@ -18,3 +37,70 @@ func (stmt *dbStatement) Prepare(ctx context.Context, db *sql.DB) (err error) {
 func (stmt *dbStatement) Close() (err error) {
 	return (*stmt).Statement.Close()
 }
+
+func (stmt *dbStatement) instrumented() instrumentedStatement {
+	return instrumentedStatement{stmt.Statement, statementNames[stmt], stmt.Query}
+}
+
+// Tx binds the statement to tx, keeping its runs instrumented.
+func (stmt *dbStatement) Tx(ctx context.Context, tx *sql.Tx) instrumentedStatement {
+	return instrumentedStatement{tx.StmtContext(ctx, stmt.Statement), statementNames[stmt], stmt.Query}
+}
+
+func (stmt *dbStatement) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return stmt.instrumented().ExecContext(ctx, args...)
+}
+
+func (stmt *dbStatement) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	return stmt.instrumented().QueryContext(ctx, args...)
+}
+
+func (stmt *dbStatement) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
+	return stmt.instrumented().QueryRowContext(ctx, args...)
+}
+
+// instrumentedStatement traces each run of a prepared statement and records
+// how long it takes, until its result or first rows come back.
+type instrumentedStatement struct {
+	stmt  *sql.Stmt
+	name  string
+	query string
+}
+
+func (stmt instrumentedStatement) start(ctx context.Context) (context.Context, func(err error)) {
+	name := stmt.name
+	if name == "" {
+		name = "unregistered"
+	}
+	ctx, span := tracer.Start(ctx, "db."+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(name),
+		semconv.DBQueryText(stmt.query),
+	))
+	start := time.Now()
+	return ctx, func(err error) {
+		metricshelper.StatementDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		tracinghelper.End(span, err)
+	}
+}
+
+func (stmt instrumentedStatement) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	ctx, end := stmt.start(ctx)
+	result, err := stmt.stmt.ExecContext(ctx, args...)
+	end(err)
+	return result, err
+}
+
+func (stmt instrumentedStatement) QueryContext(ctx context.Context, args ...any) (*sql.Rows, error) {
+	ctx, end := stmt.start(ctx)
+	rows, err := stmt.stmt.QueryContext(ctx, args...)
+	end(err)
+	return rows, err
+}
+
+func (stmt instrumentedStatement) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
+	ctx, end := stmt.start(ctx)
+	row := stmt.stmt.QueryRowContext(ctx, args...)
+	end(row.Err())
+	return row
+}
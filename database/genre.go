@@ -0,0 +1,423 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// genreBooksStr selects the books classified under a genre or any genre below
+// it. The verb is the parameter number holding the genre's id.
+const genreBooksStr = `
+			WITH RECURSIVE subtree AS (
+				SELECT
+					id
+				FROM
+					genre
+				WHERE
+					id = $%[1]d
+				UNION ALL
+				SELECT
+					g.id
+				FROM
+					genre g
+					JOIN subtree s ON g.parent_id = s.id
+			)
+			SELECT
+				bg.book_id
+			FROM
+				book_genre bg
+				JOIN subtree s ON bg.genre_id = s.id`
+
+// genreFilterStr narrows a book list to a genre, unless its parameter is null.
+func genreFilterStr(param int) string {
+	return fmt.Sprintf(`
+		AND (
+			$%[1]d::uuid IS NULL
+			OR b.id IN (`+genreBooksStr+`
+			)
+		)`, param)
+}
+
+// bookGenresStr and bookTagsStr aggregate a book's classification as JSON
+// arrays, scanned into BookGenres and BookTags.
+const bookGenresStr = `
+		COALESCE(
+			(
+				SELECT
+					json_agg(
+						json_build_object('id', g.id, 'name', g.name)
+						ORDER BY g.name
+					)
+				FROM
+					book_genre bg
+					JOIN genre g ON g.id = bg.genre_id
+				WHERE
+					bg.book_id = b.id
+			),
+			'[]'
+		)`
+
+const bookTagsStr = `
+		COALESCE(
+			(
+				SELECT
+					json_agg(t.tag ORDER BY t.tag)
+				FROM
+					book_tag t
+				WHERE
+					t.book_id = b.id
+			),
+			'[]'
+		)`
+
+var getGenresStmt = dbStatement{
+	nil, `
+	SELECT
+		g.id,
+		g.parent_id,
+		g.name,
+		COALESCE(g.bisac_code, ''),
+		COALESCE(g.dewey_code, ''),
+		(
+			SELECT
+				count(*)
+			FROM
+				book_genre bg
+			WHERE
+				bg.genre_id = g.id
+		) AS book_count
+	FROM
+		genre g
+	ORDER BY
+		g.name ASC;`,
+}
+
+var getGenreStmt = dbStatement{
+	nil, `
+	SELECT
+		g.id,
+		g.parent_id,
+		g.name,
+		COALESCE(g.bisac_code, ''),
+		COALESCE(g.dewey_code, ''),
+		(
+			SELECT
+				count(*)
+			FROM
+				book_genre bg
+			WHERE
+				bg.genre_id = g.id
+		) AS book_count
+	FROM
+		genre g
+	WHERE
+		g.id = $1;`,
+}
+
+var createGenreStmt = dbStatement{
+	nil, `
+	INSERT INTO genre (
+		id, parent_id, name, bisac_code, dewey_code
+	)
+	VALUES
+		($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''));`,
+}
+
+var getGenreBooksStmt = dbStatement{
+	nil, fmt.Sprintf(bookSelectStr, fmt.Sprintf(`
+	WHERE
+		b.id IN (`+genreBooksStr+`
+		)
+	ORDER BY
+		b.title ASC
+	LIMIT
+		$3 OFFSET $4`, 2)),
+}
+
+var lockBookStmt = dbStatement{
+	nil, `
+	SELECT
+		1
+	FROM
+		book
+	WHERE
+		id = $1
+	FOR UPDATE;`,
+}
+
+var deleteBookGenresStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		book_genre
+	WHERE
+		book_id = $1;`,
+}
+
+var addBookGenresStmt = dbStatement{
+	nil, `
+	INSERT INTO book_genre (
+		book_id, genre_id
+	)
+	SELECT
+		$1, genre_id
+	FROM
+		unnest($2::uuid[]) AS genre_id
+	ON CONFLICT DO NOTHING;`,
+}
+
+var deleteBookTagsStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		book_tag
+	WHERE
+		book_id = $1;`,
+}
+
+var addBookTagsStmt = dbStatement{
+	nil, `
+	INSERT INTO book_tag (
+		book_id, tag
+	)
+	SELECT
+		$1, tag
+	FROM
+		unnest($2::text[]) AS tag
+	ON CONFLICT DO NOTHING;`,
+}
+
+type GenreInterface interface {
+	GetGenres(ctx context.Context) ([]Genre, error)
+	GetGenre(ctx context.Context, genreID uuid.UUID) (*Genre, error)
+	CreateGenre(ctx context.Context, genre Genre) (*Genre, error)
+	GetGenreBooks(ctx context.Context, genreID uuid.UUID, limit int, offset int, accountID string) ([]Book, error)
+	SetBookClassification(ctx context.Context, bookID uuid.UUID, genreIDs []uuid.UUID, tags []string) error
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"getGenres":        &getGenresStmt,
+		"getGenre":         &getGenreStmt,
+		"createGenre":      &createGenreStmt,
+		"getGenreBooks":    &getGenreBooksStmt,
+		"lockBook":         &lockBookStmt,
+		"deleteBookGenres": &deleteBookGenresStmt,
+		"addBookGenres":    &addBookGenresStmt,
+		"deleteBookTags":   &deleteBookTagsStmt,
+		"addBookTags":      &addBookTagsStmt,
+	})
+}
+
+type Genre struct {
+	ID        uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+	BISAC     string
+	Dewey     string
+	BookCount int
+}
+
+type BookGenre struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type BookGenres []BookGenre
+
+func (b *BookGenres) Scan(value any) error {
+	return scanJSONList(value, b)
+}
+
+type BookTags []string
+
+func (b *BookTags) Scan(value any) error {
+	return scanJSONList(value, b)
+}
+
+var ErrGenreNotFound = errors.New("genre not found")
+var ErrGenreParentNotFound = errors.New("parent genre not found")
+var ErrGenreNameMissing = errors.New("genre name missing")
+var ErrGenreCodeTaken = errors.New("another genre already has this code")
+var ErrGenreBISACInvalid = errors.New("bisac code must be three letters followed by six digits")
+var ErrGenreDeweyInvalid = errors.New("dewey code must be three digits with an optional decimal part")
+var ErrTagInvalid = errors.New("tags must be between 1 and 64 characters")
+var ErrTooManyTags = errors.New("a book can't have more than 50 tags")
+
+const maxBookTags = 50
+
+var bisacCode = regexp.MustCompile(`^[A-Z]{3}[0-9]{6}$`)
+var deweyCode = regexp.MustCompile(`^[0-9]{3}(\.[0-9]+)?$`)
+
+// Validate trims the genre's fields in place and checks its codes.
+func (g *Genre) Validate() error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return ErrGenreNameMissing
+	}
+	g.BISAC = strings.ToUpper(strings.TrimSpace(g.BISAC))
+	if g.BISAC != "" && !bisacCode.MatchString(g.BISAC) {
+		return ErrGenreBISACInvalid
+	}
+	g.Dewey = strings.TrimSpace(g.Dewey)
+	if g.Dewey != "" && !deweyCode.MatchString(g.Dewey) {
+		return ErrGenreDeweyInvalid
+	}
+	return nil
+}
+
+// NormalizeTags lowercases and trims tags, dropping duplicates, and returns
+// them sorted.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || len([]rune(tag)) > 64 {
+			return nil, ErrTagInvalid
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > maxBookTags {
+		return nil, ErrTooManyTags
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func scanGenre(row interface{ Scan(...any) error }) (Genre, error) {
+	genre := Genre{}
+	err := row.Scan(
+		&genre.ID,
+		&genre.ParentID,
+		&genre.Name,
+		&genre.BISAC,
+		&genre.Dewey,
+		&genre.BookCount,
+	)
+	return genre, err
+}
+
+func (db DBInstance) GetGenres(ctx context.Context) ([]Genre, error) {
+	rows, err := getGenresStmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var genres []Genre
+	for rows.Next() {
+		genre, err := scanGenre(rows)
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (db DBInstance) GetGenre(ctx context.Context, genreID uuid.UUID) (*Genre, error) {
+	genre, err := scanGenre(getGenreStmt.QueryRowContext(ctx, genreID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGenreNotFound
+		}
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (db DBInstance) CreateGenre(ctx context.Context, genre Genre) (*Genre, error) {
+	if err := genre.Validate(); err != nil {
+		return nil, err
+	}
+
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	genre.ID = randomUUID
+	genre.BookCount = 0
+
+	if _, err := createGenreStmt.ExecContext(ctx,
+		genre.ID,
+		genre.ParentID,
+		genre.Name,
+		genre.BISAC,
+		genre.Dewey,
+	); err != nil {
+		switch {
+		case isForeignKeyViolation(err):
+			return nil, ErrGenreParentNotFound
+		case isUniqueViolation(err):
+			return nil, ErrGenreCodeTaken
+		}
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (db DBInstance) GetGenreBooks(ctx context.Context, genreID uuid.UUID, limit int, offset int, accountID string) ([]Book, error) {
+	if limit <= 0 || offset < 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+	rows, err := getGenreBooksStmt.QueryContext(ctx, accountID, genreID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// SetBookClassification replaces the genres and tags of a book. Tags are
+// expected to be normalized already.
+func (db DBInstance) SetBookClassification(ctx context.Context, bookID uuid.UUID, genreIDs []uuid.UUID, tags []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var locked int
+	if err := lockBookStmt.Tx(ctx, tx).QueryRowContext(ctx, bookID).Scan(&locked); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrBookNotFound
+		}
+		return err
+	}
+
+	ids := make([]string, 0, len(genreIDs))
+	for _, id := range genreIDs {
+		ids = append(ids, id.String())
+	}
+
+	if _, err := deleteBookGenresStmt.Tx(ctx, tx).ExecContext(ctx, bookID); err != nil {
+		return err
+	}
+	if _, err := addBookGenresStmt.Tx(ctx, tx).ExecContext(ctx, bookID, pq.Array(ids)); err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrGenreNotFound
+		}
+		return err
+	}
+	if _, err := deleteBookTagsStmt.Tx(ctx, tx).ExecContext(ctx, bookID); err != nil {
+		return err
+	}
+	if _, err := addBookTagsStmt.Tx(ctx, tx).ExecContext(ctx, bookID, pq.Array(tags)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"Sports ", "shonen", "sports", "slice  of life"})
+	if assert.Nil(t, err, "unexpected error normalizing valid tags") {
+		assert.Equal(t, []string{"shonen", "slice of life", "sports"}, tags)
+	}
+
+	_, err = NormalizeTags([]string{"ok", " "})
+	assert.Equal(t, ErrTagInvalid, err, "an empty tag should've been rejected")
+
+	_, err = NormalizeTags([]string{strings.Repeat("a", 65)})
+	assert.Equal(t, ErrTagInvalid, err, "a tag over 64 characters should've been rejected")
+}
+
+func TestValidateGenre(t *testing.T) {
+	tests := []struct {
+		genre  Genre
+		expErr error
+	}{
+		{Genre{Name: "Fantasy", BISAC: "fic009000", Dewey: "813.54"}, nil},
+		{Genre{Name: " "}, ErrGenreNameMissing},
+		{Genre{Name: "Fantasy", BISAC: "FIC9000"}, ErrGenreBISACInvalid},
+		{Genre{Name: "Fantasy", Dewey: "81"}, ErrGenreDeweyInvalid},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expErr, test.genre.Validate(), "unexpected error validating %+v", test.genre)
+	}
+}
+
+func TestNotFoundSetBookClassification(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	bookID := uuid.New()
+
+	test1 := mock.ExpectPrepare("SELECT")
+	mock.ExpectBegin()
+	test1.ExpectQuery().
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}))
+	mock.ExpectRollback()
+
+	err = lockBookStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.SetBookClassification(ctx, bookID, []uuid.UUID{uuid.New()}, []string{"sports"})
+	assert.Equal(t, ErrBookNotFound, err, "function should've reported the book as missing")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestSuccessfulSetBookClassification(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	bookID := uuid.New()
+	genreID := uuid.New()
+
+	lock := mock.ExpectPrepare("SELECT")
+	deleteGenres := mock.ExpectPrepare("DELETE")
+	addGenres := mock.ExpectPrepare("INSERT")
+	deleteTags := mock.ExpectPrepare("DELETE")
+	addTags := mock.ExpectPrepare("INSERT")
+	mock.ExpectBegin()
+	lock.ExpectQuery().
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+	deleteGenres.ExpectExec().
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	addGenres.ExpectExec().
+		WithArgs(bookID, "{\""+genreID.String()+"\"}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	deleteTags.ExpectExec().
+		WithArgs(bookID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	addTags.ExpectExec().
+		WithArgs(bookID, `{"shonen","sports"}`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	for _, stmt := range []*dbStatement{&lockBookStmt, &deleteBookGenresStmt, &addBookGenresStmt, &deleteBookTagsStmt, &addBookTagsStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	err = db.SetBookClassification(ctx, bookID, []uuid.UUID{genreID}, []string{"shonen", "sports"})
+	assert.Nil(t, err, "unexpected error in a successful book classification test")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
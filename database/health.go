@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// appliedSQLVersion is the version of the schema file InitDB ran, 0 until it
+// succeeds.
+var appliedSQLVersion atomic.Int64
+
+type HealthInterface interface {
+	PingContext(ctx context.Context) error
+	CheckStatements() error
+	CheckMigration() error
+}
+
+// CheckStatements fails when a statement of prepareStatements hasn't been
+// prepared, which happens when InitDB didn't run to its end.
+func (db DBInstance) CheckStatements() error {
+	missing := 0
+	for _, stmt := range prepareStatements {
+		if stmt.Statement == nil {
+			missing++
+		}
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d of %d statements not prepared", missing, len(prepareStatements))
+	}
+	return nil
+}
+
+// CheckMigration fails unless InitDB ran the newest schema file.
+func (db DBInstance) CheckMigration() error {
+	latest, _, err := latestSQLVersion()
+	if err != nil {
+		return err
+	}
+	if applied := appliedSQLVersion.Load(); applied != int64(latest) {
+		return fmt.Errorf("schema version %d applied, %d expected", applied, latest)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrISBNMalformed = errors.New("isbn malformed")
+var ErrISBNChecksum = errors.New("isbn checksum mismatch")
+
+// NormalizeISBN strips separators from an ISBN-10 or ISBN-13, verifies its
+// check digit and returns it as a 13 digit string, which is how ISBNs are
+// stored in the book table.
+func NormalizeISBN(isbn string) (string, error) {
+	var digits []byte
+	for _, c := range strings.ToUpper(isbn) {
+		switch {
+		case c >= '0' && c <= '9', c == 'X':
+			digits = append(digits, byte(c))
+		case c == '-', c == ' ':
+		default:
+			return "", ErrISBNMalformed
+		}
+	}
+
+	switch len(digits) {
+	case 10:
+		if !validISBN10(digits) {
+			return "", ErrISBNChecksum
+		}
+		isbn13 := append([]byte("978"), digits[:9]...)
+		return string(append(isbn13, isbn13CheckDigit(isbn13))), nil
+	case 13:
+		if strings.ContainsRune(string(digits), 'X') {
+			return "", ErrISBNMalformed
+		}
+		if isbn13CheckDigit(digits[:12]) != digits[12] {
+			return "", ErrISBNChecksum
+		}
+		return string(digits), nil
+	default:
+		return "", ErrISBNMalformed
+	}
+}
+
+func validISBN10(digits []byte) bool {
+	sum := 0
+	for i, c := range digits {
+		var v int
+		switch {
+		case c == 'X' && i == 9:
+			v = 10
+		case c == 'X':
+			return false
+		default:
+			v = int(c - '0')
+		}
+		sum += (10 - i) * v
+	}
+	return sum%11 == 0
+}
+
+func isbn13CheckDigit(first12 []byte) byte {
+	sum := 0
+	for i, c := range first12 {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(c-'0')
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		isbn    string
+		expISBN string
+		expErr  error
+	}{
+		{"978-4-08-872509-3", "9784088725093", nil},
+		{"9784088725093", "9784088725093", nil},
+		{"4-08-872509-X", "", ErrISBNChecksum},
+		{"0-306-40615-2", "9780306406157", nil},
+		{"0 8044 2957 X", "9780804429573", nil},
+		{"978-4-08-872509-4", "", ErrISBNChecksum},
+		{"978408872509", "", ErrISBNMalformed},
+		{"97840887250X3", "", ErrISBNMalformed},
+		{"ISBN 9784088725093", "", ErrISBNMalformed},
+	}
+
+	for _, test := range tests {
+		isbn, err := NormalizeISBN(test.isbn)
+		assert.Equal(t, test.expErr, err, "unexpected error normalizing %q", test.isbn)
+		assert.Equal(t, test.expISBN, isbn, "unexpected normalized isbn for %q", test.isbn)
+	}
+}
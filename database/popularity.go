@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Windows the book_popularity view is ranked over.
+const (
+	PopularityWindow7d  = "7d"
+	PopularityWindow30d = "30d"
+	PopularityWindowAll = "all"
+)
+
+const (
+	BookEventView = "view"
+	BookEventLoan = "loan"
+)
+
+// recordBookEventStmt keeps at most one event of each kind per reader and
+// book a day, so reloading a page doesn't inflate the score.
+var recordBookEventStmt = dbStatement{
+	nil, `
+	INSERT INTO book_event (
+		book_id, account_id, kind
+	)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT DO NOTHING;`,
+}
+
+var refreshPopularityStmt = dbStatement{
+	nil, `
+	REFRESH MATERIALIZED VIEW CONCURRENTLY book_popularity;`,
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"recordBookEvent":   &recordBookEventStmt,
+		"refreshPopularity": &refreshPopularityStmt,
+	})
+}
+
+var ErrPopularityWindowUnknown = errors.New("popularity window must be one of 7d, 30d or all")
+
+func IsPopularityWindow(window string) bool {
+	switch window {
+	case PopularityWindow7d, PopularityWindow30d, PopularityWindowAll:
+		return true
+	}
+	return false
+}
+
+func (db DBInstance) RecordBookView(ctx context.Context, bookID uuid.UUID, accountID string) error {
+	_, err := recordBookEventStmt.ExecContext(ctx, bookID, accountID, BookEventView)
+	if isForeignKeyViolation(err) {
+		return ErrBookNotFound
+	}
+	return err
+}
+
+func (db DBInstance) RefreshPopularity(ctx context.Context) error {
+	_, err := refreshPopularityStmt.ExecContext(ctx)
+	return err
+}
+
+// RunPopularityRefresh refreshes the popularity scores every interval until
+// ctx is cancelled.
+func (db DBInstance) RunPopularityRefresh(ctx context.Context, interval time.Duration) error {
+	return runEvery(ctx, interval, func(ctx context.Context) {
+		if err := db.RefreshPopularity(ctx); err != nil {
+			log.Error().Err(err).Msg("Refreshing book popularity returned an error")
+			return
+		}
+		log.Info().Msg("Book popularity refreshed")
+	})
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuccessfulRecordBookView(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	bookID := uuid.New()
+	mock.ExpectPrepare("INSERT").
+		ExpectExec().
+		WithArgs(bookID, expEmail, BookEventView).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = recordBookEventStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.RecordBookView(ctx, bookID, expEmail)
+	assert.Nil(t, err, "unexpected error in a successful record book view test")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestNotFoundRecordBookView(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	bookID := uuid.New()
+	mock.ExpectPrepare("INSERT").
+		ExpectExec().
+		WithArgs(bookID, expEmail, BookEventView).
+		WillReturnError(&pq.Error{Code: "23503"})
+
+	err = recordBookEventStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.RecordBookView(ctx, bookID, expEmail)
+	assert.Equal(t, ErrBookNotFound, err, "function should've reported the book as missing")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestUnknownWindowPopularBooks(t *testing.T) {
+	db := DBInstance{DB: &sql.DB{}}
+
+	_, err := db.GetPopularBooksPaginated(context.Background(), 8, 0, "1y", uuid.NullUUID{}, expEmail)
+	assert.Equal(t, ErrPopularityWindowUnknown, err, "function should've rejected an unknown window")
+}
+
+func TestRunPopularityRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	mock.ExpectPrepare("REFRESH").
+		ExpectExec().
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = refreshPopularityStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- db.RunPopularityRefresh(ctx, time.Millisecond)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, time.Second, time.Millisecond, "the popularity refresh never ran")
+	cancel()
+
+	select {
+	case err := <-stopped:
+		assert.Nil(t, err, "a cancelled popularity refresh returned an error")
+	case <-time.After(time.Second):
+		t.Fatal("the popularity refresh didn't stop when its context was cancelled")
+	}
+}
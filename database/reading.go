@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// saveReadingProgressStmt keeps the newest progress by the time the device
+// made it, so a device syncing late can't move the reader back. No row comes
+// back when the stored progress is newer. Progress stored from the future,
+// before such times were refused, is always replaced.
+var saveReadingProgressStmt = dbStatement{
+	nil, `
+	INSERT INTO reading_progress (
+		account_id, book_id, cfi, page, percentage, device_id, updated_at, finished_at
+	)
+	VALUES
+		(
+			$1, $2, NULLIF($3, ''), $4, $5::real, $6, $7,
+			CASE WHEN $5::real >= 100 THEN now() END
+		)
+	ON CONFLICT (account_id, book_id) DO UPDATE
+	SET
+		cfi = EXCLUDED.cfi,
+		page = EXCLUDED.page,
+		percentage = EXCLUDED.percentage,
+		device_id = EXCLUDED.device_id,
+		updated_at = EXCLUDED.updated_at,
+		received_at = now(),
+		finished_at = COALESCE(reading_progress.finished_at, EXCLUDED.finished_at)
+	WHERE
+		reading_progress.updated_at <= EXCLUDED.updated_at
+		OR reading_progress.updated_at > now() + interval '5 minutes'
+	RETURNING
+		(xmax = 0) AS started;`,
+}
+
+const readingProgressColumnsStr = `
+		rp.book_id,
+		COALESCE(rp.cfi, ''),
+		COALESCE(rp.page, 0),
+		rp.percentage,
+		rp.device_id,
+		rp.updated_at,
+		rp.received_at,
+		rp.finished_at`
+
+var getReadingProgressStmt = dbStatement{
+	nil, `
+	SELECT` + readingProgressColumnsStr + `
+	FROM
+		reading_progress rp
+	WHERE
+		rp.account_id = $1
+		AND rp.book_id = $2;`,
+}
+
+// getContinueReadingStmt lists the books an account started and didn't
+// finish, the last one read first.
+var getContinueReadingStmt = dbStatement{
+	nil, `
+	SELECT` + bookColumnsStr + `,` + readingProgressColumnsStr + `
+	FROM
+		book b
+		LEFT JOIN rating_avg AS av ON b.id = av.id
+		JOIN reading_progress rp ON rp.book_id = b.id
+	WHERE
+		rp.account_id = $1
+		AND rp.finished_at IS NULL
+	ORDER BY
+		rp.updated_at DESC
+	LIMIT
+		$2;`,
+}
+
+var getFinishedBookCountStmt = dbStatement{
+	nil, `
+	SELECT
+		count(*)
+	FROM
+		reading_progress
+	WHERE
+		account_id = $1
+		AND finished_at IS NOT NULL;`,
+}
+
+type ReadingInterface interface {
+	SaveReadingProgress(ctx context.Context, accountID string, progress ReadingProgress) (*ReadingProgress, bool, error)
+	GetContinueReading(ctx context.Context, limit int, accountID string) ([]ReadingBook, error)
+	GetFinishedBookCount(ctx context.Context, accountID string) (int, error)
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"saveReadingProgress":  &saveReadingProgressStmt,
+		"getReadingProgress":   &getReadingProgressStmt,
+		"getContinueReading":   &getContinueReadingStmt,
+		"getFinishedBookCount": &getFinishedBookCountStmt,
+	})
+}
+
+// ReadingProgress is where a reader is in a book, as either an EPUB CFI or a
+// PDF page. UpdatedAt is the time the device made the progress, ReceivedAt
+// the time it reached us.
+type ReadingProgress struct {
+	BookID     uuid.UUID
+	CFI        string
+	Page       int
+	Percentage float32
+	DeviceID   string
+	UpdatedAt  time.Time
+	ReceivedAt time.Time
+	FinishedAt *time.Time
+}
+
+type ReadingBook struct {
+	Book
+	Progress ReadingProgress
+}
+
+var ErrReadingLocatorInvalid = errors.New("progress needs either an epub cfi or a pdf page")
+var ErrReadingPercentageInvalid = errors.New("percentage must be between 0 and 100")
+var ErrReadingDeviceMissing = errors.New("device id missing")
+var ErrReadingTimeMissing = errors.New("progress time missing")
+var ErrReadingTimeInvalid = errors.New("progress time is in the future")
+
+// readingClockSkew is how far ahead of ours a device's clock may be. Progress
+// dated later would win over every sync until then.
+const readingClockSkew = 5 * time.Minute
+
+// Validate trims the progress's fields in place and checks them.
+func (p *ReadingProgress) Validate() error {
+	p.CFI = strings.TrimSpace(p.CFI)
+	if (p.CFI == "") == (p.Page == 0) || p.Page < 0 {
+		return ErrReadingLocatorInvalid
+	}
+	if p.Percentage < 0 || p.Percentage > 100 {
+		return ErrReadingPercentageInvalid
+	}
+	p.DeviceID = strings.TrimSpace(p.DeviceID)
+	if p.DeviceID == "" || len(p.DeviceID) > 255 {
+		return ErrReadingDeviceMissing
+	}
+	if p.UpdatedAt.IsZero() {
+		return ErrReadingTimeMissing
+	}
+	if p.UpdatedAt.After(time.Now().Add(readingClockSkew)) {
+		return ErrReadingTimeInvalid
+	}
+	return nil
+}
+
+func readingProgressColumns(progress *ReadingProgress) []any {
+	return []any{
+		&progress.BookID,
+		&progress.CFI,
+		&progress.Page,
+		&progress.Percentage,
+		&progress.DeviceID,
+		&progress.UpdatedAt,
+		&progress.ReceivedAt,
+		&progress.FinishedAt,
+	}
+}
+
+// SaveReadingProgress stores the progress unless the account already has a
+// newer one for the book. Either way it returns the progress now stored, and
+// whether it was the one given. Starting a book counts as a loan.
+func (db DBInstance) SaveReadingProgress(ctx context.Context, accountID string, progress ReadingProgress) (*ReadingProgress, bool, error) {
+	if err := progress.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	page := sql.NullInt32{Int32: int32(progress.Page), Valid: progress.Page > 0}
+	var started bool
+	err = saveReadingProgressStmt.Tx(ctx, tx).QueryRowContext(ctx,
+		accountID,
+		progress.BookID,
+		progress.CFI,
+		page,
+		progress.Percentage,
+		progress.DeviceID,
+		progress.UpdatedAt,
+	).Scan(&started)
+	applied := err == nil
+	switch {
+	case isForeignKeyViolation(err):
+		return nil, false, ErrBookNotFound
+	case err != nil && err != sql.ErrNoRows:
+		return nil, false, err
+	}
+
+	if started {
+		if _, err := recordBookEventStmt.Tx(ctx, tx).
+			ExecContext(ctx, progress.BookID, accountID, BookEventLoan); err != nil {
+			return nil, false, err
+		}
+	}
+
+	stored := ReadingProgress{}
+	if err := getReadingProgressStmt.Tx(ctx, tx).
+		QueryRowContext(ctx, accountID, progress.BookID).
+		Scan(readingProgressColumns(&stored)...); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	db.replicas.pin(accountID)
+	return &stored, applied, nil
+}
+
+func (db DBInstance) GetContinueReading(ctx context.Context, limit int, accountID string) ([]ReadingBook, error) {
+	if limit <= 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+	rows, err := getContinueReadingStmt.QueryContext(ctx, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var books []ReadingBook
+	for rows.Next() {
+		book := ReadingBook{}
+		if err := rows.Scan(append(bookColumns(&book.Book), readingProgressColumns(&book.Progress)...)...); err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (db DBInstance) GetFinishedBookCount(ctx context.Context, accountID string) (int, error) {
+	var count int
+	if err := getFinishedBookCountStmt.QueryRowContext(ctx, accountID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
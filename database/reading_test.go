@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateReadingProgress(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		progress ReadingProgress
+		expErr   error
+	}{
+		{ReadingProgress{CFI: " epubcfi(/6/4) ", Percentage: 10, DeviceID: "phone", UpdatedAt: now}, nil},
+		{ReadingProgress{Page: 3, Percentage: 100, DeviceID: "phone", UpdatedAt: now}, nil},
+		{ReadingProgress{Percentage: 10, DeviceID: "phone", UpdatedAt: now}, ErrReadingLocatorInvalid},
+		{ReadingProgress{CFI: "epubcfi(/6/4)", Page: 3, Percentage: 10, DeviceID: "phone", UpdatedAt: now}, ErrReadingLocatorInvalid},
+		{ReadingProgress{Page: 3, Percentage: 101, DeviceID: "phone", UpdatedAt: now}, ErrReadingPercentageInvalid},
+		{ReadingProgress{Page: 3, Percentage: 10, DeviceID: " ", UpdatedAt: now}, ErrReadingDeviceMissing},
+		{ReadingProgress{Page: 3, Percentage: 10, DeviceID: "phone"}, ErrReadingTimeMissing},
+		{ReadingProgress{Page: 3, Percentage: 10, DeviceID: "phone", UpdatedAt: now.Add(4 * time.Minute)}, nil},
+		{ReadingProgress{Page: 3, Percentage: 10, DeviceID: "phone", UpdatedAt: now.Add(6 * time.Minute)}, ErrReadingTimeInvalid},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expErr, test.progress.Validate(), "unexpected error validating %+v", test.progress)
+	}
+}
+
+func TestStaleSaveReadingProgress(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	progress := ReadingProgress{
+		BookID:     uuid.New(),
+		CFI:        "epubcfi(/6/4)",
+		Percentage: 10,
+		DeviceID:   "tablet",
+		UpdatedAt:  time.Date(2026, time.October, 18, 8, 30, 0, 0, time.UTC),
+	}
+	stored := ReadingProgress{
+		BookID:     progress.BookID,
+		Page:       42,
+		Percentage: 60,
+		DeviceID:   "phone",
+		UpdatedAt:  progress.UpdatedAt.Add(time.Hour),
+		ReceivedAt: progress.UpdatedAt.Add(time.Hour),
+	}
+
+	save := mock.ExpectPrepare("INSERT")
+	get := mock.ExpectPrepare("SELECT")
+	mock.ExpectBegin()
+	save.ExpectQuery().
+		WithArgs(expEmail, progress.BookID, progress.CFI, sql.NullInt32{}, progress.Percentage, progress.DeviceID, progress.UpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"started"}))
+	get.ExpectQuery().
+		WithArgs(expEmail, progress.BookID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"book_id", "cfi", "page", "percentage", "device_id", "updated_at", "received_at", "finished_at",
+		}).AddRow(stored.BookID, "", stored.Page, stored.Percentage, stored.DeviceID, stored.UpdatedAt, stored.ReceivedAt, nil))
+	mock.ExpectCommit()
+
+	for _, stmt := range []*dbStatement{&saveReadingProgressStmt, &getReadingProgressStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	got, applied, err := db.SaveReadingProgress(ctx, expEmail, progress)
+	if assert.Nil(t, err, "unexpected error saving a stale reading progress") {
+		assert.False(t, applied, "function shouldn't have applied an older progress")
+		assert.Equal(t, stored, *got, "function should've returned the stored progress")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestFutureSaveReadingProgress(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	future := ReadingProgress{
+		BookID:     uuid.New(),
+		Page:       300,
+		Percentage: 90,
+		DeviceID:   "phone",
+		UpdatedAt:  time.Date(2099, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	current := ReadingProgress{
+		BookID:     future.BookID,
+		Page:       42,
+		Percentage: 15,
+		DeviceID:   "tablet",
+		UpdatedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+	stored := current
+	stored.ReceivedAt = current.UpdatedAt
+
+	save := mock.ExpectPrepare("INSERT")
+	get := mock.ExpectPrepare("SELECT")
+	mock.ExpectBegin()
+	save.ExpectQuery().
+		WithArgs(expEmail, current.BookID, "", sql.NullInt32{Int32: 42, Valid: true}, current.Percentage, current.DeviceID, current.UpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"started"}).AddRow(false))
+	get.ExpectQuery().
+		WithArgs(expEmail, current.BookID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"book_id", "cfi", "page", "percentage", "device_id", "updated_at", "received_at", "finished_at",
+		}).AddRow(stored.BookID, "", stored.Page, stored.Percentage, stored.DeviceID, stored.UpdatedAt, stored.ReceivedAt, nil))
+	mock.ExpectCommit()
+
+	for _, stmt := range []*dbStatement{&saveReadingProgressStmt, &getReadingProgressStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	_, _, err = db.SaveReadingProgress(ctx, expEmail, future)
+	assert.Equal(t, ErrReadingTimeInvalid, err, "function should've refused a progress from the future")
+
+	got, applied, err := db.SaveReadingProgress(ctx, expEmail, current)
+	if assert.Nil(t, err, "unexpected error saving a reading progress after a future one") {
+		assert.True(t, applied, "the progress from the future shouldn't have held back the current one")
+		assert.Equal(t, stored, *got)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
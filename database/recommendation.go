@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// accountBooksStr lists the books the account in $1 has already read, with
+// how much they liked each. Ratings under three stars count against a book.
+const accountBooksStr = `
+				SELECT
+					book_id,
+					max(weight) AS weight
+				FROM
+					(
+						SELECT
+							book_id,
+							1.0
+						FROM
+							fav_book
+						WHERE
+							user_id = $1
+						UNION ALL
+						SELECT
+							book_id,
+							(rating - 2.5) / 2.5
+						FROM
+							rate_book
+						WHERE
+							user_id = $1
+						UNION ALL
+						SELECT
+							book_id,
+							1.0
+						FROM
+							book_event
+						WHERE
+							account_id = $1
+							AND kind = 'loan'
+					) AS seen (book_id, weight)
+				GROUP BY
+					book_id`
+
+// getRecommendationsStmt sums the neighbors of every book the account has
+// read, weighted by how much they liked it. Popular books are blended in with
+// a small score so accounts without history still get suggestions.
+var getRecommendationsStmt = dbStatement{
+	nil, fmt.Sprintf(bookSelectStr, `
+		JOIN (
+			SELECT
+				book_id,
+				sum(score) AS score
+			FROM
+				(
+					SELECT
+						n.neighbor_id,
+						s.weight * n.score
+					FROM
+						(`+accountBooksStr+`
+						) AS s
+						JOIN book_neighbor n ON n.book_id = s.book_id
+					UNION ALL
+					SELECT
+						p.book_id,
+						0.01 * ln(1 + p.score)
+					FROM
+						book_popularity p
+					WHERE
+						p.time_window = '30d'
+				) AS candidate (book_id, score)
+			GROUP BY
+				book_id
+		) AS c ON c.book_id = b.id
+	WHERE
+		c.score > 0
+		AND b.id NOT IN (`+accountBooksStr+`
+		)
+	ORDER BY
+		c.score DESC,
+		b.title ASC
+	LIMIT
+		$2 OFFSET $3`),
+}
+
+var getSimilarBooksStmt = dbStatement{
+	nil, fmt.Sprintf(bookSelectStr, `
+		JOIN book_neighbor n ON n.neighbor_id = b.id AND n.book_id = $2
+	ORDER BY
+		n.rank ASC
+	LIMIT
+		$3`),
+}
+
+var bookExistsStmt = dbStatement{
+	nil, `
+	SELECT
+		EXISTS (
+			SELECT
+				1
+			FROM
+				book
+			WHERE
+				id = $1
+		);`,
+}
+
+var refreshBookNeighborsStmt = dbStatement{
+	nil, `
+	REFRESH MATERIALIZED VIEW CONCURRENTLY book_neighbor;`,
+}
+
+type RecommendationInterface interface {
+	GetRecommendations(ctx context.Context, limit int, offset int, accountID string) ([]Book, error)
+	GetSimilarBooks(ctx context.Context, bookID uuid.UUID, limit int, accountID string) ([]Book, error)
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"getRecommendations":   &getRecommendationsStmt,
+		"getSimilarBooks":      &getSimilarBooksStmt,
+		"bookExists":           &bookExistsStmt,
+		"refreshBookNeighbors": &refreshBookNeighborsStmt,
+	})
+}
+
+func (db DBInstance) GetRecommendations(ctx context.Context, limit int, offset int, accountID string) ([]Book, error) {
+	if limit <= 0 || offset < 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+	rows, err := getRecommendationsStmt.QueryContext(ctx, accountID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// GetSimilarBooks returns the closest neighbors of a book. A book without
+// neighbors yet gets an empty list, only a missing book is an error.
+func (db DBInstance) GetSimilarBooks(ctx context.Context, bookID uuid.UUID, limit int, accountID string) ([]Book, error) {
+	if limit <= 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+	rows, err := getSimilarBooksStmt.QueryContext(ctx, accountID, bookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	books, err := scanBooks(rows)
+	if err != nil || len(books) > 0 {
+		return books, err
+	}
+
+	var exists bool
+	if err := bookExistsStmt.QueryRowContext(ctx, bookID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+	return books, nil
+}
+
+func (db DBInstance) RefreshBookNeighbors(ctx context.Context) error {
+	_, err := refreshBookNeighborsStmt.ExecContext(ctx)
+	return err
+}
+
+// RunRecommendationRefresh refreshes the book neighbors every interval until
+// ctx is cancelled.
+func (db DBInstance) RunRecommendationRefresh(ctx context.Context, interval time.Duration) error {
+	return runEvery(ctx, interval, func(ctx context.Context) {
+		if err := db.RefreshBookNeighbors(ctx); err != nil {
+			log.Error().Err(err).Msg("Refreshing book neighbors returned an error")
+			return
+		}
+		log.Info().Msg("Book neighbors refreshed")
+	})
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotFoundGetSimilarBooks(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	bookID := uuid.New()
+
+	similar := mock.ExpectPrepare("SELECT")
+	exists := mock.ExpectPrepare("SELECT")
+	similar.ExpectQuery().
+		WithArgs(expEmail, bookID, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"})).
+		RowsWillBeClosed()
+	exists.ExpectQuery().
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	for _, stmt := range []*dbStatement{&getSimilarBooksStmt, &bookExistsStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	_, err = db.GetSimilarBooks(ctx, bookID, 10, expEmail)
+	assert.Equal(t, ErrBookNotFound, err, "function should've reported the book as missing")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestNoNeighborsGetSimilarBooks(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	bookID := uuid.New()
+
+	similar := mock.ExpectPrepare("SELECT")
+	exists := mock.ExpectPrepare("SELECT")
+	similar.ExpectQuery().
+		WithArgs(expEmail, bookID, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"})).
+		RowsWillBeClosed()
+	exists.ExpectQuery().
+		WithArgs(bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	for _, stmt := range []*dbStatement{&getSimilarBooksStmt, &bookExistsStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	books, err := db.GetSimilarBooks(ctx, bookID, 10, expEmail)
+	if assert.Nil(t, err, "unexpected error listing a book without neighbors") {
+		assert.Empty(t, books, "function should've returned no books")
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestBoundsGetRecommendations(t *testing.T) {
+	db := DBInstance{}
+
+	_, err := db.GetRecommendations(context.Background(), 0, 0, expEmail)
+	assert.NotNil(t, err, "function should've rejected an empty page")
+}
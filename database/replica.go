@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"ic-rhadi/e_library/metricshelper"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	sqldblogger "github.com/simukti/sqldb-logger"
+)
+
+// replicaLagQuery is how far a replica is behind the primary, zero once it
+// replayed everything it received. The primary itself reports zero.
+const replicaLagQuery = `SELECT COALESCE(
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END,
+	0)`
+
+// replicaCheckInterval is how long a replica's lag is trusted before it's
+// checked again.
+var replicaCheckInterval = time.Second
+
+// Where catalog reads went, as labels of metricshelper.CatalogReads.
+const (
+	routeReplica      = "replica"
+	routePrimary      = "primary"
+	routePinned       = "pinned"
+	routeLagging      = "lagging"
+	routeReplicaError = "replica_error"
+)
+
+// replicaSet spreads the catalog reads over the replicas that aren't lagging
+// more than maxLag, and remembers the accounts whose reads stay on the
+// primary until their writes have surely reached the replicas.
+//
+// Pins are kept in memory, so they only hold for reads served by the
+// instance that made the write. Behind a load balancer spreading an
+// account's requests over several instances, another instance may still
+// read from a replica that hasn't caught up, for up to maxLag.
+type replicaSet struct {
+	replicas []*replica
+	maxLag   time.Duration
+	next     atomic.Uint64
+
+	mu     sync.Mutex
+	pinned map[string]time.Time
+}
+
+type replica struct {
+	db  *sql.DB
+	dsn string
+
+	// mu is never held while querying, so a slow replica only slows the
+	// reads sent to it.
+	mu         sync.Mutex
+	statements map[*dbStatement]*sql.Stmt
+	checkedAt  time.Time
+	checking   bool
+	usable     bool
+}
+
+// openReplicas opens a pool to each replica. They're connected to when first
+// read from, so one that's down doesn't keep the server from starting.
+func openReplicas(dsns []string, opts ConnectOptions) (*replicaSet, error) {
+	set := &replicaSet{maxLag: opts.MaxReplicaLag, pinned: map[string]time.Time{}}
+	for _, dsn := range dsns {
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			set.close()
+			return nil, err
+		}
+		db = sqldblogger.OpenDriver(dsn, db.Driver(), tracedLogAdapter{}, sqldblogger.WithSQLQueryAsMessage(true))
+		db.SetMaxOpenConns(opts.MaxOpenConns)
+		db.SetMaxIdleConns(opts.MaxIdleConns)
+		db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+		set.replicas = append(set.replicas, &replica{db: db, dsn: RedactDSN(dsn), statements: map[*dbStatement]*sql.Stmt{}})
+		log.Info().Str("dsn", RedactDSN(dsn)).Msg("Database replica added")
+	}
+	return set, nil
+}
+
+// pin keeps the account's catalog reads on the primary for maxLag, so it
+// reads its own writes. Every write changing what BookInterface returns to
+// the account, like its shelves, ratings and reading progress, pins it.
+func (s *replicaSet) pin(accountID string) {
+	if s == nil || len(s.replicas) == 0 || accountID == "" {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, until := range s.pinned {
+		if now.After(until) {
+			delete(s.pinned, id)
+		}
+	}
+	s.pinned[accountID] = now.Add(s.maxLag)
+}
+
+func (s *replicaSet) isPinned(accountID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.pinned[accountID]
+	return ok && time.Now().Before(until)
+}
+
+// route picks the replica to read from, or tells why the primary should be
+// read from instead.
+func (s *replicaSet) route(ctx context.Context, accountID string) (*replica, string) {
+	if s == nil || len(s.replicas) == 0 {
+		return nil, routePrimary
+	}
+	if accountID != "" && s.isPinned(accountID) {
+		return nil, routePinned
+	}
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		r := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+		if r.isUsable(ctx, s.maxLag) {
+			return r, routeReplica
+		}
+	}
+	return nil, routeLagging
+}
+
+func (s *replicaSet) close() {
+	if s == nil {
+		return
+	}
+	for _, r := range s.replicas {
+		for _, stmt := range r.statements {
+			stmt.Close()
+		}
+		r.db.Close()
+	}
+}
+
+// isUsable checks the replica's lag, at most once per replicaCheckInterval.
+// While one read checks it, the others go by the last result.
+func (r *replica) isUsable(ctx context.Context, maxLag time.Duration) bool {
+	r.mu.Lock()
+	if r.checking || time.Since(r.checkedAt) < replicaCheckInterval {
+		usable := r.usable
+		r.mu.Unlock()
+		return usable
+	}
+	r.checking = true
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckInterval)
+	defer cancel()
+	var lag float64
+	err := r.db.QueryRowContext(ctx, replicaLagQuery).Scan(&lag)
+	usable := err == nil && lag <= maxLag.Seconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if usable != r.usable || (r.checkedAt.IsZero() && !usable) {
+		event := log.Warn()
+		if usable {
+			event = log.Info()
+		}
+		event.Err(err).Str("dsn", r.dsn).Float64("lag_seconds", lag).Bool("usable", usable).
+			Msg("Database replica usability changed")
+	}
+	r.checkedAt, r.checking, r.usable = time.Now(), false, usable
+	return usable
+}
+
+// failed keeps reads off the replica until it's checked again.
+func (r *replica) failed(err error) {
+	log.Warn().Err(err).Str("dsn", r.dsn).Msg("Database replica read failed, reading from the primary")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkedAt, r.usable = time.Now(), false
+}
+
+// statement prepares stmt on the replica the first time it's read with.
+func (r *replica) statement(ctx context.Context, stmt *dbStatement) (instrumentedStatement, error) {
+	r.mu.Lock()
+	prepared, ok := r.statements[stmt]
+	r.mu.Unlock()
+	if !ok {
+		var err error
+		if prepared, err = r.db.PrepareContext(ctx, stmt.Query); err != nil {
+			return instrumentedStatement{}, err
+		}
+
+		r.mu.Lock()
+		// Another read may have prepared it meanwhile.
+		if stored, ok := r.statements[stmt]; ok {
+			prepared.Close()
+			prepared = stored
+		} else {
+			r.statements[stmt] = prepared
+		}
+		r.mu.Unlock()
+	}
+	return instrumentedStatement{prepared, statementNames[stmt], stmt.Query}, nil
+}
+
+// catalogQuerier runs a statement on the primary or a replica.
+type catalogQuerier interface {
+	QueryContext(ctx context.Context, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, args ...any) *sql.Row
+}
+
+// readCatalog runs read with stmt on a replica when there's one that isn't
+// lagging and the account isn't pinned to the primary. When the replica
+// fails, read is run again on the primary; so is it when the replica doesn't
+// have the book, which may have just been added.
+func readCatalog[T any](ctx context.Context, db DBInstance, accountID string, stmt *dbStatement, read func(stmt catalogQuerier) (T, error)) (T, error) {
+	r, route := db.replicas.route(ctx, accountID)
+	if r != nil {
+		onReplica, err := r.statement(ctx, stmt)
+		if err == nil {
+			var result T
+			if result, err = read(onReplica); err == nil || ctx.Err() != nil {
+				metricshelper.CatalogReads.WithLabelValues(routeReplica).Inc()
+				return result, err
+			}
+		}
+		if !errors.Is(err, ErrBookNotFound) {
+			r.failed(err)
+		}
+		route = routeReplicaError
+	}
+	metricshelper.CatalogReads.WithLabelValues(route).Inc()
+	return read(stmt)
+}
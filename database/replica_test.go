@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const newArrivalWindow = 720 * time.Hour
+
+// replicaTestDB is a primary with one replica, with getNewBooks prepared on
+// the primary.
+func replicaTestDB(t *testing.T, maxLag time.Duration) (DBInstance, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	pd, primary, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	t.Cleanup(func() { pd.Close() })
+	rd, replicaMock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	t.Cleanup(func() { rd.Close() })
+
+	primary.ExpectPrepare("SELECT")
+	require.Nil(t, getNewBooks.Prepare(context.Background(), pd))
+
+	return DBInstance{DB: pd, replicas: &replicaSet{
+		replicas: []*replica{{db: rd, statements: map[*dbStatement]*sql.Stmt{}}},
+		maxLag:   maxLag,
+		pinned:   map[string]time.Time{},
+	}}, primary, replicaMock
+}
+
+func expectLag(mock sqlmock.Sqlmock, seconds float64) {
+	mock.ExpectQuery("pg_last_wal_receive_lsn").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(seconds))
+}
+
+func noBooks() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id"})
+}
+
+func TestReplicaRead(t *testing.T) {
+	ctx := context.Background()
+	db, primary, replicaMock := replicaTestDB(t, 5*time.Second)
+
+	expectLag(replicaMock, 0.5)
+	replicaMock.ExpectPrepare("SELECT").ExpectQuery().WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+	replicaMock.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+
+	for i := 0; i < 2; i++ {
+		_, err := db.GetNewBooks(ctx, newArrivalWindow, expEmail)
+		assert.Nil(t, err, "unexpected error in a replica read test")
+	}
+	assert.Nil(t, replicaMock.ExpectationsWereMet(), "the lag should've been checked once, and the statement prepared once")
+	assert.Nil(t, primary.ExpectationsWereMet())
+}
+
+func TestLaggingReplica(t *testing.T) {
+	ctx := context.Background()
+	db, primary, replicaMock := replicaTestDB(t, 5*time.Second)
+
+	expectLag(replicaMock, 30)
+	primary.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+
+	_, err := db.GetNewBooks(ctx, newArrivalWindow, expEmail)
+	assert.Nil(t, err, "unexpected error in a lagging replica test")
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+	assert.Nil(t, primary.ExpectationsWereMet(), "a lagging replica was read from")
+}
+
+func TestFailingReplica(t *testing.T) {
+	ctx := context.Background()
+	db, primary, replicaMock := replicaTestDB(t, 5*time.Second)
+
+	expectLag(replicaMock, 0)
+	replicaMock.ExpectPrepare("SELECT").ExpectQuery().WillReturnError(errors.New("connection reset"))
+	primary.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+	primary.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+
+	for i := 0; i < 2; i++ {
+		_, err := db.GetNewBooks(ctx, newArrivalWindow, expEmail)
+		assert.Nil(t, err, "a failing replica's error wasn't recovered from")
+	}
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+	assert.Nil(t, primary.ExpectationsWereMet(), "a failed replica should be left alone until it's checked again")
+}
+
+func TestPinnedAccount(t *testing.T) {
+	ctx := context.Background()
+	db, primary, replicaMock := replicaTestDB(t, 50*time.Millisecond)
+
+	db.replicas.pin(expEmail)
+	primary.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+	_, err := db.GetNewBooks(ctx, newArrivalWindow, expEmail)
+	assert.Nil(t, err, "unexpected error in a pinned account test")
+	assert.Nil(t, primary.ExpectationsWereMet(), "a pinned account didn't read its writes from the primary")
+
+	expectLag(replicaMock, 0)
+	replicaMock.ExpectPrepare("SELECT").ExpectQuery().WithArgs("someone@example.com", newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+	_, err = db.GetNewBooks(ctx, newArrivalWindow, "someone@example.com")
+	assert.Nil(t, err, "unexpected error in a pinned account test")
+
+	time.Sleep(60 * time.Millisecond)
+	replicaMock.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+	_, err = db.GetNewBooks(ctx, newArrivalWindow, expEmail)
+	assert.Nil(t, err, "unexpected error in a pinned account test")
+	assert.Nil(t, replicaMock.ExpectationsWereMet(), "the account stayed pinned past the lag allowed")
+}
+
+func TestSlowReplicaCheck(t *testing.T) {
+	ctx := context.Background()
+	db, primary, replicaMock := replicaTestDB(t, 5*time.Second)
+	r := db.replicas.replicas[0]
+
+	replicaMock.ExpectQuery("pg_last_wal_receive_lsn").WillDelayFor(200 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	checked := make(chan bool)
+	go func() { checked <- r.isUsable(ctx, db.replicas.maxLag) }()
+	time.Sleep(20 * time.Millisecond)
+
+	// The reads made during the check go to the primary instead of waiting.
+	primary.ExpectQuery("SELECT").WithArgs(expEmail, newArrivalWindow.Seconds()).WillReturnRows(noBooks())
+	start := time.Now()
+	_, err := db.GetNewBooks(ctx, newArrivalWindow, expEmail)
+	assert.Nil(t, err, "unexpected error in a slow replica test")
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "a read waited for the replica's lag check")
+
+	assert.True(t, <-checked, "the replica should've been usable once checked")
+	assert.Nil(t, primary.ExpectationsWereMet())
+	assert.Nil(t, replicaMock.ExpectationsWereMet())
+}
+
+func TestCatalogWritesPin(t *testing.T) {
+	ctx := context.Background()
+	db, primary, _ := replicaTestDB(t, 5*time.Second)
+
+	kind := primary.ExpectPrepare("SELECT")
+	remove := primary.ExpectPrepare("DELETE")
+	kind.ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"kind"}).AddRow(ShelfCustom))
+	remove.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	require.Nil(t, getOwnedShelfKindStmt.Prepare(ctx, db.DB))
+	require.Nil(t, deleteShelfStmt.Prepare(ctx, db.DB))
+
+	require.Nil(t, db.DeleteShelf(ctx, uuid.New(), expEmail))
+	assert.True(t, db.replicas.isPinned(expEmail), "deleting a shelf didn't pin the account to the primary")
+	assert.Nil(t, primary.ExpectationsWereMet())
+}
@@ -0,0 +1,443 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReviewApproved = "approved"
+	ReviewPending  = "pending"
+	ReviewRejected = "rejected"
+	ReviewHidden   = "hidden"
+)
+
+const (
+	ReviewSortHelpful = "helpful"
+	ReviewSortRecent  = "recent"
+)
+
+const (
+	ReviewActionApprove = "approve"
+	ReviewActionReject  = "reject"
+	ReviewActionHide    = "hide"
+)
+
+// reviewActions maps a moderator's action to the status it leaves the review
+// in.
+var reviewActions = map[string]string{
+	ReviewActionApprove: ReviewApproved,
+	ReviewActionReject:  ReviewRejected,
+	ReviewActionHide:    ReviewHidden,
+}
+
+// Reports made after the last moderation are open, they put the review back
+// in the queue.
+const reviewColumnsStr = `
+		r.id,
+		r.user_id,
+		u.name,
+		r.book_id,
+		r.rating,
+		COALESCE(r.review, ''),
+		r.review_status,
+		COALESCE(r.flag_reason, ''),
+		rv.helpful,
+		rv.not_helpful,
+		COALESCE(
+			(
+				SELECT
+					json_agg(rr.reason ORDER BY rr.reported_at)
+				FROM
+					review_report rr
+				WHERE
+					rr.review_id = r.id
+					AND rr.reported_at > COALESCE(r.moderated_at, '-infinity')
+			),
+			'[]'
+		),
+		r.rated_at,
+		r.updated_at,
+		r.moderated_at`
+
+const reviewFromStr = `
+		rate_book r
+		JOIN user_account u ON u.email = r.user_id
+		CROSS JOIN LATERAL (
+			SELECT
+				count(*) FILTER (WHERE v.helpful) AS helpful,
+				count(*) FILTER (WHERE NOT v.helpful) AS not_helpful
+			FROM
+				review_vote v
+			WHERE
+				v.review_id = r.id
+		) AS rv`
+
+// saveReviewStmt rates a book and sets the text of the rating. A review a
+// moderator hid stays hidden when it's edited.
+var saveReviewStmt = dbStatement{
+	nil, `
+	INSERT INTO rate_book (
+		user_id, book_id, rating, review, review_status, flag_reason
+	)
+	VALUES
+		($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''))
+	ON CONFLICT (user_id, book_id) DO UPDATE
+	SET
+		rating = EXCLUDED.rating,
+		review = EXCLUDED.review,
+		review_status = CASE
+			WHEN rate_book.review_status = 'hidden' THEN 'hidden'
+			ELSE EXCLUDED.review_status
+		END,
+		flag_reason = EXCLUDED.flag_reason,
+		rated_at = now(),
+		updated_at = now()
+	RETURNING
+		id;`,
+}
+
+var getReviewStmt = dbStatement{
+	nil, `
+	SELECT` + reviewColumnsStr + `
+	FROM` + reviewFromStr + `
+	WHERE
+		r.id = $1;`,
+}
+
+// listBookReviewsStmt pages through the approved reviews of a book that have
+// text. The most helpful ones are those with the most helpful votes left
+// after taking away the unhelpful ones.
+var listBookReviewsStmt = dbStatement{
+	nil, `
+	SELECT` + reviewColumnsStr + `
+	FROM` + reviewFromStr + `
+	WHERE
+		r.book_id = $1
+		AND r.review_status = 'approved'
+		AND r.review IS NOT NULL
+	ORDER BY
+		CASE WHEN $2 = 'helpful' THEN rv.helpful - rv.not_helpful END DESC,
+		r.updated_at DESC,
+		r.id ASC
+	LIMIT
+		$3 OFFSET $4;`,
+}
+
+// getModerationQueueStmt lists the reviews the filter flagged and the ones
+// with open reports, the oldest first.
+var getModerationQueueStmt = dbStatement{
+	nil, `
+	SELECT` + reviewColumnsStr + `
+	FROM` + reviewFromStr + `
+	WHERE
+		r.review_status = 'pending'
+		OR EXISTS (
+			SELECT
+				1
+			FROM
+				review_report rr
+			WHERE
+				rr.review_id = r.id
+				AND rr.reported_at > COALESCE(r.moderated_at, '-infinity')
+		)
+	ORDER BY
+		r.updated_at ASC,
+		r.id ASC
+	LIMIT
+		$1 OFFSET $2;`,
+}
+
+var voteReviewStmt = dbStatement{
+	nil, `
+	INSERT INTO review_vote (
+		review_id, account_id, helpful
+	)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT (review_id, account_id) DO UPDATE
+	SET
+		helpful = EXCLUDED.helpful,
+		voted_at = now();`,
+}
+
+var reportReviewStmt = dbStatement{
+	nil, `
+	INSERT INTO review_report (
+		review_id, account_id, reason
+	)
+	VALUES
+		($1, $2, $3)
+	ON CONFLICT (review_id, account_id) DO UPDATE
+	SET
+		reason = EXCLUDED.reason,
+		reported_at = now();`,
+}
+
+var moderateReviewStmt = dbStatement{
+	nil, `
+	UPDATE
+		rate_book
+	SET
+		review_status = $2,
+		moderated_at = now(),
+		moderated_by = $3
+	WHERE
+		id = $1;`,
+}
+
+type ReviewInterface interface {
+	SaveReview(ctx context.Context, review Review) (*Review, error)
+	ListBookReviews(ctx context.Context, bookID uuid.UUID, sort string, limit int, offset int) ([]Review, error)
+	VoteReview(ctx context.Context, reviewID uuid.UUID, accountID string, helpful bool) error
+	ReportReview(ctx context.Context, reviewID uuid.UUID, accountID string, reason string) error
+	GetModerationQueue(ctx context.Context, limit int, offset int) ([]Review, error)
+	ModerateReview(ctx context.Context, reviewID uuid.UUID, action string, moderatorID string) (*Review, error)
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"saveReview":         &saveReviewStmt,
+		"getReview":          &getReviewStmt,
+		"listBookReviews":    &listBookReviewsStmt,
+		"getModerationQueue": &getModerationQueueStmt,
+		"voteReview":         &voteReviewStmt,
+		"reportReview":       &reportReviewStmt,
+		"moderateReview":     &moderateReviewStmt,
+	})
+}
+
+// Review is an account's rating of a book with its text, if any. FlagReason
+// is why the content filter held it for moderation.
+type Review struct {
+	ID            uuid.UUID
+	AccountID     string
+	AuthorName    string
+	BookID        uuid.UUID
+	Rating        int
+	Body          string
+	Status        string
+	FlagReason    string
+	Helpful       int
+	NotHelpful    int
+	ReportReasons ReviewReportReasons
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ModeratedAt   *time.Time
+}
+
+// ReviewReportReasons are the reasons of a review's open reports.
+type ReviewReportReasons []string
+
+func (r *ReviewReportReasons) Scan(value any) error {
+	return scanJSONList(value, r)
+}
+
+var ErrReviewNotFound = errors.New("review not found")
+var ErrReviewRatingInvalid = errors.New("rating must be between 1 and 5")
+var ErrReviewBodyInvalid = errors.New("reviews can't be longer than 5000 characters")
+var ErrReviewReasonInvalid = errors.New("report reason must be between 1 and 500 characters")
+var ErrReviewOwn = errors.New("reviews can't be voted on or reported by their author")
+var ErrReviewSortUnknown = errors.New("sort must be either helpful or recent")
+var ErrReviewActionUnknown = errors.New("action must be one of approve, reject or hide")
+
+const maxReviewBody = 5000
+const maxReviewReportReason = 500
+
+// Validate trims the review's body in place and checks it with the rating.
+func (r *Review) Validate() error {
+	if r.Rating < 1 || r.Rating > 5 {
+		return ErrReviewRatingInvalid
+	}
+	r.Body = strings.TrimSpace(r.Body)
+	if len([]rune(r.Body)) > maxReviewBody {
+		return ErrReviewBodyInvalid
+	}
+	return nil
+}
+
+func IsReviewSort(sort string) bool {
+	return sort == ReviewSortHelpful || sort == ReviewSortRecent
+}
+
+func reviewColumns(review *Review) []any {
+	return []any{
+		&review.ID,
+		&review.AccountID,
+		&review.AuthorName,
+		&review.BookID,
+		&review.Rating,
+		&review.Body,
+		&review.Status,
+		&review.FlagReason,
+		&review.Helpful,
+		&review.NotHelpful,
+		&review.ReportReasons,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&review.ModeratedAt,
+	}
+}
+
+func scanReviews(rows *sql.Rows) ([]Review, error) {
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		review := Review{}
+		if err := rows.Scan(reviewColumns(&review)...); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// SaveReview rates a book for the account, replacing its earlier rating and
+// review. Status is where the review goes: approved, or pending when the
+// content filter flagged it.
+func (db DBInstance) SaveReview(ctx context.Context, review Review) (*Review, error) {
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+
+	var reviewID uuid.UUID
+	if err := saveReviewStmt.QueryRowContext(ctx,
+		review.AccountID,
+		review.BookID,
+		review.Rating,
+		review.Body,
+		review.Status,
+		review.FlagReason,
+	).Scan(&reviewID); err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	db.replicas.pin(review.AccountID)
+	return db.getReview(ctx, reviewID)
+}
+
+// ListBookReviews returns a page of the book's published reviews. A book
+// without any gets an empty list, only a missing book is an error.
+func (db DBInstance) ListBookReviews(ctx context.Context, bookID uuid.UUID, sort string, limit int, offset int) ([]Review, error) {
+	if limit <= 0 || offset < 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+	if !IsReviewSort(sort) {
+		return nil, ErrReviewSortUnknown
+	}
+
+	rows, err := listBookReviewsStmt.QueryContext(ctx, bookID, sort, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := scanReviews(rows)
+	if err != nil || len(reviews) > 0 || offset > 0 {
+		return reviews, err
+	}
+
+	var exists bool
+	if err := bookExistsStmt.QueryRowContext(ctx, bookID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrBookNotFound
+	}
+	return reviews, nil
+}
+
+// VoteReview records whether the account found a published review helpful,
+// replacing its earlier vote.
+func (db DBInstance) VoteReview(ctx context.Context, reviewID uuid.UUID, accountID string, helpful bool) error {
+	if err := db.checkOthersPublishedReview(ctx, reviewID, accountID); err != nil {
+		return err
+	}
+	_, err := voteReviewStmt.ExecContext(ctx, reviewID, accountID, helpful)
+	return err
+}
+
+// ReportReview puts a published review in the moderation queue. Reporting it
+// again only updates the reason.
+func (db DBInstance) ReportReview(ctx context.Context, reviewID uuid.UUID, accountID string, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > maxReviewReportReason {
+		return ErrReviewReasonInvalid
+	}
+	if err := db.checkOthersPublishedReview(ctx, reviewID, accountID); err != nil {
+		return err
+	}
+	_, err := reportReviewStmt.ExecContext(ctx, reviewID, accountID, reason)
+	return err
+}
+
+func (db DBInstance) GetModerationQueue(ctx context.Context, limit int, offset int) ([]Review, error) {
+	if limit <= 0 || offset < 0 {
+		return nil, errors.New("function parameters outside the bounds")
+	}
+	rows, err := getModerationQueueStmt.QueryContext(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return scanReviews(rows)
+}
+
+// ModerateReview approves, rejects or hides a review, which closes its open
+// reports. Rejected reviews keep counting toward the book's rating, hidden
+// ones don't.
+func (db DBInstance) ModerateReview(ctx context.Context, reviewID uuid.UUID, action string, moderatorID string) (*Review, error) {
+	status, ok := reviewActions[action]
+	if !ok {
+		return nil, ErrReviewActionUnknown
+	}
+
+	result, err := moderateReviewStmt.ExecContext(ctx, reviewID, status, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrReviewNotFound
+	}
+	return db.getReview(ctx, reviewID)
+}
+
+func (db DBInstance) getReview(ctx context.Context, reviewID uuid.UUID) (*Review, error) {
+	review := Review{}
+	if err := getReviewStmt.QueryRowContext(ctx, reviewID).
+		Scan(reviewColumns(&review)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// checkOthersPublishedReview makes sure the review is one readers can see,
+// and that it isn't the account's own.
+func (db DBInstance) checkOthersPublishedReview(ctx context.Context, reviewID uuid.UUID, accountID string) error {
+	review, err := db.getReview(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if review.Status != ReviewApproved || review.Body == "" {
+		return ErrReviewNotFound
+	}
+	if review.AccountID == accountID {
+		return ErrReviewOwn
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var reviewColumnNames = []string{
+	"id", "user_id", "name", "book_id", "rating", "review", "review_status", "flag_reason",
+	"helpful", "not_helpful", "reports", "rated_at", "updated_at", "moderated_at",
+}
+
+func TestValidateReview(t *testing.T) {
+	tests := []struct {
+		review Review
+		expErr error
+	}{
+		{Review{Rating: 5, Body: " Loved it "}, nil},
+		{Review{Rating: 1}, nil},
+		{Review{Rating: 0}, ErrReviewRatingInvalid},
+		{Review{Rating: 6}, ErrReviewRatingInvalid},
+		{Review{Rating: 3, Body: strings.Repeat("a", 5001)}, ErrReviewBodyInvalid},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expErr, test.review.Validate(), "unexpected error validating %+v", test.review)
+	}
+}
+
+func TestOwnVoteReview(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	reviewID := uuid.New()
+	ratedAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(reviewID).
+		WillReturnRows(sqlmock.NewRows(reviewColumnNames).AddRow(
+			reviewID, expEmail, "Reza", uuid.New(), 4, "Loved it", ReviewApproved, "",
+			0, 0, "[]", ratedAt, ratedAt, nil,
+		))
+
+	err = getReviewStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.VoteReview(ctx, reviewID, expEmail, true)
+	assert.Equal(t, ErrReviewOwn, err, "function should've refused a vote on the account's own review")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestHiddenReportReview(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	reviewID := uuid.New()
+	ratedAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(reviewID).
+		WillReturnRows(sqlmock.NewRows(reviewColumnNames).AddRow(
+			reviewID, "someone@else.com", "Reza", uuid.New(), 1, "Awful", ReviewHidden, "",
+			0, 2, `["Spam"]`, ratedAt, ratedAt, ratedAt,
+		))
+
+	err = getReviewStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.ReportReview(ctx, reviewID, expEmail, "Spam")
+	assert.Equal(t, ErrReviewNotFound, err, "function should've hidden a hidden review from reports")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestSuccessfulModerateReview(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	reviewID := uuid.New()
+	bookID := uuid.New()
+	ratedAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+	moderatedAt := ratedAt.Add(time.Hour)
+
+	moderate := mock.ExpectPrepare("UPDATE")
+	get := mock.ExpectPrepare("SELECT")
+	moderate.ExpectExec().
+		WithArgs(reviewID, ReviewHidden, expEmail).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	get.ExpectQuery().
+		WithArgs(reviewID).
+		WillReturnRows(sqlmock.NewRows(reviewColumnNames).AddRow(
+			reviewID, "someone@else.com", "Reza", bookID, 1, "Awful", ReviewHidden, "",
+			0, 2, "[]", ratedAt, ratedAt, moderatedAt,
+		))
+
+	for _, stmt := range []*dbStatement{&moderateReviewStmt, &getReviewStmt} {
+		err = stmt.Prepare(ctx, d)
+		require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	}
+
+	review, err := db.ModerateReview(ctx, reviewID, ReviewActionHide, expEmail)
+	if assert.Nil(t, err, "unexpected error in a successful moderation test") {
+		assert.Equal(t, Review{
+			ID:          reviewID,
+			AccountID:   "someone@else.com",
+			AuthorName:  "Reza",
+			BookID:      bookID,
+			Rating:      1,
+			Body:        "Awful",
+			Status:      ReviewHidden,
+			NotHelpful:  2,
+			CreatedAt:   ratedAt,
+			UpdatedAt:   ratedAt,
+			ModeratedAt: &moderatedAt,
+		}, *review, "function should've returned the hidden review")
+	}
+
+	_, err = db.ModerateReview(ctx, reviewID, "delete", expEmail)
+	assert.Equal(t, ErrReviewActionUnknown, err, "function should've refused an unknown action")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,594 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	ShelfFavorites  = "favorites"
+	ShelfWantToRead = "want_to_read"
+	ShelfReading    = "reading"
+	ShelfFinished   = "finished"
+	ShelfCustom     = "custom"
+)
+
+const (
+	ShelfPrivate = "private"
+	ShelfPublic  = "public"
+)
+
+// ensureBuiltInShelvesStmt gives the account in $1 the built-in shelves it
+// doesn't have yet, as it registers. The schema file does the same for every
+// account on each boot.
+var ensureBuiltInShelvesStmt = dbStatement{
+	nil, `
+	INSERT INTO shelf (
+		id, account_id, kind, name, position
+	)
+	SELECT
+		gen_random_uuid(),
+		$1,
+		built_in.kind,
+		built_in.name,
+		built_in.position
+	FROM
+		(
+			VALUES
+				('favorites', 'Favorites', 0),
+				('want_to_read', 'Want to read', 1),
+				('reading', 'Currently reading', 2),
+				('finished', 'Finished', 3)
+		) AS built_in (kind, name, position)
+	ON CONFLICT (account_id, kind) WHERE kind <> 'custom' DO NOTHING;`,
+}
+
+// The favorites shelf keeps its books in fav_book, so is_favorite and the
+// rankings built on it don't have to know about shelves.
+const shelfColumnsStr = `
+		s.id,
+		s.account_id,
+		u.name,
+		s.kind,
+		s.name,
+		s.description,
+		s.visibility,
+		s.position,
+		CASE
+			WHEN s.kind = 'favorites' THEN (
+				SELECT
+					count(*)
+				FROM
+					fav_book f
+				WHERE
+					f.user_id = s.account_id
+			)
+			ELSE (
+				SELECT
+					count(*)
+				FROM
+					shelf_book sb
+				WHERE
+					sb.shelf_id = s.id
+			)
+		END,
+		s.created_at`
+
+// bookShelvesStr aggregates the shelves of the account in $1 a book is on, as
+// a JSON array scanned into BookShelves.
+const bookShelvesStr = `
+		COALESCE(
+			(
+				SELECT
+					json_agg(
+						json_build_object('id', s.id, 'name', s.name, 'kind', s.kind)
+						ORDER BY s.position, s.created_at
+					)
+				FROM
+					shelf s
+				WHERE
+					s.account_id = $1
+					AND (
+						EXISTS (
+							SELECT
+								1
+							FROM
+								shelf_book sb
+							WHERE
+								sb.shelf_id = s.id
+								AND sb.book_id = b.id
+						)
+						OR (
+							s.kind = 'favorites'
+							AND EXISTS (
+								SELECT
+									1
+								FROM
+									fav_book f
+								WHERE
+									f.user_id = $1
+									AND f.book_id = b.id
+							)
+						)
+					)
+			),
+			'[]'
+		)`
+
+var getShelvesStmt = dbStatement{
+	nil, `
+	SELECT` + shelfColumnsStr + `
+	FROM
+		shelf s
+		JOIN user_account u ON u.email = s.account_id
+	WHERE
+		s.account_id = $1
+	ORDER BY
+		s.position ASC,
+		s.created_at ASC;`,
+}
+
+// getShelfStmt finds a shelf the account in $1 owns, or any public one.
+var getShelfStmt = dbStatement{
+	nil, `
+	SELECT` + shelfColumnsStr + `
+	FROM
+		shelf s
+		JOIN user_account u ON u.email = s.account_id
+	WHERE
+		s.id = $2
+		AND (
+			s.account_id = $1
+			OR s.visibility = 'public'
+		);`,
+}
+
+var getShelfBooksStmt = dbStatement{
+	nil, fmt.Sprintf(bookSelectStr, `
+		JOIN (
+			SELECT
+				sb.book_id,
+				sb.position,
+				sb.added_at
+			FROM
+				shelf_book sb
+			WHERE
+				sb.shelf_id = $2
+			UNION ALL
+			SELECT
+				f.book_id,
+				f.position,
+				f.created_at
+			FROM
+				fav_book f
+				JOIN shelf s ON s.account_id = f.user_id
+			WHERE
+				s.id = $2
+				AND s.kind = 'favorites'
+		) AS sb ON sb.book_id = b.id
+	ORDER BY
+		sb.position ASC,
+		sb.added_at DESC`),
+}
+
+var createShelfStmt = dbStatement{
+	nil, `
+	INSERT INTO shelf (
+		id, account_id, name, description, visibility, position
+	)
+	SELECT
+		$1, $2, $3, $4, $5, COALESCE(max(position) + 1, 0)
+	FROM
+		shelf
+	WHERE
+		account_id = $2;`,
+}
+
+var updateShelfStmt = dbStatement{
+	nil, `
+	UPDATE
+		shelf
+	SET
+		name = $3,
+		description = $4,
+		visibility = $5,
+		position = $6
+	WHERE
+		account_id = $1
+		AND id = $2;`,
+}
+
+var deleteShelfStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		shelf
+	WHERE
+		account_id = $1
+		AND id = $2;`,
+}
+
+var getOwnedShelfKindStmt = dbStatement{
+	nil, `
+	SELECT
+		kind
+	FROM
+		shelf
+	WHERE
+		account_id = $1
+		AND id = $2;`,
+}
+
+// addShelfBookStmt puts the book at the end of the shelf. A book already on
+// it stays where it is.
+var addShelfBookStmt = dbStatement{
+	nil, `
+	INSERT INTO shelf_book (
+		shelf_id, book_id, position
+	)
+	SELECT
+		$1, $2, COALESCE(max(position) + 1, 0)
+	FROM
+		shelf_book
+	WHERE
+		shelf_id = $1
+	ON CONFLICT DO NOTHING;`,
+}
+
+var addFavoriteBookStmt = dbStatement{
+	nil, `
+	INSERT INTO fav_book (
+		user_id, book_id, position
+	)
+	SELECT
+		$1, $2, COALESCE(max(position) + 1, 0)
+	FROM
+		fav_book
+	WHERE
+		user_id = $1
+	ON CONFLICT DO NOTHING;`,
+}
+
+var removeShelfBookStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		shelf_book
+	WHERE
+		shelf_id = $1
+		AND book_id = $2;`,
+}
+
+var removeFavoriteBookStmt = dbStatement{
+	nil, `
+	DELETE FROM
+		fav_book
+	WHERE
+		user_id = $1
+		AND book_id = $2;`,
+}
+
+// reorderShelfBooksStmt numbers the books of $2 in the order given. Books of
+// the shelf left out of it keep their position.
+var reorderShelfBooksStmt = dbStatement{
+	nil, `
+	UPDATE
+		shelf_book sb
+	SET
+		position = o.position
+	FROM
+		unnest($2::uuid[]) WITH ORDINALITY AS o (book_id, position)
+	WHERE
+		sb.shelf_id = $1
+		AND sb.book_id = o.book_id;`,
+}
+
+var reorderFavoriteBooksStmt = dbStatement{
+	nil, `
+	UPDATE
+		fav_book f
+	SET
+		position = o.position
+	FROM
+		unnest($2::uuid[]) WITH ORDINALITY AS o (book_id, position)
+	WHERE
+		f.user_id = $1
+		AND f.book_id = o.book_id;`,
+}
+
+type ShelfInterface interface {
+	GetShelves(ctx context.Context, accountID string) ([]Shelf, error)
+	GetShelf(ctx context.Context, shelfID uuid.UUID, accountID string) (*Shelf, error)
+	GetShelfBooks(ctx context.Context, shelfID uuid.UUID, accountID string) ([]Book, error)
+	CreateShelf(ctx context.Context, shelf Shelf) (*Shelf, error)
+	UpdateShelf(ctx context.Context, shelf Shelf) (*Shelf, error)
+	DeleteShelf(ctx context.Context, shelfID uuid.UUID, accountID string) error
+	AddShelfBook(ctx context.Context, shelfID uuid.UUID, bookID uuid.UUID, accountID string) error
+	RemoveShelfBook(ctx context.Context, shelfID uuid.UUID, bookID uuid.UUID, accountID string) error
+	ReorderShelfBooks(ctx context.Context, shelfID uuid.UUID, bookIDs []uuid.UUID, accountID string) error
+}
+
+func init() {
+	registerStatements(map[string]*dbStatement{
+		"ensureBuiltInShelves": &ensureBuiltInShelvesStmt,
+		"getShelves":           &getShelvesStmt,
+		"getShelf":             &getShelfStmt,
+		"getShelfBooks":        &getShelfBooksStmt,
+		"createShelf":          &createShelfStmt,
+		"updateShelf":          &updateShelfStmt,
+		"deleteShelf":          &deleteShelfStmt,
+		"getOwnedShelfKind":    &getOwnedShelfKindStmt,
+		"addShelfBook":         &addShelfBookStmt,
+		"addFavoriteBook":      &addFavoriteBookStmt,
+		"removeShelfBook":      &removeShelfBookStmt,
+		"removeFavoriteBook":   &removeFavoriteBookStmt,
+		"reorderShelfBooks":    &reorderShelfBooksStmt,
+		"reorderFavoriteBooks": &reorderFavoriteBooksStmt,
+	})
+}
+
+// Shelf is a named, ordered list of books. Every account has the built-in
+// kinds, which can't be renamed or deleted, and as many custom ones as it
+// likes.
+type Shelf struct {
+	ID          uuid.UUID
+	AccountID   string
+	OwnerName   string
+	Kind        string
+	Name        string
+	Description string
+	Visibility  string
+	Position    int
+	BookCount   int
+	CreatedAt   time.Time
+}
+
+// BookShelf is a shelf a book is on, as listed on the book.
+type BookShelf struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Kind string    `json:"kind"`
+}
+
+type BookShelves []BookShelf
+
+func (b *BookShelves) Scan(value any) error {
+	return scanJSONList(value, b)
+}
+
+var ErrShelfNotFound = errors.New("shelf not found")
+var ErrShelfNameInvalid = errors.New("shelf name must be between 1 and 100 characters")
+var ErrShelfDescriptionInvalid = errors.New("shelf description can't be longer than 2000 characters")
+var ErrShelfVisibilityInvalid = errors.New("visibility must be either private or public")
+var ErrShelfBuiltIn = errors.New("built-in shelves can't be renamed or deleted")
+
+const maxShelfName = 100
+const maxShelfDescription = 2000
+
+// Validate trims the shelf's fields in place and checks them. Visibility
+// defaults to private.
+func (s *Shelf) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" || len([]rune(s.Name)) > maxShelfName {
+		return ErrShelfNameInvalid
+	}
+	s.Description = strings.TrimSpace(s.Description)
+	if len([]rune(s.Description)) > maxShelfDescription {
+		return ErrShelfDescriptionInvalid
+	}
+	switch s.Visibility {
+	case "":
+		s.Visibility = ShelfPrivate
+	case ShelfPrivate, ShelfPublic:
+	default:
+		return ErrShelfVisibilityInvalid
+	}
+	return nil
+}
+
+func shelfColumns(shelf *Shelf) []any {
+	return []any{
+		&shelf.ID,
+		&shelf.AccountID,
+		&shelf.OwnerName,
+		&shelf.Kind,
+		&shelf.Name,
+		&shelf.Description,
+		&shelf.Visibility,
+		&shelf.Position,
+		&shelf.BookCount,
+		&shelf.CreatedAt,
+	}
+}
+
+// GetShelves lists the account's shelves in their order. The built-in ones
+// are made when the account registers, or by the schema file for the older
+// accounts, so listing them never writes.
+func (db DBInstance) GetShelves(ctx context.Context, accountID string) ([]Shelf, error) {
+	rows, err := getShelvesStmt.QueryContext(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shelves []Shelf
+	for rows.Next() {
+		shelf := Shelf{}
+		if err := rows.Scan(shelfColumns(&shelf)...); err != nil {
+			return nil, err
+		}
+		shelves = append(shelves, shelf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return shelves, nil
+}
+
+// GetShelf returns a shelf the account owns, or a public one of anyone. An
+// empty accountID only finds public shelves.
+func (db DBInstance) GetShelf(ctx context.Context, shelfID uuid.UUID, accountID string) (*Shelf, error) {
+	shelf := Shelf{}
+	if err := getShelfStmt.QueryRowContext(ctx, accountID, shelfID).
+		Scan(shelfColumns(&shelf)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrShelfNotFound
+		}
+		return nil, err
+	}
+	return &shelf, nil
+}
+
+// GetShelfBooks lists the books of a shelf in the owner's order. It doesn't
+// check who may see the shelf, GetShelf does.
+func (db DBInstance) GetShelfBooks(ctx context.Context, shelfID uuid.UUID, accountID string) ([]Book, error) {
+	rows, err := getShelfBooksStmt.QueryContext(ctx, accountID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBooks(rows)
+}
+
+// CreateShelf makes a custom shelf at the end of the account's shelves.
+func (db DBInstance) CreateShelf(ctx context.Context, shelf Shelf) (*Shelf, error) {
+	if err := shelf.Validate(); err != nil {
+		return nil, err
+	}
+
+	randomUUID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	shelf.ID = randomUUID
+
+	if _, err := createShelfStmt.ExecContext(ctx,
+		shelf.ID,
+		shelf.AccountID,
+		shelf.Name,
+		shelf.Description,
+		shelf.Visibility,
+	); err != nil {
+		return nil, err
+	}
+	db.replicas.pin(shelf.AccountID)
+	return db.GetShelf(ctx, shelf.ID, shelf.AccountID)
+}
+
+// UpdateShelf sets the name, description, visibility and position of one of
+// the account's shelves. Built-in shelves keep their name.
+func (db DBInstance) UpdateShelf(ctx context.Context, shelf Shelf) (*Shelf, error) {
+	if err := shelf.Validate(); err != nil {
+		return nil, err
+	}
+
+	stored, err := db.GetShelf(ctx, shelf.ID, shelf.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.AccountID != shelf.AccountID {
+		return nil, ErrShelfNotFound
+	}
+	if stored.Kind != ShelfCustom && stored.Name != shelf.Name {
+		return nil, ErrShelfBuiltIn
+	}
+
+	if _, err := updateShelfStmt.ExecContext(ctx,
+		shelf.AccountID,
+		shelf.ID,
+		shelf.Name,
+		shelf.Description,
+		shelf.Visibility,
+		shelf.Position,
+	); err != nil {
+		return nil, err
+	}
+	db.replicas.pin(shelf.AccountID)
+	return db.GetShelf(ctx, shelf.ID, shelf.AccountID)
+}
+
+func (db DBInstance) DeleteShelf(ctx context.Context, shelfID uuid.UUID, accountID string) error {
+	kind, err := db.getOwnedShelfKind(ctx, shelfID, accountID)
+	if err != nil {
+		return err
+	}
+	if kind != ShelfCustom {
+		return ErrShelfBuiltIn
+	}
+
+	_, err = deleteShelfStmt.ExecContext(ctx, accountID, shelfID)
+	db.replicas.pin(accountID)
+	return err
+}
+
+func (db DBInstance) AddShelfBook(ctx context.Context, shelfID uuid.UUID, bookID uuid.UUID, accountID string) error {
+	kind, err := db.getOwnedShelfKind(ctx, shelfID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if kind == ShelfFavorites {
+		_, err = addFavoriteBookStmt.ExecContext(ctx, accountID, bookID)
+	} else {
+		_, err = addShelfBookStmt.ExecContext(ctx, shelfID, bookID)
+	}
+	db.replicas.pin(accountID)
+	if isForeignKeyViolation(err) {
+		return ErrBookNotFound
+	}
+	return err
+}
+
+// RemoveShelfBook takes a book off one of the account's shelves. Removing a
+// book that isn't on it isn't an error.
+func (db DBInstance) RemoveShelfBook(ctx context.Context, shelfID uuid.UUID, bookID uuid.UUID, accountID string) error {
+	kind, err := db.getOwnedShelfKind(ctx, shelfID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if kind == ShelfFavorites {
+		_, err = removeFavoriteBookStmt.ExecContext(ctx, accountID, bookID)
+	} else {
+		_, err = removeShelfBookStmt.ExecContext(ctx, shelfID, bookID)
+	}
+	db.replicas.pin(accountID)
+	return err
+}
+
+// ReorderShelfBooks puts the books of one of the account's shelves in the
+// order of bookIDs. Ids of books not on the shelf are ignored.
+func (db DBInstance) ReorderShelfBooks(ctx context.Context, shelfID uuid.UUID, bookIDs []uuid.UUID, accountID string) error {
+	kind, err := db.getOwnedShelfKind(ctx, shelfID, accountID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(bookIDs))
+	for _, id := range bookIDs {
+		ids = append(ids, id.String())
+	}
+
+	if kind == ShelfFavorites {
+		_, err = reorderFavoriteBooksStmt.ExecContext(ctx, accountID, pq.Array(ids))
+	} else {
+		_, err = reorderShelfBooksStmt.ExecContext(ctx, shelfID, pq.Array(ids))
+	}
+	db.replicas.pin(accountID)
+	return err
+}
+
+func (db DBInstance) getOwnedShelfKind(ctx context.Context, shelfID uuid.UUID, accountID string) (string, error) {
+	var kind string
+	if err := getOwnedShelfKindStmt.QueryRowContext(ctx, accountID, shelfID).Scan(&kind); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrShelfNotFound
+		}
+		return "", err
+	}
+	return kind, nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateShelf(t *testing.T) {
+	tests := []struct {
+		shelf  Shelf
+		expErr error
+	}{
+		{Shelf{Name: " Summer reads "}, nil},
+		{Shelf{Name: "Classics", Visibility: ShelfPublic}, nil},
+		{Shelf{Name: " "}, ErrShelfNameInvalid},
+		{Shelf{Name: strings.Repeat("a", 101)}, ErrShelfNameInvalid},
+		{Shelf{Name: "Classics", Description: strings.Repeat("a", 2001)}, ErrShelfDescriptionInvalid},
+		{Shelf{Name: "Classics", Visibility: "shared"}, ErrShelfVisibilityInvalid},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expErr, test.shelf.Validate(), "unexpected error validating %+v", test.shelf)
+	}
+
+	shelf := Shelf{Name: " Summer reads "}
+	if assert.Nil(t, shelf.Validate()) {
+		assert.Equal(t, "Summer reads", shelf.Name, "validation should've trimmed the name")
+		assert.Equal(t, ShelfPrivate, shelf.Visibility, "shelves should've defaulted to private")
+	}
+}
+
+func TestFavoritesAddShelfBook(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	shelfID := uuid.New()
+	bookID := uuid.New()
+
+	kind := mock.ExpectPrepare("SELECT")
+	add := mock.ExpectPrepare("INSERT INTO fav_book")
+	kind.ExpectQuery().
+		WithArgs(expEmail, shelfID).
+		WillReturnRows(sqlmock.NewRows([]string{"kind"}).AddRow(ShelfFavorites))
+	add.ExpectExec().
+		WithArgs(expEmail, bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = getOwnedShelfKindStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+	err = addFavoriteBookStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.AddShelfBook(ctx, shelfID, bookID, expEmail)
+	assert.Nil(t, err, "unexpected error in a successful favorites shelf test")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestBuiltInDeleteShelf(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+	shelfID := uuid.New()
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(expEmail, shelfID).
+		WillReturnRows(sqlmock.NewRows([]string{"kind"}).AddRow(ShelfWantToRead))
+
+	err = getOwnedShelfKindStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.DeleteShelf(ctx, shelfID, expEmail)
+	assert.Equal(t, ErrShelfBuiltIn, err, "function should've refused to delete a built-in shelf")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
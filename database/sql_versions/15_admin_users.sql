@@ -0,0 +1,735 @@
+CREATE TABLE IF NOT EXISTS user_account (
+	email varchar(255) NOT NULL,
+	password varchar(60),
+	g_id TEXT,
+	activated BOOLEAN NOT NULL DEFAULT 'false',
+	activation_token uuid,
+	expires_in timestamptz,
+	name varchar(255) NOT NULL,
+	CONSTRAINT user_account_pk PRIMARY KEY (email)
+);
+
+CREATE TABLE IF NOT EXISTS user_session (
+	user_id varchar(255) NOT NULL,
+	refresh_token uuid NOT NULL,
+	token_family uuid NOT NULL,
+	exhausted BOOLEAN NOT NULL DEFAULT 'false',
+	expires_in timestamptz NOT NULL,
+	CONSTRAINT user_session_pk PRIMARY KEY (user_id, refresh_token),
+	CONSTRAINT user_session_fk_user_id FOREIGN KEY (user_id) REFERENCES user_account(email) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS user_session_index_family ON user_session(token_family);
+
+CREATE TABLE IF NOT EXISTS book (
+	id uuid NOT NULL,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	cover_image TEXT NOT NULL,
+	summary TEXT NOT NULL,
+	CONSTRAINT book_pk PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS fav_book (
+	user_id varchar(255) NOT NULL,
+	book_id uuid NOT NULL,
+	CONSTRAINT fav_book_pk PRIMARY KEY (user_id, book_id),
+	CONSTRAINT fav_book_fk_user_id FOREIGN KEY (user_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT fav_book_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS rate_book (
+	user_id varchar(255) NOT NULL,
+	book_id uuid NOT NULL,
+	rating int8 NOT NULL,
+	CONSTRAINT rate_book_pk PRIMARY KEY (user_id, book_id),
+	CONSTRAINT rate_book_fk_user_id FOREIGN KEY (user_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT rate_book_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE VIEW rating_avg AS 
+	SELECT 
+		book.id, 
+		(
+			COALESCE(
+				avg(rate_book.rating), 
+				(0)
+			)
+		) AS rating 
+	FROM 
+		book 
+		LEFT JOIN rate_book ON book.id = rate_book.book_id 
+	GROUP BY 
+		book.id;
+
+ALTER TABLE user_account ADD COLUMN IF NOT EXISTS role varchar(16) NOT NULL DEFAULT 'user';
+
+ALTER TABLE book ADD COLUMN IF NOT EXISTS cover_id uuid;
+
+ALTER TABLE book ADD COLUMN IF NOT EXISTS isbn varchar(13);
+CREATE UNIQUE INDEX IF NOT EXISTS book_index_isbn ON book(isbn);
+
+CREATE TABLE IF NOT EXISTS import_job (
+	id uuid NOT NULL,
+	format varchar(16) NOT NULL,
+	dry_run BOOLEAN NOT NULL DEFAULT 'false',
+	status varchar(16) NOT NULL DEFAULT 'running',
+	progress real NOT NULL DEFAULT '0',
+	processed_rows integer NOT NULL DEFAULT '0',
+	created_rows integer NOT NULL DEFAULT '0',
+	updated_rows integer NOT NULL DEFAULT '0',
+	failed_rows integer NOT NULL DEFAULT '0',
+	message TEXT,
+	created_by varchar(255) NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	finished_at timestamptz,
+	CONSTRAINT import_job_pk PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS import_job_error (
+	job_id uuid NOT NULL,
+	row_number integer NOT NULL,
+	isbn TEXT NOT NULL,
+	message TEXT NOT NULL,
+	CONSTRAINT import_job_error_pk PRIMARY KEY (job_id, row_number),
+	CONSTRAINT import_job_error_fk_job_id FOREIGN KEY (job_id) REFERENCES import_job(id) ON DELETE CASCADE
+);
+
+ALTER TABLE book ADD COLUMN IF NOT EXISTS publisher TEXT;
+ALTER TABLE book ADD COLUMN IF NOT EXISTS publication_date date;
+ALTER TABLE book ADD COLUMN IF NOT EXISTS language varchar(35);
+ALTER TABLE book ADD COLUMN IF NOT EXISTS page_count integer CONSTRAINT book_page_count_check CHECK (page_count >= 0);
+ALTER TABLE book ADD COLUMN IF NOT EXISTS edition TEXT;
+ALTER TABLE book ADD COLUMN IF NOT EXISTS format varchar(16) CONSTRAINT book_format_check CHECK (format IN ('hardcover', 'paperback', 'ebook', 'audiobook'));
+ALTER TABLE book ADD COLUMN IF NOT EXISTS acquired_at timestamptz NOT NULL DEFAULT now();
+
+CREATE TABLE IF NOT EXISTS author (
+	id uuid NOT NULL,
+	name TEXT NOT NULL,
+	CONSTRAINT author_pk PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS author_index_name ON author(lower(name));
+
+CREATE TABLE IF NOT EXISTS book_author (
+	book_id uuid NOT NULL,
+	author_id uuid NOT NULL,
+	role varchar(16) NOT NULL DEFAULT 'author',
+	position integer NOT NULL DEFAULT '0',
+	CONSTRAINT book_author_pk PRIMARY KEY (book_id, author_id, role),
+	CONSTRAINT book_author_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+	CONSTRAINT book_author_fk_author_id FOREIGN KEY (author_id) REFERENCES author(id) ON DELETE CASCADE,
+	CONSTRAINT book_author_role_check CHECK (role IN ('author', 'illustrator', 'translator', 'editor'))
+);
+CREATE INDEX IF NOT EXISTS book_author_index_author ON book_author(author_id);
+
+-- schema_backfill records the one-off data fixes that already ran, so the
+-- schema file can run on every boot without redoing them.
+CREATE TABLE IF NOT EXISTS schema_backfill (
+	name varchar(64) NOT NULL,
+	done_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT schema_backfill_pk PRIMARY KEY (name)
+);
+
+-- Books get one author per name in their author string, as split by
+-- database.SplitAuthorNames, the first time credits are set up. Names
+-- already known are reused. A database with credits and no marker ran the
+-- backfill before the marker existed; credits removed since stay removed.
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM schema_backfill WHERE name = 'authors') THEN
+		RETURN;
+	END IF;
+	IF NOT EXISTS (SELECT 1 FROM book_author) THEN
+		INSERT INTO author (id, name)
+			SELECT DISTINCT ON (lower(split.name))
+				gen_random_uuid(),
+				split.name
+			FROM
+				(
+					SELECT
+						trim(BOTH ', ' FROM s.name) AS name
+					FROM
+						book b
+						CROSS JOIN LATERAL regexp_split_to_table(
+							b.author,
+							CASE
+								WHEN b.author !~ ';' AND b.author ~ '&|\s+and\s+' THEN '\s*(?:,?\s+and\s+|,|;|&)\s*'
+								ELSE '\s*(?:;|&|\s+and\s+)\s*'
+							END
+						) AS s(name)
+				) AS split
+			WHERE
+				split.name <> ''
+				AND NOT EXISTS (SELECT 1 FROM author a WHERE lower(a.name) = lower(split.name));
+
+		INSERT INTO book_author (book_id, author_id, role, position)
+			SELECT
+				split.book_id,
+				(
+					SELECT
+						a.id
+					FROM
+						author a
+					WHERE
+						lower(a.name) = lower(split.name)
+					ORDER BY
+						a.id ASC
+					LIMIT
+						1
+				),
+				'author',
+				row_number() OVER (PARTITION BY split.book_id ORDER BY split.position) - 1
+			FROM
+				(
+					SELECT
+						b.id AS book_id,
+						trim(BOTH ', ' FROM s.name) AS name,
+						s.position
+					FROM
+						book b
+						CROSS JOIN LATERAL regexp_split_to_table(
+							b.author,
+							CASE
+								WHEN b.author !~ ';' AND b.author ~ '&|\s+and\s+' THEN '\s*(?:,?\s+and\s+|,|;|&)\s*'
+								ELSE '\s*(?:;|&|\s+and\s+)\s*'
+							END
+						) WITH ORDINALITY AS s(name, position)
+				) AS split
+			WHERE
+				split.name <> ''
+			ON CONFLICT DO NOTHING;
+	END IF;
+	INSERT INTO schema_backfill (name) VALUES ('authors');
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS genre (
+	id uuid NOT NULL,
+	parent_id uuid,
+	name TEXT NOT NULL,
+	bisac_code varchar(9),
+	dewey_code varchar(16),
+	CONSTRAINT genre_pk PRIMARY KEY (id),
+	CONSTRAINT genre_fk_parent_id FOREIGN KEY (parent_id) REFERENCES genre(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS genre_index_parent ON genre(parent_id);
+CREATE UNIQUE INDEX IF NOT EXISTS genre_index_bisac ON genre(bisac_code);
+CREATE UNIQUE INDEX IF NOT EXISTS genre_index_dewey ON genre(dewey_code);
+
+CREATE TABLE IF NOT EXISTS book_genre (
+	book_id uuid NOT NULL,
+	genre_id uuid NOT NULL,
+	CONSTRAINT book_genre_pk PRIMARY KEY (book_id, genre_id),
+	CONSTRAINT book_genre_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+	CONSTRAINT book_genre_fk_genre_id FOREIGN KEY (genre_id) REFERENCES genre(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS book_genre_index_genre ON book_genre(genre_id);
+
+CREATE TABLE IF NOT EXISTS book_tag (
+	book_id uuid NOT NULL,
+	tag varchar(64) NOT NULL,
+	CONSTRAINT book_tag_pk PRIMARY KEY (book_id, tag),
+	CONSTRAINT book_tag_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS book_tag_index_tag ON book_tag(tag);
+
+-- acquired_at was added as of the upgrade, so before the curated flags go
+-- the books that weren't new are moved out of the new arrivals.
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'book' AND column_name = 'is_new'
+	) THEN
+		UPDATE book SET acquired_at = least(acquired_at, now() - interval '1 year') WHERE NOT is_new;
+	END IF;
+END
+$$;
+ALTER TABLE book DROP COLUMN IF EXISTS is_new;
+ALTER TABLE book DROP COLUMN IF EXISTS is_popular;
+CREATE INDEX IF NOT EXISTS book_index_acquired_at ON book(acquired_at);
+
+ALTER TABLE fav_book ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS rated_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS review_status varchar(16) NOT NULL DEFAULT 'approved';
+
+CREATE TABLE IF NOT EXISTS book_event (
+	book_id uuid NOT NULL,
+	account_id varchar(255) NOT NULL,
+	kind varchar(16) NOT NULL,
+	occurred_at timestamptz NOT NULL DEFAULT now(),
+	occurred_on date NOT NULL DEFAULT current_date,
+	CONSTRAINT book_event_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+	CONSTRAINT book_event_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT book_event_kind_check CHECK (kind IN ('view', 'loan'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS book_event_index_daily ON book_event(book_id, account_id, kind, occurred_on);
+CREATE INDEX IF NOT EXISTS book_event_index_occurred_at ON book_event(occurred_at);
+
+-- Hidden reviews take their rating out of the signals below. Views built
+-- before that are dropped once so they come back with the filter.
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM pg_matviews
+		WHERE matviewname IN ('book_popularity', 'book_neighbor')
+		AND definition NOT LIKE '%review_status%'
+	) THEN
+		DROP MATERIALIZED VIEW IF EXISTS book_popularity;
+		DROP MATERIALIZED VIEW IF EXISTS book_neighbor;
+	END IF;
+END
+$$;
+
+-- Every signal decays by half each half_life days. Loans and favorites weigh
+-- the most, a rating adds up to the weight of a favorite and a view is only a
+-- hint of interest.
+CREATE MATERIALIZED VIEW IF NOT EXISTS book_popularity AS
+	WITH windows (time_window, length, half_life) AS (
+		VALUES
+			('7d', interval '7 days', 2.0),
+			('30d', interval '30 days', 7.0),
+			('all', NULL::interval, 30.0)
+	), signals (book_id, weight, occurred_at) AS (
+		SELECT
+			book_id,
+			CASE kind WHEN 'loan' THEN 3.0 ELSE 0.2 END,
+			occurred_at
+		FROM
+			book_event
+		UNION ALL
+		SELECT
+			book_id,
+			2.0,
+			created_at
+		FROM
+			fav_book
+		UNION ALL
+		SELECT
+			book_id,
+			rating / 5.0 * 2.0,
+			rated_at
+		FROM
+			rate_book
+		WHERE
+			review_status <> 'hidden'
+	)
+	SELECT
+		w.time_window,
+		s.book_id,
+		sum(
+			s.weight * power(0.5, extract(epoch FROM now() - s.occurred_at) / 86400 / w.half_life)
+		)::double precision AS score
+	FROM
+		windows w
+		JOIN signals s ON w.length IS NULL OR s.occurred_at >= now() - w.length
+	GROUP BY
+		w.time_window,
+		s.book_id;
+CREATE UNIQUE INDEX IF NOT EXISTS book_popularity_index_window ON book_popularity(time_window, book_id);
+CREATE INDEX IF NOT EXISTS book_popularity_index_score ON book_popularity(time_window, score DESC);
+
+-- book_neighbor keeps the closest books to every book. Readers who liked both
+-- books make them close (cosine similarity over favorites, good ratings and
+-- loans), and so does sharing authors or genres (Jaccard similarity).
+CREATE MATERIALIZED VIEW IF NOT EXISTS book_neighbor AS
+	WITH interactions (account_id, book_id, weight) AS (
+		SELECT
+			account_id,
+			book_id,
+			max(weight)
+		FROM
+			(
+				SELECT
+					user_id,
+					book_id,
+					1.0
+				FROM
+					fav_book
+				UNION ALL
+				SELECT
+					user_id,
+					book_id,
+					rating / 5.0
+				FROM
+					rate_book
+				WHERE
+					rating >= 4
+					AND review_status <> 'hidden'
+				UNION ALL
+				SELECT
+					account_id,
+					book_id,
+					1.0
+				FROM
+					book_event
+				WHERE
+					kind = 'loan'
+			) AS liked (account_id, book_id, weight)
+		GROUP BY
+			account_id,
+			book_id
+	), norms AS (
+		SELECT
+			book_id,
+			sqrt(sum(weight * weight)) AS norm
+		FROM
+			interactions
+		GROUP BY
+			book_id
+	), collaborative AS (
+		SELECT
+			a.book_id,
+			b.book_id AS neighbor_id,
+			sum(a.weight * b.weight) / (na.norm * nb.norm) AS score
+		FROM
+			interactions a
+			JOIN interactions b ON a.account_id = b.account_id AND a.book_id <> b.book_id
+			JOIN norms na ON na.book_id = a.book_id
+			JOIN norms nb ON nb.book_id = b.book_id
+		GROUP BY
+			a.book_id,
+			b.book_id,
+			na.norm,
+			nb.norm
+	), features (book_id, feature) AS (
+		SELECT
+			book_id,
+			'author:' || author_id
+		FROM
+			book_author
+		WHERE
+			role = 'author'
+		UNION
+		SELECT
+			book_id,
+			'genre:' || genre_id
+		FROM
+			book_genre
+	), feature_counts AS (
+		SELECT
+			book_id,
+			count(*) AS total
+		FROM
+			features
+		GROUP BY
+			book_id
+	), content AS (
+		SELECT
+			a.book_id,
+			b.book_id AS neighbor_id,
+			count(*)::numeric / (ca.total + cb.total - count(*)) AS score
+		FROM
+			features a
+			JOIN features b ON a.feature = b.feature AND a.book_id <> b.book_id
+			JOIN feature_counts ca ON ca.book_id = a.book_id
+			JOIN feature_counts cb ON cb.book_id = b.book_id
+		GROUP BY
+			a.book_id,
+			b.book_id,
+			ca.total,
+			cb.total
+	), blended AS (
+		SELECT
+			book_id,
+			neighbor_id,
+			sum(score) AS score
+		FROM
+			(
+				SELECT book_id, neighbor_id, 0.7 * score FROM collaborative
+				UNION ALL
+				SELECT book_id, neighbor_id, 0.3 * score FROM content
+			) AS similarity (book_id, neighbor_id, score)
+		GROUP BY
+			book_id,
+			neighbor_id
+	), ranked AS (
+		SELECT
+			book_id,
+			neighbor_id,
+			score,
+			row_number() OVER (PARTITION BY book_id ORDER BY score DESC, neighbor_id ASC) AS rank
+		FROM
+			blended
+	)
+	SELECT
+		book_id,
+		neighbor_id,
+		score::double precision AS score,
+		rank::integer AS rank
+	FROM
+		ranked
+	WHERE
+		rank <= 20;
+CREATE UNIQUE INDEX IF NOT EXISTS book_neighbor_index_pair ON book_neighbor(book_id, neighbor_id);
+CREATE INDEX IF NOT EXISTS book_neighbor_index_rank ON book_neighbor(book_id, rank);
+
+CREATE TABLE IF NOT EXISTS reading_progress (
+	account_id varchar(255) NOT NULL,
+	book_id uuid NOT NULL,
+	cfi TEXT,
+	page integer,
+	percentage real NOT NULL,
+	device_id varchar(255) NOT NULL,
+	updated_at timestamptz NOT NULL,
+	received_at timestamptz NOT NULL DEFAULT now(),
+	started_at timestamptz NOT NULL DEFAULT now(),
+	finished_at timestamptz,
+	CONSTRAINT reading_progress_pk PRIMARY KEY (account_id, book_id),
+	CONSTRAINT reading_progress_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT reading_progress_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+	CONSTRAINT reading_progress_percentage_check CHECK (percentage BETWEEN 0 AND 100),
+	CONSTRAINT reading_progress_page_check CHECK (page > 0),
+	CONSTRAINT reading_progress_locator_check CHECK ((cfi IS NULL) <> (page IS NULL))
+);
+CREATE INDEX IF NOT EXISTS reading_progress_index_book ON reading_progress(book_id);
+CREATE INDEX IF NOT EXISTS reading_progress_index_updated_at ON reading_progress(account_id, updated_at DESC);
+
+ALTER TABLE book DROP COLUMN IF EXISTS readers_count;
+
+-- Older versions created these foreign keys without cascading, so deleting
+-- an account or a book failed once it had sessions, favorites or ratings.
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'user_session_fk_user_id' AND confdeltype = 'c') THEN
+		ALTER TABLE user_session DROP CONSTRAINT IF EXISTS user_session_fk_user_id;
+		ALTER TABLE user_session ADD CONSTRAINT user_session_fk_user_id FOREIGN KEY (user_id) REFERENCES user_account(email) ON DELETE CASCADE;
+	END IF;
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fav_book_fk_user_id' AND confdeltype = 'c') THEN
+		ALTER TABLE fav_book DROP CONSTRAINT IF EXISTS fav_book_fk_user_id;
+		ALTER TABLE fav_book ADD CONSTRAINT fav_book_fk_user_id FOREIGN KEY (user_id) REFERENCES user_account(email) ON DELETE CASCADE;
+	END IF;
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fav_book_fk_book_id' AND confdeltype = 'c') THEN
+		ALTER TABLE fav_book DROP CONSTRAINT IF EXISTS fav_book_fk_book_id;
+		ALTER TABLE fav_book ADD CONSTRAINT fav_book_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE;
+	END IF;
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'rate_book_fk_user_id' AND confdeltype = 'c') THEN
+		ALTER TABLE rate_book DROP CONSTRAINT IF EXISTS rate_book_fk_user_id;
+		ALTER TABLE rate_book ADD CONSTRAINT rate_book_fk_user_id FOREIGN KEY (user_id) REFERENCES user_account(email) ON DELETE CASCADE;
+	END IF;
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'rate_book_fk_book_id' AND confdeltype = 'c') THEN
+		ALTER TABLE rate_book DROP CONSTRAINT IF EXISTS rate_book_fk_book_id;
+		ALTER TABLE rate_book ADD CONSTRAINT rate_book_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE;
+	END IF;
+	IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'book_event_fk_account_id') THEN
+		DELETE FROM book_event e WHERE NOT EXISTS (SELECT 1 FROM user_account u WHERE u.email = e.account_id);
+		ALTER TABLE book_event ADD CONSTRAINT book_event_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE;
+	END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS account_follow (
+	follower_id varchar(255) NOT NULL,
+	followee_id varchar(255) NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT account_follow_pk PRIMARY KEY (follower_id, followee_id),
+	CONSTRAINT account_follow_fk_follower_id FOREIGN KEY (follower_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT account_follow_fk_followee_id FOREIGN KEY (followee_id) REFERENCES user_account(email) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS account_follow_index_followee ON account_follow(followee_id);
+
+CREATE TABLE IF NOT EXISTS annotation (
+	id uuid NOT NULL,
+	account_id varchar(255) NOT NULL,
+	book_id uuid NOT NULL,
+	kind varchar(16) NOT NULL,
+	cfi_range TEXT NOT NULL,
+	quote TEXT,
+	color varchar(16),
+	note TEXT,
+	privacy varchar(16) NOT NULL DEFAULT 'private',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT annotation_pk PRIMARY KEY (id),
+	CONSTRAINT annotation_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT annotation_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+	CONSTRAINT annotation_kind_check CHECK (kind IN ('bookmark', 'highlight', 'note')),
+	CONSTRAINT annotation_color_check CHECK (color IN ('yellow', 'green', 'blue', 'pink', 'purple')),
+	CONSTRAINT annotation_privacy_check CHECK (privacy IN ('private', 'shared'))
+);
+CREATE INDEX IF NOT EXISTS annotation_index_book ON annotation(book_id, created_at, id);
+CREATE INDEX IF NOT EXISTS annotation_index_account ON annotation(account_id, book_id);
+
+ALTER TABLE fav_book ADD COLUMN IF NOT EXISTS position integer NOT NULL DEFAULT 0;
+
+-- Every account has one shelf of each built-in kind. The favorites shelf
+-- lists fav_book, the others list shelf_book like custom shelves do.
+CREATE TABLE IF NOT EXISTS shelf (
+	id uuid NOT NULL,
+	account_id varchar(255) NOT NULL,
+	kind varchar(16) NOT NULL DEFAULT 'custom',
+	name TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	visibility varchar(16) NOT NULL DEFAULT 'private',
+	position integer NOT NULL DEFAULT 0,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT shelf_pk PRIMARY KEY (id),
+	CONSTRAINT shelf_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE,
+	CONSTRAINT shelf_kind_check CHECK (kind IN ('favorites', 'want_to_read', 'reading', 'finished', 'custom')),
+	CONSTRAINT shelf_visibility_check CHECK (visibility IN ('private', 'public'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS shelf_index_built_in ON shelf(account_id, kind) WHERE kind <> 'custom';
+CREATE INDEX IF NOT EXISTS shelf_index_account ON shelf(account_id, position);
+
+CREATE TABLE IF NOT EXISTS shelf_book (
+	shelf_id uuid NOT NULL,
+	book_id uuid NOT NULL,
+	position integer NOT NULL DEFAULT 0,
+	added_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT shelf_book_pk PRIMARY KEY (shelf_id, book_id),
+	CONSTRAINT shelf_book_fk_shelf_id FOREIGN KEY (shelf_id) REFERENCES shelf(id) ON DELETE CASCADE,
+	CONSTRAINT shelf_book_fk_book_id FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS shelf_book_index_book ON shelf_book(book_id);
+
+INSERT INTO shelf (
+	id, account_id, kind, name, position
+)
+SELECT
+	gen_random_uuid(),
+	u.email,
+	built_in.kind,
+	built_in.name,
+	built_in.position
+FROM
+	user_account u
+	CROSS JOIN (
+		VALUES
+			('favorites', 'Favorites', 0),
+			('want_to_read', 'Want to read', 1),
+			('reading', 'Currently reading', 2),
+			('finished', 'Finished', 3)
+	) AS built_in (kind, name, position)
+ON CONFLICT (account_id, kind) WHERE kind <> 'custom' DO NOTHING;
+
+-- A review is the text attached to a rating. Ratings without text are
+-- reviews without a body, they never go through moderation.
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS id uuid NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS review TEXT;
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS flag_reason TEXT;
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS moderated_at timestamptz;
+ALTER TABLE rate_book ADD COLUMN IF NOT EXISTS moderated_by varchar(255);
+CREATE UNIQUE INDEX IF NOT EXISTS rate_book_index_id ON rate_book(id);
+CREATE INDEX IF NOT EXISTS rate_book_index_review_status ON rate_book(review_status) WHERE review_status = 'pending';
+
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM pg_constraint WHERE conname = 'rate_book_review_status_check'
+	) THEN
+		ALTER TABLE rate_book ADD CONSTRAINT rate_book_review_status_check
+			CHECK (review_status IN ('approved', 'pending', 'rejected', 'hidden'));
+	END IF;
+	IF NOT EXISTS (
+		SELECT 1 FROM pg_constraint WHERE conname = 'rate_book_fk_moderated_by'
+	) THEN
+		ALTER TABLE rate_book ADD CONSTRAINT rate_book_fk_moderated_by
+			FOREIGN KEY (moderated_by) REFERENCES user_account(email) ON DELETE SET NULL;
+	END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS review_vote (
+	review_id uuid NOT NULL,
+	account_id varchar(255) NOT NULL,
+	helpful boolean NOT NULL,
+	voted_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT review_vote_pk PRIMARY KEY (review_id, account_id),
+	CONSTRAINT review_vote_fk_review_id FOREIGN KEY (review_id) REFERENCES rate_book(id) ON DELETE CASCADE,
+	CONSTRAINT review_vote_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS review_report (
+	review_id uuid NOT NULL,
+	account_id varchar(255) NOT NULL,
+	reason TEXT NOT NULL,
+	reported_at timestamptz NOT NULL DEFAULT now(),
+	CONSTRAINT review_report_pk PRIMARY KEY (review_id, account_id),
+	CONSTRAINT review_report_fk_review_id FOREIGN KEY (review_id) REFERENCES rate_book(id) ON DELETE CASCADE,
+	CONSTRAINT review_report_fk_account_id FOREIGN KEY (account_id) REFERENCES user_account(email) ON DELETE CASCADE
+);
+
+-- Hidden reviews take their rating with them.
+CREATE OR REPLACE VIEW rating_avg AS
+	SELECT
+		book.id,
+		(
+			COALESCE(
+				avg(rate_book.rating),
+				(0)
+			)
+		) AS rating
+	FROM
+		book
+		LEFT JOIN rate_book ON book.id = rate_book.book_id
+		AND rate_book.review_status <> 'hidden'
+	GROUP BY
+		book.id;
+
+-- Security relevant events, who did what and when. Each row's hash covers the
+-- row and the hash before it, so an edited or removed row breaks the chain.
+-- The payload is json, not jsonb, to keep the exact text that was hashed.
+CREATE TABLE IF NOT EXISTS audit_event (
+	id bigserial NOT NULL,
+	occurred_at timestamptz NOT NULL,
+	action varchar(64) NOT NULL,
+	actor varchar(255) NOT NULL DEFAULT '',
+	ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT '',
+	payload json NOT NULL,
+	prev_hash varchar(64) NOT NULL,
+	hash char(64) NOT NULL,
+	CONSTRAINT audit_event_pk PRIMARY KEY (id),
+	CONSTRAINT audit_event_prev_hash_check CHECK (length(prev_hash) IN (0, 64))
+);
+
+-- The first event links to an empty hash, which a char(64) column padded
+-- with spaces. Casting to varchar drops the padding.
+DO $$
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_name = 'audit_event' AND column_name = 'prev_hash' AND data_type = 'character'
+	) THEN
+		ALTER TABLE audit_event ALTER COLUMN prev_hash TYPE varchar(64);
+	END IF;
+	IF NOT EXISTS (
+		SELECT 1 FROM pg_constraint WHERE conname = 'audit_event_prev_hash_check'
+	) THEN
+		ALTER TABLE audit_event ADD CONSTRAINT audit_event_prev_hash_check
+			CHECK (length(prev_hash) IN (0, 64));
+	END IF;
+END
+$$;
+CREATE INDEX IF NOT EXISTS audit_event_index_actor ON audit_event(actor, id);
+CREATE INDEX IF NOT EXISTS audit_event_index_action ON audit_event(action, id);
+CREATE INDEX IF NOT EXISTS audit_event_index_occurred_at ON audit_event(occurred_at);
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_event_no_update ON audit_event;
+CREATE TRIGGER audit_event_no_update
+	BEFORE UPDATE OR DELETE ON audit_event
+	FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
+
+DROP TRIGGER IF EXISTS audit_event_no_truncate ON audit_event;
+CREATE TRIGGER audit_event_no_truncate
+	BEFORE TRUNCATE ON audit_event
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_event_append_only();
+
+-- Support staff disable or ban accounts with a reason instead of deleting
+-- them, so their reviews and the audit log keep pointing somewhere.
+ALTER TABLE user_account ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'active' CONSTRAINT user_account_status_check CHECK (status IN ('active', 'disabled', 'banned'));
+ALTER TABLE user_account ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_account ADD COLUMN IF NOT EXISTS status_changed_at timestamptz;
+
+ALTER TABLE user_session ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS user_session_index_user_id ON user_session(user_id, exhausted);
+
+-- The language the account's messages are sent in, NULL to go by the
+-- client's cookie and Accept-Language.
+ALTER TABLE user_account ADD COLUMN IF NOT EXISTS language varchar(35);
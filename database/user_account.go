@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"ic-rhadi/e_library/tracinghelper"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,18 +13,35 @@ import (
 
 type UserAccountInterface interface {
 	Login(ctx context.Context, email string, pass string, sessionLength time.Duration) (refreshID string, err error)
+	VerifyPassword(ctx context.Context, email string, pass string) error
 	LoginGoogle(ctx context.Context, email string, gID string, sessionLength time.Duration) (refreshID string, err error)
 	Register(ctx context.Context, email string, password string, name string, activationDuration time.Duration) (activationToken string, validUntil *time.Time, err error)
 	RegisterGoogle(ctx context.Context, email string, gID string, name string, activationDuration time.Duration) (activationToken string, validUntil *time.Time, err error)
 	GetActivationData(ctx context.Context, email string) (activated bool, activationToken string, expiresIn *time.Time, err error)
 	RefreshActivation(ctx context.Context, email string, activationToken string, expiresIn time.Time) error
 	ActivateAccount(ctx context.Context, email string) error
+	GetAccountRole(ctx context.Context, email string) (role string, err error)
+	GetAccountLanguage(ctx context.Context, email string) (lang string, err error)
+	SetAccountLanguage(ctx context.Context, email string, lang string) error
 }
 
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// The statuses of an account. Only active accounts can sign in.
+const (
+	AccountActive   = "active"
+	AccountDisabled = "disabled"
+	AccountBanned   = "banned"
+)
+
 var loginStmt = dbStatement{
 	nil, `
 	SELECT 
-		password, activated
+		password, activated, status
 	FROM 
 		user_account 
 	WHERE 
@@ -32,7 +50,7 @@ var loginStmt = dbStatement{
 var loginGoogleStmt = dbStatement{
 	nil, `
 	SELECT 
-		g_id, activated
+		g_id, activated, status
 	FROM 
 		user_account 
 	WHERE 
@@ -128,6 +146,36 @@ var refreshActivationStmt = dbStatement{
 		email = $4`,
 }
 
+var getAccountRoleStmt = dbStatement{
+	nil, `
+	SELECT
+		role
+	FROM
+		user_account
+	WHERE
+		email = $1`,
+}
+
+var getAccountLanguageStmt = dbStatement{
+	nil, `
+	SELECT
+		COALESCE(language, '')
+	FROM
+		user_account
+	WHERE
+		email = $1`,
+}
+
+var setAccountLanguageStmt = dbStatement{
+	nil, `
+	UPDATE
+		user_account
+	SET
+		language = NULLIF($2, '')
+	WHERE
+		email = $1`,
+}
+
 var deleteExpiredAccountStmt = dbStatement{
 	nil, `
 	DELETE FROM
@@ -138,29 +186,35 @@ var deleteExpiredAccountStmt = dbStatement{
 }
 
 func init() {
-	prepareStatements = append(prepareStatements,
-		&loginStmt,
-		&loginGoogleStmt,
-		&registerSearchStmt,
-		&registerSearchGoogleStmt,
-		&registerStmt,
-		&registerAddStmt,
-		&registerGoogleStmt,
-		&registerAddGoogleStmt,
-		&checkActivationStmt,
-		&checkActivationTokenStmt,
-		&refreshActivationStmt,
-	)
+	registerStatements(map[string]*dbStatement{
+		"login":                &loginStmt,
+		"loginGoogle":          &loginGoogleStmt,
+		"registerSearch":       &registerSearchStmt,
+		"registerSearchGoogle": &registerSearchGoogleStmt,
+		"register":             &registerStmt,
+		"registerAdd":          &registerAddStmt,
+		"registerGoogle":       &registerGoogleStmt,
+		"registerAddGoogle":    &registerAddGoogleStmt,
+		"checkActivation":      &checkActivationStmt,
+		"checkActivationToken": &checkActivationTokenStmt,
+		"refreshActivation":    &refreshActivationStmt,
+		"getAccountRole":       &getAccountRoleStmt,
+		"getAccountLanguage":   &getAccountLanguageStmt,
+		"setAccountLanguage":   &setAccountLanguageStmt,
+		"deleteExpiredAccount": &deleteExpiredAccountStmt,
+	})
 }
 
 var ErrAccountNotActive error = errors.New("account not activated")
 var ErrAccountNotFound error = errors.New("account not found")
 var ErrWrongID error = errors.New("google account id invalid")
 var ErrWrongPass error = errors.New("account password invalid")
+var ErrAccountDisabled error = errors.New("account disabled")
 
 func (db DBInstance) Login(ctx context.Context, email string, pass string, sessionLength time.Duration) (refreshID string, err error) {
 	var hash sql.NullString
 	var activated bool
+	var status string
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -168,24 +222,16 @@ func (db DBInstance) Login(ctx context.Context, email string, pass string, sessi
 	}
 	defer tx.Rollback()
 
-	row := tx.StmtContext(ctx, loginStmt.Statement).QueryRowContext(ctx, email)
-	if err := row.Scan(&hash, &activated); err != nil {
+	row := loginStmt.Tx(ctx, tx).QueryRowContext(ctx, email)
+	if err := row.Scan(&hash, &activated, &status); err != nil {
 		if err == sql.ErrNoRows {
 			return "", ErrAccountNotFound
 		}
 		return "", err
 	}
 
-	if !hash.Valid {
-		return "", ErrAccountNotFound
-	}
-
-	if !activated {
-		return "", ErrAccountNotActive
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(hash.String), []byte(pass)); err != nil {
-		return "", ErrWrongPass
+	if err := checkPassword(ctx, hash, activated, status, pass); err != nil {
+		return "", err
 	}
 
 	randomUUID, err := uuid.NewRandom()
@@ -195,8 +241,7 @@ func (db DBInstance) Login(ctx context.Context, email string, pass string, sessi
 	refreshID = randomUUID.String()
 	expiresIn := time.Now().Add(sessionLength)
 
-	if _, err := tx.
-		StmtContext(ctx, addRefreshStmt.Statement).
+	if _, err := addRefreshStmt.Tx(ctx, tx).
 		ExecContext(ctx,
 			email,
 			randomUUID,
@@ -211,9 +256,50 @@ func (db DBInstance) Login(ctx context.Context, email string, pass string, sessi
 	return
 }
 
+// VerifyPassword checks an account's credentials without starting a session,
+// for clients that send them with every request.
+func (db DBInstance) VerifyPassword(ctx context.Context, email string, pass string) error {
+	var hash sql.NullString
+	var activated bool
+	var status string
+
+	row := loginStmt.QueryRowContext(ctx, email)
+	if err := row.Scan(&hash, &activated, &status); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+	return checkPassword(ctx, hash, activated, status, pass)
+}
+
+// checkPassword only tells a disabled account apart once the password is
+// right, so the status isn't given away to anyone knowing the email.
+func checkPassword(ctx context.Context, hash sql.NullString, activated bool, status string, pass string) error {
+	if !hash.Valid {
+		return ErrAccountNotFound
+	}
+
+	if !activated {
+		return ErrAccountNotActive
+	}
+
+	_, span := tracer.Start(ctx, "bcrypt.compare")
+	err := bcrypt.CompareHashAndPassword([]byte(hash.String), []byte(pass))
+	span.End()
+	if err != nil {
+		return ErrWrongPass
+	}
+	if status != AccountActive {
+		return ErrAccountDisabled
+	}
+	return nil
+}
+
 func (db DBInstance) LoginGoogle(ctx context.Context, email string, gID string, sessionLength time.Duration) (refreshID string, err error) {
 	var gid sql.NullString
 	var activated bool
+	var status string
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -221,8 +307,8 @@ func (db DBInstance) LoginGoogle(ctx context.Context, email string, gID string,
 	}
 	defer tx.Rollback()
 
-	row := tx.StmtContext(ctx, loginGoogleStmt.Statement).QueryRowContext(ctx, email)
-	if err := row.Scan(&gid, &activated); err != nil {
+	row := loginGoogleStmt.Tx(ctx, tx).QueryRowContext(ctx, email)
+	if err := row.Scan(&gid, &activated, &status); err != nil {
 		if err == sql.ErrNoRows {
 			return "", ErrAccountNotFound
 		}
@@ -241,6 +327,10 @@ func (db DBInstance) LoginGoogle(ctx context.Context, email string, gID string,
 		return "", ErrWrongID
 	}
 
+	if status != AccountActive {
+		return "", ErrAccountDisabled
+	}
+
 	randomUUID, err := uuid.NewRandom()
 	if err != nil {
 		return "", err
@@ -248,8 +338,7 @@ func (db DBInstance) LoginGoogle(ctx context.Context, email string, gID string,
 	refreshID = randomUUID.String()
 	expiresIn := time.Now().Add(sessionLength)
 
-	if _, err := tx.
-		StmtContext(ctx, addRefreshStmt.Statement).
+	if _, err := addRefreshStmt.Tx(ctx, tx).
 		ExecContext(ctx,
 			email,
 			randomUUID,
@@ -273,11 +362,13 @@ func (db DBInstance) Register(ctx context.Context, email string, password string
 	}
 	defer tx.Rollback()
 
-	row := tx.StmtContext(ctx, registerSearchStmt.Statement).QueryRowContext(ctx, email)
+	row := registerSearchStmt.Tx(ctx, tx).QueryRowContext(ctx, email)
 	var nullHash sql.NullString
 	var activated bool
 
+	_, span := tracer.Start(ctx, "bcrypt.generate")
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	tracinghelper.End(span, err)
 	if err != nil {
 		return "", nil, err
 	}
@@ -296,17 +387,22 @@ func (db DBInstance) Register(ctx context.Context, email string, password string
 		if nullHash.Valid || !activated {
 			return "", nil, ErrAccountExisted
 		}
-		_, err = tx.StmtContext(ctx, registerAddStmt.Statement).ExecContext(ctx, email, hash, randomUUID, validUntil)
+		_, err = registerAddStmt.Tx(ctx, tx).ExecContext(ctx, email, hash, randomUUID, validUntil)
 		if err != nil {
 			return "", nil, err
 		}
 	} else if err != sql.ErrNoRows {
 		return "", nil, err
 	} else {
-		_, err = tx.StmtContext(ctx, registerStmt.Statement).ExecContext(ctx, email, hash, randomUUID, validUntil, name)
+		_, err = registerStmt.Tx(ctx, tx).ExecContext(ctx, email, hash, randomUUID, validUntil, name)
 		if err != nil {
 			return "", nil, err
 		}
+		// Shelves are only read afterwards, so the built-in ones are made
+		// along with the account.
+		if _, err := ensureBuiltInShelvesStmt.Tx(ctx, tx).ExecContext(ctx, email); err != nil {
+			return "", nil, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -322,7 +418,7 @@ func (db DBInstance) RegisterGoogle(ctx context.Context, email string, gID strin
 	}
 	defer tx.Rollback()
 
-	row := tx.StmtContext(ctx, registerSearchGoogleStmt.Statement).QueryRowContext(ctx, email)
+	row := registerSearchGoogleStmt.Tx(ctx, tx).QueryRowContext(ctx, email)
 	var nullGID sql.NullString
 	var activated bool
 
@@ -340,17 +436,22 @@ func (db DBInstance) RegisterGoogle(ctx context.Context, email string, gID strin
 		if nullGID.Valid || !activated {
 			return "", nil, ErrAccountExisted
 		}
-		_, err = tx.StmtContext(ctx, registerAddGoogleStmt.Statement).ExecContext(ctx, email, gID, randomUUID, validUntil)
+		_, err = registerAddGoogleStmt.Tx(ctx, tx).ExecContext(ctx, email, gID, randomUUID, validUntil)
 		if err != nil {
 			return "", nil, err
 		}
 	} else if err != sql.ErrNoRows {
 		return "", nil, err
 	} else {
-		_, err = tx.StmtContext(ctx, registerGoogleStmt.Statement).ExecContext(ctx, email, gID, randomUUID, validUntil, name)
+		_, err = registerGoogleStmt.Tx(ctx, tx).ExecContext(ctx, email, gID, randomUUID, validUntil, name)
 		if err != nil {
 			return "", nil, err
 		}
+		// Shelves are only read afterwards, so the built-in ones are made
+		// along with the account.
+		if _, err := ensureBuiltInShelvesStmt.Tx(ctx, tx).ExecContext(ctx, email); err != nil {
+			return "", nil, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -362,7 +463,7 @@ func (db DBInstance) RegisterGoogle(ctx context.Context, email string, gID strin
 func (db DBInstance) GetActivationData(ctx context.Context, email string) (activated bool, activationToken string, expiresIn *time.Time, err error) {
 	var token sql.NullString
 	var exp sql.NullTime
-	if err := checkActivationTokenStmt.Statement.QueryRowContext(ctx, email).
+	if err := checkActivationTokenStmt.QueryRowContext(ctx, email).
 		Scan(&activated, &token, &exp); err != nil {
 		if err == sql.ErrNoRows {
 			return false, "", nil, ErrAccountNotFound
@@ -375,17 +476,56 @@ func (db DBInstance) GetActivationData(ctx context.Context, email string) (activ
 }
 
 func (db DBInstance) RefreshActivation(ctx context.Context, email string, activationToken string, expiresIn time.Time) error {
-	_, err := refreshActivationStmt.Statement.ExecContext(ctx, false, activationToken, expiresIn, email)
+	_, err := refreshActivationStmt.ExecContext(ctx, false, activationToken, expiresIn, email)
 	return err
 }
 
 func (db DBInstance) ActivateAccount(ctx context.Context, email string) error {
-	_, err := refreshActivationStmt.Statement.ExecContext(ctx, true, nil, nil, email)
+	_, err := refreshActivationStmt.ExecContext(ctx, true, nil, nil, email)
 	return err
 }
 
+func (db DBInstance) GetAccountRole(ctx context.Context, email string) (role string, err error) {
+	if err := getAccountRoleStmt.QueryRowContext(ctx, email).Scan(&role); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+	return
+}
+
+// GetAccountLanguage returns the language the account picked for its
+// messages, or "" when it didn't pick one.
+func (db DBInstance) GetAccountLanguage(ctx context.Context, email string) (lang string, err error) {
+	if err := getAccountLanguageStmt.QueryRowContext(ctx, email).Scan(&lang); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+	return
+}
+
+// SetAccountLanguage saves the language the account's messages are sent in,
+// "" to go back to the client's.
+func (db DBInstance) SetAccountLanguage(ctx context.Context, email string, lang string) error {
+	result, err := setAccountLanguageStmt.ExecContext(ctx, email, lang)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
 func (db DBInstance) DeleteExpiredAccount(ctx context.Context, currTime time.Time) (deleted int64, err error) {
-	result, err := deleteExpiredAccountStmt.Statement.ExecContext(ctx, currTime)
+	result, err := deleteExpiredAccountStmt.ExecContext(ctx, currTime)
 	if err != nil {
 		return 0, err
 	}
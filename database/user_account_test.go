@@ -67,14 +67,14 @@ func TestSuccessfulLogin(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	hashPass, err := bcrypt.GenerateFromPassword([]byte(expPassword), 4)
 	require.NoErrorf(t, err, "an error '%s' was not expected when creating a mock hashed password", err)
 
 	var rowsPost = sqlmock.
-		NewRows([]string{"password", "activated"}).
-		AddRow(hashPass, true)
+		NewRows([]string{"password", "activated", "status"}).
+		AddRow(hashPass, true, AccountActive)
 
 	test1 := mock.ExpectPrepare("SELECT")
 	test2 := mock.ExpectPrepare("INSERT")
@@ -106,7 +106,7 @@ func TestNotFoundLogin(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	test1 := mock.ExpectPrepare("SELECT")
 	mock.ExpectBegin()
@@ -131,14 +131,14 @@ func TestNotActiveLogin(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	hashPass, err := bcrypt.GenerateFromPassword([]byte(expPassword), 4)
 	require.NoErrorf(t, err, "an error '%s' was not expected when creating a mock hashed password", err)
 
 	var rows = sqlmock.
-		NewRows([]string{"password", "activated"}).
-		AddRow(hashPass, false)
+		NewRows([]string{"password", "activated", "status"}).
+		AddRow(hashPass, false, AccountActive)
 
 	test1 := mock.ExpectPrepare("SELECT")
 	mock.ExpectBegin()
@@ -157,6 +157,39 @@ func TestNotActiveLogin(t *testing.T) {
 	assert.Nil(t, mock.ExpectationsWereMet())
 }
 
+func TestDisabledLogin(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	hashPass, err := bcrypt.GenerateFromPassword([]byte(expPassword), 4)
+	require.NoErrorf(t, err, "an error '%s' was not expected when creating a mock hashed password", err)
+
+	var rows = sqlmock.
+		NewRows([]string{"password", "activated", "status"}).
+		AddRow(hashPass, true, AccountBanned)
+
+	test1 := mock.ExpectPrepare("SELECT")
+	mock.ExpectBegin()
+	test1.ExpectQuery().
+		WithArgs(expEmail).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+	mock.ExpectRollback()
+
+	err = loginStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	id, err := db.Login(ctx, expEmail, expPassword, time.Duration(48)*time.Hour)
+	assert.Empty(t, id, "unexpected output in a failed login test")
+	assert.Equal(t, ErrAccountDisabled, err, "function should've returned ErrAccountDisabled error")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
 func TestFailedLogins(t *testing.T) {
 	ctx := context.Background()
 
@@ -164,14 +197,14 @@ func TestFailedLogins(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	hashPass, err := bcrypt.GenerateFromPassword([]byte(expPassword[1:]), 4)
 	require.NoErrorf(t, err, "an error '%s' was not expected when creating a mock hashed password", err)
 
 	var rowsPost = sqlmock.
-		NewRows([]string{"password", "activated"}).
-		AddRow(hashPass, true)
+		NewRows([]string{"password", "activated", "status"}).
+		AddRow(hashPass, true, AccountActive)
 
 	test1 := mock.ExpectPrepare("SELECT")
 	mock.ExpectBegin()
@@ -190,6 +223,72 @@ func TestFailedLogins(t *testing.T) {
 	assert.Nil(t, mock.ExpectationsWereMet())
 }
 
+func TestSuccessfulVerifyPassword(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	hashPass, err := bcrypt.GenerateFromPassword([]byte(expPassword), 4)
+	require.NoErrorf(t, err, "an error '%s' was not expected when creating a mock hashed password", err)
+
+	var rows = sqlmock.
+		NewRows([]string{"password", "activated", "status"}).
+		AddRow(hashPass, true, AccountActive)
+
+	mock.ExpectPrepare("SELECT").
+		ExpectQuery().
+		WithArgs(expEmail).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	err = loginStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.VerifyPassword(ctx, expEmail, expPassword)
+	assert.Nil(t, err, "unexpected error in a successful password check")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestFailedVerifyPassword(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	hashPass, err := bcrypt.GenerateFromPassword([]byte(expPassword[1:]), 4)
+	require.NoErrorf(t, err, "an error '%s' was not expected when creating a mock hashed password", err)
+
+	var rows = sqlmock.
+		NewRows([]string{"password", "activated", "status"}).
+		AddRow(hashPass, true, AccountActive)
+
+	test1 := mock.ExpectPrepare("SELECT")
+	test1.ExpectQuery().
+		WithArgs(expEmail).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+	test1.ExpectQuery().
+		WithArgs(expEmail).
+		WillReturnError(sql.ErrNoRows)
+
+	err = loginStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.VerifyPassword(ctx, expEmail, expPassword)
+	assert.Equal(t, ErrWrongPass, err, "function should've returned ErrWrongPass error")
+
+	err = db.VerifyPassword(ctx, expEmail, expPassword)
+	assert.Equal(t, ErrAccountNotFound, err, "function should've returned an ErrAccountNotFound error")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
 func TestSuccessfulLoginGoogle(t *testing.T) {
 	ctx := context.Background()
 
@@ -197,11 +296,11 @@ func TestSuccessfulLoginGoogle(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	var rowsPost = sqlmock.
-		NewRows([]string{"g_id", "activated"}).
-		AddRow(expGID, true)
+		NewRows([]string{"g_id", "activated", "status"}).
+		AddRow(expGID, true, AccountActive)
 
 	test1 := mock.ExpectPrepare("SELECT")
 	test2 := mock.ExpectPrepare("INSERT")
@@ -234,7 +333,7 @@ func TestNotFoundLoginGoogle(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	test1 := mock.ExpectPrepare("SELECT")
 	mock.ExpectBegin()
@@ -259,11 +358,11 @@ func TestNotActiveLoginGoogle(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	var rows = sqlmock.
-		NewRows([]string{"g_id", "activated"}).
-		AddRow(expGID, false)
+		NewRows([]string{"g_id", "activated", "status"}).
+		AddRow(expGID, false, AccountActive)
 
 	test1 := mock.ExpectPrepare("SELECT")
 	mock.ExpectBegin()
@@ -289,11 +388,11 @@ func TestFailedLoginsGoogle(t *testing.T) {
 	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
 	defer d.Close()
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	var rowsPost = sqlmock.
-		NewRows([]string{"g_id", "activated"}).
-		AddRow(expGID[1:], true)
+		NewRows([]string{"g_id", "activated", "status"}).
+		AddRow(expGID[1:], true, AccountActive)
 
 	test1 := mock.ExpectPrepare("SELECT")
 	mock.ExpectBegin()
@@ -323,11 +422,12 @@ func TestSuccessfulRegister(t *testing.T) {
 
 	expDur := time.Minute * time.Duration(10)
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	th := &testString{}
 	test1 := mock.ExpectPrepare("SELECT")
 	test2 := mock.ExpectPrepare("INSERT")
+	test3 := mock.ExpectPrepare("INSERT INTO shelf")
 	mock.ExpectBegin()
 	test1.ExpectQuery().
 		WithArgs(expEmail).
@@ -336,6 +436,9 @@ func TestSuccessfulRegister(t *testing.T) {
 	test2.ExpectExec().
 		WithArgs(expEmail, th, tu, sqlmock.AnyArg(), expName).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	test3.ExpectExec().
+		WithArgs(expEmail).
+		WillReturnResult(sqlmock.NewResult(0, 4))
 	mock.ExpectCommit()
 
 	err = registerSearchStmt.Prepare(ctx, d)
@@ -344,6 +447,9 @@ func TestSuccessfulRegister(t *testing.T) {
 	err = registerStmt.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
+	err = ensureBuiltInShelvesStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
 	actToken, _, err := db.Register(ctx, expEmail, expPassword, expName, expDur)
 	if assert.Nil(t, err, "unexpected error in a successful register test") {
 		assert.Equal(t, tu.uuid, actToken, "function should've returned a new session id")
@@ -365,7 +471,7 @@ func TestSuccessfulAddRegister(t *testing.T) {
 
 	expDur := time.Minute * time.Duration(10)
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	th := &testString{}
 	test1 := mock.ExpectPrepare("SELECT")
@@ -402,11 +508,12 @@ func TestSuccessfulRegisterGoogle(t *testing.T) {
 	defer d.Close()
 	expDur := time.Minute * time.Duration(10)
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	th := &testString{}
 	test1 := mock.ExpectPrepare("SELECT")
 	test2 := mock.ExpectPrepare("INSERT")
+	test3 := mock.ExpectPrepare("INSERT INTO shelf")
 	mock.ExpectBegin()
 	test1.ExpectQuery().
 		WithArgs(expEmail).
@@ -415,6 +522,9 @@ func TestSuccessfulRegisterGoogle(t *testing.T) {
 	test2.ExpectExec().
 		WithArgs(expEmail, th, tu, sqlmock.AnyArg(), expName).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	test3.ExpectExec().
+		WithArgs(expEmail).
+		WillReturnResult(sqlmock.NewResult(0, 4))
 	mock.ExpectCommit()
 
 	err = registerSearchGoogleStmt.Prepare(ctx, d)
@@ -423,6 +533,9 @@ func TestSuccessfulRegisterGoogle(t *testing.T) {
 	err = registerGoogleStmt.Prepare(ctx, d)
 	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
 
+	err = ensureBuiltInShelvesStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
 	actToken, _, err := db.RegisterGoogle(ctx, expEmail, expGID, expName, expDur)
 	if assert.Nil(t, err, "unexpected error in a successful register-google test") {
 		assert.Equal(t, tu.uuid, actToken, "function should've returned a new session id")
@@ -443,7 +556,7 @@ func TestSuccessfulAddRegisterGoogle(t *testing.T) {
 	defer d.Close()
 	expDur := time.Minute * time.Duration(10)
 
-	db := DBInstance{d}
+	db := DBInstance{DB: d}
 
 	th := &testString{}
 	test1 := mock.ExpectPrepare("SELECT")
@@ -471,3 +584,50 @@ func TestSuccessfulAddRegisterGoogle(t *testing.T) {
 	assert.Equal(t, expGID, th.str, "function should've returned a correct google account id")
 	assert.Nil(t, mock.ExpectationsWereMet())
 }
+
+func TestAccountLanguage(t *testing.T) {
+	ctx := context.Background()
+
+	d, mock, err := sqlmock.New()
+	require.NoErrorf(t, err, "an error '%s' was not expected when opening a stub database connection", err)
+	defer d.Close()
+
+	db := DBInstance{DB: d}
+
+	get := mock.ExpectPrepare("SELECT")
+	get.ExpectQuery().
+		WithArgs(expEmail).
+		WillReturnRows(sqlmock.NewRows([]string{"language"}).AddRow("ja")).
+		RowsWillBeClosed()
+	get.ExpectQuery().
+		WithArgs(expEmail).
+		WillReturnError(sql.ErrNoRows)
+
+	err = getAccountLanguageStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	lang, err := db.GetAccountLanguage(ctx, expEmail)
+	assert.Nil(t, err, "unexpected error in a successful account language read")
+	assert.Equal(t, "ja", lang, "the account's language wasn't returned")
+
+	_, err = db.GetAccountLanguage(ctx, expEmail)
+	assert.Equal(t, ErrAccountNotFound, err, "function should've returned an ErrAccountNotFound error")
+
+	set := mock.ExpectPrepare("UPDATE")
+	set.ExpectExec().
+		WithArgs(expEmail, "id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	set.ExpectExec().
+		WithArgs(expEmail, "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = setAccountLanguageStmt.Prepare(ctx, d)
+	require.NoErrorf(t, err, "an error '%s' was not expected when preparing a stub database connection", err)
+
+	err = db.SetAccountLanguage(ctx, expEmail, "id")
+	assert.Nil(t, err, "unexpected error in a successful account language change")
+
+	err = db.SetAccountLanguage(ctx, expEmail, "")
+	assert.Equal(t, ErrAccountNotFound, err, "function should've returned an ErrAccountNotFound error")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
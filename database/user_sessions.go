@@ -59,19 +59,19 @@ var deleteExpiredRefreshStmt = dbStatement{
 }
 
 func init() {
-	prepareStatements = append(prepareStatements,
-		&getRefreshStmt,
-		&exhaustRefreshStmt,
-		&addRefreshStmt,
-		&invalidateTokenFamilyStmt,
-		&deleteExpiredRefreshStmt,
-	)
+	registerStatements(map[string]*dbStatement{
+		"getRefresh":            &getRefreshStmt,
+		"exhaustRefresh":        &exhaustRefreshStmt,
+		"addRefresh":            &addRefreshStmt,
+		"invalidateTokenFamily": &invalidateTokenFamilyStmt,
+		"deleteExpiredRefresh":  &deleteExpiredRefreshStmt,
+	})
 }
 
 var ErrSessionNotFound = errors.New("")
 
 func (db DBInstance) GetSession(ctx context.Context, userID string, sessionToken string, currTime time.Time) (tokenFamily string, exhausted bool, expiresIn *time.Time, err error) {
-	if err = getRefreshStmt.Statement.QueryRowContext(ctx, userID, sessionToken, currTime).Scan(&tokenFamily, &exhausted, &expiresIn); err != nil {
+	if err = getRefreshStmt.QueryRowContext(ctx, userID, sessionToken, currTime).Scan(&tokenFamily, &exhausted, &expiresIn); err != nil {
 		if err == sql.ErrNoRows {
 			return "", false, nil, ErrSessionNotFound
 		}
@@ -87,10 +87,10 @@ func (db DBInstance) AddNewSession(ctx context.Context, userID string, refreshTo
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.StmtContext(ctx, addRefreshStmt.Statement).Exec(userID, refreshToken, tokenFamily, expiresIn); err != nil {
+	if _, err := addRefreshStmt.Tx(ctx, tx).ExecContext(ctx, userID, refreshToken, tokenFamily, expiresIn); err != nil {
 		return err
 	}
-	if _, err := exhaustRefreshStmt.Statement.Exec(userID, refreshToken); err != nil {
+	if _, err := exhaustRefreshStmt.ExecContext(ctx, userID, refreshToken); err != nil {
 		return err
 	}
 
@@ -98,12 +98,12 @@ func (db DBInstance) AddNewSession(ctx context.Context, userID string, refreshTo
 }
 
 func (db DBInstance) InvaildateSession(ctx context.Context, userID string, tokenFamily string) error {
-	_, err := invalidateTokenFamilyStmt.Statement.Exec(userID, tokenFamily)
+	_, err := invalidateTokenFamilyStmt.ExecContext(ctx, userID, tokenFamily)
 	return err
 }
 
 func (db DBInstance) DeleteExpiredSession(ctx context.Context, currTime time.Time) (deleted int64, err error) {
-	result, err := deleteExpiredRefreshStmt.Statement.ExecContext(ctx, currTime)
+	result, err := deleteExpiredRefreshStmt.ExecContext(ctx, currTime)
 	if err != nil {
 		return 0, err
 	}
@@ -2,16 +2,24 @@ package emailhelper
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"ic-rhadi/e_library/metricshelper"
+	"ic-rhadi/e_library/tracinghelper"
+	"net"
+	"net/smtp"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/sethvargo/go-envconfig"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/gomail.v2"
 )
 
 type ActivationMailDriver interface {
-	SendActivationEmail(email string, activationToken string, validUntil time.Time) error
+	SendActivationEmail(ctx context.Context, email string, activationToken string, validUntil time.Time) error
+	CheckConnection(ctx context.Context) error
 }
 
 func NewActivationMailHelper(ctx context.Context) (ActivationMailDriver, error) {
@@ -35,7 +43,9 @@ type activationMailDriverImpl struct {
 	EmailFrom string `env:"SMTP_EMAIL, required"`
 }
 
-func (dialMail activationMailDriverImpl) SendActivationEmail(email string, activationToken string, validUntil time.Time) error {
+var tracer = tracinghelper.Tracer("emailhelper")
+
+func (dialMail activationMailDriverImpl) SendActivationEmail(ctx context.Context, email string, activationToken string, validUntil time.Time) error {
 	mailSetup := gomail.NewMessage()
 	activationLink := fmt.Sprintf(
 		"%s/auth/activate?email=%s&token=%s",
@@ -58,5 +68,37 @@ func (dialMail activationMailDriverImpl) SendActivationEmail(email string, activ
 			"<p>--eLibrary--</p>",
 	)
 
-	return dialMail.Dialer.DialAndSend(mailSetup)
+	_, span := tracer.Start(ctx, "smtp.send", trace.WithSpanKind(trace.SpanKindClient))
+	err := dialMail.Dialer.DialAndSend(mailSetup)
+	tracinghelper.End(span, err)
+	if err != nil {
+		metricshelper.Emails.WithLabelValues("activation", metricshelper.ResultFailure).Inc()
+		return err
+	}
+	metricshelper.Emails.WithLabelValues("activation", metricshelper.ResultSuccess).Inc()
+	return nil
+}
+
+// CheckConnection connects to the SMTP server and waits for its greeting,
+// without logging in or sending anything.
+func (dialMail activationMailDriverImpl) CheckConnection(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(dialMail.EmailHost, strconv.Itoa(dialMail.EmailPort)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// gomail uses implicit TLS on 465, so the greeting comes after the handshake.
+	if dialMail.EmailPort == 465 {
+		conn = tls.Client(conn, &tls.Config{ServerName: dialMail.EmailHost})
+	}
+
+	client, err := smtp.NewClient(conn, dialMail.EmailHost)
+	if err != nil {
+		return err
+	}
+	return client.Quit()
 }
@@ -0,0 +1,126 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+type AccountResponse struct {
+	Email           string   `json:"email"`
+	Name            string   `json:"name"`
+	Role            string   `json:"role"`
+	Activated       bool     `json:"activated"`
+	SignInMethods   []string `json:"sign_in_methods"`
+	Status          string   `json:"status"`
+	StatusReason    string   `json:"status_reason,omitempty"`
+	StatusChangedAt string   `json:"status_changed_at,omitempty"`
+	Sessions        int      `json:"sessions"`
+}
+
+func (a *AccountResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func AccountFromDatabase(dAccount database.Account) AccountResponse {
+	a := AccountResponse{
+		Email:         dAccount.Email,
+		Name:          dAccount.Name,
+		Role:          dAccount.Role,
+		Activated:     dAccount.Activated,
+		SignInMethods: []string{},
+		Status:        dAccount.Status,
+		StatusReason:  dAccount.StatusReason,
+		Sessions:      dAccount.Sessions,
+	}
+	if dAccount.HasPassword {
+		a.SignInMethods = append(a.SignInMethods, loginMethodPassword)
+	}
+	if dAccount.HasGoogle {
+		a.SignInMethods = append(a.SignInMethods, loginMethodGoogle)
+	}
+	if dAccount.StatusChangedAt != nil {
+		a.StatusChangedAt = dAccount.StatusChangedAt.UTC().Format(time.RFC3339)
+	}
+	return a
+}
+
+type AccountsResponse struct {
+	Data []AccountResponse `json:"data"`
+}
+
+func (a *AccountsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+type AccountSessionResponse struct {
+	TokenFamily   string `json:"token_family"`
+	StartedAt     string `json:"started_at"`
+	LastRefreshAt string `json:"last_refresh_at"`
+	ExpiresAt     string `json:"expires_at"`
+}
+
+type AccountSessionsResponse struct {
+	Data []AccountSessionResponse `json:"data"`
+}
+
+func (a *AccountSessionsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func AccountSessionsFromDatabase(dSessions []database.AccountSession) AccountSessionsResponse {
+	resp := AccountSessionsResponse{Data: []AccountSessionResponse{}}
+	for _, s := range dSessions {
+		resp.Data = append(resp.Data, AccountSessionResponse{
+			TokenFamily:   s.TokenFamily,
+			StartedAt:     s.StartedAt.UTC().Format(time.RFC3339),
+			LastRefreshAt: s.LastRefreshAt.UTC().Format(time.RFC3339),
+			ExpiresAt:     s.ExpiresIn.UTC().Format(time.RFC3339),
+		})
+	}
+	return resp
+}
+
+type AccountLoansResponse struct {
+	Data []readingBookResponse `json:"data"`
+}
+
+func (a *AccountLoansResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+type revokedSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+func (rs *revokedSessionsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+type impersonationResponse struct {
+	Session      string `json:"session_token"`
+	Scheme       string `json:"scheme"`
+	ExpiresAt    string `json:"expires_at"`
+	Account      string `json:"account"`
+	Impersonator string `json:"impersonator"`
+	ReadOnly     bool   `json:"read_only"`
+}
+
+func (i *impersonationResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusCreated)
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("cache-control", "no-store")
+	return nil
+}
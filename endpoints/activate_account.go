@@ -28,47 +28,49 @@ var errAccountActivationQueryMalformed = errors.New("email or token query missin
 
 func ActivateAccount(
 	db database.UserAccountInterface,
+	audit database.AuditInterface,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		accountEmail := r.URL.Query().Get("email")
 		activationToken := r.URL.Query().Get("token")
 		if accountEmail == "" || activationToken == "" {
-			log.Debug().Msg("activating account endpoint called with insufficient queries")
+			log.Ctx(ctx).Debug().Msg("activating account endpoint called with insufficient queries")
 			render.Render(w, r, BadRequestError(errAccountActivationQueryMalformed))
 			return
 		}
 
 		activated, token, expiresIn, err := db.GetActivationData(ctx, accountEmail)
 		if err == database.ErrAccountNotFound {
-			log.Debug().Err(err).Msg("Resending activating email on an account that couldn't be found")
+			log.Ctx(ctx).Debug().Err(err).Msg("Resending activating email on an account that couldn't be found")
 			render.Render(w, r, UnauthorizedRequestError(errAccountNotFound))
 			return
 		}
 		if activated {
-			log.Debug().Err(err).Msg("Resending activating email on an account that's already activated")
+			log.Ctx(ctx).Debug().Err(err).Msg("Resending activating email on an account that's already activated")
 			render.Render(w, r, UnauthorizedRequestError(errAccountAlreadyActivated))
 			return
 		}
 		if activationToken == "" || expiresIn == nil {
-			log.Error().Str("account", accountEmail).Msg("trying to activate account returned an unexpected error")
+			log.Ctx(ctx).Error().Str("account", accountEmail).Msg("trying to activate account returned an unexpected error")
 			render.Render(w, r, InternalServerError())
 			return
 		}
 		if activationToken != token || expiresIn.Before(time.Now()) {
-			log.Debug().Err(err).Msg("trying to activate account failed")
+			log.Ctx(ctx).Debug().Err(err).Msg("trying to activate account failed")
 			render.Render(w, r, UnauthorizedRequestError(errAccountActivationFailed))
 			return
 		}
 
 		if err := db.ActivateAccount(ctx, accountEmail); err != nil {
-			log.Error().Err(err).Msg("trying to activate account returned an unexpected error")
+			log.Ctx(ctx).Error().Err(err).Msg("trying to activate account returned an unexpected error")
 			render.Render(w, r, InternalServerError())
 			return
 		}
+		recordAudit(r, audit, database.AuditAccountActivated, accountEmail, nil)
 
 		resp := activatedResponse{
-			Message: "account activated",
+			Message: localize(r, "account_activated"),
 			Email:   accountEmail,
 		}
 		render.Render(w, r, &resp)
@@ -24,7 +24,7 @@ func TestSuccessfulActivateAccount(t *testing.T) {
 		Return(nil)
 
 	w, r := mockRequest(t, path, nil, false)
-	handler := ActivateAccount(dbMock)
+	handler := ActivateAccount(dbMock, newAuditMock())
 	handler.ServeHTTP(w, r)
 
 	expResp := activatedResponse{
@@ -47,7 +47,7 @@ func TestMalformedActivateAccount(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 
 	w, r := mockRequest(t, path, nil, false)
-	handler := ActivateAccount(dbMock)
+	handler := ActivateAccount(dbMock, newAuditMock())
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode := BadRequestError(errAccountActivationQueryMalformed).(*ErrorResponse).
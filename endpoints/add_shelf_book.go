@@ -0,0 +1,54 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// AddShelfBook puts a book at the end of one of the account's shelves.
+// Adding it to the favorites shelf favorites it.
+func AddShelfBook(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("AddShelfBook: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelfID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Adding a book to a shelf with a malformed id")
+			render.Render(w, r, BadRequestError(errShelfIDMalformed))
+			return
+		}
+		bookID, err := uuid.Parse(chi.URLParam(r, "bookId"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Adding a book with a malformed id to a shelf")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		if err := db.AddShelfBook(ctx, shelfID, bookID, sch.Email); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while adding a book to a shelf")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
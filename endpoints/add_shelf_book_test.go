@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) AddShelfBook(ctx context.Context, shelfID uuid.UUID, bookID uuid.UUID, accountID string) error {
+	args := db.Called(shelfID, bookID, accountID)
+	return args.Error(0)
+}
+
+func TestSuccessfulAddShelfBook(t *testing.T) {
+	shelfID := uuid.New()
+	bookID := uuid.New()
+	path := "/shelves/" + shelfID.String() + "/books/" + bookID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("AddShelfBook", shelfID, bookID, expID.Account).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", shelfID.String()}, param{"bookId", bookID.String()})
+	handler := AddShelfBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful shelf addition didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedAddShelfBook(t *testing.T) {
+	shelfID := uuid.New()
+	missingShelfID := uuid.New()
+	bookID := uuid.New()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("AddShelfBook", shelfID, bookID, expID.Account).
+		Return(database.ErrBookNotFound).Once()
+	dbMock.On("AddShelfBook", missingShelfID, bookID, expID.Account).
+		Return(database.ErrShelfNotFound).Once()
+
+	tests := []struct {
+		name    string
+		shelfID string
+		bookID  string
+		expResp *ErrorResponse
+	}{
+		{"malformed", shelfID.String(), "abc", BadRequestError(errBookIDMalformed).(*ErrorResponse)},
+		{"bookless", shelfID.String(), bookID.String(), NotFoundError(errBookNotFound).(*ErrorResponse)},
+		{"shelfless", missingShelfID.String(), bookID.String(), NotFoundError(errShelfNotFound).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		path := "/shelves/" + test.shelfID + "/books/" + test.bookID
+		w, r := mockRequest(t, path, nil, true, param{"id", test.shelfID}, param{"bookId", test.bookID})
+		handler := AddShelfBook(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s shelf addition didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s shelf addition didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s shelf addition didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,92 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/emailhelper"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// accountAudit is the payload of audit events where staff act on an account.
+type accountAudit struct {
+	Account string `json:"account"`
+}
+
+// AdminActivateAccount activates an account for support staff, for users
+// whose activation email never arrived.
+func AdminActivateAccount(
+	db database.UserAccountInterface,
+	audit database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accountEmail := chi.URLParam(r, "email")
+
+		activated, _, _, err := db.GetActivationData(ctx, accountEmail)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting activation data")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		if activated {
+			render.Render(w, r, RequestConflictError(errAccountAlreadyActivated))
+			return
+		}
+
+		if err := db.ActivateAccount(ctx, accountEmail); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while activating an account")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		recordSessionAudit(r, audit, database.AuditAccountActivated, accountAudit{accountEmail})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// AdminResendActivation mails an account a new activation link for support
+// staff.
+func AdminResendActivation(
+	db database.UserAccountInterface,
+	audit database.AuditInterface,
+	email emailhelper.ActivationMailDriver,
+	activationDuration time.Duration,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accountEmail := chi.URLParam(r, "email")
+
+		activated, _, _, err := db.GetActivationData(ctx, accountEmail)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting activation data")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		if activated {
+			render.Render(w, r, RequestConflictError(errAccountAlreadyActivated))
+			return
+		}
+
+		if err := resendActivation(ctx, db, email, accountEmail, activationDuration); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Trying to resend activating email failed")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		recordSessionAudit(r, audit, database.AuditActivationResent, accountAudit{accountEmail})
+
+		resp := resendResponse{localize(r, "activation_email_resent")}
+		render.Render(w, r, &resp)
+	}
+}
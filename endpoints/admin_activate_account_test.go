@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulAdminActivateAccount(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetActivationData", mockAccountEmail).
+		Return(false, expID.AccountActivation, (*time.Time)(nil), nil).Once()
+	dbMock.On("ActivateAccount", mockAccountEmail).
+		Return(nil).Once()
+	auditMock := dBMock{&mock.Mock{}}
+	auditMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditAccountActivated && e.Actor == expID.Account && string(e.Payload) == `{"account":"someone@example.com"}`
+	})).Return(nil).Once()
+
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/activation", nil, true, param{"email", mockAccountEmail})
+	handler := AdminActivateAccount(dbMock, auditMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful manual activation didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func TestActivatedAdminActivateAccount(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetActivationData", mockAccountEmail).
+		Return(true, "", (*time.Time)(nil), nil).Once()
+
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/activation", nil, true, param{"email", mockAccountEmail})
+	handler := AdminActivateAccount(dbMock, dBMock{&mock.Mock{}})
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := RequestConflictError(errAccountAlreadyActivated).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "Manually activating an active account didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "Manually activating an active account didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "Manually activating an active account didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+var ErrAdminRoleRequired = errors.New("this action requires an admin account")
+var ErrSupportRoleRequired = errors.New("this action requires a support or admin account")
+var ErrImpersonationNotAllowed = errors.New("impersonation tokens can't be used for staff actions")
+
+// AdminAuthorizerMiddleware has to run after SessionAuthenticatorMiddleware.
+// The role is read from the database on each request rather than the token, so
+// demoting an admin takes effect immediately.
+func AdminAuthorizerMiddleware(
+	db database.UserAccountInterface,
+) func(next http.Handler) http.Handler {
+	return roleAuthorizerMiddleware(db, ErrAdminRoleRequired, database.RoleAdmin)
+}
+
+// SupportAuthorizerMiddleware lets support staff through as well as admins,
+// like AdminAuthorizerMiddleware.
+func SupportAuthorizerMiddleware(
+	db database.UserAccountInterface,
+) func(next http.Handler) http.Handler {
+	return roleAuthorizerMiddleware(db, ErrSupportRoleRequired, database.RoleSupport, database.RoleAdmin)
+}
+
+// roleAuthorizerMiddleware only lets accounts with one of roles through,
+// answering the others with errForbidden. Staff impersonating an account
+// never get through, whatever the account's role.
+func roleAuthorizerMiddleware(
+	db database.UserAccountInterface,
+	errForbidden error,
+	roles ...string,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			sch, err := sessiontoken.FromContext(ctx)
+			if err != nil || sch == nil {
+				log.Ctx(ctx).Debug().Err(err).Msg("Getting account for role check failed")
+				render.Render(w, r, UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid))
+				return
+			}
+			if sch.Impersonated() {
+				log.Ctx(ctx).Info().Str("account", sch.Email).Str("impersonator", sch.Actor.Email).Msg("Impersonation token tried a staff action")
+				render.Render(w, r, ForbiddenRequestError(ErrImpersonationNotAllowed))
+				return
+			}
+
+			role, err := db.GetAccountRole(ctx, sch.Email)
+			if err != nil {
+				if err == database.ErrAccountNotFound {
+					render.Render(w, r, UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid))
+					return
+				}
+				log.Ctx(ctx).Error().Err(err).Msg("Database error while getting account role")
+				render.Render(w, r, InternalServerError())
+				return
+			}
+			if !slices.Contains(roles, role) {
+				log.Ctx(ctx).Debug().Str("account", sch.Email).Str("role", role).Msg("Account without the role tried a staff action")
+				render.Render(w, r, ForbiddenRequestError(errForbidden))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
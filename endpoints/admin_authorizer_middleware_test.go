@@ -0,0 +1,97 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetAccountRole(ctx context.Context, email string) (string, error) {
+	args := db.Called(email)
+	return args.String(0), args.Error(1)
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+func TestSuccessfulAdminAuthorizer(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccountRole", expID.Account).
+		Return(database.RoleAdmin, nil).Once()
+
+	w, r := mockRequest(t, "/admin", nil, true)
+	AdminAuthorizerMiddleware(dbMock)(okHandler).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "An admin account didn't get through the admin authorizer")
+	dbMock.AssertExpectations(t)
+}
+
+func TestForbiddenAdminAuthorizer(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccountRole", expID.Account).
+		Return(database.RoleUser, nil).Once()
+
+	w, r := mockRequest(t, "/admin", nil, true)
+	AdminAuthorizerMiddleware(dbMock)(okHandler).ServeHTTP(w, r)
+
+	expResp, expCode := ForbiddenRequestError(ErrAdminRoleRequired).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A user account didn't get stopped by the admin authorizer")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A forbidden admin request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A forbidden admin request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+
+	w, r = mockRequest(t, "/admin", nil, false)
+	AdminAuthorizerMiddleware(dbMock)(okHandler).ServeHTTP(w, r)
+
+	expResp, expCode = UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid).(*ErrorResponse).sentForm()
+
+	resp = &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A tokenless request didn't get stopped by the admin authorizer")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A tokenless admin request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A tokenless admin request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestSupportAuthorizer(t *testing.T) {
+	for role, expCode := range map[string]int{
+		database.RoleSupport: http.StatusNoContent,
+		database.RoleAdmin:   http.StatusNoContent,
+		database.RoleUser:    http.StatusForbidden,
+	} {
+		dbMock := dBMock{&mock.Mock{}}
+		dbMock.On("GetAccountRole", expID.Account).
+			Return(role, nil).Once()
+
+		w, r := mockRequest(t, "/admin/users", nil, true)
+		SupportAuthorizerMiddleware(dbMock)(okHandler).ServeHTTP(w, r)
+
+		assert.Equal(t, expCode, w.Code, "A %s account wasn't authorized properly by the support authorizer", role)
+		dbMock.AssertExpectations(t)
+	}
+}
+
+func TestImpersonatedAdminAuthorizer(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := impersonatedRequest(t, http.MethodGet, "/admin/users")
+	AdminAuthorizerMiddleware(dbMock)(okHandler).ServeHTTP(w, r)
+
+	expResp, expCode := ForbiddenRequestError(ErrImpersonationNotAllowed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An impersonation token got through the admin authorizer")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An impersonated admin request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An impersonated admin request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,112 @@
+package endpoints
+
+import (
+	"encoding/base64"
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+)
+
+type AnnotationResponse struct {
+	ID        string `json:"id"`
+	BookID    string `json:"book_id"`
+	Kind      string `json:"kind"`
+	CFIRange  string `json:"cfi_range"`
+	Quote     string `json:"quote,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Note      string `json:"note,omitempty"`
+	Privacy   string `json:"privacy"`
+	OwnerName string `json:"owner_name"`
+	IsOwn     bool   `json:"is_own"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+
+	httpStatus int
+}
+
+func (a *AnnotationResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	status := a.httpStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	render.Status(r, status)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+// AnnotationFromDatabase builds the response for the account in accountID,
+// which decides is_own.
+func AnnotationFromDatabase(dAnnotation database.Annotation, accountID string) AnnotationResponse {
+	var a AnnotationResponse
+
+	a.ID = dAnnotation.ID.String()
+	a.BookID = dAnnotation.BookID.String()
+	a.Kind = dAnnotation.Kind
+	a.CFIRange = dAnnotation.CFIRange
+	a.Quote = dAnnotation.Quote
+	a.Color = dAnnotation.Color
+	a.Note = dAnnotation.Note
+	a.Privacy = dAnnotation.Privacy
+	a.OwnerName = dAnnotation.OwnerName
+	a.IsOwn = dAnnotation.AccountID == accountID
+	a.CreatedAt = dAnnotation.CreatedAt.UTC().Format(time.RFC3339Nano)
+	a.UpdatedAt = dAnnotation.UpdatedAt.UTC().Format(time.RFC3339Nano)
+
+	return a
+}
+
+type AnnotationsResponse struct {
+	Data       []AnnotationResponse `json:"data"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+func (a *AnnotationsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func AnnotationsFromDatabase(dAnnotations []database.Annotation, accountID string) AnnotationsResponse {
+	a := AnnotationsResponse{Data: []AnnotationResponse{}}
+
+	for _, dAnnotation := range dAnnotations {
+		a.Data = append(a.Data, AnnotationFromDatabase(dAnnotation, accountID))
+	}
+
+	return a
+}
+
+var errAnnotationCursorMalformed = errors.New("cursor malformed")
+
+// encodeAnnotationCursor makes the opaque cursor pointing after an
+// annotation.
+func encodeAnnotationCursor(annotation database.Annotation) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(
+		annotation.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + annotation.ID.String(),
+	))
+}
+
+func decodeAnnotationCursor(cursor string) (*database.AnnotationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errAnnotationCursorMalformed
+	}
+	createdAt, id, found := strings.Cut(string(raw), "|")
+	if !found {
+		return nil, errAnnotationCursorMalformed
+	}
+
+	c := database.AnnotationCursor{}
+	if c.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, errAnnotationCursorMalformed
+	}
+	if c.ID, err = uuid.Parse(id); err != nil {
+		return nil, errAnnotationCursorMalformed
+	}
+	return &c, nil
+}
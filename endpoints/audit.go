@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+// recordAudit appends an event about r to the audit log. Failing to record it
+// is logged rather than failing the request, which has already taken effect.
+func recordAudit(r *http.Request, audit database.AuditInterface, action string, actor string, payload any) {
+	ctx := r.Context()
+
+	event := database.AuditEvent{
+		Action:    action,
+		Actor:     actor,
+		IP:        r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		RequestID: middleware.GetReqID(ctx),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		event.IP = host
+	}
+	if payload != nil {
+		js, err := json.Marshal(payload)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("action", action).Msg("Encoding audit event payload failed")
+		}
+		event.Payload = js
+	}
+
+	// The event is kept even when the client is gone before it's written.
+	if err := audit.RecordAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("action", action).Str("actor", actor).Msg("Recording audit event failed")
+	}
+}
+
+// recordSessionAudit records an event acted by the account signed in to r.
+func recordSessionAudit(r *http.Request, audit database.AuditInterface, action string, payload any) {
+	var actor string
+	if sch, err := sessiontoken.FromContext(r.Context()); err == nil && sch != nil {
+		actor = sch.Email
+	}
+	recordAudit(r, audit, action, actor, payload)
+}
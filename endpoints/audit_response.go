@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+type AuditEventResponse struct {
+	ID         int64           `json:"id"`
+	OccurredAt string          `json:"occurred_at"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	Payload    json.RawMessage `json:"payload"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+func AuditEventFromDatabase(dEvent database.AuditEvent) AuditEventResponse {
+	return AuditEventResponse{
+		ID:         dEvent.ID,
+		OccurredAt: dEvent.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Action:     dEvent.Action,
+		Actor:      dEvent.Actor,
+		IP:         dEvent.IP,
+		UserAgent:  dEvent.UserAgent,
+		RequestID:  dEvent.RequestID,
+		Payload:    dEvent.Payload,
+		PrevHash:   dEvent.PrevHash,
+		Hash:       dEvent.Hash,
+	}
+}
+
+// csvRecord is the event as a row under auditCSVHeader.
+func (a AuditEventResponse) csvRecord() []string {
+	return []string{
+		strconv.FormatInt(a.ID, 10),
+		a.OccurredAt,
+		a.Action,
+		a.Actor,
+		a.IP,
+		a.UserAgent,
+		a.RequestID,
+		string(a.Payload),
+		a.PrevHash,
+		a.Hash,
+	}
+}
+
+var auditCSVHeader = []string{"id", "occurred_at", "action", "actor", "ip", "user_agent", "request_id", "payload", "prev_hash", "hash"}
+
+type AuditEventsResponse struct {
+	Data []AuditEventResponse `json:"data"`
+}
+
+func (a *AuditEventsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+type AuditVerificationResponse struct {
+	Intact   bool  `json:"intact"`
+	Checked  int64 `json:"checked"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
+
+func (a *AuditVerificationResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
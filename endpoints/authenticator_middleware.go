@@ -11,6 +11,7 @@ import (
 )
 
 var ErrSessionTokenMissingOrInvalid = errors.New("session token has expired or missing")
+var ErrImpersonationReadOnly = errors.New("impersonation tokens can only read")
 
 func SessionAuthenticatorMiddleware() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -22,24 +23,48 @@ func SessionAuthenticatorMiddleware() func(next http.Handler) http.Handler {
 			token, _, err := jwtauth.FromContext(ctx)
 
 			if err != nil {
-				log.Debug().Err(err).Msg("Getting the jwt token returned an error")
+				log.Ctx(ctx).Debug().Err(err).Msg("Getting the jwt token returned an error")
 				render.Render(w, r, UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid))
 				return
 			}
 
 			if token == nil {
-				log.Debug().Msg("Getting the jwt token returned null")
+				log.Ctx(ctx).Debug().Msg("Getting the jwt token returned null")
 				render.Render(w, r, UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid))
 				return
 			}
 
 			if err := sessiontoken.Validator(ctx, token); err != nil {
-				log.Debug().Err(err).Msg("Validating jwt token returned failure")
+				log.Ctx(ctx).Debug().Err(err).Msg("Validating jwt token returned failure")
 				render.Render(w, r, UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid))
 				return
 			}
 
+			sch, err := sessiontoken.FromContext(ctx)
+			if err != nil {
+				log.Ctx(ctx).Debug().Err(err).Msg("Getting the jwt token's claims returned an error")
+				render.Render(w, r, UnauthorizedRequestError(ErrSessionTokenMissingOrInvalid))
+				return
+			}
+			if sch.ReadOnly && !safeMethod(r.Method) {
+				log.Ctx(ctx).Info().Str("account", sch.Email).Str("method", r.Method).Msg("Read-only token tried to make a change")
+				render.Render(w, r, ForbiddenRequestError(ErrImpersonationReadOnly))
+				return
+			}
+			if sch.Impersonated() {
+				log.Ctx(ctx).Info().Str("account", sch.Email).Str("impersonator", sch.Actor.Email).Msg("Impersonated request")
+			}
+
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// safeMethod tells methods that only read, which read-only tokens can use.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
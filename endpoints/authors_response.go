@@ -0,0 +1,72 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+type BookAuthorResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func BookAuthorsFromDatabase(dAuthors database.BookAuthors) []BookAuthorResponse {
+	authors := make([]BookAuthorResponse, 0, len(dAuthors))
+	for _, dAuthor := range dAuthors {
+		authors = append(authors, BookAuthorResponse{
+			ID:   dAuthor.ID.String(),
+			Name: dAuthor.Name,
+			Role: dAuthor.Role,
+		})
+	}
+	return authors
+}
+
+type AuthorResponse struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	BookCount int            `json:"book_count"`
+	Books     []BookResponse `json:"books,omitempty"`
+}
+
+func (a *AuthorResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func AuthorFromDatabase(dAuthor database.Author, dBooks []database.Book) AuthorResponse {
+	var a AuthorResponse
+
+	a.ID = dAuthor.ID.String()
+	a.Name = dAuthor.Name
+	a.BookCount = dAuthor.BookCount
+	for _, dBook := range dBooks {
+		a.Books = append(a.Books, BookFromDatabase(dBook))
+	}
+
+	return a
+}
+
+type AuthorsResponse struct {
+	Data []AuthorResponse `json:"data"`
+}
+
+func (a *AuthorsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func AuthorsFromDatabase(dAuthors []database.Author) AuthorsResponse {
+	var a AuthorsResponse
+
+	for _, dAuthor := range dAuthors {
+		a.Data = append(a.Data, AuthorFromDatabase(dAuthor, nil))
+	}
+
+	return a
+}
@@ -8,14 +8,25 @@ import (
 )
 
 type BookResponse struct {
-	ID       string  `json:"id"`
-	Title    string  `json:"title"`
-	Author   string  `json:"author"`
-	CoverURL string  `json:"cover_url"`
-	Summary  string  `json:"summary"`
-	Readers  int     `json:"readers"`
-	Rating   float32 `json:"rating"`
-	IsFav    bool    `json:"is_favorite"`
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Author      string               `json:"author"`
+	Authors     []BookAuthorResponse `json:"authors"`
+	CoverURL    string               `json:"cover_url"`
+	Covers      map[string]string    `json:"covers,omitempty"`
+	Summary     string               `json:"summary"`
+	Readers     int                  `json:"readers"`
+	Rating      float32              `json:"rating"`
+	IsFav       bool                 `json:"is_favorite"`
+	ISBN        string               `json:"isbn,omitempty"`
+	Publisher   string               `json:"publisher,omitempty"`
+	PublishedOn string               `json:"publication_date,omitempty"`
+	Language    string               `json:"language,omitempty"`
+	PageCount   int                  `json:"page_count,omitempty"`
+	Edition     string               `json:"edition,omitempty"`
+	Format      string               `json:"format,omitempty"`
+	Genres      []BookGenreResponse  `json:"genres"`
+	Tags        []string             `json:"tags"`
 }
 
 func (b *BookResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -30,15 +41,45 @@ func BookFromDatabase(dBook database.Book) BookResponse {
 	b.ID = dBook.ID.String()
 	b.Title = dBook.Title
 	b.Author = dBook.Author
+	b.Authors = BookAuthorsFromDatabase(dBook.Authors)
 	b.Summary = dBook.Summary
 	b.IsFav = dBook.IsFav
 	b.CoverURL = dBook.Cover.String()
+	if dBook.CoverID.Valid {
+		b.Covers = CoverURLs(dBook.CoverID.UUID)
+		b.CoverURL = b.Covers["large"]
+	}
 	b.Rating = dBook.Rating
 	b.Readers = dBook.Readers
+	b.ISBN = dBook.ISBN
+	b.Publisher = dBook.Publisher
+	if dBook.PublishedOn != nil {
+		b.PublishedOn = dBook.PublishedOn.Format("2006-01-02")
+	}
+	b.Language = dBook.Language
+	b.PageCount = dBook.PageCount
+	b.Edition = dBook.Edition
+	b.Format = dBook.Format
+	b.Genres = BookGenresFromDatabase(dBook.Genres)
+	b.Tags = append([]string{}, dBook.Tags...)
 
 	return b
 }
 
+// BookDetailResponse is a book as shown on its own page, with the shelves of
+// the account it's on.
+type BookDetailResponse struct {
+	BookResponse
+	Shelves []BookShelfResponse `json:"shelves"`
+}
+
+func BookDetailFromDatabase(dBook database.Book) BookDetailResponse {
+	return BookDetailResponse{
+		BookResponse: BookFromDatabase(dBook),
+		Shelves:      BookShelvesFromDatabase(dBook.Shelves),
+	}
+}
+
 type BooksResponse struct {
 	Data []BookResponse `json:"data"`
 }
@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type createAnnotationRequest struct {
+	Kind     string `json:"kind"`
+	CFIRange string `json:"cfi_range"`
+	Quote    string `json:"quote"`
+	Color    string `json:"color"`
+	Note     string `json:"note"`
+	Privacy  string `json:"privacy"`
+}
+
+func (c *createAnnotationRequest) Bind(r *http.Request) error {
+	if c.Kind == "" || c.CFIRange == "" {
+		return errCreateAnnotationMalformed
+	}
+	return nil
+}
+
+var errCreateAnnotationMalformed = errors.New("kind or cfi_range missing")
+var errAnnotationIDMalformed = errors.New("annotation id malformed")
+var errAnnotationNotFound = errors.New("annotation not found")
+
+func CreateAnnotation(
+	db database.AnnotationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("CreateAnnotation: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Annotating a book with a malformed id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		data := &createAnnotationRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Creating annotation attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		annotation, err := db.CreateAnnotation(ctx, database.Annotation{
+			AccountID: sch.Email,
+			BookID:    bookID,
+			Kind:      data.Kind,
+			CFIRange:  data.CFIRange,
+			Quote:     data.Quote,
+			Color:     data.Color,
+			Note:      data.Note,
+			Privacy:   data.Privacy,
+		})
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while creating an annotation")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AnnotationFromDatabase(*annotation, sch.Email)
+		resp.httpStatus = http.StatusCreated
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,112 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) CreateAnnotation(ctx context.Context, annotation database.Annotation) (*database.Annotation, error) {
+	args := db.Called(annotation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Annotation), args.Error(1)
+}
+
+func mockAnnotation(bookID uuid.UUID) database.Annotation {
+	createdAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+	return database.Annotation{
+		ID:        uuid.New(),
+		AccountID: expID.Account,
+		OwnerName: "Reza",
+		BookID:    bookID,
+		Kind:      database.AnnotationHighlight,
+		CFIRange:  "epubcfi(/6/4!/4/2,/1:0,/1:20)",
+		Quote:     "It was a dark and stormy night",
+		Color:     "yellow",
+		Privacy:   database.AnnotationPrivate,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestSuccessfulCreateAnnotation(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/annotations"
+
+	expDBAnnotation := mockAnnotation(bookID)
+	req := createAnnotationRequest{
+		Kind:     expDBAnnotation.Kind,
+		CFIRange: expDBAnnotation.CFIRange,
+		Quote:    expDBAnnotation.Quote,
+		Color:    expDBAnnotation.Color,
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateAnnotation", database.Annotation{
+		AccountID: expID.Account,
+		BookID:    bookID,
+		Kind:      req.Kind,
+		CFIRange:  req.CFIRange,
+		Quote:     req.Quote,
+		Color:     req.Color,
+	}).Return(&expDBAnnotation, nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", bookID.String()})
+	handler := CreateAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AnnotationFromDatabase(expDBAnnotation, expID.Account)
+
+	resp := &AnnotationResponse{}
+	assert.Equal(t, http.StatusCreated, w.Code, "A successful annotation creation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful annotation creation didn't return a valid AnnotationResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful annotation creation didn't return a valid response")
+		assert.True(t, resp.IsOwn, "A successful annotation creation didn't mark the annotation as the account's own")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedCreateAnnotation(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/annotations"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateAnnotation", mock.MatchedBy(func(a database.Annotation) bool { return a.Color == "red" })).
+		Return(nil, database.ErrAnnotationColorInvalid).Once()
+	dbMock.On("CreateAnnotation", mock.MatchedBy(func(a database.Annotation) bool { return a.Color == "" })).
+		Return(nil, database.ErrBookNotFound).Once()
+
+	tests := []struct {
+		name    string
+		body    createAnnotationRequest
+		expResp *ErrorResponse
+	}{
+		{"anchorless", createAnnotationRequest{Kind: "bookmark"}, BadRequestError(errCreateAnnotationMalformed).(*ErrorResponse)},
+		{"red", createAnnotationRequest{Kind: "highlight", CFIRange: "epubcfi(/6/4)", Color: "red"}, ValidationFailedError(database.ErrAnnotationColorInvalid).(*ErrorResponse)},
+		{"bookless", createAnnotationRequest{Kind: "bookmark", CFIRange: "epubcfi(/6/4)"}, NotFoundError(errBookNotFound).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, path, test.body, true, param{"id", bookID.String()})
+		handler := CreateAnnotation(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s annotation creation didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s annotation creation didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s annotation creation didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
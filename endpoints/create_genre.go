@@ -0,0 +1,70 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type createGenreRequest struct {
+	Name     string     `json:"name"`
+	ParentID *uuid.UUID `json:"parent_id"`
+	BISAC    string     `json:"bisac_code"`
+	Dewey    string     `json:"dewey_code"`
+}
+
+func (c *createGenreRequest) Bind(r *http.Request) error {
+	if c.Name == "" {
+		return errCreateGenreMalformed
+	}
+	return nil
+}
+
+var errCreateGenreMalformed = errors.New("genre name missing")
+var errGenreParentNotFound = errors.New("parent genre not found")
+var errGenreCodeTaken = errors.New("another genre already has this code")
+
+func CreateGenre(
+	db database.GenreInterface,
+	audit database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		data := &createGenreRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Creating genre attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		genre := database.Genre{
+			Name:  data.Name,
+			BISAC: data.BISAC,
+			Dewey: data.Dewey,
+		}
+		if data.ParentID != nil {
+			genre.ParentID = uuid.NullUUID{UUID: *data.ParentID, Valid: true}
+		}
+
+		created, err := db.CreateGenre(ctx, genre)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while creating a genre")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := GenreFromDatabase(*created)
+		recordSessionAudit(r, audit, database.AuditGenreCreated, resp)
+		resp.httpStatus = http.StatusCreated
+		render.Render(w, r, &resp)
+	}
+}
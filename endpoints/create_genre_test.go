@@ -0,0 +1,92 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) CreateGenre(ctx context.Context, genre database.Genre) (*database.Genre, error) {
+	args := db.Called(genre)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Genre), args.Error(1)
+}
+
+func TestSuccessfulCreateGenre(t *testing.T) {
+	path := "/admin/genres"
+
+	parentID := uuid.New()
+	req := createGenreRequest{Name: "Fantasy", ParentID: &parentID, BISAC: "FIC009000"}
+	genre := database.Genre{Name: req.Name, ParentID: uuid.NullUUID{UUID: parentID, Valid: true}, BISAC: req.BISAC}
+	created := genre
+	created.ID = uuid.New()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateGenre", genre).
+		Return(&created, nil).Once()
+
+	w, r := mockRequest(t, path, req, true)
+	handler := CreateGenre(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	expResp := GenreFromDatabase(created)
+	expCode := http.StatusCreated
+
+	resp := &GenreResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful genre creation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful genre creation didn't return a valid GenreResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful genre creation didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestConflictCreateGenre(t *testing.T) {
+	path := "/admin/genres"
+
+	req := createGenreRequest{Name: "Fantasy", BISAC: "FIC009000"}
+	genre := database.Genre{Name: req.Name, BISAC: req.BISAC}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateGenre", genre).
+		Return(nil, database.ErrGenreCodeTaken).Once()
+
+	w, r := mockRequest(t, path, req, true)
+	handler := CreateGenre(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := RequestConflictError(errGenreCodeTaken).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A conflicting genre creation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A conflicting genre creation didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A conflicting genre creation didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedCreateGenre(t *testing.T) {
+	path := "/admin/genres"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, createGenreRequest{BISAC: "FIC009000"}, true)
+	handler := CreateGenre(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errCreateGenreMalformed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed genre creation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed genre creation didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A malformed genre creation didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
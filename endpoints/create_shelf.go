@@ -0,0 +1,70 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+type createShelfRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
+func (c *createShelfRequest) Bind(r *http.Request) error {
+	if c.Name == "" {
+		return errCreateShelfMalformed
+	}
+	return nil
+}
+
+var errCreateShelfMalformed = errors.New("name missing")
+
+// CreateShelf makes a custom shelf, placed after the account's other shelves.
+func CreateShelf(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("CreateShelf: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		data := &createShelfRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Creating shelf attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		shelf, err := db.CreateShelf(ctx, database.Shelf{
+			AccountID:   sch.Email,
+			Kind:        database.ShelfCustom,
+			Name:        data.Name,
+			Description: data.Description,
+			Visibility:  data.Visibility,
+		})
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while creating a shelf")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ShelfFromDatabase(*shelf, sch.Email)
+		resp.httpStatus = http.StatusCreated
+		render.Render(w, r, &resp)
+	}
+}
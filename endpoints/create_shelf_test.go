@@ -0,0 +1,81 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) CreateShelf(ctx context.Context, shelf database.Shelf) (*database.Shelf, error) {
+	args := db.Called(shelf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Shelf), args.Error(1)
+}
+
+func TestSuccessfulCreateShelf(t *testing.T) {
+	path := "/me/shelves"
+
+	expDBShelf := mockShelf(database.ShelfCustom, "Summer reads")
+	expDBShelf.Description = "Light ones"
+	req := createShelfRequest{Name: expDBShelf.Name, Description: expDBShelf.Description}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateShelf", database.Shelf{
+		AccountID:   expID.Account,
+		Kind:        database.ShelfCustom,
+		Name:        req.Name,
+		Description: req.Description,
+	}).Return(&expDBShelf, nil).Once()
+
+	w, r := mockRequest(t, path, req, true)
+	handler := CreateShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ShelfFromDatabase(expDBShelf, expID.Account)
+
+	resp := &ShelfResponse{}
+	assert.Equal(t, http.StatusCreated, w.Code, "A successful shelf creation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful shelf creation didn't return a valid ShelfResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful shelf creation didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedCreateShelf(t *testing.T) {
+	path := "/me/shelves"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateShelf", mock.MatchedBy(func(s database.Shelf) bool { return s.Visibility == "shared" })).
+		Return(nil, database.ErrShelfVisibilityInvalid).Once()
+
+	tests := []struct {
+		name    string
+		body    createShelfRequest
+		expResp *ErrorResponse
+	}{
+		{"nameless", createShelfRequest{Description: "Light ones"}, BadRequestError(errCreateShelfMalformed).(*ErrorResponse)},
+		{"shared", createShelfRequest{Name: "Summer reads", Visibility: "shared"}, ValidationFailedError(database.ErrShelfVisibilityInvalid).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, path, test.body, true)
+		handler := CreateShelf(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s shelf creation didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s shelf creation didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s shelf creation didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+func DeleteAnnotation(
+	db database.AnnotationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("DeleteAnnotation: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		annotationID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Deleting an annotation with a malformed id")
+			render.Render(w, r, BadRequestError(errAnnotationIDMalformed))
+			return
+		}
+
+		if err := db.DeleteAnnotation(ctx, annotationID, sch.Email); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while deleting an annotation")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) DeleteAnnotation(ctx context.Context, annotationID uuid.UUID, accountID string) error {
+	args := db.Called(annotationID, accountID)
+	return args.Error(0)
+}
+
+func TestSuccessfulDeleteAnnotation(t *testing.T) {
+	annotationID := uuid.New()
+	path := "/annotations/" + annotationID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("DeleteAnnotation", annotationID, expID.Account).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", annotationID.String()})
+	handler := DeleteAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful annotation deletion didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundDeleteAnnotation(t *testing.T) {
+	annotationID := uuid.New()
+	path := "/annotations/" + annotationID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("DeleteAnnotation", annotationID, expID.Account).
+		Return(database.ErrAnnotationNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", annotationID.String()})
+	handler := DeleteAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errAnnotationNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing annotation deletion didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing annotation deletion didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing annotation deletion didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,48 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// DeleteShelf deletes one of the account's custom shelves. The books on it
+// stay in the library.
+func DeleteShelf(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("DeleteShelf: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelfID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Deleting a shelf with a malformed id")
+			render.Render(w, r, BadRequestError(errShelfIDMalformed))
+			return
+		}
+
+		if err := db.DeleteShelf(ctx, shelfID, sch.Email); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while deleting a shelf")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
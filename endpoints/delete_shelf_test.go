@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) DeleteShelf(ctx context.Context, shelfID uuid.UUID, accountID string) error {
+	args := db.Called(shelfID, accountID)
+	return args.Error(0)
+}
+
+func TestSuccessfulDeleteShelf(t *testing.T) {
+	shelfID := uuid.New()
+	path := "/shelves/" + shelfID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("DeleteShelf", shelfID, expID.Account).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", shelfID.String()})
+	handler := DeleteShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful shelf deletion didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestBuiltInDeleteShelf(t *testing.T) {
+	shelfID := uuid.New()
+	path := "/shelves/" + shelfID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("DeleteShelf", shelfID, expID.Account).
+		Return(database.ErrShelfBuiltIn).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", shelfID.String()})
+	handler := DeleteShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := RequestConflictError(database.ErrShelfBuiltIn).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A built-in shelf deletion didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A built-in shelf deletion didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A built-in shelf deletion didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"ic-rhadi/e_library/googlehelper"
+	"ic-rhadi/e_library/moderationhelper"
 	"ic-rhadi/e_library/sessiontoken"
 	"io"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/jwtauth"
+	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/jwt"
 	"github.com/stretchr/testify/mock"
 )
@@ -129,11 +131,57 @@ func (g gTokenValidatorMock) ValidateGToken(ctx context.Context, token string) (
 	return args.Get(0).(*googlehelper.GoogleClaimsSchema), args.Error(1)
 }
 
+func (g gTokenValidatorMock) CheckJWKS(ctx context.Context) error {
+	return g.Called().Error(0)
+}
+
 type activationMailDriverMock struct {
 	*mock.Mock
 }
 
-func (mail activationMailDriverMock) SendActivationEmail(email string, activationToken string, validUntil time.Time) error {
+func (mail activationMailDriverMock) SendActivationEmail(ctx context.Context, email string, activationToken string, validUntil time.Time) error {
 	args := mail.Called(email, activationToken, validUntil)
 	return args.Error(0)
 }
+
+func (mail activationMailDriverMock) CheckConnection(ctx context.Context) error {
+	return mail.Called().Error(0)
+}
+
+type coverStorageMock struct {
+	*mock.Mock
+}
+
+func (c coverStorageMock) StoreCover(ctx context.Context, image io.Reader) (uuid.UUID, error) {
+	args := c.Called()
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (c coverStorageMock) OpenCover(ctx context.Context, coverID uuid.UUID, size string, format string) (io.ReadSeekCloser, time.Time, error) {
+	args := c.Called(coverID, size, format)
+	if args.Get(0) == nil {
+		return nil, time.Time{}, args.Error(2)
+	}
+	return args.Get(0).(io.ReadSeekCloser), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (c coverStorageMock) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	args := c.Called(coverID)
+	return args.Error(0)
+}
+
+type contentFilterMock struct {
+	*mock.Mock
+}
+
+func (f contentFilterMock) CheckContent(ctx context.Context, text string) (moderationhelper.Verdict, error) {
+	args := f.Called(text)
+	return args.Get(0).(moderationhelper.Verdict), args.Error(1)
+}
+
+// newAuditMock takes any audit event, for tests that don't look at them.
+func newAuditMock() dBMock {
+	auditMock := dBMock{&mock.Mock{}}
+	auditMock.On("RecordAuditEvent", mock.Anything).Return(nil).Maybe()
+	return auditMock
+}
@@ -0,0 +1,285 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/coverhelper"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/render"
+)
+
+// errorCodes gives every error a client can receive a stable code, so clients
+// never have to match on the human-readable message. Errors with the same
+// meaning share a code wherever they come from.
+var errorCodes = map[error]string{
+	errInternal: "internal_error",
+
+	ErrSessionTokenMissingOrInvalid: "session_token_invalid",
+	ErrAdminRoleRequired:            "admin_role_required",
+	ErrSupportRoleRequired:          "support_role_required",
+	ErrImpersonationNotAllowed:      "impersonation_not_allowed",
+	ErrImpersonationReadOnly:        "impersonation_read_only",
+
+	ErrLoginAccountNotActive:           "account_not_active",
+	ErrLoginAccountDisabled:            "account_disabled",
+	ErrLoginFailed:                     "login_failed",
+	ErrLoginPostMalformed:              "login_malformed",
+	ErrLoginRateLimited:                "login_rate_limited",
+	errAccountLanguageMalformed:        "language_missing",
+	errAccountLanguageUnsupported:      "language_unsupported",
+	ErrLoginGoogleMalformed:            "google_token_missing",
+	errRegisterGoogleMalformed:         "google_token_missing",
+	errGoogleTokenFailed:               "google_token_invalid",
+	errRegisterPostMalformed:           "register_malformed",
+	errEmailMalformed:                  "email_malformed",
+	errPasswordTooShort:                "password_too_short",
+	errPasswordTooLong:                 "password_too_long",
+	errPasswordDontHaveNumber:          "password_missing_number",
+	errPasswordDontHaveUppercase:       "password_missing_uppercase",
+	errPasswordDontHaveSpecials:        "password_missing_special",
+	errAccountAlreadyRegistered:        "account_already_registered",
+	errAccountNotFound:                 "account_not_found",
+	errAccountAlreadyActivated:         "account_already_activated",
+	errAccountActivationFailed:         "account_activation_failed",
+	errAccountActivationQueryMalformed: "activation_query_malformed",
+	errResendActivationEmailMalformed:  "activation_query_malformed",
+	errRefreshTokenMalformed:           "refresh_token_missing",
+	errRefreshTokenInvalid:             "refresh_token_invalid",
+	errRefreshTokenExpired:             "refresh_token_expired",
+
+	errBookIDMalformed:              "book_id_malformed",
+	errBookNotFound:                 "book_not_found",
+	database.ErrBookNotFound:        "book_not_found",
+	errListBookCriteriaUnrecognized: "book_criteria_unrecognized",
+	errPopularityWindowUnrecognized: "popularity_window_unrecognized",
+	errOPDSSearchQueryMissing:       "search_query_missing",
+	errBookClassificationMalformed:  "book_classification_malformed",
+	errCoverNotFound:                "cover_not_found",
+
+	coverhelper.ErrCoverTooLarge:          "cover_too_large",
+	coverhelper.ErrCoverDimensionTooLarge: "cover_dimension_too_large",
+	coverhelper.ErrCoverTypeUnsupported:   "cover_type_unsupported",
+
+	errAuthorIDMalformed:       "author_id_malformed",
+	errAuthorNotFound:          "author_not_found",
+	database.ErrAuthorNotFound: "author_not_found",
+
+	errGenreIDMalformed:             "genre_id_malformed",
+	errGenreNotFound:                "genre_not_found",
+	database.ErrGenreNotFound:       "genre_not_found",
+	errCreateGenreMalformed:         "genre_name_missing",
+	database.ErrGenreNameMissing:    "genre_name_missing",
+	errGenreParentNotFound:          "genre_parent_not_found",
+	database.ErrGenreParentNotFound: "genre_parent_not_found",
+	errGenreCodeTaken:               "genre_code_taken",
+	database.ErrGenreCodeTaken:      "genre_code_taken",
+	database.ErrGenreBISACInvalid:   "genre_bisac_invalid",
+	database.ErrGenreDeweyInvalid:   "genre_dewey_invalid",
+	database.ErrTagInvalid:          "tag_invalid",
+	database.ErrTooManyTags:         "too_many_tags",
+
+	errCatalogFormatUnrecognized:  "catalog_format_unrecognized",
+	errCatalogTooLarge:            "catalog_too_large",
+	errImportJobNotFound:          "import_job_not_found",
+	database.ErrImportJobNotFound: "import_job_not_found",
+
+	errReadingProgressMalformed:          "reading_progress_malformed",
+	database.ErrReadingLocatorInvalid:    "reading_locator_invalid",
+	database.ErrReadingPercentageInvalid: "reading_percentage_invalid",
+	database.ErrReadingDeviceMissing:     "reading_device_missing",
+	database.ErrReadingTimeMissing:       "reading_time_missing",
+	database.ErrReadingTimeInvalid:       "reading_time_invalid",
+
+	errShelfIDMalformed:                 "shelf_id_malformed",
+	errShelfNotFound:                    "shelf_not_found",
+	database.ErrShelfNotFound:           "shelf_not_found",
+	errCreateShelfMalformed:             "shelf_name_missing",
+	errReorderShelfMalformed:            "shelf_order_missing",
+	errReorderShelfTooLong:              "shelf_order_too_long",
+	database.ErrShelfNameInvalid:        "shelf_name_invalid",
+	database.ErrShelfDescriptionInvalid: "shelf_description_invalid",
+	database.ErrShelfVisibilityInvalid:  "shelf_visibility_invalid",
+	database.ErrShelfBuiltIn:            "shelf_built_in",
+
+	errCreateAnnotationMalformed:          "annotation_malformed",
+	errAnnotationIDMalformed:              "annotation_id_malformed",
+	errAnnotationNotFound:                 "annotation_not_found",
+	database.ErrAnnotationNotFound:        "annotation_not_found",
+	errAnnotationLimitInvalid:             "annotation_limit_invalid",
+	errAnnotationCursorMalformed:          "annotation_cursor_malformed",
+	errAnnotationExportFormatUnrecognized: "annotation_export_format_unrecognized",
+	database.ErrAnnotationKindInvalid:     "annotation_kind_invalid",
+	database.ErrAnnotationRangeInvalid:    "annotation_range_invalid",
+	database.ErrAnnotationColorInvalid:    "annotation_color_invalid",
+	database.ErrAnnotationNoteInvalid:     "annotation_note_invalid",
+	database.ErrAnnotationNoteMissing:     "annotation_note_missing",
+	database.ErrAnnotationPrivacyInvalid:  "annotation_privacy_invalid",
+
+	errReviewIDMalformed:            "review_id_malformed",
+	errReviewNotFound:               "review_not_found",
+	database.ErrReviewNotFound:      "review_not_found",
+	errReviewSortUnrecognized:       "review_sort_unrecognized",
+	errSaveReviewMalformed:          "review_rating_missing",
+	errVoteReviewMalformed:          "review_vote_missing",
+	errReportReviewMalformed:        "review_report_reason_missing",
+	errModerateReviewMalformed:      "review_action_missing",
+	database.ErrReviewRatingInvalid: "review_rating_invalid",
+	database.ErrReviewBodyInvalid:   "review_body_invalid",
+	database.ErrReviewReasonInvalid: "review_report_reason_invalid",
+	database.ErrReviewOwn:           "review_own",
+	database.ErrReviewActionUnknown: "review_action_unknown",
+
+	errAuditActionUnrecognized: "audit_action_unrecognized",
+	errAuditTimeMalformed:      "audit_time_malformed",
+	errAuditFormatUnrecognized: "audit_format_unrecognized",
+
+	database.ErrAccountNotFound:            "account_not_found",
+	errAccountStatusMalformed:              "account_status_missing",
+	errAccountStatusOwn:                    "account_status_own",
+	database.ErrAccountStatusInvalid:       "account_status_invalid",
+	database.ErrAccountStatusReasonMissing: "account_status_reason_missing",
+	database.ErrAccountStatusReasonInvalid: "account_status_reason_invalid",
+	errImpersonateMalformed:                "impersonation_reason_missing",
+	errImpersonateStaff:                    "impersonation_staff",
+}
+
+// statusCodes are the codes of errors missing from errorCodes, such as the
+// decoding errors render.Bind returns for malformed bodies.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusUnprocessableEntity:   "validation_failed",
+	http.StatusInternalServerError:   "internal_error",
+}
+
+// codeFieldsInvalid is the code of errors that carry per-field errors.
+const codeFieldsInvalid = "fields_invalid"
+
+func errorCode(status int, err error) string {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if _, ok := e.(fieldErrors); ok {
+			return codeFieldsInvalid
+		}
+		if !reflect.TypeOf(e).Comparable() {
+			continue
+		}
+		if code, ok := errorCodes[e]; ok {
+			return code
+		}
+	}
+	return statusCodes[status]
+}
+
+// errorStatus is how a database or helper error is answered: with which status
+// and, when set, which client-facing error in its place.
+type errorStatus struct {
+	status int
+	err    error
+}
+
+// errorStatuses maps the errors every handler answers the same way, in place
+// of a switch block in each of them. Handlers check the errors whose meaning
+// depends on the endpoint, like an unknown account on login with
+// loginErrorResponse, before falling back to errorResponse.
+var errorStatuses = map[error]errorStatus{
+	database.ErrBookNotFound:        {http.StatusNotFound, errBookNotFound},
+	database.ErrAuthorNotFound:      {http.StatusNotFound, errAuthorNotFound},
+	database.ErrGenreNotFound:       {http.StatusNotFound, errGenreNotFound},
+	database.ErrImportJobNotFound:   {http.StatusNotFound, errImportJobNotFound},
+	database.ErrShelfNotFound:       {http.StatusNotFound, errShelfNotFound},
+	database.ErrAnnotationNotFound:  {http.StatusNotFound, errAnnotationNotFound},
+	database.ErrReviewNotFound:      {http.StatusNotFound, errReviewNotFound},
+	database.ErrAccountNotFound:     {http.StatusNotFound, errAccountNotFound},
+	database.ErrAccountExisted:      {http.StatusConflict, errAccountAlreadyRegistered},
+	database.ErrGenreCodeTaken:      {http.StatusConflict, errGenreCodeTaken},
+	database.ErrShelfBuiltIn:        {http.StatusConflict, nil},
+	database.ErrReviewOwn:           {http.StatusConflict, nil},
+	database.ErrGenreParentNotFound: {http.StatusUnprocessableEntity, errGenreParentNotFound},
+
+	database.ErrGenreNameMissing:  {http.StatusUnprocessableEntity, nil},
+	database.ErrGenreBISACInvalid: {http.StatusUnprocessableEntity, nil},
+	database.ErrGenreDeweyInvalid: {http.StatusUnprocessableEntity, nil},
+	database.ErrTagInvalid:        {http.StatusUnprocessableEntity, nil},
+	database.ErrTooManyTags:       {http.StatusUnprocessableEntity, nil},
+
+	database.ErrReadingLocatorInvalid:    {http.StatusUnprocessableEntity, nil},
+	database.ErrReadingPercentageInvalid: {http.StatusUnprocessableEntity, nil},
+	database.ErrReadingDeviceMissing:     {http.StatusUnprocessableEntity, nil},
+	database.ErrReadingTimeMissing:       {http.StatusUnprocessableEntity, nil},
+	database.ErrReadingTimeInvalid:       {http.StatusUnprocessableEntity, nil},
+
+	database.ErrShelfNameInvalid:        {http.StatusUnprocessableEntity, nil},
+	database.ErrShelfDescriptionInvalid: {http.StatusUnprocessableEntity, nil},
+	database.ErrShelfVisibilityInvalid:  {http.StatusUnprocessableEntity, nil},
+
+	database.ErrAnnotationKindInvalid:    {http.StatusUnprocessableEntity, nil},
+	database.ErrAnnotationRangeInvalid:   {http.StatusUnprocessableEntity, nil},
+	database.ErrAnnotationColorInvalid:   {http.StatusUnprocessableEntity, nil},
+	database.ErrAnnotationNoteInvalid:    {http.StatusUnprocessableEntity, nil},
+	database.ErrAnnotationNoteMissing:    {http.StatusUnprocessableEntity, nil},
+	database.ErrAnnotationPrivacyInvalid: {http.StatusUnprocessableEntity, nil},
+
+	database.ErrReviewRatingInvalid: {http.StatusUnprocessableEntity, nil},
+	database.ErrReviewBodyInvalid:   {http.StatusUnprocessableEntity, nil},
+	database.ErrReviewReasonInvalid: {http.StatusUnprocessableEntity, nil},
+	database.ErrReviewActionUnknown: {http.StatusUnprocessableEntity, nil},
+
+	database.ErrAccountStatusInvalid:       {http.StatusUnprocessableEntity, nil},
+	database.ErrAccountStatusReasonMissing: {http.StatusUnprocessableEntity, nil},
+	database.ErrAccountStatusReasonInvalid: {http.StatusUnprocessableEntity, nil},
+
+	coverhelper.ErrCoverNotFound:          {http.StatusNotFound, errCoverNotFound},
+	coverhelper.ErrCoverTooLarge:          {http.StatusRequestEntityTooLarge, nil},
+	coverhelper.ErrCoverDimensionTooLarge: {http.StatusRequestEntityTooLarge, nil},
+	coverhelper.ErrCoverTypeUnsupported:   {http.StatusUnsupportedMediaType, nil},
+}
+
+// errorResponse returns the response for err, or nil when err isn't one the
+// clients are told about and should be logged and answered with
+// InternalServerError.
+func errorResponse(err error) render.Renderer {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if !reflect.TypeOf(e).Comparable() {
+			continue
+		}
+		if mapped, ok := errorStatuses[e]; ok {
+			if mapped.err == nil {
+				return newErrorResponse(mapped.status, e)
+			}
+			return newErrorResponse(mapped.status, mapped.err)
+		}
+	}
+	return nil
+}
+
+// loginErrorStatuses answer the failed sign ins the same way whatever the
+// method. An unknown account is answered like a wrong password, so the
+// response doesn't tell which accounts exist.
+var loginErrorStatuses = map[error]errorStatus{
+	database.ErrAccountNotActive: {http.StatusUnauthorized, ErrLoginAccountNotActive},
+	database.ErrAccountDisabled:  {http.StatusForbidden, ErrLoginAccountDisabled},
+	database.ErrAccountNotFound:  {http.StatusUnprocessableEntity, ErrLoginFailed},
+	database.ErrWrongPass:        {http.StatusUnprocessableEntity, ErrLoginFailed},
+	database.ErrWrongID:          {http.StatusUnprocessableEntity, ErrLoginFailed},
+}
+
+// loginErrorResponse returns the response for a failed sign in, or nil when
+// err isn't one. Its Code is the reason the failure is audited with.
+func loginErrorResponse(err error) *ErrorResponse {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if !reflect.TypeOf(e).Comparable() {
+			continue
+		}
+		if mapped, ok := loginErrorStatuses[e]; ok {
+			return newErrorResponse(mapped.status, mapped.err)
+		}
+	}
+	return nil
+}
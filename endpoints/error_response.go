@@ -1,59 +1,222 @@
 package endpoints
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"sort"
+	"strings"
 
+	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
+	"golang.org/x/text/language"
 )
 
 type ErrorResponse struct {
-	httpStatusCode int    `json:"-"`
-	ErrorType      string `json:"error_type"`
-	Message        string `json:"message"`
+	httpStatusCode int                             `json:"-"`
+	ErrorType      string                          `json:"error_type"`
+	Code           string                          `json:"code"`
+	Message        string                          `json:"message"`
+	Fields         map[string][]FieldErrorResponse `json:"fields,omitempty"`
+	RequestID      string                          `json:"request_id,omitempty"`
+}
+
+// FieldErrorResponse is one of the reasons a request field was rejected.
+type FieldErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 func (er *ErrorResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	lang := requestLanguage(r)
+	er.localize(lang)
+	er.RequestID = middleware.GetReqID(r.Context())
 	render.Status(r, er.httpStatusCode)
 	w.Header().Set("content-type", "application/json")
+	w.Header().Set("content-language", lang.String())
 	return nil
 }
 
-func BadRequestError(err error) render.Renderer {
-	return &ErrorResponse{
-		httpStatusCode: http.StatusBadRequest,
-		ErrorType:      "Bad Request",
-		Message:        err.Error(),
+// localize swaps the messages for their translation in lang. Messages whose
+// code has no catalog entry, like JSON decoding errors, are sent as they are.
+func (er *ErrorResponse) localize(lang language.Tag) {
+	if msg, ok := translate(lang, er.Code); ok {
+		er.Message = msg
+	}
+	if len(er.Fields) == 0 {
+		return
+	}
+
+	fields := make([]string, 0, len(er.Fields))
+	for field := range er.Fields {
+		fields = append(fields, field)
 	}
+	sort.Strings(fields)
+
+	var msgs []string
+	for _, field := range fields {
+		for i, fe := range er.Fields[field] {
+			if msg, ok := translate(lang, fe.Code); ok {
+				er.Fields[field][i].Message = msg
+			}
+			msgs = append(msgs, er.Fields[field][i].Message)
+		}
+	}
+	er.Message = strings.Join(msgs, "; ")
 }
 
-func RequestConflictError(err error) render.Renderer {
-	return &ErrorResponse{
-		httpStatusCode: http.StatusConflict,
-		ErrorType:      "Conflict",
-		Message:        err.Error(),
+// fieldErrors collects every rule a request broke, keyed by the JSON field,
+// so clients can show all of them at once instead of one per attempt.
+type fieldErrors map[string][]error
+
+func (fe fieldErrors) add(field string, err error) {
+	fe[field] = append(fe[field], err)
+}
+
+func (fe fieldErrors) fields() []string {
+	fields := make([]string, 0, len(fe))
+	for field := range fe {
+		fields = append(fields, field)
 	}
+	sort.Strings(fields)
+	return fields
 }
 
-func UnauthorizedRequestError(err error) render.Renderer {
-	return &ErrorResponse{
-		httpStatusCode: http.StatusUnauthorized,
-		ErrorType:      "Unauthorized Request",
-		Message:        err.Error(),
+func (fe fieldErrors) Error() string {
+	var msgs []string
+	for _, field := range fe.fields() {
+		for _, err := range fe[field] {
+			msgs = append(msgs, err.Error())
+		}
 	}
+	return strings.Join(msgs, "; ")
 }
 
-func ValidationFailedError(err error) render.Renderer {
-	return &ErrorResponse{
-		httpStatusCode: http.StatusUnprocessableEntity,
-		ErrorType:      "Validation Failed",
+// orNil lets Bind return a fieldErrors only when something was added to it.
+func (fe fieldErrors) orNil() error {
+	if len(fe) == 0 {
+		return nil
+	}
+	return fe
+}
+
+var errorTypes = map[int]string{
+	http.StatusBadRequest:            "Bad Request",
+	http.StatusUnauthorized:          "Unauthorized Request",
+	http.StatusForbidden:             "Forbidden Request",
+	http.StatusNotFound:              "Not Found",
+	http.StatusConflict:              "Conflict",
+	http.StatusRequestEntityTooLarge: "Payload Too Large",
+	http.StatusUnsupportedMediaType:  "Unsupported Media Type",
+	http.StatusUnprocessableEntity:   "Validation Failed",
+	http.StatusTooManyRequests:       "Too Many Requests",
+	http.StatusInternalServerError:   "Internal Server Error",
+}
+
+func newErrorResponse(status int, err error) *ErrorResponse {
+	er := &ErrorResponse{
+		httpStatusCode: status,
+		ErrorType:      errorTypes[status],
+		Code:           errorCode(status, err),
 		Message:        err.Error(),
 	}
+
+	var fe fieldErrors
+	if errors.As(err, &fe) {
+		er.Fields = make(map[string][]FieldErrorResponse, len(fe))
+		for field, errs := range fe {
+			for _, err := range errs {
+				er.Fields[field] = append(er.Fields[field], FieldErrorResponse{
+					Code:    errorCode(status, err),
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+	return er
+}
+
+func BadRequestError(err error) render.Renderer {
+	return newErrorResponse(http.StatusBadRequest, err)
+}
+
+func RequestConflictError(err error) render.Renderer {
+	return newErrorResponse(http.StatusConflict, err)
+}
+
+func UnauthorizedRequestError(err error) render.Renderer {
+	return newErrorResponse(http.StatusUnauthorized, err)
+}
+
+func ForbiddenRequestError(err error) render.Renderer {
+	return newErrorResponse(http.StatusForbidden, err)
+}
+
+func NotFoundError(err error) render.Renderer {
+	return newErrorResponse(http.StatusNotFound, err)
+}
+
+func PayloadTooLargeError(err error) render.Renderer {
+	return newErrorResponse(http.StatusRequestEntityTooLarge, err)
+}
+
+func UnsupportedMediaTypeError(err error) render.Renderer {
+	return newErrorResponse(http.StatusUnsupportedMediaType, err)
+}
+
+func ValidationFailedError(err error) render.Renderer {
+	return newErrorResponse(http.StatusUnprocessableEntity, err)
 }
 
 func InternalServerError() render.Renderer {
-	return &ErrorResponse{
-		httpStatusCode: http.StatusInternalServerError,
-		ErrorType:      "Internal Server Error",
-		Message:        "something went wrong",
+	return newErrorResponse(http.StatusInternalServerError, errInternal)
+}
+
+var errInternal = errors.New("something went wrong")
+
+const problemContentType = "application/problem+json"
+
+// problemDetails is an ErrorResponse in the RFC 7807 form, sent to clients
+// that accept application/problem+json.
+type problemDetails struct {
+	Type      string                          `json:"type"`
+	Title     string                          `json:"title"`
+	Status    int                             `json:"status"`
+	Detail    string                          `json:"detail"`
+	Instance  string                          `json:"instance"`
+	Code      string                          `json:"code"`
+	Fields    map[string][]FieldErrorResponse `json:"fields,omitempty"`
+	RequestID string                          `json:"request_id,omitempty"`
+}
+
+func init() {
+	render.Respond = respondProblem
+}
+
+// respondProblem sends error responses as problem details when the client
+// asks for them, and leaves everything else to render's default responder.
+func respondProblem(w http.ResponseWriter, r *http.Request, v interface{}) {
+	er, ok := v.(*ErrorResponse)
+	if !ok || !strings.Contains(r.Header.Get("Accept"), problemContentType) {
+		render.DefaultResponder(w, r, v)
+		return
+	}
+
+	body, err := json.Marshal(problemDetails{
+		Type:      "urn:e-library:error:" + er.Code,
+		Title:     er.ErrorType,
+		Status:    er.httpStatusCode,
+		Detail:    er.Message,
+		Instance:  r.URL.Path,
+		Code:      er.Code,
+		Fields:    er.Fields,
+		RequestID: er.RequestID,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("content-type", problemContentType)
+	w.WriteHeader(er.httpStatusCode)
+	w.Write(body)
 }
@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func missingShelfHandler() http.Handler {
+	return middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		render.Render(w, r, errorResponse(fmt.Errorf("loading shelf: %w", database.ErrShelfNotFound)))
+	}))
+}
+
+func TestErrorResponse(t *testing.T) {
+	w, r := mockRequest(t, "/shelves/missing", nil, false)
+	missingShelfHandler().ServeHTTP(w, r)
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, http.StatusNotFound, w.Code, "A mapped error didn't return the proper response code")
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("content-type"), "An error sent to a JSON client didn't return JSON")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An error didn't return a valid errorResponse object") {
+		assert.Equal(t, "shelf_not_found", resp.Code, "A wrapped error didn't return the code of the error it wraps")
+		assert.Equal(t, errShelfNotFound.Error(), resp.Message, "A mapped error didn't return its client-facing message")
+		assert.NotEmpty(t, resp.RequestID, "An error didn't return the request id")
+	}
+
+	assert.Nil(t, errorResponse(sql.ErrConnDone), "An unmapped error was sent to the client")
+	assert.Equal(t, "bad_request", BadRequestError(errors.New("unexpected EOF")).(*ErrorResponse).Code, "An error without a code didn't fall back to the status code")
+}
+
+func TestLoginErrorResponse(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{database.ErrWrongPass, http.StatusUnprocessableEntity, "login_failed"},
+		{fmt.Errorf("logging in: %w", database.ErrAccountNotFound), http.StatusUnprocessableEntity, "login_failed"},
+		{database.ErrAccountNotActive, http.StatusUnauthorized, "account_not_active"},
+		{database.ErrAccountDisabled, http.StatusForbidden, "account_disabled"},
+	}
+	for _, tt := range tests {
+		resp := loginErrorResponse(tt.err)
+		if assert.NotNil(t, resp, "A failed sign in wasn't mapped: %v", tt.err) {
+			assert.Equal(t, tt.status, resp.httpStatusCode, "A failed sign in didn't return the proper response code: %v", tt.err)
+			assert.Equal(t, tt.code, resp.Code, "A failed sign in didn't return the proper code: %v", tt.err)
+		}
+	}
+
+	assert.Nil(t, loginErrorResponse(sql.ErrConnDone), "An unmapped error was answered as a failed sign in")
+}
+
+func TestProblemDetailsErrorResponse(t *testing.T) {
+	w, r := mockRequest(t, "/shelves/missing", nil, false)
+	r.Header.Set("Accept", "application/problem+json")
+	missingShelfHandler().ServeHTTP(w, r)
+
+	resp := &problemDetails{}
+	assert.Equal(t, http.StatusNotFound, w.Code, "A problem details error didn't return the proper response code")
+	assert.Equal(t, problemContentType, w.Header().Get("content-type"), "A client accepting problem details didn't receive them")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A problem details error didn't return a valid problemDetails object") {
+		assert.Equal(t, "urn:e-library:error:shelf_not_found", resp.Type, "A problem details error didn't return the proper type")
+		assert.Equal(t, "Not Found", resp.Title, "A problem details error didn't return the proper title")
+		assert.Equal(t, http.StatusNotFound, resp.Status, "A problem details error didn't repeat its status")
+		assert.Equal(t, errShelfNotFound.Error(), resp.Detail, "A problem details error didn't return the message")
+		assert.Equal(t, "/shelves/missing", resp.Instance, "A problem details error didn't return the request path")
+		assert.Equal(t, "shelf_not_found", resp.Code, "A problem details error didn't return the code")
+		assert.NotEmpty(t, resp.RequestID, "A problem details error didn't return the request id")
+	}
+}
@@ -0,0 +1,129 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var errAnnotationExportFormatUnrecognized = errors.New("format must be either markdown or json")
+
+type annotationExport struct {
+	Book struct {
+		ID     string `json:"id"`
+		Title  string `json:"title"`
+		Author string `json:"author"`
+	} `json:"book"`
+	Annotations []AnnotationResponse `json:"annotations"`
+}
+
+// ExportAnnotations downloads every annotation the account made on a book,
+// as Markdown or JSON.
+func ExportAnnotations(
+	books database.BookInterface,
+	db database.AnnotationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ExportAnnotations: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Exporting annotations of a malformed book id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+		format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+		if format == "" {
+			format = "markdown"
+		}
+		if format != "markdown" && format != "json" {
+			render.Render(w, r, BadRequestError(errAnnotationExportFormatUnrecognized))
+			return
+		}
+
+		book, err := books.GetBook(ctx, bookID, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting a book")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		annotations, err := db.ExportBookAnnotations(ctx, bookID, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while exporting annotations")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		export := annotationExport{Annotations: AnnotationsFromDatabase(annotations, sch.Email).Data}
+		export.Book.ID = book.ID.String()
+		export.Book.Title = book.Title
+		export.Book.Author = book.Author
+
+		if format == "json" {
+			w.Header().Set("content-type", "application/json")
+			w.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="annotations-%s.json"`, bookID))
+			if err := json.NewEncoder(w).Encode(export); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Exporting annotations failed")
+			}
+			return
+		}
+
+		w.Header().Set("content-type", "text/markdown; charset=utf-8")
+		w.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="annotations-%s.md"`, bookID))
+		if err := writeAnnotationsMarkdown(w, export); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Exporting annotations failed")
+		}
+	}
+}
+
+// writeAnnotationsMarkdown writes the annotations in the order they were
+// made, each under a heading with its kind, color and date.
+func writeAnnotationsMarkdown(w io.Writer, export annotationExport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n", export.Book.Title)
+	if export.Book.Author != "" {
+		fmt.Fprintf(&b, "\n_%s_\n", export.Book.Author)
+	}
+	for _, a := range export.Annotations {
+		heading := strings.ToUpper(a.Kind[:1]) + a.Kind[1:]
+		if a.Color != "" {
+			heading += " (" + a.Color + ")"
+		}
+		fmt.Fprintf(&b, "\n## %s, %s\n\n", heading, a.CreatedAt[:len("2006-01-02")])
+		if a.Quote != "" {
+			for _, line := range strings.Split(a.Quote, "\n") {
+				fmt.Fprintf(&b, "> %s\n", line)
+			}
+			b.WriteString("\n")
+		}
+		if a.Note != "" {
+			fmt.Fprintf(&b, "%s\n\n", a.Note)
+		}
+		fmt.Fprintf(&b, "`%s`\n", a.CFIRange)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
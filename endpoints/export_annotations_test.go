@@ -0,0 +1,81 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) ExportBookAnnotations(ctx context.Context, bookID uuid.UUID, accountID string) ([]database.Annotation, error) {
+	args := db.Called(bookID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Annotation), args.Error(1)
+}
+
+func TestMarkdownExportAnnotations(t *testing.T) {
+	book := &database.Book{ID: uuid.New(), Title: "Chäos;HEĀd", Author: "Hayashi Naotaka"}
+	path := "/books/" + book.ID.String() + "/annotations/export"
+
+	note := mockAnnotation(book.ID)
+	note.Kind = database.AnnotationNote
+	note.Color = ""
+	note.Quote = ""
+	note.Note = "Who is watching?"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetBook", book.ID, expID.Account).
+		Return(book, nil).Once()
+	dbMock.On("ExportBookAnnotations", book.ID, expID.Account).
+		Return([]database.Annotation{mockAnnotation(book.ID), note}, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", book.ID.String()})
+	handler := ExportAnnotations(dbMock, dbMock)
+	handler.ServeHTTP(w, r)
+
+	expBody := "# Chäos;HEĀd\n" +
+		"\n_Hayashi Naotaka_\n" +
+		"\n## Highlight (yellow), 2026-10-19\n\n" +
+		"> It was a dark and stormy night\n\n" +
+		"`epubcfi(/6/4!/4/2,/1:0,/1:20)`\n" +
+		"\n## Note, 2026-10-19\n\n" +
+		"Who is watching?\n\n" +
+		"`epubcfi(/6/4!/4/2,/1:0,/1:20)`\n"
+
+	assert.Equal(t, http.StatusOK, w.Code, "A successful Markdown annotation export didn't return the proper response code")
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("content-type"), "A successful Markdown annotation export didn't return the proper content type")
+	assert.Equal(t, expBody, w.Body.String(), "A successful Markdown annotation export didn't return the expected document")
+	dbMock.AssertExpectations(t)
+}
+
+func TestJSONExportAnnotations(t *testing.T) {
+	book := &database.Book{ID: uuid.New(), Title: "Chäos;HEĀd", Author: "Hayashi Naotaka"}
+	path := "/books/" + book.ID.String() + "/annotations/export?format=json"
+
+	expDBAnnotations := []database.Annotation{mockAnnotation(book.ID)}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetBook", book.ID, expID.Account).
+		Return(book, nil).Once()
+	dbMock.On("ExportBookAnnotations", book.ID, expID.Account).
+		Return(expDBAnnotations, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", book.ID.String()})
+	handler := ExportAnnotations(dbMock, dbMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &annotationExport{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful JSON annotation export didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful JSON annotation export didn't return a valid document") {
+		assert.Equal(t, book.Title, resp.Book.Title, "A successful JSON annotation export didn't name the book")
+		assert.Equal(t, AnnotationsFromDatabase(expDBAnnotations, expID.Account).Data, resp.Annotations, "A successful JSON annotation export didn't return every annotation")
+	}
+	dbMock.AssertExpectations(t)
+}
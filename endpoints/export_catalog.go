@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"fmt"
+	"ic-rhadi/e_library/cataloghelper"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+func ExportCatalog(
+	db database.CatalogInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		format, err := catalogFormatFromQuery(r)
+		if err != nil {
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		w.Header().Set("content-type", cataloghelper.ContentType(format))
+		w.Header().Set("content-disposition", fmt.Sprintf(
+			`attachment; filename="catalog-%s.%s"`,
+			time.Now().Format("20060102"),
+			cataloghelper.FileExtension(format),
+		))
+
+		// The body is streamed, so once the first row is out an error can
+		// only be logged, the status has already been sent.
+		if err := cataloghelper.ExportCatalog(ctx, db, format, w); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("format", format).Msg("Exporting catalog failed")
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulExportCatalog(t *testing.T) {
+	path := "/admin/catalog/export?format=csv"
+
+	expEntries := []database.CatalogEntry{
+		{ISBN: "9784088725093", Title: "E", Author: "MC2", Readers: 10, Rating: 3.5, RatingCount: 2},
+		{ISBN: "9780306406157", Title: "F, G", Author: "H"},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ExportCatalog").
+		Return(expEntries, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ExportCatalog(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expBody := "isbn,title,author,summary,cover_url,publisher,publication_date,language,page_count,edition,format,readers,rating,rating_count\n" +
+		"9784088725093,E,MC2,,,,,,,,,10,3.50,2\n" +
+		"9780306406157,\"F, G\",H,,,,,,,,,0,0.00,0\n"
+
+	assert.Equal(t, http.StatusOK, w.Code, "A successful catalog export didn't return the proper response code")
+	assert.Equal(t, "text/csv", w.Header().Get("content-type"), "A csv catalog export didn't return the proper content type")
+	assert.Equal(t, expBody, w.Body.String(), "A successful catalog export didn't return the catalog")
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,120 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+type GenreResponse struct {
+	ID        string          `json:"id"`
+	ParentID  string          `json:"parent_id,omitempty"`
+	Name      string          `json:"name"`
+	BISAC     string          `json:"bisac_code,omitempty"`
+	Dewey     string          `json:"dewey_code,omitempty"`
+	BookCount int             `json:"book_count"`
+	Children  []GenreResponse `json:"children,omitempty"`
+
+	httpStatus int
+}
+
+func (g *GenreResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	status := g.httpStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	render.Status(r, status)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func GenreFromDatabase(dGenre database.Genre) GenreResponse {
+	var g GenreResponse
+
+	g.ID = dGenre.ID.String()
+	if dGenre.ParentID.Valid {
+		g.ParentID = dGenre.ParentID.UUID.String()
+	}
+	g.Name = dGenre.Name
+	g.BISAC = dGenre.BISAC
+	g.Dewey = dGenre.Dewey
+	g.BookCount = dGenre.BookCount
+
+	return g
+}
+
+type GenresResponse struct {
+	Data []GenreResponse `json:"data"`
+}
+
+func (g *GenresResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+// GenreTreeFromDatabase nests every genre under its parent, keeping the order
+// they were listed in. Genres whose parent isn't listed become roots.
+func GenreTreeFromDatabase(dGenres []database.Genre) GenresResponse {
+	listed := make(map[string]bool, len(dGenres))
+	children := make(map[string][]database.Genre)
+	for _, dGenre := range dGenres {
+		listed[dGenre.ID.String()] = true
+	}
+	var roots []database.Genre
+	for _, dGenre := range dGenres {
+		if dGenre.ParentID.Valid && listed[dGenre.ParentID.UUID.String()] {
+			parent := dGenre.ParentID.UUID.String()
+			children[parent] = append(children[parent], dGenre)
+		} else {
+			roots = append(roots, dGenre)
+		}
+	}
+
+	var build func(dGenre database.Genre) GenreResponse
+	build = func(dGenre database.Genre) GenreResponse {
+		g := GenreFromDatabase(dGenre)
+		for _, child := range children[g.ID] {
+			g.Children = append(g.Children, build(child))
+		}
+		return g
+	}
+
+	var g GenresResponse
+	for _, root := range roots {
+		g.Data = append(g.Data, build(root))
+	}
+	return g
+}
+
+type BookGenreResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func BookGenresFromDatabase(dGenres database.BookGenres) []BookGenreResponse {
+	genres := make([]BookGenreResponse, 0, len(dGenres))
+	for _, dGenre := range dGenres {
+		genres = append(genres, BookGenreResponse{
+			ID:   dGenre.ID.String(),
+			Name: dGenre.Name,
+		})
+	}
+	return genres
+}
+
+type genreCarouselResponse struct {
+	Genre GenreResponse  `json:"genre"`
+	Books []BookResponse `json:"books"`
+}
+
+type GenreCarouselsResponse struct {
+	Data []genreCarouselResponse `json:"data"`
+}
+
+func (g *GenreCarouselsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
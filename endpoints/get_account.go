@@ -0,0 +1,34 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// GetAccount shows an account to support staff, with its status and how
+// many devices are signed in to it.
+func GetAccount(
+	db database.AdminUserInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		account, err := db.GetAccount(ctx, chi.URLParam(r, "email"))
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an account")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AccountFromDatabase(*account)
+		render.Render(w, r, &resp)
+	}
+}
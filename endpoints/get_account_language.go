@@ -0,0 +1,52 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// LanguageResponse is the language the account's messages are sent in, empty
+// when they go by the client's.
+type LanguageResponse struct {
+	Language string `json:"language"`
+}
+
+func (l *LanguageResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+// GetAccountLanguage returns the language the account picked for its
+// messages.
+func GetAccountLanguage(
+	db database.UserAccountInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("GetAccountLanguage: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		lang, err := db.GetAccountLanguage(ctx, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting the account's language")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		render.Render(w, r, &LanguageResponse{Language: lang})
+	}
+}
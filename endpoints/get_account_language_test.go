@@ -0,0 +1,47 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetAccountLanguage(ctx context.Context, email string) (string, error) {
+	args := db.Called(email)
+	return args.String(0), args.Error(1)
+}
+
+func TestSuccessfulGetAccountLanguage(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccountLanguage", expID.Account).
+		Return("ja", nil).Once()
+
+	w, r := mockRequest(t, "/me/language", nil, true)
+	handler := GetAccountLanguage(dbMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &LanguageResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful account language request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful account language request didn't return a valid LanguageResponse object") {
+		assert.Equal(t, LanguageResponse{Language: "ja"}, *resp, "A successful account language request didn't return the account's language")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedGetAccountLanguage(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccountLanguage", expID.Account).
+		Return("", sql.ErrConnDone).Once()
+
+	w, r := mockRequest(t, "/me/language", nil, true)
+	handler := GetAccountLanguage(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "A failing account language request didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,48 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+func GetAnnotation(
+	db database.AnnotationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("GetAnnotation: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		annotationID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Getting an annotation with a malformed id")
+			render.Render(w, r, BadRequestError(errAnnotationIDMalformed))
+			return
+		}
+
+		annotation, err := db.GetAnnotation(ctx, annotationID, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an annotation")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AnnotationFromDatabase(*annotation, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
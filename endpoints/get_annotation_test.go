@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulGetAnnotation(t *testing.T) {
+	expDBAnnotation := mockAnnotation(uuid.New())
+	path := "/annotations/" + expDBAnnotation.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAnnotation", expDBAnnotation.ID, expID.Account).
+		Return(&expDBAnnotation, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", expDBAnnotation.ID.String()})
+	handler := GetAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AnnotationFromDatabase(expDBAnnotation, expID.Account)
+
+	resp := &AnnotationResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful annotation request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful annotation request didn't return a valid AnnotationResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful annotation request didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundGetAnnotation(t *testing.T) {
+	annotationID := uuid.New()
+	path := "/annotations/" + annotationID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAnnotation", annotationID, expID.Account).
+		Return(nil, database.ErrAnnotationNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", annotationID.String()})
+	handler := GetAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errAnnotationNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing annotation request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing annotation request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing annotation request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
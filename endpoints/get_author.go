@@ -0,0 +1,59 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var errAuthorIDMalformed = errors.New("author id malformed")
+var errAuthorNotFound = errors.New("author not found")
+
+func GetAuthor(
+	db database.AuthorInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("GetAuthor: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		authorID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Getting an author with a malformed id")
+			render.Render(w, r, BadRequestError(errAuthorIDMalformed))
+			return
+		}
+
+		author, err := db.GetAuthor(ctx, authorID)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an author")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		books, err := db.GetAuthorBooks(ctx, authorID, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an author's books")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AuthorFromDatabase(*author, books)
+		render.Render(w, r, &resp)
+	}
+}
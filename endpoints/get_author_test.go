@@ -0,0 +1,104 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetAuthor(ctx context.Context, authorID uuid.UUID) (*database.Author, error) {
+	args := db.Called(authorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Author), args.Error(1)
+}
+
+func (db dBMock) GetAuthorBooks(ctx context.Context, authorID uuid.UUID, accountID string) ([]database.Book, error) {
+	args := db.Called(authorID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Book), args.Error(1)
+}
+
+func TestSuccessfulGetAuthor(t *testing.T) {
+	expDBAuthor := &database.Author{ID: uuid.New(), Name: "Hayashi Naotaka", BookCount: 2}
+	credits := database.BookAuthors{
+		{ID: expDBAuthor.ID, Name: expDBAuthor.Name, Role: database.CreditAuthor},
+		{ID: uuid.New(), Name: "Chiyomaru Shikura", Role: database.CreditAuthor},
+	}
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "Hayashi Naotaka, Chiyomaru Shikura", Authors: credits},
+		{ID: uuid.New(), Title: "bbbb", Author: "Hayashi Naotaka", Authors: credits[:1]},
+	}
+	path := "/authors/" + expDBAuthor.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAuthor", expDBAuthor.ID).
+		Return(expDBAuthor, nil).Once()
+	dbMock.On("GetAuthorBooks", expDBAuthor.ID, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", expDBAuthor.ID.String()})
+	handler := GetAuthor(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AuthorFromDatabase(*expDBAuthor, expDBBooks)
+	expCode := http.StatusOK
+
+	resp := &AuthorResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful author request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful author request didn't return a valid AuthorResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful author request didn't return a valid response")
+		assert.Len(t, resp.Books[0].Authors, 2, "A successful author request didn't return every credit of a book")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundGetAuthor(t *testing.T) {
+	authorID := uuid.New()
+	path := "/authors/" + authorID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAuthor", authorID).
+		Return(nil, database.ErrAuthorNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", authorID.String()})
+	handler := GetAuthor(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errAuthorNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing author request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing author request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing author request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedGetAuthor(t *testing.T) {
+	path := "/authors/abc"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, nil, true, param{"id", "abc"})
+	handler := GetAuthor(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errAuthorIDMalformed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed author request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed author request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A malformed author request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+func GetBook(
+	db database.BookInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("GetBook: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Getting a book with a malformed id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		book, err := db.GetBook(ctx, bookID, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting a book")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		// Staff impersonating the account only look, and mustn't change its
+		// history, popularity or recommendations.
+		if !sch.Impersonated() {
+			if err := db.RecordBookView(ctx, bookID, sch.Email); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Database error while recording a book view")
+			}
+		}
+
+		resp := BookDetailFromDatabase(*book)
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,157 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetBook(ctx context.Context, bookID uuid.UUID, accountID string) (*database.Book, error) {
+	args := db.Called(bookID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Book), args.Error(1)
+}
+
+func (db dBMock) RecordBookView(ctx context.Context, bookID uuid.UUID, accountID string) error {
+	args := db.Called(bookID, accountID)
+	return args.Error(0)
+}
+
+func TestSuccessfulGetBook(t *testing.T) {
+	publishedOn := time.Date(2003, time.May, 2, 0, 0, 0, 0, time.UTC)
+	expDBBook := &database.Book{
+		ID:          uuid.New(),
+		Title:       "aaaa",
+		Author:      "ae",
+		Summary:     "eeee",
+		Readers:     10,
+		ISBN:        "9784088725093",
+		Publisher:   "Shueisha",
+		PublishedOn: &publishedOn,
+		Language:    "ja",
+		PageCount:   216,
+		Format:      database.FormatPaperback,
+		Shelves: database.BookShelves{
+			{ID: uuid.New(), Name: "Want to read", Kind: database.ShelfWantToRead},
+		},
+	}
+	path := "/books/" + expDBBook.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetBook", expDBBook.ID, expID.Account).
+		Return(expDBBook, nil).Once()
+	dbMock.On("RecordBookView", expDBBook.ID, expID.Account).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", expDBBook.ID.String()})
+	handler := GetBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := BookDetailFromDatabase(*expDBBook)
+	expCode := http.StatusOK
+
+	resp := &BookDetailResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful book request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful book request didn't return a valid BookResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful book request didn't return a valid response")
+		assert.Equal(t, "2003-05-02", resp.PublishedOn, "A successful book request didn't return the publication date as a calendar date")
+		assert.Equal(t, "want_to_read", resp.Shelves[0].Kind, "A successful book request didn't return the account's shelves")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedGetBook(t *testing.T) {
+	path := "/books/abc"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, nil, true, param{"id", "abc"})
+	handler := GetBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errBookIDMalformed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed book request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed book request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A malformed book request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundGetBook(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetBook", bookID, expID.Account).
+		Return(nil, database.ErrBookNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := GetBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errBookNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing book request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing book request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing book request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedRecordViewGetBook(t *testing.T) {
+	expDBBook := &database.Book{ID: uuid.New(), Title: "aaaa", Author: "ae"}
+	path := "/books/" + expDBBook.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetBook", expDBBook.ID, expID.Account).
+		Return(expDBBook, nil).Once()
+	dbMock.On("RecordBookView", expDBBook.ID, expID.Account).
+		Return(sql.ErrConnDone).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", expDBBook.ID.String()})
+	handler := GetBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := BookFromDatabase(*expDBBook)
+
+	resp := &BookResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A book request with a failed view record didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A book request with a failed view record didn't return a valid BookResponse object") {
+		assert.Equal(t, expResp, *resp, "A book request with a failed view record didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestImpersonatedGetBook(t *testing.T) {
+	expDBBook := &database.Book{ID: uuid.New(), Title: "aaaa"}
+	path := "/books/" + expDBBook.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetBook", expDBBook.ID, mockAccountEmail).
+		Return(expDBBook, nil).Once()
+
+	w, r := impersonatedRequest(t, http.MethodGet, path)
+	routerCtx := chi.NewRouteContext()
+	routerCtx.URLParams.Add("id", expDBBook.ID.String())
+	r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routerCtx))
+	handler := GetBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "An impersonated book request didn't return the proper response code")
+	dbMock.AssertNotCalled(t, "RecordBookView", mock.Anything, mock.Anything)
+	dbMock.AssertExpectations(t)
+}
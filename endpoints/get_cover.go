@@ -0,0 +1,67 @@
+package endpoints
+
+import (
+	"errors"
+	"fmt"
+	"ic-rhadi/e_library/coverhelper"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var errCoverNotFound = errors.New("cover not found")
+
+// Cover files are never rewritten in place, a new upload gets a new id, so
+// clients may keep them for as long as they like.
+const coverCacheControl = "public, max-age=31536000, immutable"
+
+func GetCover(
+	covers coverhelper.CoverStorage,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		coverID, err := uuid.Parse(chi.URLParam(r, "id"))
+		size := chi.URLParam(r, "size")
+		if err != nil || !coverhelper.IsCoverSize(size) {
+			log.Ctx(ctx).Debug().Err(err).Str("size", size).Msg("Cover requested with a malformed id or size")
+			render.Render(w, r, NotFoundError(errCoverNotFound))
+			return
+		}
+
+		format := coverhelper.FormatJPEG
+		if strings.Contains(r.Header.Get("Accept"), coverhelper.ContentType(coverhelper.FormatWebP)) {
+			format = coverhelper.FormatWebP
+		}
+
+		cover, modTime, err := covers.OpenCover(ctx, coverID, size, format)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Str("cover", coverID.String()).Msg("Opening cover returned an error")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		defer cover.Close()
+
+		w.Header().Set("content-type", coverhelper.ContentType(format))
+		w.Header().Set("cache-control", coverCacheControl)
+		w.Header().Set("vary", "Accept")
+		w.Header().Set("etag", fmt.Sprintf(`"%s-%s-%s"`, coverID, size, format))
+		http.ServeContent(w, r, "", modTime, cover)
+	}
+}
+
+func CoverURLs(coverID uuid.UUID) map[string]string {
+	urls := make(map[string]string)
+	for _, size := range coverhelper.CoverSizes() {
+		urls[size] = fmt.Sprintf("/covers/%s/%s", coverID, size)
+	}
+	return urls
+}
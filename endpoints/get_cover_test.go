@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"ic-rhadi/e_library/coverhelper"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error { return nil }
+
+func TestSuccessfulGetCover(t *testing.T) {
+	coverID := uuid.New()
+	path := fmt.Sprintf("/covers/%s/small", coverID)
+	expBody := []byte("webp image")
+	modTime := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	coverMock := coverStorageMock{&mock.Mock{}}
+	coverMock.On("OpenCover", coverID, "small", coverhelper.FormatWebP).
+		Return(readSeekNopCloser{bytes.NewReader(expBody)}, modTime, nil).Once()
+
+	w, r := mockRequest(t, path, nil, false, param{"id", coverID.String()}, param{"size", "small"})
+	r.Method = http.MethodGet
+	r.Header.Set("Accept", "image/avif,image/webp,*/*")
+	handler := GetCover(coverMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "A successful cover request didn't return the proper response code")
+	assert.Equal(t, "image/webp", w.Header().Get("content-type"), "A webp-accepting cover request didn't get a webp cover")
+	assert.Equal(t, coverCacheControl, w.Header().Get("cache-control"), "A successful cover request didn't return long-cache headers")
+	assert.Equal(t, expBody, w.Body.Bytes(), "A successful cover request didn't return the cover")
+	coverMock.AssertExpectations(t)
+
+	expBody = []byte("jpeg image")
+	coverMock.On("OpenCover", coverID, "small", coverhelper.FormatJPEG).
+		Return(readSeekNopCloser{bytes.NewReader(expBody)}, modTime, nil).Once()
+
+	w, r = mockRequest(t, path, nil, false, param{"id", coverID.String()}, param{"size", "small"})
+	r.Method = http.MethodGet
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "A successful cover request didn't return the proper response code")
+	assert.Equal(t, "image/jpeg", w.Header().Get("content-type"), "A cover request without webp support didn't get a jpeg cover")
+	assert.Equal(t, expBody, w.Body.Bytes(), "A successful cover request didn't return the cover")
+	coverMock.AssertExpectations(t)
+}
+
+func TestNotFoundGetCover(t *testing.T) {
+	coverID := uuid.New()
+
+	coverMock := coverStorageMock{&mock.Mock{}}
+	coverMock.On("OpenCover", coverID, "large", coverhelper.FormatJPEG).
+		Return(nil, time.Time{}, coverhelper.ErrCoverNotFound).Once()
+
+	params := [][]param{
+		{{"id", coverID.String()}, {"size", "large"}},
+		{{"id", coverID.String()}, {"size", "huge"}},
+		{{"id", "abc"}, {"size", "large"}},
+	}
+	for _, p := range params {
+		w, r := mockRequest(t, "/covers", nil, false, p...)
+		r.Method = http.MethodGet
+		handler := GetCover(coverMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := NotFoundError(errCoverNotFound).(*ErrorResponse).sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A missing cover request didn't return the proper response code")
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing cover request didn't return a valid errorResponse object") {
+			assert.Equal(t, expResp, *resp, "A missing cover request didn't return the proper error")
+		}
+	}
+	coverMock.AssertExpectations(t)
+}
@@ -0,0 +1,42 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var errImportJobNotFound = errors.New("import job not found")
+
+func GetImportJob(
+	db database.CatalogInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			render.Render(w, r, NotFoundError(errImportJobNotFound))
+			return
+		}
+
+		job, err := db.GetImportJob(ctx, jobID)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting import job")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := importJobFromDatabase(*job, http.StatusOK)
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulGetImportJob(t *testing.T) {
+	finishedAt := time.Now()
+	expJob := &database.ImportJob{
+		ID:         uuid.New(),
+		Format:     "csv",
+		Status:     database.ImportJobDone,
+		Progress:   100,
+		Processed:  3,
+		Created:    1,
+		Updated:    1,
+		Failed:     1,
+		CreatedBy:  expID.Account,
+		CreatedAt:  finishedAt.Add(-time.Minute),
+		FinishedAt: &finishedAt,
+		Errors: []database.ImportRowError{
+			{Row: 2, ISBN: "978-0", Message: database.ErrISBNMalformed.Error()},
+		},
+	}
+	path := "/admin/catalog/import/" + expJob.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetImportJob", expJob.ID).
+		Return(expJob, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", expJob.ID.String()})
+	handler := GetImportJob(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := importJobFromDatabase(*expJob, 0)
+	expCode := http.StatusOK
+
+	resp := &importJobResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful import job request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful import job request didn't return a valid importJobResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful import job request didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundGetImportJob(t *testing.T) {
+	jobID := uuid.New()
+	path := "/admin/catalog/import/" + jobID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetImportJob", jobID).
+		Return(nil, database.ErrImportJobNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", jobID.String()})
+	handler := GetImportJob(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errImportJobNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing import job request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing import job request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing import job request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// GetShelf returns one of the account's shelves, or a public shelf of
+// another account, with its books.
+func GetShelf(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("GetShelf: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		renderShelf(w, r, db, sch.Email)
+	}
+}
+
+// GetSharedShelf returns a public shelf with its books to anyone with its
+// link, signed in or not.
+func GetSharedShelf(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderShelf(w, r, db, "")
+	}
+}
+
+func renderShelf(w http.ResponseWriter, r *http.Request, db database.ShelfInterface, accountID string) {
+	ctx := r.Context()
+
+	shelfID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Getting a shelf with a malformed id")
+		render.Render(w, r, BadRequestError(errShelfIDMalformed))
+		return
+	}
+
+	shelf, err := db.GetShelf(ctx, shelfID, accountID)
+	if err != nil {
+		if errResp := errorResponse(err); errResp != nil {
+			render.Render(w, r, errResp)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("Database error while getting a shelf")
+		render.Render(w, r, InternalServerError())
+		return
+	}
+
+	books, err := db.GetShelfBooks(ctx, shelfID, accountID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Database error while listing a shelf's books")
+		render.Render(w, r, InternalServerError())
+		return
+	}
+
+	resp := ShelfBooksFromDatabase(*shelf, books, accountID)
+	render.Render(w, r, &resp)
+}
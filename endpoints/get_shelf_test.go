@@ -0,0 +1,104 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetShelf(ctx context.Context, shelfID uuid.UUID, accountID string) (*database.Shelf, error) {
+	args := db.Called(shelfID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Shelf), args.Error(1)
+}
+
+func (db dBMock) GetShelfBooks(ctx context.Context, shelfID uuid.UUID, accountID string) ([]database.Book, error) {
+	args := db.Called(shelfID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Book), args.Error(1)
+}
+
+func TestSuccessfulGetShelf(t *testing.T) {
+	expDBShelf := mockShelf(database.ShelfWantToRead, "Want to read")
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "ae"},
+		{ID: uuid.New(), Title: "bbbb", Author: "be"},
+	}
+	path := "/shelves/" + expDBShelf.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", expDBShelf.ID, expID.Account).
+		Return(&expDBShelf, nil).Once()
+	dbMock.On("GetShelfBooks", expDBShelf.ID, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", expDBShelf.ID.String()})
+	handler := GetShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ShelfBooksFromDatabase(expDBShelf, expDBBooks, expID.Account)
+
+	resp := &ShelfBooksResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful shelf request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful shelf request didn't return a valid ShelfBooksResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful shelf request didn't return a valid response")
+		assert.Equal(t, "bbbb", resp.Books[1].Title, "A successful shelf request didn't keep the shelf's order")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestSuccessfulGetSharedShelf(t *testing.T) {
+	expDBShelf := mockShelf(database.ShelfCustom, "Summer reads")
+	expDBShelf.Visibility = database.ShelfPublic
+	path := "/shared/shelves/" + expDBShelf.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", expDBShelf.ID, "").
+		Return(&expDBShelf, nil).Once()
+	dbMock.On("GetShelfBooks", expDBShelf.ID, "").
+		Return([]database.Book{}, nil).Once()
+
+	w, r := mockRequest(t, path, nil, false, param{"id", expDBShelf.ID.String()})
+	handler := GetSharedShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &ShelfBooksResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful shared shelf request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful shared shelf request didn't return a valid ShelfBooksResponse object") {
+		assert.Equal(t, ShelfBooksFromDatabase(expDBShelf, nil, ""), *resp, "A successful shared shelf request didn't return a valid response")
+		assert.False(t, resp.IsOwn, "A successful shared shelf request marked the shelf as the visitor's own")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundGetSharedShelf(t *testing.T) {
+	shelfID := uuid.New()
+	path := "/shared/shelves/" + shelfID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", shelfID, "").
+		Return(nil, database.ErrShelfNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, false, param{"id", shelfID.String()})
+	handler := GetSharedShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errShelfNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A private shared shelf request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A private shared shelf request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A private shared shelf request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
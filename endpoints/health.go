@@ -0,0 +1,166 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthFailing  = "failing"
+	healthDraining = "draining"
+)
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+func (h *HealthResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+type ReadinessCheckResponse struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Optional  bool    `json:"optional,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status    string                   `json:"status"`
+	CheckedAt string                   `json:"checked_at,omitempty"`
+	Checks    []ReadinessCheckResponse `json:"checks"`
+}
+
+func (rr *ReadinessResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	status := http.StatusOK
+	if rr.Status == healthFailing || rr.Status == healthDraining {
+		status = http.StatusServiceUnavailable
+	}
+	render.Status(r, status)
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("cache-control", "no-store")
+	return nil
+}
+
+// ReadinessCheck is one dependency /readyz looks at. A failing optional check
+// is reported, but leaves the server ready as most requests don't need it.
+type ReadinessCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error
+	Optional bool
+}
+
+// ReadinessProbe runs the readiness checks and keeps their result for a
+// while, so that frequent probes don't reach the database every time.
+type ReadinessProbe struct {
+	checks   []ReadinessCheck
+	timeout  time.Duration
+	cacheFor time.Duration
+	draining atomic.Bool
+
+	mu        sync.Mutex
+	last      ReadinessResponse
+	checkedAt time.Time
+}
+
+// NewReadinessProbe gives each check up to timeout to finish, and reuses their
+// result for cacheFor.
+func NewReadinessProbe(timeout time.Duration, cacheFor time.Duration, checks ...ReadinessCheck) *ReadinessProbe {
+	return &ReadinessProbe{checks: checks, timeout: timeout, cacheFor: cacheFor}
+}
+
+// Drain makes the server report itself as not ready from now on, so load
+// balancers stop sending it requests before it shuts down.
+func (p *ReadinessProbe) Drain() {
+	p.draining.Store(true)
+}
+
+func (p *ReadinessProbe) result(ctx context.Context) ReadinessResponse {
+	if p.draining.Load() {
+		return ReadinessResponse{Status: healthDraining, Checks: []ReadinessCheckResponse{}}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.checkedAt.IsZero() && time.Since(p.checkedAt) < p.cacheFor {
+		return p.last
+	}
+
+	results := make([]ReadinessCheckResponse, len(p.checks))
+	var wg sync.WaitGroup
+	for i, check := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	status := healthOK
+	for i, result := range results {
+		if result.Status == healthOK {
+			continue
+		}
+		if !p.checks[i].Optional {
+			status = healthFailing
+		} else if status == healthOK {
+			status = healthDegraded
+		}
+	}
+
+	p.checkedAt = time.Now()
+	p.last = ReadinessResponse{
+		Status:    status,
+		CheckedAt: p.checkedAt.UTC().Format(time.RFC3339),
+		Checks:    results,
+	}
+	return p.last
+}
+
+func (p *ReadinessProbe) run(ctx context.Context, check ReadinessCheck) ReadinessCheckResponse {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+	result := ReadinessCheckResponse{
+		Name:      check.Name,
+		Status:    healthOK,
+		Optional:  check.Optional,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("check", check.Name).Msg("Readiness check failed")
+		result.Status = healthFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Liveness only tells the process is up and serving.
+func Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.Render(w, r, &HealthResponse{Status: healthOK})
+	}
+}
+
+// Readiness tells whether the server can take requests, with the result and
+// latency of each check.
+func Readiness(probe *ReadinessProbe) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := probe.result(r.Context())
+		render.Render(w, r, &resp)
+	}
+}
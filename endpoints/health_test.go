@@ -0,0 +1,96 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness(t *testing.T) {
+	w, r := mockRequest(t, "/healthz", nil, false)
+	Liveness()(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "A live server didn't return 200 OK")
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String(), "A live server returned an unexpected body")
+}
+
+func TestReadiness(t *testing.T) {
+	passing := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("unreachable") }
+	tests := []struct {
+		name      string
+		checks    []ReadinessCheck
+		expCode   int
+		expStatus string
+	}{
+		{"passing checks", []ReadinessCheck{{Name: "database", Check: passing}, {Name: "smtp", Check: passing, Optional: true}}, http.StatusOK, healthOK},
+		{"a failing optional check", []ReadinessCheck{{Name: "database", Check: passing}, {Name: "smtp", Check: failing, Optional: true}}, http.StatusOK, healthDegraded},
+		{"a failing check", []ReadinessCheck{{Name: "database", Check: failing}, {Name: "smtp", Check: passing, Optional: true}}, http.StatusServiceUnavailable, healthFailing},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, "/readyz", nil, false)
+		Readiness(NewReadinessProbe(time.Second, time.Minute, test.checks...))(w, r)
+
+		resp := ReadinessResponse{}
+		assert.Equal(t, test.expCode, w.Code, "Readiness with %s returned an unexpected status code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(&resp), "Readiness with %s didn't return a valid response", test.name) {
+			assert.Equal(t, test.expStatus, resp.Status, "Readiness with %s returned an unexpected status", test.name)
+			assert.Len(t, resp.Checks, len(test.checks), "Readiness with %s didn't report every check", test.name)
+			for i, check := range resp.Checks {
+				assert.Equal(t, test.checks[i].Name, check.Name, "Readiness with %s reported checks out of order", test.name)
+			}
+		}
+	}
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	w, r := mockRequest(t, "/readyz", nil, false)
+	Readiness(NewReadinessProbe(10*time.Millisecond, time.Minute, ReadinessCheck{Name: "database", Check: hanging}))(w, r)
+
+	resp := ReadinessResponse{}
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "A hanging check didn't fail readiness")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(&resp), "A hanging check didn't return a valid response") {
+		assert.Equal(t, context.DeadlineExceeded.Error(), resp.Checks[0].Error, "A hanging check didn't report its timeout")
+	}
+}
+
+func TestReadinessCache(t *testing.T) {
+	calls := 0
+	probe := NewReadinessProbe(time.Second, time.Minute, ReadinessCheck{Name: "database", Check: func(ctx context.Context) error {
+		calls++
+		return nil
+	}})
+
+	for i := 0; i < 3; i++ {
+		w, r := mockRequest(t, "/readyz", nil, false)
+		Readiness(probe)(w, r)
+		assert.Equal(t, http.StatusOK, w.Code, "A cached readiness returned an unexpected status code")
+	}
+	assert.Equal(t, 1, calls, "Readiness checks ran again while their result was cached")
+}
+
+func TestReadinessDraining(t *testing.T) {
+	probe := NewReadinessProbe(time.Second, time.Minute, ReadinessCheck{Name: "database", Check: func(ctx context.Context) error {
+		return nil
+	}})
+	probe.Drain()
+
+	w, r := mockRequest(t, "/readyz", nil, false)
+	Readiness(probe)(w, r)
+
+	resp := ReadinessResponse{}
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "A draining server didn't return 503")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(&resp), "A draining server didn't return a valid response") {
+		assert.Equal(t, healthDraining, resp.Status, "A draining server returned an unexpected status")
+	}
+}
@@ -0,0 +1,110 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+type impersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (i *impersonateRequest) Bind(r *http.Request) error {
+	i.Reason = strings.TrimSpace(i.Reason)
+	if i.Reason == "" {
+		return errImpersonateMalformed
+	}
+	return nil
+}
+
+type impersonationAudit struct {
+	Account   string `json:"account"`
+	Reason    string `json:"reason"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+var errImpersonateMalformed = errors.New("reason missing")
+var errImpersonateStaff = errors.New("staff accounts can't be impersonated")
+
+// ImpersonateAccount gives an admin a session token to see the library as an
+// account does, to reproduce what a user reports. The token names the admin
+// in its act claim, only lasts impersonationLength, has no refresh token and
+// can only read.
+func ImpersonateAccount(
+	db database.AdminUserInterface,
+	audit database.AuditInterface,
+	sessionAuth *jwtauth.JWTAuth,
+	impersonationLength time.Duration,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ImpersonateAccount: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		data := &impersonateRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Impersonation attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		account, err := db.GetAccount(ctx, chi.URLParam(r, "email"))
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an account")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		if account.Role != database.RoleUser {
+			render.Render(w, r, ForbiddenRequestError(errImpersonateStaff))
+			return
+		}
+
+		token, tokenString, err := sessiontoken.CreateNewSessionToken(
+			sessionAuth,
+			sessiontoken.AccessClaimsSchema{
+				Email:    account.Email,
+				Actor:    &sessiontoken.ActorClaimsSchema{Email: sch.Email},
+				ReadOnly: true,
+			},
+			impersonationLength,
+		)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Error encoding impersonation token")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		expiresAt := token.Expiration().Format(time.RFC3339)
+		recordAudit(r, audit, database.AuditAccountImpersonated, sch.Email, impersonationAudit{
+			Account:   account.Email,
+			Reason:    data.Reason,
+			ExpiresAt: expiresAt,
+		})
+
+		render.Render(w, r, &impersonationResponse{
+			Session:      tokenString,
+			Scheme:       "Bearer",
+			ExpiresAt:    expiresAt,
+			Account:      account.Email,
+			Impersonator: sch.Email,
+			ReadOnly:     true,
+		})
+	}
+}
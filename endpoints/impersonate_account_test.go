@@ -0,0 +1,112 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// impersonatedRequest is a request carrying the token an admin got to
+// impersonate mockAccountEmail.
+func impersonatedRequest(t *testing.T, method string, path string) (*httptest.ResponseRecorder, *http.Request) {
+	claims, err := sessiontoken.AccessClaimsSchema{
+		Email:    mockAccountEmail,
+		Actor:    &sessiontoken.ActorClaimsSchema{Email: expID.Account},
+		ReadOnly: true,
+	}.ToInterface()
+	require.Nil(t, err)
+	token, _, err := tokenAuth.Encode(claims)
+
+	r := httptest.NewRequest(method, path, nil)
+	return httptest.NewRecorder(), r.WithContext(jwtauth.NewContext(context.Background(), token, err))
+}
+
+func TestSuccessfulImpersonateAccount(t *testing.T) {
+	account := mockAccount()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccount", mockAccountEmail).
+		Return(&account, nil).Once()
+	auditMock := dBMock{&mock.Mock{}}
+	auditMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditAccountImpersonated && e.Actor == expID.Account
+	})).Return(nil).Once()
+
+	body := impersonateRequest{Reason: "ticket 4411"}
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/impersonation", body, true, param{"email", mockAccountEmail})
+	handler := ImpersonateAccount(dbMock, auditMock, tokenAuth, 15*time.Minute)
+	handler.ServeHTTP(w, r)
+
+	resp := &impersonationResponse{}
+	assert.Equal(t, http.StatusCreated, w.Code, "A successful impersonation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful impersonation didn't return a valid impersonationResponse object") {
+		assert.Equal(t, mockAccountEmail, resp.Account, "A successful impersonation didn't return the impersonated account")
+		assert.Equal(t, expID.Account, resp.Impersonator, "A successful impersonation didn't return the impersonator")
+		assert.True(t, resp.ReadOnly, "An impersonation token wasn't read-only")
+
+		token, err := jwtauth.VerifyToken(tokenAuth, resp.Session)
+		if assert.Nil(t, err, "A successful impersonation didn't return a valid token") {
+			claims, _ := token.AsMap(context.Background())
+			var sch sessiontoken.AccessClaimsSchema
+			assert.Nil(t, sch.FromInterface(claims))
+			assert.Equal(t, sessiontoken.AccessClaimsSchema{
+				Email:    mockAccountEmail,
+				Actor:    &sessiontoken.ActorClaimsSchema{Email: expID.Account},
+				ReadOnly: true,
+			}, sch, "An impersonation token wasn't marked in its claims")
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), token.Expiration(), time.Minute, "An impersonation token didn't last the impersonation length")
+		}
+	}
+	dbMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func TestStaffImpersonateAccount(t *testing.T) {
+	account := mockAccount()
+	account.Role = database.RoleSupport
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccount", mockAccountEmail).
+		Return(&account, nil).Once()
+
+	body := impersonateRequest{Reason: "ticket 4411"}
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/impersonation", body, true, param{"email", mockAccountEmail})
+	handler := ImpersonateAccount(dbMock, dBMock{&mock.Mock{}}, tokenAuth, 15*time.Minute)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := ForbiddenRequestError(errImpersonateStaff).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "Impersonating a staff account didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "Impersonating a staff account didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "Impersonating a staff account didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestReadOnlyImpersonationToken(t *testing.T) {
+	w, r := impersonatedRequest(t, http.MethodGet, "/me/shelves")
+	SessionAuthenticatorMiddleware()(okHandler).ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code, "An impersonation token couldn't read")
+
+	w, r = impersonatedRequest(t, http.MethodPost, "/me/shelves")
+	SessionAuthenticatorMiddleware()(okHandler).ServeHTTP(w, r)
+
+	expResp, expCode := ForbiddenRequestError(ErrImpersonationReadOnly).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An impersonation token could make a change")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A write with an impersonation token didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A write with an impersonation token didn't return the proper error")
+	}
+}
@@ -0,0 +1,194 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"ic-rhadi/e_library/cataloghelper"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+type importJobResponse struct {
+	ID         string                   `json:"id"`
+	Format     string                   `json:"format"`
+	DryRun     bool                     `json:"dry_run"`
+	Status     string                   `json:"status"`
+	Progress   float32                  `json:"progress"`
+	Processed  int                      `json:"processed_rows"`
+	Created    int                      `json:"created_rows"`
+	Updated    int                      `json:"updated_rows"`
+	Failed     int                      `json:"failed_rows"`
+	Message    string                   `json:"message,omitempty"`
+	CreatedBy  string                   `json:"created_by"`
+	CreatedAt  string                   `json:"created_at"`
+	FinishedAt string                   `json:"finished_at,omitempty"`
+	Errors     []importRowErrorResponse `json:"errors"`
+	httpStatus int                      `json:"-"`
+}
+
+type importRowErrorResponse struct {
+	Row     int    `json:"row"`
+	ISBN    string `json:"isbn"`
+	Message string `json:"message"`
+}
+
+func (job *importJobResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, job.httpStatus)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func importJobFromDatabase(dJob database.ImportJob, httpStatus int) importJobResponse {
+	job := importJobResponse{
+		ID:         dJob.ID.String(),
+		Format:     dJob.Format,
+		DryRun:     dJob.DryRun,
+		Status:     dJob.Status,
+		Progress:   dJob.Progress,
+		Processed:  dJob.Processed,
+		Created:    dJob.Created,
+		Updated:    dJob.Updated,
+		Failed:     dJob.Failed,
+		Message:    dJob.Message,
+		CreatedBy:  dJob.CreatedBy,
+		CreatedAt:  dJob.CreatedAt.Format(time.RFC3339),
+		Errors:     []importRowErrorResponse{},
+		httpStatus: httpStatus,
+	}
+	if dJob.FinishedAt != nil {
+		job.FinishedAt = dJob.FinishedAt.Format(time.RFC3339)
+	}
+	for _, e := range dJob.Errors {
+		job.Errors = append(job.Errors, importRowErrorResponse(e))
+	}
+	return job
+}
+
+var errCatalogFormatUnrecognized = errors.New("format unrecognized, use csv or onix")
+var errCatalogTooLarge = errors.New("catalog file is larger than allowed")
+
+func catalogFormatFromQuery(r *http.Request) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		return cataloghelper.FormatCSV, nil
+	}
+	if !cataloghelper.IsCatalogFormat(format) {
+		return "", errCatalogFormatUnrecognized
+	}
+	return format, nil
+}
+
+// BackgroundJobs runs work that outlives the request that started it. The
+// context given to a job is cancelled when the server shuts down, and the
+// server waits for the job to return before closing the database.
+type BackgroundJobs interface {
+	Go(job func(ctx context.Context))
+}
+
+type catalogImportAudit struct {
+	Job    string `json:"job_id"`
+	Format string `json:"format"`
+	DryRun bool   `json:"dry_run"`
+	Size   int64  `json:"size"`
+}
+
+// ImportCatalog stores the uploaded feed in a temporary file and imports it in
+// the background. The returned job can be polled with GetImportJob. Feeds
+// larger than maxSize bytes are refused with 413.
+func ImportCatalog(
+	db database.CatalogInterface,
+	audit database.AuditInterface,
+	jobs BackgroundJobs,
+	maxSize int64,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ImportCatalog: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		format, err := catalogFormatFromQuery(r)
+		if err != nil {
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+		dryRun := r.URL.Query().Get("dry_run") == "true"
+
+		if r.ContentLength > maxSize {
+			render.Render(w, r, PayloadTooLargeError(errCatalogTooLarge))
+			return
+		}
+		body := http.MaxBytesReader(w, r.Body, maxSize)
+
+		file, err := os.CreateTemp("", "catalog-import-*")
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Creating temporary import file failed")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		size, err := io.Copy(file, body)
+		if err == nil {
+			_, err = file.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				render.Render(w, r, PayloadTooLargeError(errCatalogTooLarge))
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Saving uploaded catalog failed")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		job, err := db.CreateImportJob(ctx, format, dryRun, sch.Email)
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while creating import job")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		recordAudit(r, audit, database.AuditCatalogImportStarted, sch.Email, catalogImportAudit{
+			Job:    job.ID.String(),
+			Format: format,
+			DryRun: dryRun,
+			Size:   size,
+		})
+
+		jobs.Go(func(ctx context.Context) {
+			defer os.Remove(file.Name())
+			defer file.Close()
+
+			result, err := cataloghelper.RunImport(ctx, db, *job, file, size)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("job", job.ID.String()).Msg("Catalog import failed")
+				return
+			}
+			log.Ctx(ctx).Info().
+				Str("job", job.ID.String()).
+				Int("created", result.Created).
+				Int("updated", result.Updated).
+				Int("failed", result.Failed).
+				Msg("Catalog import finished")
+		})
+
+		resp := importJobFromDatabase(*job, http.StatusAccepted)
+		render.Render(w, r, &resp)
+	}
+}
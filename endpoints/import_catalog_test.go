@@ -0,0 +1,161 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) UpsertCatalogEntry(ctx context.Context, entry database.CatalogEntry, dryRun bool) (bool, error) {
+	args := db.Called(entry, dryRun)
+	return args.Bool(0), args.Error(1)
+}
+
+func (db dBMock) ExportCatalog(ctx context.Context, each func(database.CatalogEntry) error) error {
+	args := db.Called()
+	if entries, ok := args.Get(0).([]database.CatalogEntry); ok {
+		for _, e := range entries {
+			if err := each(e); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (db dBMock) CreateImportJob(ctx context.Context, format string, dryRun bool, createdBy string) (*database.ImportJob, error) {
+	args := db.Called(format, dryRun, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ImportJob), args.Error(1)
+}
+
+func (db dBMock) UpdateImportJob(ctx context.Context, job database.ImportJob) error {
+	args := db.Called(job.ID, job.Status)
+	return args.Error(0)
+}
+
+func (db dBMock) AddImportRowError(ctx context.Context, jobID uuid.UUID, rowError database.ImportRowError) error {
+	args := db.Called(jobID, rowError)
+	return args.Error(0)
+}
+
+func (db dBMock) GetImportJob(ctx context.Context, jobID uuid.UUID) (*database.ImportJob, error) {
+	args := db.Called(jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.ImportJob), args.Error(1)
+}
+
+// goJobs runs background jobs like the server did before it waited for them.
+type goJobs struct{}
+
+func (goJobs) Go(job func(ctx context.Context)) {
+	go job(context.Background())
+}
+
+func TestSuccessfulImportCatalog(t *testing.T) {
+	path := "/admin/catalog/import?format=onix&dry_run=true"
+
+	expJob := &database.ImportJob{
+		ID:        uuid.New(),
+		Format:    "onix",
+		DryRun:    true,
+		Status:    database.ImportJobRunning,
+		CreatedBy: expID.Account,
+		CreatedAt: time.Now(),
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("CreateImportJob", "onix", true, expID.Account).
+		Return(expJob, nil).Once()
+	// The import itself runs in the background and may finish after the test.
+	dbMock.On("UpdateImportJob", expJob.ID, mock.Anything).
+		Return(nil).Maybe()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ImportCatalog(dbMock, newAuditMock(), goJobs{}, 1<<20)
+	handler.ServeHTTP(w, r)
+
+	expResp := importJobFromDatabase(*expJob, 0)
+	expCode := http.StatusAccepted
+
+	resp := &importJobResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful catalog import didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful catalog import didn't return a valid importJobResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful catalog import didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedImportCatalog(t *testing.T) {
+	path := "/admin/catalog/import?format=xlsx"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ImportCatalog(dbMock, newAuditMock(), goJobs{}, 1<<20)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errCatalogFormatUnrecognized).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A catalog import with an unknown format didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A catalog import with an unknown format didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A catalog import with an unknown format didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+
+	path = "/admin/catalog/import"
+	dbMock.On("CreateImportJob", "csv", false, expID.Account).
+		Return(nil, sql.ErrConnDone).Once()
+
+	w, r = mockRequest(t, path, nil, true)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode = InternalServerError().(*ErrorResponse).sentForm()
+
+	resp = &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An errored catalog import didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An errored catalog import didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An errored catalog import didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestTooLargeImportCatalog(t *testing.T) {
+	path := "/admin/catalog/import"
+	feed := strings.Repeat("9780000000001,Title\n", 10)
+
+	dbMock := dBMock{&mock.Mock{}}
+	handler := ImportCatalog(dbMock, newAuditMock(), goJobs{}, int64(len(feed)-1))
+
+	expResp, expCode := PayloadTooLargeError(errCatalogTooLarge).(*ErrorResponse).sentForm()
+
+	// Once with the length announced, once streamed without it.
+	for _, contentLength := range []int64{int64(len(feed)), -1} {
+		w, r := mockRequest(t, path, nil, true)
+		r.Body = io.NopCloser(strings.NewReader(feed))
+		r.ContentLength = contentLength
+		handler.ServeHTTP(w, r)
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A catalog import over the size limit didn't return the proper response code")
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A catalog import over the size limit didn't return a valid errorResponse object") {
+			assert.Equal(t, expResp, *resp, "A catalog import over the size limit didn't return the proper error")
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
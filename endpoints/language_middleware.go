@@ -0,0 +1,24 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+)
+
+// AccountLanguageMiddleware has to run after SessionAuthenticatorMiddleware.
+// It sends the messages in the language the account picked, when it picked
+// one. Like the role, it's read from the database rather than the token, so
+// a change applies to every device at once, but only when a message is sent.
+func AccountLanguageMiddleware(
+	db database.UserAccountInterface,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sch, err := sessiontoken.FromContext(r.Context()); err == nil && sch != nil {
+				r = withAccountLanguage(r, db, sch.Email)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const accountLoansPageSize = 50
+
+// ListAccountLoans lists every book an account started, finished or not,
+// with its reading progress, the last one read first.
+func ListAccountLoans(
+	db database.AdminUserInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		email := chi.URLParam(r, "email")
+
+		if _, err := db.GetAccount(ctx, email); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an account")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		books, err := db.GetAccountLoans(ctx, email, accountLoansPageSize, page*accountLoansPageSize)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing an account's loans")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AccountLoansResponse{Data: ReadingFromDatabase(books, 0).Data}
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,42 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// ListAccountSessions lists the devices signed in to an account, the most
+// recently refreshed first.
+func ListAccountSessions(
+	db database.AdminUserInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		email := chi.URLParam(r, "email")
+
+		if _, err := db.GetAccount(ctx, email); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an account")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		sessions, err := db.GetAccountSessions(ctx, email, time.Now())
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing an account's sessions")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AccountSessionsFromDatabase(sessions)
+		render.Render(w, r, &resp)
+	}
+}
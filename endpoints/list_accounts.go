@@ -0,0 +1,40 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const accountsPageSize = 50
+
+// ListAccounts searches the accounts by email or name for support staff, or
+// lists all of them without a query.
+func ListAccounts(
+	db database.AdminUserInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		accounts, err := db.SearchAccounts(ctx, r.URL.Query().Get("query"), accountsPageSize, page*accountsPageSize)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while searching accounts")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AccountsResponse{Data: []AccountResponse{}}
+		for _, account := range accounts {
+			resp.Data = append(resp.Data, AccountFromDatabase(account))
+		}
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,112 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SearchAccounts(ctx context.Context, query string, limit int, offset int) ([]database.Account, error) {
+	args := db.Called(query, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Account), args.Error(1)
+}
+
+func (db dBMock) GetAccount(ctx context.Context, email string) (*database.Account, error) {
+	args := db.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Account), args.Error(1)
+}
+
+func (db dBMock) GetAccountSessions(ctx context.Context, email string, currTime time.Time) ([]database.AccountSession, error) {
+	args := db.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.AccountSession), args.Error(1)
+}
+
+func (db dBMock) GetAccountLoans(ctx context.Context, email string, limit int, offset int) ([]database.ReadingBook, error) {
+	args := db.Called(email, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ReadingBook), args.Error(1)
+}
+
+func (db dBMock) SetAccountStatus(ctx context.Context, email string, status string, reason string) (*database.Account, error) {
+	args := db.Called(email, status, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Account), args.Error(1)
+}
+
+func (db dBMock) RevokeAccountSessions(ctx context.Context, email string) (int64, error) {
+	args := db.Called(email)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (db dBMock) SetAccountRole(ctx context.Context, email string, role string) error {
+	args := db.Called(email, role)
+	return args.Error(0)
+}
+
+func (db dBMock) RevokeAllSessions(ctx context.Context) (int64, error) {
+	args := db.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+const mockAccountEmail = "someone@example.com"
+
+func mockAccount() database.Account {
+	return database.Account{
+		Email:       mockAccountEmail,
+		Name:        "Someone",
+		Role:        database.RoleUser,
+		Activated:   true,
+		HasPassword: true,
+		HasGoogle:   true,
+		Status:      database.AccountActive,
+		Sessions:    2,
+	}
+}
+
+func TestSuccessfulListAccounts(t *testing.T) {
+	expAccounts := []database.Account{mockAccount()}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SearchAccounts", "someone", accountsPageSize, accountsPageSize).
+		Return(expAccounts, nil).Once()
+
+	w, r := mockRequest(t, "/admin/users?query=someone&page=1", nil, true)
+	handler := ListAccounts(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AccountsResponse{Data: []AccountResponse{{
+		Email:         mockAccountEmail,
+		Name:          "Someone",
+		Role:          database.RoleUser,
+		Activated:     true,
+		SignInMethods: []string{loginMethodPassword, loginMethodGoogle},
+		Status:        database.AccountActive,
+		Sessions:      2,
+	}}}
+
+	resp := &AccountsResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful account search didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful account search didn't return a valid AccountsResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful account search didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
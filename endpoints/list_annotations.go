@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	annotationsDefaultLimit = 50
+	annotationsMaxLimit     = 200
+)
+
+var errAnnotationLimitInvalid = errors.New("limit must be between 1 and 200")
+
+// ListAnnotations pages through the annotations of a book the account can
+// read, oldest first. The next_cursor of a page gets the one after it.
+func ListAnnotations(
+	db database.AnnotationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListAnnotations: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Listing annotations of a malformed book id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		limit := annotationsDefaultLimit
+		if l := r.URL.Query().Get("limit"); l != "" {
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 || limit > annotationsMaxLimit {
+				render.Render(w, r, BadRequestError(errAnnotationLimitInvalid))
+				return
+			}
+		}
+		var after *database.AnnotationCursor
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			if after, err = decodeAnnotationCursor(cursor); err != nil {
+				render.Render(w, r, BadRequestError(err))
+				return
+			}
+		}
+
+		// One more than asked tells whether there's a next page.
+		annotations, err := db.ListBookAnnotations(ctx, bookID, after, limit+1, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing annotations")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		var next string
+		if len(annotations) > limit {
+			annotations = annotations[:limit]
+			next = encodeAnnotationCursor(annotations[limit-1])
+		}
+
+		resp := AnnotationsFromDatabase(annotations, sch.Email)
+		resp.NextCursor = next
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) ListBookAnnotations(ctx context.Context, bookID uuid.UUID, after *database.AnnotationCursor, limit int, accountID string) ([]database.Annotation, error) {
+	args := db.Called(bookID, after, limit, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Annotation), args.Error(1)
+}
+
+func TestSuccessfulListAnnotations(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/annotations?limit=2"
+
+	expDBAnnotations := []database.Annotation{mockAnnotation(bookID), mockAnnotation(bookID), mockAnnotation(bookID)}
+	expDBAnnotations[1].CreatedAt = expDBAnnotations[1].CreatedAt.Add(time.Minute)
+	expDBAnnotations[2].AccountID = "friend@example.com"
+	expDBAnnotations[2].Privacy = database.AnnotationShared
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ListBookAnnotations", bookID, (*database.AnnotationCursor)(nil), 3, expID.Account).
+		Return(expDBAnnotations, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := ListAnnotations(dbMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &AnnotationsResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful annotation list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful annotation list didn't return a valid AnnotationsResponse object") {
+		expResp := AnnotationsFromDatabase(expDBAnnotations[:2], expID.Account)
+		assert.Equal(t, expResp.Data, resp.Data, "A successful annotation list didn't return the asked page")
+		assert.NotEmpty(t, resp.NextCursor, "A successful annotation list with more annotations didn't return a cursor")
+	}
+	dbMock.AssertExpectations(t)
+
+	cursor, err := decodeAnnotationCursor(resp.NextCursor)
+	if assert.Nil(t, err, "A successful annotation list returned a malformed cursor") {
+		assert.Equal(t, expDBAnnotations[1].ID, cursor.ID, "A successful annotation list's cursor didn't point after the page")
+		assert.True(t, expDBAnnotations[1].CreatedAt.Equal(cursor.CreatedAt), "A successful annotation list's cursor didn't point after the page")
+	}
+
+	dbMock.On("ListBookAnnotations", bookID, cursor, 3, expID.Account).
+		Return(expDBAnnotations[2:], nil).Once()
+
+	w, r = mockRequest(t, path+"&cursor="+resp.NextCursor, nil, true, param{"id", bookID.String()})
+	handler.ServeHTTP(w, r)
+
+	resp = &AnnotationsResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful annotation list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful annotation list didn't return a valid AnnotationsResponse object") {
+		assert.Equal(t, AnnotationsFromDatabase(expDBAnnotations[2:], expID.Account), *resp, "A successful last annotation page didn't return a valid response")
+		assert.False(t, resp.Data[0].IsOwn, "A successful annotation list marked a followed account's annotation as the account's own")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedListAnnotations(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/annotations"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	tests := []struct {
+		query   string
+		expResp *ErrorResponse
+	}{
+		{"?cursor=abc", BadRequestError(errAnnotationCursorMalformed).(*ErrorResponse)},
+		{"?limit=0", BadRequestError(errAnnotationLimitInvalid).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, path+test.query, nil, true, param{"id", bookID.String()})
+		handler := ListAnnotations(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A malformed annotation list didn't return the proper response code")
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed annotation list didn't return a valid errorResponse object") {
+			assert.Equal(t, expResp, *resp, "A malformed annotation list didn't return the proper error")
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,132 @@
+package endpoints
+
+import (
+	"encoding/csv"
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const auditPageSize = 100
+
+var errAuditActionUnrecognized = errors.New("audit action unrecognized")
+var errAuditTimeMalformed = errors.New("from and until must be RFC 3339 times")
+var errAuditFormatUnrecognized = errors.New("format must be either json or csv")
+
+func auditFilterFromQuery(r *http.Request) (database.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := database.AuditFilter{
+		Actor:  strings.TrimSpace(query.Get("actor")),
+		Action: strings.TrimSpace(query.Get("action")),
+	}
+	if filter.Action != "" && !slices.Contains(database.AuditActions, filter.Action) {
+		return filter, errAuditActionUnrecognized
+	}
+
+	for _, t := range []struct {
+		key  string
+		into *time.Time
+	}{{"from", &filter.From}, {"until", &filter.Until}} {
+		value := query.Get(t.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, errAuditTimeMalformed
+		}
+		*t.into = parsed
+	}
+	return filter, nil
+}
+
+// ListAuditEvents lists the audit log, the newest events first, filtered by
+// actor, action and time. With format=csv it downloads every matching event
+// instead, the oldest first so the hash chain reads in order.
+func ListAuditEvents(
+	db database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		filter, err := auditFilterFromQuery(r)
+		if err != nil {
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+		format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+		if format == "" {
+			format = "json"
+		}
+		if format != "json" && format != "csv" {
+			render.Render(w, r, BadRequestError(errAuditFormatUnrecognized))
+			return
+		}
+
+		if format == "csv" {
+			w.Header().Set("content-type", "text/csv; charset=utf-8")
+			w.Header().Set("content-disposition", `attachment; filename="audit.csv"`)
+			out := csv.NewWriter(w)
+			out.Write(auditCSVHeader)
+			err := db.ExportAuditEvents(ctx, filter, func(event database.AuditEvent) error {
+				return out.Write(AuditEventFromDatabase(event).csvRecord())
+			})
+			out.Flush()
+			if err == nil {
+				err = out.Error()
+			}
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Exporting the audit log failed")
+			}
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		events, err := db.ListAuditEvents(ctx, filter, auditPageSize, page*auditPageSize)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing the audit log")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AuditEventsResponse{Data: []AuditEventResponse{}}
+		for _, event := range events {
+			resp.Data = append(resp.Data, AuditEventFromDatabase(event))
+		}
+		render.Render(w, r, &resp)
+	}
+}
+
+// VerifyAuditLog walks the hash chain of the audit log and tells the first
+// event that was altered, or that follows a removed one.
+func VerifyAuditLog(
+	db database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		verification, err := db.VerifyAuditChain(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while verifying the audit log")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		render.Render(w, r, &AuditVerificationResponse{
+			Intact:   verification.BrokenAt == 0,
+			Checked:  verification.Checked,
+			BrokenAt: verification.BrokenAt,
+		})
+	}
+}
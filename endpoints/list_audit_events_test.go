@@ -0,0 +1,158 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) RecordAuditEvent(ctx context.Context, event database.AuditEvent) error {
+	args := db.Called(event)
+	return args.Error(0)
+}
+
+func (db dBMock) ListAuditEvents(ctx context.Context, filter database.AuditFilter, limit int, offset int) ([]database.AuditEvent, error) {
+	args := db.Called(filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.AuditEvent), args.Error(1)
+}
+
+func (db dBMock) ExportAuditEvents(ctx context.Context, filter database.AuditFilter, each func(database.AuditEvent) error) error {
+	args := db.Called(filter)
+	if events, ok := args.Get(0).([]database.AuditEvent); ok {
+		for _, event := range events {
+			if err := each(event); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (db dBMock) VerifyAuditChain(ctx context.Context) (database.AuditVerification, error) {
+	args := db.Called()
+	return args.Get(0).(database.AuditVerification), args.Error(1)
+}
+
+func mockAuditEvent(id int64, action string) database.AuditEvent {
+	return database.AuditEvent{
+		ID:         id,
+		OccurredAt: time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC),
+		Action:     action,
+		Actor:      expID.Account,
+		IP:         "192.0.2.1",
+		UserAgent:  "Go-http-client/1.1",
+		RequestID:  "host/abc-000001",
+		Payload:    json.RawMessage(`{"method":"password"}`),
+		PrevHash:   "0f",
+		Hash:       "1e",
+	}
+}
+
+func TestSuccessfulListAuditEvents(t *testing.T) {
+	path := "/admin/audit?actor=" + expID.Account + "&action=login.succeeded&from=2022-03-01T00:00:00Z&page=2"
+
+	expFilter := database.AuditFilter{
+		Actor:  expID.Account,
+		Action: database.AuditLoginSucceeded,
+		From:   time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	expEvents := []database.AuditEvent{mockAuditEvent(2, database.AuditLoginSucceeded), mockAuditEvent(1, database.AuditLoginSucceeded)}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ListAuditEvents", expFilter, auditPageSize, 2*auditPageSize).
+		Return(expEvents, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListAuditEvents(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AuditEventsResponse{Data: []AuditEventResponse{AuditEventFromDatabase(expEvents[0]), AuditEventFromDatabase(expEvents[1])}}
+
+	resp := &AuditEventsResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful audit log listing didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful audit log listing didn't return a valid AuditEventsResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful audit log listing didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestCSVListAuditEvents(t *testing.T) {
+	path := "/admin/audit?format=csv&until=2022-04-01T00:00:00%2B07:00"
+
+	expFilter := database.AuditFilter{Until: time.Date(2022, 4, 1, 0, 0, 0, 0, time.FixedZone("", 7*60*60))}
+	expEvents := []database.AuditEvent{mockAuditEvent(1, database.AuditLoginFailed), mockAuditEvent(2, database.AuditLoginSucceeded)}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ExportAuditEvents", mock.MatchedBy(func(f database.AuditFilter) bool {
+		return f.Until.Equal(expFilter.Until) && f.From.IsZero() && f.Actor == "" && f.Action == ""
+	})).Return(expEvents, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListAuditEvents(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "A CSV audit log export didn't return the proper response code")
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("content-type"), "A CSV audit log export didn't return CSV")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if assert.NoError(t, err, "A CSV audit log export didn't return valid CSV") && assert.Len(t, records, 3, "A CSV audit log export didn't return every event") {
+		assert.Equal(t, auditCSVHeader, records[0], "A CSV audit log export didn't start with its header")
+		assert.Equal(t, AuditEventFromDatabase(expEvents[0]).csvRecord(), records[1], "A CSV audit log export didn't return the events oldest first")
+		assert.Equal(t, `{"method":"password"}`, records[2][7], "A CSV audit log export didn't keep the payload")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedListAuditEvents(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		expErr error
+	}{
+		{"action=book.deleted", errAuditActionUnrecognized},
+		{"from=yesterday", errAuditTimeMalformed},
+		{"format=xml", errAuditFormatUnrecognized},
+	} {
+		dbMock := dBMock{&mock.Mock{}}
+
+		w, r := mockRequest(t, "/admin/audit?"+tc.query, nil, true)
+		handler := ListAuditEvents(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := BadRequestError(tc.expErr).(*ErrorResponse).sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A malformed audit log listing didn't return the proper response code for %s", tc.query)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed audit log listing didn't return a valid errorResponse object") {
+			assert.Equal(t, expResp, *resp, "A malformed audit log listing didn't return the proper error for %s", tc.query)
+		}
+		dbMock.AssertExpectations(t)
+	}
+}
+
+func TestBrokenVerifyAuditLog(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VerifyAuditChain").
+		Return(database.AuditVerification{Checked: 12, BrokenAt: 12}, nil).Once()
+
+	w, r := mockRequest(t, "/admin/audit/verify", nil, true)
+	handler := VerifyAuditLog(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AuditVerificationResponse{Intact: false, Checked: 12, BrokenAt: 12}
+
+	resp := &AuditVerificationResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "Verifying a tampered audit log didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "Verifying a tampered audit log didn't return a valid AuditVerificationResponse object") {
+		assert.Equal(t, expResp, *resp, "Verifying a tampered audit log didn't point at the altered event")
+	}
+	dbMock.AssertExpectations(t)
+}
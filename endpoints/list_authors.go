@@ -0,0 +1,39 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const authorsPageSize = 20
+
+func ListAuthors(
+	db database.AuthorInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query := strings.TrimSpace(r.URL.Query().Get("query"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+		log.Ctx(ctx).Debug().Str("query", query).Int("page", page).Send()
+
+		authors, err := db.SearchAuthors(ctx, authorsPageSize, page*authorsPageSize, query)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while searching authors")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		authorsResponse := AuthorsFromDatabase(authors)
+
+		render.Render(w, r, &authorsResponse)
+	}
+}
@@ -0,0 +1,71 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SearchAuthors(ctx context.Context, limit int, offset int, query string) ([]database.Author, error) {
+	args := db.Called(limit, offset, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Author), args.Error(1)
+}
+
+func TestSuccessfulListAuthors(t *testing.T) {
+	expQuery := "Hayashi"
+	path := "/authors?query=" + expQuery + "&page=1"
+
+	expDBAuthors := []database.Author{
+		{ID: uuid.New(), Name: "Hayashi Naotaka", BookCount: 3},
+		{ID: uuid.New(), Name: "Hayashi Naotaka", BookCount: 1},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SearchAuthors", 20, 20, expQuery).
+		Return(expDBAuthors, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListAuthors(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AuthorsFromDatabase(expDBAuthors)
+	expCode := http.StatusOK
+
+	resp := &AuthorsResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful authors search didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful authors search didn't return a valid AuthorsResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful authors search didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedListAuthors(t *testing.T) {
+	path := "/authors"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SearchAuthors", 20, 0, "").
+		Return(nil, errors.New("unexpected error")).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListAuthors(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A failed authors search didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A failed authors search didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A failed authors search didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -7,27 +7,35 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
 var errListBookCriteriaUnrecognized = errors.New("criteria unrecognized")
+var errGenreIDMalformed = errors.New("genre id malformed")
+var errPopularityWindowUnrecognized = errors.New("window must be one of 7d, 30d or all")
 
+// ListBooks lists books by criteria. New arrivals are the books acquired
+// within newArrivalWindow, popular books are ranked over the window query
+// parameter, 30 days unless given.
 func ListBooks(
 	db database.BookInterface,
+	newArrivalWindow time.Duration,
 ) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		sch, err := sessiontoken.FromContext(ctx)
 		if err != nil {
-			log.Error().Err(err).Msg("ListBook: Getting account failed unexpectedly")
+			log.Ctx(ctx).Error().Err(err).Msg("ListBook: Getting account failed unexpectedly")
 			render.Render(w, r, InternalServerError())
 			return
 		}
 		if sch == nil {
-			log.Error().Msg("ListBook: Getting account returned nil")
+			log.Ctx(ctx).Error().Msg("ListBook: Getting account returned nil")
 			render.Render(w, r, InternalServerError())
 			return
 		}
@@ -38,28 +46,46 @@ func ListBooks(
 			render.Render(w, r, InternalServerError())
 			return
 		}
-		log.Debug().Str("criteria", criteria).Int("page", page).Send()
+		var genreID uuid.NullUUID
+		if genre := strings.TrimSpace(r.URL.Query().Get("genre")); genre != "" {
+			id, err := uuid.Parse(genre)
+			if err != nil {
+				log.Ctx(ctx).Debug().Err(err).Msg("ListBook: genre id malformed")
+				render.Render(w, r, BadRequestError(errGenreIDMalformed))
+				return
+			}
+			genreID = uuid.NullUUID{UUID: id, Valid: true}
+		}
+		window := strings.TrimSpace(r.URL.Query().Get("window"))
+		if window == "" {
+			window = database.PopularityWindow30d
+		} else if !database.IsPopularityWindow(window) {
+			log.Ctx(ctx).Debug().Str("window", window).Msg("ListBook: popularity window unrecognized")
+			render.Render(w, r, BadRequestError(errPopularityWindowUnrecognized))
+			return
+		}
+		log.Ctx(ctx).Debug().Str("criteria", criteria).Int("page", page).Str("window", window).Send()
 
 		var books []database.Book
 		switch criteria {
 		case "newHomepage":
-			books, err = db.GetNewBooksPaginated(ctx, 8, 0, sch.Email)
+			books, err = db.GetNewBooksPaginated(ctx, 8, 0, newArrivalWindow, genreID, sch.Email)
 		case "new":
-			books, err = db.GetNewBooksPaginated(ctx, 20, page, sch.Email)
+			books, err = db.GetNewBooksPaginated(ctx, 20, page, newArrivalWindow, genreID, sch.Email)
 		case "popularHomepage":
-			books, err = db.GetPopularBooksPaginated(ctx, 8, 0, sch.Email)
+			books, err = db.GetPopularBooksPaginated(ctx, 8, 0, window, genreID, sch.Email)
 		case "popular":
-			books, err = db.GetPopularBooksPaginated(ctx, 20, page, sch.Email)
+			books, err = db.GetPopularBooksPaginated(ctx, 20, page, window, genreID, sch.Email)
 		case "search", "":
 			query := strings.TrimSpace(r.URL.Query().Get("query"))
-			books, err = db.SearchBooks(ctx, 20, page, query, sch.Email)
+			books, err = db.SearchBooks(ctx, 20, page, query, genreID, sch.Email)
 		default:
-			log.Debug().Str("criteria", criteria).Msg("ListBook: criteria unrecognized")
+			log.Ctx(ctx).Debug().Str("criteria", criteria).Msg("ListBook: criteria unrecognized")
 			render.Render(w, r, BadRequestError(errListBookCriteriaUnrecognized))
 			return
 		}
 		if err != nil {
-			log.Error().Err(err).Msg("ListBook: Getting account failed unexpectedly")
+			log.Ctx(ctx).Error().Err(err).Msg("ListBook: Getting account failed unexpectedly")
 			render.Render(w, r, InternalServerError())
 			return
 		}
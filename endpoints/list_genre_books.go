@@ -0,0 +1,65 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const genreBooksPageSize = 20
+
+var errGenreNotFound = errors.New("genre not found")
+
+func ListGenreBooks(
+	db database.GenreInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListGenreBooks: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		genreID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Listing books of a malformed genre id")
+			render.Render(w, r, BadRequestError(errGenreIDMalformed))
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		if _, err := db.GetGenre(ctx, genreID); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting a genre")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		books, err := db.GetGenreBooks(ctx, genreID, genreBooksPageSize, page*genreBooksPageSize, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing a genre's books")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		booksResponse := BooksFromDatabase(books)
+
+		render.Render(w, r, &booksResponse)
+	}
+}
@@ -0,0 +1,78 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetGenreBooks(ctx context.Context, genreID uuid.UUID, limit int, offset int, accountID string) ([]database.Book, error) {
+	args := db.Called(genreID, limit, offset, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Book), args.Error(1)
+}
+
+func TestSuccessfulListGenreBooks(t *testing.T) {
+	genre := &database.Genre{ID: uuid.New(), Name: "Fantasy"}
+	path := "/genres/" + genre.ID.String() + "/books?page=2"
+
+	expDBBooks := []database.Book{
+		{
+			ID:     uuid.New(),
+			Title:  "aaaa",
+			Author: "ae",
+			Genres: database.BookGenres{{ID: genre.ID, Name: genre.Name}},
+			Tags:   database.BookTags{"dragons"},
+		},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetGenre", genre.ID).
+		Return(genre, nil).Once()
+	dbMock.On("GetGenreBooks", genre.ID, 20, 40, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", genre.ID.String()})
+	handler := ListGenreBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := BooksFromDatabase(expDBBooks)
+	expCode := http.StatusOK
+
+	resp := &BooksResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful genre book list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful genre book list didn't return a valid BooksResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful genre book list didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundListGenreBooks(t *testing.T) {
+	genreID := uuid.New()
+	path := "/genres/" + genreID.String() + "/books"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetGenre", genreID).
+		Return(nil, database.ErrGenreNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", genreID.String()})
+	handler := ListGenreBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errGenreNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing genre book list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing genre book list didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing genre book list didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
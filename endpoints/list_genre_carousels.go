@@ -0,0 +1,61 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// How many books each homepage genre carousel holds.
+const genreCarouselSize = 8
+
+// ListGenreCarousels returns a carousel for every top level genre that has
+// books in it, for the homepage.
+func ListGenreCarousels(
+	db database.GenreInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListGenreCarousels: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		genres, err := db.GetGenres(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing genres")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		carousels := GenreCarouselsResponse{Data: []genreCarouselResponse{}}
+		for _, genre := range genres {
+			if genre.ParentID.Valid {
+				continue
+			}
+
+			books, err := db.GetGenreBooks(ctx, genre.ID, genreCarouselSize, 0, sch.Email)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("genre", genre.ID.String()).Msg("Database error while listing a genre's books")
+				render.Render(w, r, InternalServerError())
+				return
+			}
+			if len(books) == 0 {
+				continue
+			}
+
+			carousels.Data = append(carousels.Data, genreCarouselResponse{
+				Genre: GenreFromDatabase(genre),
+				Books: BooksFromDatabase(books).Data,
+			})
+		}
+
+		render.Render(w, r, &carousels)
+	}
+}
@@ -0,0 +1,47 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulListGenreCarousels(t *testing.T) {
+	path := "/genres/carousels"
+
+	fiction := database.Genre{ID: uuid.New(), Name: "Fiction"}
+	fantasy := database.Genre{ID: uuid.New(), ParentID: uuid.NullUUID{UUID: fiction.ID, Valid: true}, Name: "Fantasy"}
+	poetry := database.Genre{ID: uuid.New(), Name: "Poetry"}
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "ae"},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetGenres").
+		Return([]database.Genre{fantasy, fiction, poetry}, nil).Once()
+	dbMock.On("GetGenreBooks", fiction.ID, 8, 0, expID.Account).
+		Return(expDBBooks, nil).Once()
+	dbMock.On("GetGenreBooks", poetry.ID, 8, 0, expID.Account).
+		Return([]database.Book{}, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListGenreCarousels(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := GenreCarouselsResponse{Data: []genreCarouselResponse{
+		{Genre: GenreFromDatabase(fiction), Books: BooksFromDatabase(expDBBooks).Data},
+	}}
+	expCode := http.StatusOK
+
+	resp := &GenreCarouselsResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful genre carousel list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful genre carousel list didn't return a valid GenreCarouselsResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful genre carousel list should've only had top level genres with books")
+	}
+	dbMock.AssertExpectations(t)
+}
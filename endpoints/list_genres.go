@@ -0,0 +1,28 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+func ListGenres(
+	db database.GenreInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		genres, err := db.GetGenres(ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing genres")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		genresResponse := GenreTreeFromDatabase(genres)
+
+		render.Render(w, r, &genresResponse)
+	}
+}
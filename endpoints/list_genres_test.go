@@ -0,0 +1,62 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetGenres(ctx context.Context) ([]database.Genre, error) {
+	args := db.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Genre), args.Error(1)
+}
+
+func (db dBMock) GetGenre(ctx context.Context, genreID uuid.UUID) (*database.Genre, error) {
+	args := db.Called(genreID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Genre), args.Error(1)
+}
+
+func TestSuccessfulListGenres(t *testing.T) {
+	path := "/genres"
+
+	fiction := database.Genre{ID: uuid.New(), Name: "Fiction", BISAC: "FIC000000", BookCount: 1}
+	fantasy := database.Genre{ID: uuid.New(), ParentID: uuid.NullUUID{UUID: fiction.ID, Valid: true}, Name: "Fantasy", BookCount: 4}
+	epic := database.Genre{ID: uuid.New(), ParentID: uuid.NullUUID{UUID: fantasy.ID, Valid: true}, Name: "Epic", BookCount: 2}
+	science := database.Genre{ID: uuid.New(), Name: "Science", Dewey: "500"}
+	expDBGenres := []database.Genre{epic, fantasy, fiction, science}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetGenres").
+		Return(expDBGenres, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListGenres(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expEpic := GenreFromDatabase(epic)
+	expFantasy := GenreFromDatabase(fantasy)
+	expFantasy.Children = []GenreResponse{expEpic}
+	expFiction := GenreFromDatabase(fiction)
+	expFiction.Children = []GenreResponse{expFantasy}
+	expResp := GenresResponse{Data: []GenreResponse{expFiction, GenreFromDatabase(science)}}
+	expCode := http.StatusOK
+
+	resp := &GenresResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful genre list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful genre list didn't return a valid GenresResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful genre list didn't return the genre tree")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -7,14 +7,15 @@ import (
 	"ic-rhadi/e_library/database"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-func (db dBMock) GetNewBooksPaginated(ctx context.Context, limit int, offset int, accountID string) ([]database.Book, error) {
-	args := db.Called(limit, offset, accountID)
+func (db dBMock) GetNewBooksPaginated(ctx context.Context, limit int, offset int, newWindow time.Duration, genreID uuid.NullUUID, accountID string) ([]database.Book, error) {
+	args := db.Called(limit, offset, newWindow, genreID, accountID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -39,13 +40,13 @@ func TestSuccessfulHomepageListNewBooks(t *testing.T) {
 	}
 
 	dbMock := dBMock{&mock.Mock{}}
-	dbMock.On("GetNewBooksPaginated", 8, 0, expID.Account).
+	dbMock.On("GetNewBooksPaginated", 8, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	expCode := http.StatusOK
 
 	w, r := mockRequest(t, path, nil, true)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp := BooksFromDatabase(expDBBooks)
@@ -59,11 +60,11 @@ func TestSuccessfulHomepageListNewBooks(t *testing.T) {
 
 	expDBBooks = []database.Book{}
 
-	dbMock.On("GetNewBooksPaginated", 8, 0, expID.Account).
+	dbMock.On("GetNewBooksPaginated", 8, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp = BooksFromDatabase(expDBBooks)
@@ -82,7 +83,7 @@ func TestFailedHomepageListNewBooks(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 
 	w, r := mockRequest(t, path, nil, false)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
@@ -94,11 +95,11 @@ func TestFailedHomepageListNewBooks(t *testing.T) {
 	}
 	dbMock.AssertExpectations(t)
 
-	dbMock.On("GetNewBooksPaginated", 8, 0, expID.Account).
+	dbMock.On("GetNewBooksPaginated", 8, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
 		Return(nil, sql.ErrConnDone).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode = InternalServerError().(*ErrorResponse).sentForm()
@@ -13,8 +13,8 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-func (db dBMock) GetPopularBooksPaginated(ctx context.Context, limit int, offset int, accountID string) ([]database.Book, error) {
-	args := db.Called(limit, offset, accountID)
+func (db dBMock) GetPopularBooksPaginated(ctx context.Context, limit int, offset int, window string, genreID uuid.NullUUID, accountID string) ([]database.Book, error) {
+	args := db.Called(limit, offset, window, genreID, accountID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -39,13 +39,13 @@ func TestSuccessfulHomepageListPopularBooks(t *testing.T) {
 	}
 
 	dbMock := dBMock{&mock.Mock{}}
-	dbMock.On("GetPopularBooksPaginated", 8, 0, expID.Account).
+	dbMock.On("GetPopularBooksPaginated", 8, 0, database.PopularityWindow30d, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	expCode := http.StatusOK
 
 	w, r := mockRequest(t, path, nil, true)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp := BooksFromDatabase(expDBBooks)
@@ -59,11 +59,11 @@ func TestSuccessfulHomepageListPopularBooks(t *testing.T) {
 
 	expDBBooks = []database.Book{}
 
-	dbMock.On("GetPopularBooksPaginated", 8, 0, expID.Account).
+	dbMock.On("GetPopularBooksPaginated", 8, 0, database.PopularityWindow30d, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp = BooksFromDatabase(expDBBooks)
@@ -82,7 +82,7 @@ func TestFailedHomepageListPopularBooks(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 
 	w, r := mockRequest(t, path, nil, false)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
@@ -94,11 +94,11 @@ func TestFailedHomepageListPopularBooks(t *testing.T) {
 	}
 	dbMock.AssertExpectations(t)
 
-	dbMock.On("GetPopularBooksPaginated", 8, 0, expID.Account).
+	dbMock.On("GetPopularBooksPaginated", 8, 0, database.PopularityWindow30d, uuid.NullUUID{}, expID.Account).
 		Return(nil, sql.ErrConnDone).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -0,0 +1,48 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const moderationQueuePageSize = 50
+
+// ListModerationQueue lists the reviews waiting for a moderator, flagged or
+// reported, the oldest first.
+func ListModerationQueue(
+	db database.ReviewInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListModerationQueue: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		reviews, err := db.GetModerationQueue(ctx, moderationQueuePageSize, page*moderationQueuePageSize)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing the moderation queue")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ReviewsResponse{Data: []ReviewResponse{}}
+		for _, review := range reviews {
+			resp.Data = append(resp.Data, ModerationReviewFromDatabase(review, sch.Email))
+		}
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,48 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetModerationQueue(ctx context.Context, limit int, offset int) ([]database.Review, error) {
+	args := db.Called(limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Review), args.Error(1)
+}
+
+func TestSuccessfulListModerationQueue(t *testing.T) {
+	path := "/admin/reviews?page=1"
+
+	flagged := mockReview(uuid.New(), "someone@else.com")
+	flagged.Status = database.ReviewPending
+	flagged.FlagReason = "listed word"
+	reported := mockReview(uuid.New(), "another@else.com")
+	reported.ReportReasons = database.ReviewReportReasons{"Spoils the ending"}
+	expDBReviews := []database.Review{flagged, reported}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetModerationQueue", 50, 50).
+		Return(expDBReviews, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListModerationQueue(dbMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &ReviewsResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful moderation queue request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful moderation queue request didn't return a valid ReviewsResponse object") {
+		assert.Equal(t, ModerationReviewFromDatabase(flagged, expID.Account), resp.Data[0], "A successful moderation queue request didn't return the flagged review")
+		assert.Equal(t, []string{"Spoils the ending"}, resp.Data[1].ReportReasons, "A successful moderation queue request didn't return the report reasons")
+	}
+	dbMock.AssertExpectations(t)
+}
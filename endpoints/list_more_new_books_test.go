@@ -7,14 +7,19 @@ import (
 	"ic-rhadi/e_library/database"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-func (db dBMock) GetNewBooks(ctx context.Context, accountID string) ([]database.Book, error) {
-	args := db.Called(accountID)
+// expNewArrivalWindow is the new arrival window ListBooks is built with in
+// tests.
+var expNewArrivalWindow = 30 * 24 * time.Hour
+
+func (db dBMock) GetNewBooks(ctx context.Context, newWindow time.Duration, accountID string) ([]database.Book, error) {
+	args := db.Called(newWindow, accountID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -39,13 +44,13 @@ func TestSuccessfulListMoreNewBooks(t *testing.T) {
 	}
 
 	dbMock := dBMock{&mock.Mock{}}
-	dbMock.On("GetNewBooksPaginated", 20, 0, expID.Account).
+	dbMock.On("GetNewBooksPaginated", 20, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	expCode := http.StatusOK
 
 	w, r := mockRequest(t, path, nil, true)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp := BooksFromDatabase(expDBBooks)
@@ -59,11 +64,11 @@ func TestSuccessfulListMoreNewBooks(t *testing.T) {
 
 	expDBBooks = []database.Book{}
 
-	dbMock.On("GetNewBooksPaginated", 20, 0, expID.Account).
+	dbMock.On("GetNewBooksPaginated", 20, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp = BooksFromDatabase(expDBBooks)
@@ -82,7 +87,7 @@ func TestFailedListMoreNewBooks(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 
 	w, r := mockRequest(t, path, nil, false)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
@@ -94,11 +99,11 @@ func TestFailedListMoreNewBooks(t *testing.T) {
 	}
 	dbMock.AssertExpectations(t)
 
-	dbMock.On("GetNewBooksPaginated", 20, 0, expID.Account).
+	dbMock.On("GetNewBooksPaginated", 20, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
 		Return(nil, sql.ErrConnDone).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode = InternalServerError().(*ErrorResponse).sentForm()
@@ -110,3 +115,42 @@ func TestFailedListMoreNewBooks(t *testing.T) {
 	}
 	dbMock.AssertExpectations(t)
 }
+
+func TestGenreListNewBooks(t *testing.T) {
+	genreID := uuid.New()
+	path := "/books?criteria=new&genre=" + genreID.String()
+
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "ae", Readers: 10},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetNewBooksPaginated", 20, 0, expNewArrivalWindow, uuid.NullUUID{UUID: genreID, Valid: true}, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	expResp := BooksFromDatabase(expDBBooks)
+
+	resp := &BooksResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A genre filtered New-Books-List didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A genre filtered New-Books-List didn't return a valid BooksResponse object") {
+		assert.Equal(t, expResp, *resp, "A genre filtered New-Books-List didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+
+	w, r = mockRequest(t, "/books?criteria=new&genre=abc", nil, true)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	expErrResp, expCode := BadRequestError(errGenreIDMalformed).(*ErrorResponse).sentForm()
+
+	errResp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed genre New-Books-List didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(errResp), "A malformed genre New-Books-List didn't return a valid errorResponse object") {
+		assert.Equal(t, expErrResp, *errResp, "A malformed genre New-Books-List didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
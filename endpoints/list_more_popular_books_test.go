@@ -13,8 +13,8 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-func (db dBMock) GetPopularBooks(ctx context.Context, accountID string) ([]database.Book, error) {
-	args := db.Called(accountID)
+func (db dBMock) GetPopularBooks(ctx context.Context, window string, accountID string) ([]database.Book, error) {
+	args := db.Called(window, accountID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -39,13 +39,13 @@ func TestSuccessfulListMorePopularBooks(t *testing.T) {
 	}
 
 	dbMock := dBMock{&mock.Mock{}}
-	dbMock.On("GetPopularBooksPaginated", 20, 0, expID.Account).
+	dbMock.On("GetPopularBooksPaginated", 20, 0, database.PopularityWindow30d, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	expCode := http.StatusOK
 
 	w, r := mockRequest(t, path, nil, true)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp := BooksFromDatabase(expDBBooks)
@@ -59,11 +59,11 @@ func TestSuccessfulListMorePopularBooks(t *testing.T) {
 
 	expDBBooks = []database.Book{}
 
-	dbMock.On("GetPopularBooksPaginated", 20, 0, expID.Account).
+	dbMock.On("GetPopularBooksPaginated", 20, 0, database.PopularityWindow30d, uuid.NullUUID{}, expID.Account).
 		Return(expDBBooks, nil).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp = BooksFromDatabase(expDBBooks)
@@ -82,7 +82,7 @@ func TestFailedListMorePopularBooks(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 
 	w, r := mockRequest(t, path, nil, false)
-	handler := ListBooks(dbMock)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
@@ -94,11 +94,11 @@ func TestFailedListMorePopularBooks(t *testing.T) {
 	}
 	dbMock.AssertExpectations(t)
 
-	dbMock.On("GetPopularBooksPaginated", 20, 0, expID.Account).
+	dbMock.On("GetPopularBooksPaginated", 20, 0, database.PopularityWindow30d, uuid.NullUUID{}, expID.Account).
 		Return(nil, sql.ErrConnDone).Once()
 
 	w, r = mockRequest(t, path, nil, true)
-	handler = ListBooks(dbMock)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -108,3 +108,41 @@ func TestFailedListMorePopularBooks(t *testing.T) {
 	}
 	dbMock.AssertExpectations(t)
 }
+
+func TestWindowListMorePopularBooks(t *testing.T) {
+	path := "/books?criteria=popular&window=7d"
+
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "ae", Readers: 10},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetPopularBooksPaginated", 20, 0, database.PopularityWindow7d, uuid.NullUUID{}, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListBooks(dbMock, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	expResp := BooksFromDatabase(expDBBooks)
+
+	resp := &BooksResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A windowed Popular-Books-List didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A windowed Popular-Books-List didn't return a valid BooksResponse object") {
+		assert.Equal(t, expResp, *resp, "A windowed Popular-Books-List didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+
+	w, r = mockRequest(t, "/books?criteria=popular&window=1y", nil, true)
+	handler = ListBooks(dbMock, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	expErrResp, expCode := BadRequestError(errPopularityWindowUnrecognized).(*ErrorResponse).sentForm()
+
+	errResp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An unknown window Popular-Books-List didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(errResp), "An unknown window Popular-Books-List didn't return a valid errorResponse object") {
+		assert.Equal(t, expErrResp, *errResp, "An unknown window Popular-Books-List didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
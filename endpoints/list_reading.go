@@ -0,0 +1,45 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const continueReadingLimit = 20
+
+// ListReading lists the books the account is in the middle of, for the
+// homepage's continue reading row, with how many books it finished.
+func ListReading(
+	db database.ReadingInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListReading: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		books, err := db.GetContinueReading(ctx, continueReadingLimit, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing books being read")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		finished, err := db.GetFinishedBookCount(ctx, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while counting finished books")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ReadingFromDatabase(books, finished)
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,67 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetContinueReading(ctx context.Context, limit int, accountID string) ([]database.ReadingBook, error) {
+	args := db.Called(limit, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.ReadingBook), args.Error(1)
+}
+
+func (db dBMock) GetFinishedBookCount(ctx context.Context, accountID string) (int, error) {
+	args := db.Called(accountID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestSuccessfulListReading(t *testing.T) {
+	path := "/me/reading"
+
+	bookID := uuid.New()
+	updatedAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+	expDBBooks := []database.ReadingBook{
+		{
+			Book: database.Book{ID: bookID, Title: "aaaa", Author: "ae", Readers: 2},
+			Progress: database.ReadingProgress{
+				BookID:     bookID,
+				Page:       12,
+				Percentage: 4,
+				DeviceID:   "phone",
+				UpdatedAt:  updatedAt,
+				ReceivedAt: updatedAt,
+			},
+		},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetContinueReading", 20, expID.Account).
+		Return(expDBBooks, nil).Once()
+	dbMock.On("GetFinishedBookCount", expID.Account).
+		Return(3, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListReading(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ReadingFromDatabase(expDBBooks, 3)
+
+	resp := &ReadingResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful reading list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful reading list didn't return a valid ReadingResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful reading list didn't return a valid response")
+		assert.Equal(t, 3, resp.FinishedCount, "A successful reading list didn't return the finished book count")
+	}
+	dbMock.AssertExpectations(t)
+}
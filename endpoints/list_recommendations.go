@@ -0,0 +1,44 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+const recommendationsPageSize = 20
+
+func ListRecommendations(
+	db database.RecommendationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListRecommendations: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		books, err := db.GetRecommendations(ctx, recommendationsPageSize, page*recommendationsPageSize, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing recommendations")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		booksResponse := BooksFromDatabase(books)
+
+		render.Render(w, r, &booksResponse)
+	}
+}
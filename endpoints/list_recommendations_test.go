@@ -0,0 +1,69 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetRecommendations(ctx context.Context, limit int, offset int, accountID string) ([]database.Book, error) {
+	args := db.Called(limit, offset, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Book), args.Error(1)
+}
+
+func TestSuccessfulListRecommendations(t *testing.T) {
+	path := "/me/recommendations?page=1"
+
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "ae", Readers: 10},
+		{ID: uuid.New(), Title: "bbbb", Author: "ae", Readers: 3},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetRecommendations", 20, 20, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListRecommendations(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := BooksFromDatabase(expDBBooks)
+
+	resp := &BooksResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful recommendation list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful recommendation list didn't return a valid BooksResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful recommendation list didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedListRecommendations(t *testing.T) {
+	path := "/me/recommendations"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetRecommendations", 20, 0, expID.Account).
+		Return(nil, sql.ErrConnDone).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListRecommendations(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An errored recommendation list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An errored recommendation list didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An errored recommendation list didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
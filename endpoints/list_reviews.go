@@ -0,0 +1,70 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const reviewsPageSize = 20
+
+var errReviewSortUnrecognized = errors.New("sort must be either helpful or recent")
+
+// ListReviews lists the published reviews of a book, the most helpful first
+// unless sort asks for the most recent.
+func ListReviews(
+	db database.ReviewInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListReviews: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Listing reviews of a book with a malformed id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		sort := r.URL.Query().Get("sort")
+		if sort == "" {
+			sort = database.ReviewSortHelpful
+		}
+		if !database.IsReviewSort(sort) {
+			render.Render(w, r, BadRequestError(errReviewSortUnrecognized))
+			return
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 0 {
+			page = 0
+		}
+
+		reviews, err := db.ListBookReviews(ctx, bookID, sort, reviewsPageSize, page*reviewsPageSize)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing reviews")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ReviewsFromDatabase(reviews, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
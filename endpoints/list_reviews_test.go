@@ -0,0 +1,72 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) ListBookReviews(ctx context.Context, bookID uuid.UUID, sort string, limit int, offset int) ([]database.Review, error) {
+	args := db.Called(bookID, sort, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Review), args.Error(1)
+}
+
+func TestSuccessfulListReviews(t *testing.T) {
+	bookID := uuid.New()
+	expDBReviews := []database.Review{
+		mockReview(bookID, "someone@else.com"),
+		mockReview(bookID, expID.Account),
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ListBookReviews", bookID, database.ReviewSortHelpful, 20, 0).
+		Return(expDBReviews, nil).Once()
+	dbMock.On("ListBookReviews", bookID, database.ReviewSortRecent, 20, 40).
+		Return(expDBReviews, nil).Once()
+
+	for _, query := range []string{"", "?sort=recent&page=2"} {
+		path := "/books/" + bookID.String() + "/reviews" + query
+		w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+		handler := ListReviews(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp := ReviewsFromDatabase(expDBReviews, expID.Account)
+
+		resp := &ReviewsResponse{}
+		assert.Equal(t, http.StatusOK, w.Code, "A successful reviews request didn't return the proper response code")
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful reviews request didn't return a valid ReviewsResponse object") {
+			assert.Equal(t, expResp, *resp, "A successful reviews request didn't return a valid response")
+			assert.True(t, resp.Data[1].IsOwn, "A successful reviews request didn't mark the account's own review")
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestUnrecognizedSortListReviews(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/reviews?sort=rating"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := ListReviews(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errReviewSortUnrecognized).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An unrecognized reviews sort didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An unrecognized reviews sort didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An unrecognized reviews sort didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
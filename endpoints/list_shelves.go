@@ -0,0 +1,36 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+// ListShelves lists the account's shelves, the built-in ones included.
+func ListShelves(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListShelves: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelves, err := db.GetShelves(ctx, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing shelves")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ShelvesFromDatabase(shelves, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,88 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetShelves(ctx context.Context, accountID string) ([]database.Shelf, error) {
+	args := db.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Shelf), args.Error(1)
+}
+
+func mockShelf(kind string, name string) database.Shelf {
+	return database.Shelf{
+		ID:         uuid.New(),
+		AccountID:  expID.Account,
+		OwnerName:  "Reza",
+		Kind:       kind,
+		Name:       name,
+		Visibility: database.ShelfPrivate,
+		BookCount:  2,
+		CreatedAt:  time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestSuccessfulListShelves(t *testing.T) {
+	path := "/me/shelves"
+
+	custom := mockShelf(database.ShelfCustom, "Summer reads")
+	custom.Visibility = database.ShelfPublic
+	custom.Position = 4
+	expDBShelves := []database.Shelf{
+		mockShelf(database.ShelfFavorites, "Favorites"),
+		custom,
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelves", expID.Account).
+		Return(expDBShelves, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListShelves(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ShelvesFromDatabase(expDBShelves, expID.Account)
+
+	resp := &ShelvesResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful shelves request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful shelves request didn't return a valid ShelvesResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful shelves request didn't return a valid response")
+		assert.Empty(t, resp.Data[0].SharePath, "A successful shelves request shared a private shelf")
+		assert.Equal(t, "/shared/shelves/"+custom.ID.String(), resp.Data[1].SharePath, "A successful shelves request didn't share a public shelf")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedListShelves(t *testing.T) {
+	path := "/me/shelves"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelves", expID.Account).
+		Return(nil, sql.ErrConnDone).Once()
+
+	w, r := mockRequest(t, path, nil, true)
+	handler := ListShelves(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A failed shelves request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A failed shelves request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A failed shelves request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
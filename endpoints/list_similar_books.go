@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const similarBooksLimit = 10
+
+func ListSimilarBooks(
+	db database.RecommendationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ListSimilarBooks: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Listing similar books of a malformed id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		books, err := db.GetSimilarBooks(ctx, bookID, similarBooksLimit, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing similar books")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		booksResponse := BooksFromDatabase(books)
+
+		render.Render(w, r, &booksResponse)
+	}
+}
@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetSimilarBooks(ctx context.Context, bookID uuid.UUID, limit int, accountID string) ([]database.Book, error) {
+	args := db.Called(bookID, limit, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]database.Book), args.Error(1)
+}
+
+func TestSuccessfulListSimilarBooks(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/similar"
+
+	expDBBooks := []database.Book{
+		{ID: uuid.New(), Title: "aaaa", Author: "ae", Readers: 10},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetSimilarBooks", bookID, 10, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := ListSimilarBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := BooksFromDatabase(expDBBooks)
+
+	resp := &BooksResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful similar book list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful similar book list didn't return a valid BooksResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful similar book list didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundListSimilarBooks(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/similar"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetSimilarBooks", bookID, 10, expID.Account).
+		Return(nil, database.ErrBookNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := ListSimilarBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errBookNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing book's similar list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing book's similar list didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing book's similar list didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedListSimilarBooks(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, "/books/abc/similar", nil, true, param{"id", "abc"})
+	handler := ListSimilarBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errBookIDMalformed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed similar book list didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed similar book list didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A malformed similar book list didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
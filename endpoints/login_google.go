@@ -4,6 +4,7 @@ import (
 	"errors"
 	"ic-rhadi/e_library/database"
 	"ic-rhadi/e_library/googlehelper"
+	"ic-rhadi/e_library/metricshelper"
 	"ic-rhadi/e_library/sessiontoken"
 	"net/http"
 	"time"
@@ -25,10 +26,14 @@ func (l *loginGoogleRequest) Bind(r *http.Request) error {
 	return nil
 }
 
+// loginMethodGoogle labels the login metrics of Google sign ins.
+const loginMethodGoogle = "google"
+
 var ErrLoginGoogleMalformed = errors.New("token missing")
 
 func LoginGoogle(
 	db database.UserAccountInterface,
+	audit database.AuditInterface,
 	sessionAuth *jwtauth.JWTAuth,
 	gValidator googlehelper.GTokenValidator,
 	sessionLength time.Duration,
@@ -38,29 +43,31 @@ func LoginGoogle(
 		ctx := r.Context()
 		data := &loginGoogleRequest{}
 		if err := render.Bind(r, data); err != nil {
-			log.Debug().Err(err).Msg("Google token body malformed")
+			log.Ctx(ctx).Debug().Err(err).Msg("Google token body malformed")
 			render.Render(w, r, BadRequestError(ErrLoginGoogleMalformed))
 			return
 		}
 
 		gClaims, err := gValidator.ValidateGToken(ctx, data.GoogleToken)
 		if err != nil {
-			log.Debug().Err(err).Str("token", data.GoogleToken).Msg("Google token validation failed")
+			log.Ctx(ctx).Debug().Err(err).Str("token", data.GoogleToken).Msg("Google token validation failed")
+			metricshelper.Logins.WithLabelValues(loginMethodGoogle, metricshelper.ResultFailure).Inc()
+			recordAudit(r, audit, database.AuditLoginFailed, "", loginAudit{loginMethodGoogle, errorCodes[errGoogleTokenFailed]})
 			render.Render(w, r, ValidationFailedError(ErrLoginFailed))
 			return
 		}
 
 		session, err := db.LoginGoogle(ctx, gClaims.Email, gClaims.AccountID, sessionLength)
 		if err != nil {
-			switch err {
-			case database.ErrAccountNotActive:
-				render.Render(w, r, UnauthorizedRequestError(ErrLoginAccountNotActive))
-			case database.ErrAccountNotFound, database.ErrWrongID:
-				render.Render(w, r, ValidationFailedError(ErrLoginFailed))
-			default:
-				log.Debug().Err(err).Str("email", gClaims.Email).Msg("Login attempt failed")
+			resp := loginErrorResponse(err)
+			if resp == nil {
+				log.Ctx(ctx).Debug().Err(err).Str("email", gClaims.Email).Msg("Login attempt failed")
 				render.Render(w, r, InternalServerError())
+				return
 			}
+			metricshelper.Logins.WithLabelValues(loginMethodGoogle, metricshelper.ResultFailure).Inc()
+			recordAudit(r, audit, database.AuditLoginFailed, gClaims.Email, loginAudit{loginMethodGoogle, resp.Code})
+			render.Render(w, r, resp)
 			return
 		}
 
@@ -72,7 +79,7 @@ func LoginGoogle(
 			tokenLength,
 		)
 		if err != nil {
-			log.Error().Err(err).Msg("Error encoding new token")
+			log.Ctx(ctx).Error().Err(err).Msg("Error encoding new token")
 			render.Render(w, r, InternalServerError())
 			return
 		}
@@ -86,6 +93,8 @@ func LoginGoogle(
 			sessionLength,
 		)
 
+		metricshelper.Logins.WithLabelValues(loginMethodGoogle, metricshelper.ResultSuccess).Inc()
+		recordAudit(r, audit, database.AuditLoginSucceeded, gClaims.Email, loginAudit{Method: loginMethodGoogle})
 		render.Render(w, r, &tokenResponse{
 			Session:   accessTokenString,
 			Refresh:   refreshTokenString,
@@ -43,7 +43,7 @@ func TestSuccessfulLoginGoogle(t *testing.T) {
 	}
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginGoogle(dbMock, tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
+	handler := LoginGoogle(dbMock, newAuditMock(), tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &tokenResponse{}
@@ -91,7 +91,7 @@ func TestMalformedLoginGoogle(t *testing.T) {
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginGoogle(dbMock, tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
+	handler := LoginGoogle(dbMock, newAuditMock(), tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -121,7 +121,7 @@ func TestTokenFailedLoginGoogle(t *testing.T) {
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginGoogle(dbMock, tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
+	handler := LoginGoogle(dbMock, newAuditMock(), tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -162,7 +162,7 @@ func TestFailedLoginGoogle(t *testing.T) {
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginGoogle(dbMock, tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
+	handler := LoginGoogle(dbMock, newAuditMock(), tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -201,7 +201,7 @@ func TestNotActivatedLoginGoogle(t *testing.T) {
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginGoogle(dbMock, tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
+	handler := LoginGoogle(dbMock, newAuditMock(), tokenAuth, gValidatorMock, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -0,0 +1,92 @@
+package endpoints
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrLoginRateLimited = errors.New("too many failed logins, try again later")
+
+// LoginLimiter slows down password guessing on every sign in checking a
+// password. Once an account, or the address the sign ins come from, failed
+// maxFailures times, its sign ins are refused without checking the password
+// until its window runs out. Only failures count.
+//
+// The failures are kept in each server instance's memory, so behind a load
+// balancer every instance allows maxFailures.
+type LoginLimiter struct {
+	maxFailures int
+	window      time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+}
+
+type loginFailures struct {
+	count int
+	until time.Time
+}
+
+// NewLoginLimiter refuses the sign ins of a key for the rest of window once
+// maxFailures of them failed within it.
+func NewLoginLimiter(maxFailures int, window time.Duration) *LoginLimiter {
+	return &LoginLimiter{maxFailures: maxFailures, window: window, failures: map[string]*loginFailures{}}
+}
+
+// loginLimitKeys are what a sign in is limited by, the account and the
+// address it came from.
+func loginLimitKeys(r *http.Request, email string) []string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return []string{"account:" + strings.ToLower(email), "address:" + host}
+}
+
+// retryAfter is how long until every key may sign in again, 0 when they
+// already may.
+func (l *LoginLimiter) retryAfter(keys []string) time.Duration {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var wait time.Duration
+	for _, key := range keys {
+		f, ok := l.failures[key]
+		if ok && f.count >= l.maxFailures && now.Before(f.until) {
+			wait = max(wait, f.until.Sub(now))
+		}
+	}
+	return wait
+}
+
+// fail counts a failed sign in against every key.
+func (l *LoginLimiter) fail(keys []string) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, f := range l.failures {
+		if !now.Before(f.until) {
+			delete(l.failures, key)
+		}
+	}
+	for _, key := range keys {
+		f, ok := l.failures[key]
+		if !ok {
+			f = &loginFailures{until: now.Add(l.window)}
+			l.failures[key] = f
+		}
+		f.count++
+	}
+}
+
+// setRetryAfter tells a refused client when to sign in again, in whole
+// seconds rounded up.
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
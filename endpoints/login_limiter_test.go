@@ -0,0 +1,31 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	limiter := NewLoginLimiter(2, 50*time.Millisecond)
+	r := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+
+	limiter.fail(loginLimitKeys(r, "a@example.com"))
+	assert.Zero(t, limiter.retryAfter(loginLimitKeys(r, "a@example.com")), "A key under the failure limit was refused")
+
+	limiter.fail(loginLimitKeys(r, "B@example.com"))
+	assert.Positive(t, limiter.retryAfter(loginLimitKeys(r, "c@example.com")), "An address over the failure limit wasn't refused for another account")
+	other := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	other.RemoteAddr = "192.0.2.2:1234"
+	assert.Zero(t, limiter.retryAfter(loginLimitKeys(other, "a@example.com")), "An account under the failure limit was refused from another address")
+
+	limiter.fail(loginLimitKeys(other, "A@EXAMPLE.COM"))
+	assert.Positive(t, limiter.retryAfter(loginLimitKeys(other, "a@example.com")), "An account over the failure limit wasn't refused whatever its case")
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Zero(t, limiter.retryAfter(loginLimitKeys(r, "a@example.com")), "A key was still refused after its window ran out")
+}
@@ -3,6 +3,7 @@ package endpoints
 import (
 	"errors"
 	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/metricshelper"
 	"ic-rhadi/e_library/sessiontoken"
 	"net/http"
 	"time"
@@ -27,10 +28,24 @@ func (l *loginPostRequest) Bind(r *http.Request) error {
 
 var ErrLoginAccountNotActive = errors.New("account has not been activated yet")
 var ErrLoginFailed = errors.New("login failed")
+var ErrLoginAccountDisabled = errors.New("account has been disabled, contact support")
+
+// loginMethodPassword labels the login metrics of email and password logins.
+const loginMethodPassword = "password"
+
+// loginAudit is the payload of login audit events. Failures don't tell an
+// unknown account from a wrong password, like the response.
+type loginAudit struct {
+	Method string `json:"method"`
+	Reason string `json:"reason,omitempty"`
+}
+
 var ErrLoginPostMalformed = errors.New("username or password missing")
 
 func LoginPost(
 	db database.UserAccountInterface,
+	audit database.AuditInterface,
+	limiter *LoginLimiter,
 	sessionAuth *jwtauth.JWTAuth,
 	sessionLength time.Duration,
 	tokenLength time.Duration,
@@ -40,22 +55,32 @@ func LoginPost(
 
 		data := &loginPostRequest{}
 		if err := render.Bind(r, data); err != nil {
-			log.Debug().Err(err).Str("username", data.Email).Msg("Login attempt malformed")
+			log.Ctx(ctx).Debug().Err(err).Str("username", data.Email).Msg("Login attempt malformed")
 			render.Render(w, r, BadRequestError(ErrLoginPostMalformed))
 			return
 		}
 
+		limitKeys := loginLimitKeys(r, data.Email)
+		if wait := limiter.retryAfter(limitKeys); wait > 0 {
+			log.Ctx(ctx).Debug().Str("email", data.Email).Dur("retry_after", wait).Msg("Login attempt refused after too many failures")
+			metricshelper.Logins.WithLabelValues(loginMethodPassword, metricshelper.ResultLimited).Inc()
+			setRetryAfter(w, wait)
+			render.Render(w, r, newErrorResponse(http.StatusTooManyRequests, ErrLoginRateLimited))
+			return
+		}
+
 		session, err := db.Login(ctx, data.Email, data.Password, sessionLength)
 		if err != nil {
-			switch err {
-			case database.ErrAccountNotActive:
-				render.Render(w, r, UnauthorizedRequestError(ErrLoginAccountNotActive))
-			case database.ErrAccountNotFound, database.ErrWrongPass:
-				render.Render(w, r, ValidationFailedError(ErrLoginFailed))
-			default:
-				log.Debug().Err(err).Str("email", data.Email).Msg("Login attempt failed")
+			resp := loginErrorResponse(err)
+			if resp == nil {
+				log.Ctx(ctx).Debug().Err(err).Str("email", data.Email).Msg("Login attempt failed")
 				render.Render(w, r, InternalServerError())
+				return
 			}
+			limiter.fail(limitKeys)
+			metricshelper.Logins.WithLabelValues(loginMethodPassword, metricshelper.ResultFailure).Inc()
+			recordAudit(r, audit, database.AuditLoginFailed, data.Email, loginAudit{loginMethodPassword, resp.Code})
+			render.Render(w, r, resp)
 			return
 		}
 
@@ -67,7 +92,7 @@ func LoginPost(
 			tokenLength,
 		)
 		if err != nil {
-			log.Error().Err(err).Msg("Error encoding new token")
+			log.Ctx(ctx).Error().Err(err).Msg("Error encoding new token")
 			render.Render(w, r, InternalServerError())
 			return
 		}
@@ -81,6 +106,8 @@ func LoginPost(
 			sessionLength,
 		)
 
+		metricshelper.Logins.WithLabelValues(loginMethodPassword, metricshelper.ResultSuccess).Inc()
+		recordAudit(r, audit, database.AuditLoginSucceeded, data.Email, loginAudit{Method: loginMethodPassword})
 		render.Render(w, r, &tokenResponse{
 			Session:   accessTokenString,
 			Refresh:   refreshTokenString,
@@ -7,6 +7,7 @@ import (
 	"ic-rhadi/e_library/sessiontoken"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/go-chi/jwtauth"
 	"github.com/stretchr/testify/assert"
@@ -24,6 +25,9 @@ func TestSuccessfulLoginPost(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 	dbMock.On("Login", login.Email, login.Password, expSessionLen).
 		Return(expID.Session, nil).Once()
+	dbMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditLoginSucceeded && e.Actor == login.Email
+	})).Return(nil).Once()
 
 	expCode := http.StatusOK
 	expClaims := sessiontoken.AccessClaimsSchema{
@@ -31,7 +35,7 @@ func TestSuccessfulLoginPost(t *testing.T) {
 	}
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginPost(dbMock, tokenAuth, expSessionLen, expTokenLen)
+	handler := LoginPost(dbMock, dbMock, NewLoginLimiter(10, time.Minute), tokenAuth, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &tokenResponse{}
@@ -77,7 +81,7 @@ func TestMalformedLoginPost(t *testing.T) {
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginPost(dbMock, tokenAuth, expSessionLen, expTokenLen)
+	handler := LoginPost(dbMock, newAuditMock(), NewLoginLimiter(10, time.Minute), tokenAuth, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -100,12 +104,15 @@ func TestFailedLoginPost(t *testing.T) {
 	dbMock := dBMock{&mock.Mock{}}
 	dbMock.On("Login", login.Email, login.Password, expSessionLen).
 		Return("", database.ErrAccountNotFound).Once()
+	dbMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditLoginFailed && e.Actor == login.Email && string(e.Payload) == `{"method":"password","reason":"login_failed"}`
+	})).Return(nil).Once()
 
 	expResp, expCode := ValidationFailedError(ErrLoginFailed).(*ErrorResponse).
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginPost(dbMock, tokenAuth, expSessionLen, expTokenLen)
+	handler := LoginPost(dbMock, dbMock, NewLoginLimiter(10, time.Minute), tokenAuth, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -117,6 +124,40 @@ func TestFailedLoginPost(t *testing.T) {
 	dbMock.AssertExpectations(t)
 }
 
+func TestRateLimitedLoginPost(t *testing.T) {
+	path := "/auth/login"
+
+	login := loginPostRequest{
+		Email:    expID.Account,
+		Password: "Passwor",
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("Login", login.Email, login.Password, expSessionLen).
+		Return("", database.ErrWrongPass).Once()
+	dbMock.On("RecordAuditEvent", mock.Anything).Return(nil).Once()
+
+	expResp, expCode := newErrorResponse(http.StatusTooManyRequests, ErrLoginRateLimited).
+		sentForm()
+
+	handler := LoginPost(dbMock, dbMock, NewLoginLimiter(1, time.Minute), tokenAuth, expSessionLen, expTokenLen)
+	w, r := mockRequest(t, path, login, false)
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "A failed Post-Login under the limit wasn't checked")
+
+	w, r = mockRequest(t, path, login, false)
+	handler.ServeHTTP(w, r)
+
+	resp := &ErrorResponse{}
+
+	assert.Equal(t, expCode, w.Code, "A Post-Login over the failure limit didn't return the proper response code")
+	assert.Equal(t, "60", w.Header().Get("Retry-After"), "A Post-Login over the failure limit wasn't told when to retry")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A rate limited Post-Login didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A rate limited Post-Login didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
 func TestNotActivatedLoginPost(t *testing.T) {
 	path := "/auth/login"
 
@@ -133,7 +174,7 @@ func TestNotActivatedLoginPost(t *testing.T) {
 		sentForm()
 
 	w, r := mockRequest(t, path, login, false)
-	handler := LoginPost(dbMock, tokenAuth, expSessionLen, expTokenLen)
+	handler := LoginPost(dbMock, newAuditMock(), NewLoginLimiter(10, time.Minute), tokenAuth, expSessionLen, expTokenLen)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
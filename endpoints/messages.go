@@ -0,0 +1,124 @@
+package endpoints
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/text/language"
+)
+
+//go:embed messages/*.json
+var messageFiles embed.FS
+
+// messageLanguages are the languages with a catalog in messages/, English
+// first as it is the fallback.
+var messageLanguages = []language.Tag{language.English, language.Indonesian, language.Japanese}
+
+var messageMatcher = language.NewMatcher(messageLanguages)
+
+// messageCatalogs holds the messages sent to clients, keyed by language and
+// then by the message's code. Error messages use their error code.
+var messageCatalogs = loadMessageCatalogs()
+
+// languageCookie holds the language a user picked on a client, which wins
+// over the browser's Accept-Language but not over the account's language.
+const languageCookie = "lang"
+
+type accountLanguageKey struct{}
+
+// accountLanguage reads the signed in account's language the first time a
+// message needs it, so requests that send none don't read it.
+type accountLanguage struct {
+	once sync.Once
+	read func() string
+	lang string
+}
+
+func (al *accountLanguage) get() string {
+	al.once.Do(func() { al.lang = al.read() })
+	return al.lang
+}
+
+// withAccountLanguage makes the messages sent for r go by the language email
+// picked first.
+func withAccountLanguage(r *http.Request, db database.UserAccountInterface, email string) *http.Request {
+	ctx := r.Context()
+	al := &accountLanguage{read: func() string {
+		lang, err := db.GetAccountLanguage(ctx, email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Reading the account's language failed")
+		}
+		return lang
+	}}
+	return r.WithContext(context.WithValue(ctx, accountLanguageKey{}, al))
+}
+
+// supportedLanguage returns the catalog language closest to tag, and false
+// when no catalog is close enough.
+func supportedLanguage(tag string) (language.Tag, bool) {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return language.English, false
+	}
+	_, index, confidence := messageMatcher.Match(t)
+	return messageLanguages[index], confidence != language.No
+}
+
+func loadMessageCatalogs() map[language.Tag]map[string]string {
+	catalogs := make(map[language.Tag]map[string]string, len(messageLanguages))
+	for _, tag := range messageLanguages {
+		file, err := messageFiles.ReadFile("messages/" + tag.String() + ".json")
+		if err != nil {
+			panic("message catalog missing: " + err.Error())
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(file, &catalog); err != nil {
+			panic("message catalog malformed: " + err.Error())
+		}
+		catalogs[tag] = catalog
+	}
+	return catalogs
+}
+
+// requestLanguage picks the catalog language for r, from the signed in
+// account's language, then the language cookie and then Accept-Language,
+// falling back to English.
+func requestLanguage(r *http.Request) language.Tag {
+	var preferred []language.Tag
+	if al, ok := r.Context().Value(accountLanguageKey{}).(*accountLanguage); ok {
+		if tag, err := language.Parse(al.get()); err == nil {
+			preferred = append(preferred, tag)
+		}
+	}
+	if cookie, err := r.Cookie(languageCookie); err == nil {
+		if tag, err := language.Parse(cookie.Value); err == nil {
+			preferred = append(preferred, tag)
+		}
+	}
+	accepted, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	preferred = append(preferred, accepted...)
+
+	_, index, _ := messageMatcher.Match(preferred...)
+	return messageLanguages[index]
+}
+
+// translate returns the message for code in lang, or in English when lang's
+// catalog lacks it. ok is false when no catalog has the code.
+func translate(lang language.Tag, code string) (msg string, ok bool) {
+	if msg, ok := messageCatalogs[lang][code]; ok {
+		return msg, true
+	}
+	msg, ok = messageCatalogs[language.English][code]
+	return msg, ok
+}
+
+// localize returns the message for code in the language of r.
+func localize(r *http.Request, code string) string {
+	msg, _ := translate(requestLanguage(r), code)
+	return msg
+}
@@ -0,0 +1,112 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/text/language"
+)
+
+func catalogKeys(catalog map[string]string) []string {
+	keys := make([]string, 0, len(catalog))
+	for key := range catalog {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestMessageCatalogs fails when a code is missing from a catalog, or when
+// the English catalog drifts from the errors it translates.
+func TestMessageCatalogs(t *testing.T) {
+	english := messageCatalogs[language.English]
+	for err, code := range errorCodes {
+		assert.Equal(t, err.Error(), english[code], "The English catalog doesn't match the %s error", code)
+	}
+
+	for _, lang := range messageLanguages[1:] {
+		assert.Equal(t, catalogKeys(english), catalogKeys(messageCatalogs[lang]), "The %s catalog doesn't have the same codes as the English one", lang)
+		for code, msg := range messageCatalogs[lang] {
+			assert.NotEmpty(t, msg, "The %s catalog has an empty %s message", lang, code)
+		}
+	}
+}
+
+func TestLocalizedErrorResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		cookie         string
+		expLanguage    language.Tag
+	}{
+		{"Accept-Language", "ja-JP,ja;q=0.9,en;q=0.8", "", language.Japanese},
+		{"language cookie", "ja-JP,ja;q=0.9", "id", language.Indonesian},
+		{"unsupported language", "fr-FR", "", language.English},
+		{"no preference", "", "", language.English},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, "/auth/register", nil, false)
+		r.Header.Set("Accept-Language", test.acceptLanguage)
+		if test.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: languageCookie, Value: test.cookie})
+		}
+		render.Render(w, r, BadRequestError(fieldErrors{
+			"email":    {errEmailMalformed},
+			"password": {errPasswordTooShort},
+		}))
+
+		catalog := messageCatalogs[test.expLanguage]
+		resp := &ErrorResponse{}
+		assert.Equal(t, test.expLanguage.String(), w.Header().Get("content-language"), "An error with %s wasn't sent in the proper language", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An error with %s didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, catalog["email_malformed"]+"; "+catalog["password_too_short"], resp.Message, "An error with %s wasn't translated", test.name)
+			assert.Equal(t, catalog["password_too_short"], resp.Fields["password"][0].Message, "A field error with %s wasn't translated", test.name)
+			assert.Equal(t, "password_too_short", resp.Fields["password"][0].Code, "A field error with %s changed its code", test.name)
+		}
+	}
+}
+
+func TestAccountLanguage(t *testing.T) {
+	tests := []struct {
+		name            string
+		accountLanguage string
+		cookie          string
+		expLanguage     language.Tag
+	}{
+		{"account language", "ja", "id", language.Japanese},
+		{"no account language", "", "id", language.Indonesian},
+	}
+
+	for _, test := range tests {
+		dbMock := dBMock{&mock.Mock{}}
+		dbMock.On("GetAccountLanguage", expID.Account).
+			Return(test.accountLanguage, nil).Once()
+
+		var lang language.Tag
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang = requestLanguage(r)
+			requestLanguage(r)
+		})
+
+		w, r := mockRequest(t, "/me/shelves", nil, true)
+		r.AddCookie(&http.Cookie{Name: languageCookie, Value: test.cookie})
+		r.Header.Set("Accept-Language", "en")
+		AccountLanguageMiddleware(dbMock)(next).ServeHTTP(w, r)
+
+		assert.Equal(t, test.expLanguage, lang, "A request with %s wasn't sent in the proper language", test.name)
+		dbMock.AssertExpectations(t)
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	w, r := mockRequest(t, "/me/shelves", nil, true)
+	AccountLanguageMiddleware(dbMock)(okHandler).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A request sending no message didn't get through")
+	dbMock.AssertNotCalled(t, "GetAccountLanguage", expID.Account)
+}
@@ -0,0 +1,49 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/metricshelper"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+)
+
+// unmatchedRoute labels requests no route matched, so that scanners probing
+// random paths don't create a series each.
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware counts and times every request under the pattern of the
+// route that served it, like /books/{id}, rather than its path.
+func MetricsMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			route := routePattern(r)
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			labels := []string{route, r.Method, strconv.Itoa(status)}
+			metricshelper.HTTPRequests.WithLabelValues(labels...).Inc()
+			metricshelper.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// routePattern is the pattern of the route that served r, once it's served.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return unmatchedRoute
+}
+
+// Metrics serves the metrics for Prometheus to scrape.
+func Metrics() http.HandlerFunc {
+	return metricshelper.Handler().ServeHTTP
+}
@@ -0,0 +1,44 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/metricshelper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(MetricsMiddleware())
+	r.Route("/test", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+	})
+
+	requests := metricshelper.HTTPRequests.WithLabelValues("/test/{id}", http.MethodGet, "418")
+	unmatched := metricshelper.HTTPRequests.WithLabelValues(unmatchedRoute, http.MethodGet, "404")
+	before, beforeUnmatched := testutil.ToFloat64(requests), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/test/1", "/test/2", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, before+2, testutil.ToFloat64(requests), "Requests weren't counted under their route pattern")
+	assert.Equal(t, beforeUnmatched+1, testutil.ToFloat64(unmatched), "A request no route matched wasn't counted as unmatched")
+}
+
+func TestMetrics(t *testing.T) {
+	metricshelper.RefreshTokenReuses.Inc()
+
+	w, r := mockRequest(t, "/metrics", nil, false)
+	Metrics()(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "The metrics weren't served")
+	assert.True(t, strings.Contains(w.Body.String(), "elibrary_refresh_token_reuse_total"), "The served metrics miss the application's")
+}
@@ -0,0 +1,79 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type moderateReviewRequest struct {
+	Action string `json:"action"`
+}
+
+func (m *moderateReviewRequest) Bind(r *http.Request) error {
+	if m.Action == "" {
+		return errModerateReviewMalformed
+	}
+	return nil
+}
+
+type reviewModerationAudit struct {
+	Review string `json:"review_id"`
+	Action string `json:"action"`
+}
+
+var errModerateReviewMalformed = errors.New("action missing")
+
+// ModerateReview approves, rejects or hides a review, taking it out of the
+// moderation queue.
+func ModerateReview(
+	db database.ReviewInterface,
+	audit database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ModerateReview: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		reviewID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Moderating a review with a malformed id")
+			render.Render(w, r, BadRequestError(errReviewIDMalformed))
+			return
+		}
+
+		data := &moderateReviewRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Moderating review attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		review, err := db.ModerateReview(ctx, reviewID, data.Action, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while moderating a review")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		recordAudit(r, audit, database.AuditReviewModerated, sch.Email, reviewModerationAudit{reviewID.String(), data.Action})
+
+		resp := ModerationReviewFromDatabase(*review, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,70 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) ModerateReview(ctx context.Context, reviewID uuid.UUID, action string, moderatorID string) (*database.Review, error) {
+	args := db.Called(reviewID, action, moderatorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Review), args.Error(1)
+}
+
+func TestSuccessfulModerateReview(t *testing.T) {
+	expDBReview := mockReview(uuid.New(), "someone@else.com")
+	expDBReview.Status = database.ReviewHidden
+	path := "/admin/reviews/" + expDBReview.ID.String() + "/moderation"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ModerateReview", expDBReview.ID, database.ReviewActionHide, expID.Account).
+		Return(&expDBReview, nil).Once()
+	dbMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditReviewModerated && e.Actor == expID.Account &&
+			string(e.Payload) == `{"review_id":"`+expDBReview.ID.String()+`","action":"hide"}`
+	})).Return(nil).Once()
+
+	w, r := mockRequest(t, path, moderateReviewRequest{Action: "hide"}, true, param{"id", expDBReview.ID.String()})
+	handler := ModerateReview(dbMock, dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ModerationReviewFromDatabase(expDBReview, expID.Account)
+
+	resp := &ReviewResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful moderation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful moderation didn't return a valid ReviewResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful moderation didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestUnknownActionModerateReview(t *testing.T) {
+	reviewID := uuid.New()
+	path := "/admin/reviews/" + reviewID.String() + "/moderation"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ModerateReview", reviewID, "delete", expID.Account).
+		Return(nil, database.ErrReviewActionUnknown).Once()
+
+	w, r := mockRequest(t, path, moderateReviewRequest{Action: "delete"}, true, param{"id", reviewID.String()})
+	handler := ModerateReview(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := ValidationFailedError(database.ErrReviewActionUnknown).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An unknown moderation action didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An unknown moderation action didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An unknown moderation action didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,186 @@
+package endpoints
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/metricshelper"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type opdsAuthDocument struct {
+	ID             string         `json:"id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	Authentication []opdsAuthFlow `json:"authentication"`
+	Links          []opdsJSONLink `json:"links"`
+}
+
+type opdsAuthFlow struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+}
+
+var opdsAuthentication = opdsAuthDocument{
+	ID:          "/opds/auth",
+	Title:       "E-Library",
+	Description: "Sign in with the email and password of your E-Library account.",
+	Authentication: []opdsAuthFlow{
+		{
+			Type:   "http://opds-spec.org/auth/basic",
+			Labels: map[string]string{"login": "Email", "password": "Password"},
+		},
+	},
+	Links: []opdsJSONLink{
+		{Rel: "start", Href: OPDSAtom.root(), Type: opdsNavigationType},
+		{Rel: "start", Href: OPDSJSON.root(), Type: opdsJSONType},
+	},
+}
+
+func writeOPDSAuthentication(w http.ResponseWriter, status int) {
+	w.Header().Set("content-type", opdsAuthDocumentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(opdsAuthentication); err != nil {
+		log.Error().Err(err).Msg("Encoding the OPDS authentication document failed")
+	}
+}
+
+// OPDSAuthentication describes how e-readers sign in to the catalog.
+func OPDSAuthentication() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeOPDSAuthentication(w, http.StatusOK)
+	}
+}
+
+// loginMethodOPDS labels the login metrics of OPDS Basic credentials.
+const loginMethodOPDS = "opds"
+
+// OPDSAuthenticatorMiddleware signs e-readers in with HTTP Basic credentials
+// on every request, since they can't refresh session tokens. The account is
+// put in the context the same way a session token would be. Failed sign ins
+// are limited, audited and counted like the other logins; successful ones
+// aren't, since every request of an e-reader is one. Credentials that signed
+// in are trusted again for cacheLength without checking the password, so an
+// account disabled or a password changed meanwhile takes up to that long to
+// apply here.
+func OPDSAuthenticatorMiddleware(
+	db database.UserAccountInterface,
+	audit database.AuditInterface,
+	limiter *LoginLimiter,
+	cacheLength time.Duration,
+) func(next http.Handler) http.Handler {
+	verified := newCredentialCache(cacheLength)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			email, pass, ok := r.BasicAuth()
+			if !ok || email == "" {
+				log.Ctx(ctx).Debug().Msg("OPDS request without credentials")
+				unauthorizedOPDS(w)
+				return
+			}
+
+			credential := verified.sum(email, pass)
+			if !verified.has(credential) {
+				limitKeys := loginLimitKeys(r, email)
+				if wait := limiter.retryAfter(limitKeys); wait > 0 {
+					log.Ctx(ctx).Debug().Str("email", email).Dur("retry_after", wait).Msg("OPDS sign in refused after too many failures")
+					metricshelper.Logins.WithLabelValues(loginMethodOPDS, metricshelper.ResultLimited).Inc()
+					setRetryAfter(w, wait)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+
+				if err := db.VerifyPassword(ctx, email, pass); err != nil {
+					resp := loginErrorResponse(err)
+					if resp == nil {
+						log.Ctx(ctx).Error().Err(err).Msg("Database error while checking OPDS credentials")
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					limiter.fail(limitKeys)
+					log.Ctx(ctx).Debug().Err(err).Str("email", email).Msg("OPDS sign in failed")
+					metricshelper.Logins.WithLabelValues(loginMethodOPDS, metricshelper.ResultFailure).Inc()
+					recordAudit(r, audit, database.AuditLoginFailed, email, loginAudit{loginMethodOPDS, resp.Code})
+					unauthorizedOPDS(w)
+					return
+				}
+				verified.add(credential)
+			}
+
+			ctx, err := sessiontoken.NewContext(ctx, sessiontoken.AccessClaimsSchema{Email: email})
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("OPDSAuthenticator: Setting account failed unexpectedly")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// credentialCache remembers the Basic credentials that signed in for a
+// while, so an e-reader's requests don't each cost a bcrypt check. They're
+// kept as an HMAC under a key made at startup, never as sent.
+type credentialCache struct {
+	key    []byte
+	length time.Duration
+
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// newCredentialCache remembers credentials for length, or not at all when
+// length isn't positive.
+func newCredentialCache(length time.Duration) *credentialCache {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		log.Error().Err(err).Msg("Making the OPDS credential cache key failed, credentials won't be cached")
+		length = 0
+	}
+	return &credentialCache{key: key, length: length, until: map[string]time.Time{}}
+}
+
+func (c *credentialCache) sum(email string, pass string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(email))
+	mac.Write([]byte{0})
+	mac.Write([]byte(pass))
+	return string(mac.Sum(nil))
+}
+
+func (c *credentialCache) has(credential string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.until[credential]
+	return ok && time.Now().Before(until)
+}
+
+func (c *credentialCache) add(credential string) {
+	if c.length <= 0 {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for cred, until := range c.until {
+		if now.After(until) {
+			delete(c.until, cred)
+		}
+	}
+	c.until[credential] = now.Add(c.length)
+}
+
+func unauthorizedOPDS(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="E-Library", charset="UTF-8"`)
+	writeOPDSAuthentication(w, http.StatusUnauthorized)
+}
@@ -0,0 +1,162 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/metricshelper"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) VerifyPassword(ctx context.Context, email string, pass string) error {
+	args := db.Called(email, pass)
+	return args.Error(0)
+}
+
+const expOPDSPassword = "correct horse"
+
+func TestSuccessfulOPDSAuthenticator(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VerifyPassword", expID.Account, expOPDSPassword).
+		Return(nil).Once()
+
+	var account string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sch, err := sessiontoken.FromContext(r.Context())
+		if err == nil {
+			account = sch.Email
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	w, r := mockRequest(t, "/opds", nil, false)
+	r.SetBasicAuth(expID.Account, expOPDSPassword)
+	OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(10, time.Minute), 0)(next).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "Valid OPDS credentials didn't get through the authenticator")
+	assert.Equal(t, expID.Account, account, "Valid OPDS credentials didn't sign the account in")
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedOPDSAuthenticator(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VerifyPassword", expID.Account, expOPDSPassword).
+		Return(database.ErrWrongPass).Once()
+	dbMock.On("VerifyPassword", expID.Account, expOPDSPassword).
+		Return(sql.ErrConnDone).Once()
+	dbMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditLoginFailed && e.Actor == expID.Account && string(e.Payload) == `{"method":"opds","reason":"login_failed"}`
+	})).Return(nil).Once()
+	failures := metricshelper.Logins.WithLabelValues(loginMethodOPDS, metricshelper.ResultFailure)
+	before := testutil.ToFloat64(failures)
+
+	w, r := mockRequest(t, "/opds", nil, false)
+	OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(10, time.Minute), 0)(okHandler).ServeHTTP(w, r)
+
+	resp := &opdsAuthDocument{}
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "An OPDS request without credentials didn't get stopped by the authenticator")
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic", "An OPDS request without credentials wasn't asked for them")
+	assert.Equal(t, opdsAuthDocumentType, w.Header().Get("content-type"), "An OPDS request without credentials didn't return the authentication document")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An OPDS request without credentials didn't return a valid opdsAuthDocument object") {
+		assert.Equal(t, opdsAuthentication, *resp, "An OPDS request without credentials didn't return the proper authentication document")
+	}
+
+	w, r = mockRequest(t, "/opds", nil, false)
+	r.SetBasicAuth(expID.Account, expOPDSPassword)
+	OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(10, time.Minute), 0)(okHandler).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Wrong OPDS credentials didn't get stopped by the authenticator")
+	assert.Equal(t, before+1, testutil.ToFloat64(failures), "Wrong OPDS credentials weren't counted as a failed login")
+
+	w, r = mockRequest(t, "/opds", nil, false)
+	r.SetBasicAuth(expID.Account, expOPDSPassword)
+	OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(10, time.Minute), 0)(okHandler).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "A failing OPDS credentials check didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestDisabledOPDSAuthenticator(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VerifyPassword", expID.Account, expOPDSPassword).
+		Return(database.ErrAccountDisabled).Once()
+	dbMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditLoginFailed && e.Actor == expID.Account && string(e.Payload) == `{"method":"opds","reason":"account_disabled"}`
+	})).Return(nil).Once()
+
+	w, r := mockRequest(t, "/opds", nil, false)
+	r.SetBasicAuth(expID.Account, expOPDSPassword)
+	OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(10, time.Minute), 0)(okHandler).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "A disabled account's OPDS credentials didn't get stopped by the authenticator")
+	dbMock.AssertExpectations(t)
+}
+
+func TestCachedOPDSAuthenticator(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VerifyPassword", expID.Account, expOPDSPassword).
+		Return(nil).Once()
+	dbMock.On("VerifyPassword", expID.Account, "wrong").
+		Return(database.ErrWrongPass).Once()
+	dbMock.On("RecordAuditEvent", mock.Anything).Return(nil).Once()
+
+	handler := OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(10, time.Minute), time.Minute)(okHandler)
+	for i := 0; i < 3; i++ {
+		w, r := mockRequest(t, "/opds", nil, false)
+		r.SetBasicAuth(expID.Account, expOPDSPassword)
+		handler.ServeHTTP(w, r)
+		assert.NotEqual(t, http.StatusUnauthorized, w.Code, "Cached OPDS credentials didn't get through the authenticator")
+	}
+
+	w, r := mockRequest(t, "/opds", nil, false)
+	r.SetBasicAuth(expID.Account, "wrong")
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Another password than the cached one got through the authenticator")
+	dbMock.AssertExpectations(t)
+}
+
+func TestRateLimitedOPDSAuthenticator(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VerifyPassword", expID.Account, "wrong").
+		Return(database.ErrWrongPass).Twice()
+	dbMock.On("RecordAuditEvent", mock.Anything).Return(nil).Twice()
+	limited := metricshelper.Logins.WithLabelValues(loginMethodOPDS, metricshelper.ResultLimited)
+	before := testutil.ToFloat64(limited)
+
+	handler := OPDSAuthenticatorMiddleware(dbMock, dbMock, NewLoginLimiter(2, time.Minute), time.Minute)(okHandler)
+	for i := 0; i < 2; i++ {
+		w, r := mockRequest(t, "/opds", nil, false)
+		r.SetBasicAuth(expID.Account, "wrong")
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Wrong OPDS credentials under the limit weren't checked")
+	}
+
+	w, r := mockRequest(t, "/opds", nil, false)
+	r.SetBasicAuth(expID.Account, expOPDSPassword)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "OPDS credentials over the failure limit didn't get refused")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"), "OPDS credentials over the failure limit weren't told when to retry")
+	assert.Equal(t, before+1, testutil.ToFloat64(limited), "Refused OPDS credentials weren't counted as a limited login")
+	dbMock.AssertExpectations(t)
+}
+
+func TestSuccessfulOPDSAuthentication(t *testing.T) {
+	w, r := mockRequest(t, "/opds/auth", nil, false)
+	OPDSAuthentication().ServeHTTP(w, r)
+
+	resp := &opdsAuthDocument{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS authentication document request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful OPDS authentication document request didn't return a valid opdsAuthDocument object") {
+		assert.Equal(t, "http://opds-spec.org/auth/basic", resp.Authentication[0].Type, "A successful OPDS authentication document request didn't offer basic authentication")
+	}
+}
@@ -0,0 +1,150 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var errOPDSSearchQueryMissing = errors.New("query missing")
+
+// OPDSNewBooks is the acquisition feed of the books added within
+// newArrivalWindow, newest first.
+func OPDSNewBooks(
+	db database.BookInterface,
+	format OPDSFormat,
+	conf OPDSConfig,
+	newArrivalWindow time.Duration,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("OPDSNewBooks: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		page := opdsPage(r)
+		books, err := db.GetNewBooksPaginated(ctx, opdsPageSize, page*opdsPageSize, newArrivalWindow, uuid.NullUUID{}, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing new books for OPDS")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		renderOPDS(w, format, conf, opdsFeed{
+			id:          opdsID("/new"),
+			title:       "New arrivals",
+			path:        "/new",
+			acquisition: true,
+			paged:       true,
+			page:        page,
+			books:       books,
+		})
+	}
+}
+
+// OPDSPopularBooks is the acquisition feed of the most read books over the
+// window query parameter, 30 days unless told otherwise.
+func OPDSPopularBooks(
+	db database.BookInterface,
+	format OPDSFormat,
+	conf OPDSConfig,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("OPDSPopularBooks: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		query := url.Values{}
+		window := strings.TrimSpace(r.URL.Query().Get("window"))
+		if window == "" {
+			window = database.PopularityWindow30d
+		} else if !database.IsPopularityWindow(window) {
+			log.Ctx(ctx).Debug().Str("window", window).Msg("OPDSPopularBooks: popularity window unrecognized")
+			render.Render(w, r, BadRequestError(errPopularityWindowUnrecognized))
+			return
+		} else {
+			query.Set("window", window)
+		}
+
+		page := opdsPage(r)
+		books, err := db.GetPopularBooksPaginated(ctx, opdsPageSize, page*opdsPageSize, window, uuid.NullUUID{}, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing popular books for OPDS")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		renderOPDS(w, format, conf, opdsFeed{
+			id:          opdsID("/popular"),
+			title:       "Popular",
+			path:        "/popular",
+			query:       query,
+			acquisition: true,
+			paged:       true,
+			page:        page,
+			books:       books,
+		})
+	}
+}
+
+// OPDSSearchBooks is the acquisition feed of the books matching the query
+// parameter, searched like on /books.
+func OPDSSearchBooks(
+	db database.BookInterface,
+	format OPDSFormat,
+	conf OPDSConfig,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("OPDSSearchBooks: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		searchQuery := strings.TrimSpace(r.URL.Query().Get("query"))
+		if searchQuery == "" {
+			log.Ctx(ctx).Debug().Msg("OPDSSearchBooks: search query missing")
+			render.Render(w, r, BadRequestError(errOPDSSearchQueryMissing))
+			return
+		}
+
+		page := opdsPage(r)
+		books, err := db.SearchBooks(ctx, opdsPageSize, page*opdsPageSize, searchQuery, uuid.NullUUID{}, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while searching books for OPDS")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		renderOPDS(w, format, conf, opdsFeed{
+			id:          opdsID("/search"),
+			title:       "Search results for " + searchQuery,
+			path:        "/search",
+			query:       url.Values{"query": {searchQuery}},
+			acquisition: true,
+			paged:       true,
+			page:        page,
+			books:       books,
+		})
+	}
+}
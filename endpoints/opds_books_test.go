@@ -0,0 +1,198 @@
+package endpoints
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var expOPDSConfig = OPDSConfig{
+	AcquisitionURL:  "https://files.example.com/books/{id}.epub",
+	AcquisitionType: "application/epub+zip",
+}
+
+func mockOPDSBook() database.Book {
+	return database.Book{
+		ID:       uuid.New(),
+		Title:    "The Left Hand of Darkness",
+		Author:   "Ursula K. Le Guin",
+		CoverID:  uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		Summary:  "An envoy on a frozen world.",
+		ISBN:     "9780441478125",
+		Language: "en",
+		Authors: database.BookAuthors{
+			{ID: uuid.New(), Name: "Ursula K. Le Guin", Role: database.CreditAuthor},
+			{ID: uuid.New(), Name: "Someone Else", Role: "translator"},
+		},
+		Genres: database.BookGenres{
+			{ID: uuid.New(), Name: "Science fiction"},
+		},
+	}
+}
+
+func TestSuccessfulOPDSNewBooks(t *testing.T) {
+	expDBBook := mockOPDSBook()
+	expDBBooks := make([]database.Book, opdsPageSize)
+	for i := range expDBBooks {
+		expDBBooks[i] = expDBBook
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetNewBooksPaginated", opdsPageSize, opdsPageSize, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, "/opds/new?page=1", nil, true)
+	handler := OPDSNewBooks(dbMock, OPDSAtom, expOPDSConfig, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	resp := &atomTestFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS new books request didn't return the proper response code")
+	assert.Equal(t, opdsAcquisitionType, w.Header().Get("content-type"), "A successful OPDS new books request didn't return an acquisition feed")
+	if assert.Nil(t, xml.NewDecoder(w.Body).Decode(resp), "A successful OPDS new books request didn't return a valid Atom feed") {
+		assert.Len(t, resp.Entries, opdsPageSize, "A successful OPDS new books request didn't list the books")
+		assert.Equal(t, opdsPageSize, resp.ItemsPerPage, "A successful OPDS new books request didn't return the page size")
+
+		entry := resp.Entries[0]
+		assert.Equal(t, "urn:uuid:"+expDBBook.ID.String(), entry.ID, "A successful OPDS new books request didn't identify the book")
+		assert.Equal(t, "urn:isbn:"+expDBBook.ISBN, entry.Identifier, "A successful OPDS new books request didn't return the ISBN")
+		assert.Equal(t, "en", entry.Language, "A successful OPDS new books request didn't return the language")
+		assert.Equal(t, []atomAuthor{{Name: "Ursula K. Le Guin"}}, entry.Authors, "A successful OPDS new books request didn't return only the writers")
+		assert.Equal(t, expDBBook.Genres[0].Name, entry.Categories[0].Label, "A successful OPDS new books request didn't return the genres")
+		assert.Equal(t, expDBBook.Summary, entry.Summary, "A successful OPDS new books request didn't return the summary")
+
+		covers := CoverURLs(expDBBook.CoverID.UUID)
+		assert.Equal(t, covers["large"], atomLinkTo(entry.Links, opdsRelImage).Href, "A successful OPDS new books request didn't link the cover")
+		assert.Equal(t, covers["small"], atomLinkTo(entry.Links, opdsRelThumbnail).Href, "A successful OPDS new books request didn't link the thumbnail")
+		if acquisition := atomLinkTo(entry.Links, opdsRelAcquisition); assert.NotNil(t, acquisition, "A successful OPDS new books request didn't link the download") {
+			assert.Equal(t, "https://files.example.com/books/"+expDBBook.ID.String()+".epub", acquisition.Href, "A successful OPDS new books request didn't fill in the acquisition link")
+			assert.Equal(t, "application/epub+zip", acquisition.Type, "A successful OPDS new books request didn't return the acquisition type")
+		}
+
+		assert.Equal(t, "/opds/new?page=2", atomLinkTo(resp.Links, "next").Href, "A full OPDS new books page didn't link the next page")
+		assert.Equal(t, "/opds/new", atomLinkTo(resp.Links, "previous").Href, "A later OPDS new books page didn't link the previous page")
+	}
+	dbMock.AssertExpectations(t)
+
+	dbMock.On("GetNewBooksPaginated", opdsPageSize, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
+		Return([]database.Book{expDBBook}, nil).Once()
+
+	w, r = mockRequest(t, "/opds/new", nil, true)
+	handler = OPDSNewBooks(dbMock, OPDSAtom, OPDSConfig{}, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	resp = &atomTestFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS new books request didn't return the proper response code")
+	if assert.Nil(t, xml.NewDecoder(w.Body).Decode(resp), "A successful OPDS new books request didn't return a valid Atom feed") {
+		assert.Nil(t, atomLinkTo(resp.Links, "next"), "The last OPDS new books page linked a next page")
+		assert.Nil(t, atomLinkTo(resp.Links, "previous"), "The first OPDS new books page linked a previous page")
+		assert.Nil(t, atomLinkTo(resp.Entries[0].Links, opdsRelAcquisition), "An OPDS catalog without downloads linked one")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedOPDSNewBooks(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetNewBooksPaginated", opdsPageSize, 0, expNewArrivalWindow, uuid.NullUUID{}, expID.Account).
+		Return(nil, sql.ErrConnDone).Once()
+
+	w, r := mockRequest(t, "/opds/new", nil, true)
+	handler := OPDSNewBooks(dbMock, OPDSAtom, expOPDSConfig, expNewArrivalWindow)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := InternalServerError().(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A failing OPDS new books request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A failing OPDS new books request didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A failing OPDS new books request didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestSuccessfulOPDSPopularBooks(t *testing.T) {
+	expDBBook := mockOPDSBook()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetPopularBooksPaginated", opdsPageSize, 0, database.PopularityWindow7d, uuid.NullUUID{}, expID.Account).
+		Return([]database.Book{expDBBook}, nil).Once()
+
+	w, r := mockRequest(t, "/opds/v2/popular?window=7d", nil, true)
+	handler := OPDSPopularBooks(dbMock, OPDSJSON, expOPDSConfig)
+	handler.ServeHTTP(w, r)
+
+	resp := &opdsJSONFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS 2.0 popular books request didn't return the proper response code")
+	assert.Equal(t, opdsJSONType, w.Header().Get("content-type"), "A successful OPDS 2.0 popular books request didn't return an OPDS 2.0 feed")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful OPDS 2.0 popular books request didn't return a valid opdsJSONFeed object") {
+		assert.Equal(t, "/opds/v2/popular?window=7d", jsonLinkTo(resp.Links, "self").Href, "A successful OPDS 2.0 popular books request didn't keep its window")
+		assert.Equal(t, 1, resp.Metadata.CurrentPage, "A successful OPDS 2.0 popular books request didn't return the page")
+		if assert.Len(t, resp.Publications, 1, "A successful OPDS 2.0 popular books request didn't list the books") {
+			publication := resp.Publications[0]
+			assert.Equal(t, "urn:isbn:"+expDBBook.ISBN, publication.Metadata.Identifier, "A successful OPDS 2.0 popular books request didn't identify the book")
+			assert.Equal(t, expDBBook.Title, publication.Metadata.Title, "A successful OPDS 2.0 popular books request didn't return the title")
+			assert.Equal(t, []opdsJSONContributor{{Name: "Ursula K. Le Guin"}}, publication.Metadata.Author, "A successful OPDS 2.0 popular books request didn't return only the writers")
+			assert.NotNil(t, jsonLinkTo(publication.Links, opdsRelAcquisition), "A successful OPDS 2.0 popular books request didn't link the download")
+			assert.NotNil(t, jsonLinkTo(publication.Images, opdsRelThumbnail), "A successful OPDS 2.0 popular books request didn't link the thumbnail")
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedOPDSPopularBooks(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, "/opds/popular?window=1y", nil, true)
+	handler := OPDSPopularBooks(dbMock, OPDSAtom, expOPDSConfig)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errPopularityWindowUnrecognized).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An OPDS popular books request with an unknown window didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An OPDS popular books request with an unknown window didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An OPDS popular books request with an unknown window didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestSuccessfulOPDSSearchBooks(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SearchBooks", opdsPageSize, 0, "le guin", uuid.NullUUID{}, expID.Account).
+		Return([]database.Book{}, nil).Once()
+
+	w, r := mockRequest(t, "/opds/v2/search?query=le+guin", nil, true)
+	handler := OPDSSearchBooks(dbMock, OPDSJSON, expOPDSConfig)
+	handler.ServeHTTP(w, r)
+
+	resp := &opdsJSONFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "An empty OPDS 2.0 search didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An empty OPDS 2.0 search didn't return a valid opdsJSONFeed object") {
+		assert.Empty(t, resp.Publications, "An empty OPDS 2.0 search returned publications")
+		assert.Equal(t, "/opds/v2/search?query=le+guin", jsonLinkTo(resp.Links, "self").Href, "An empty OPDS 2.0 search didn't keep its query")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedOPDSSearchBooks(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, "/opds/search?query=%20", nil, true)
+	handler := OPDSSearchBooks(dbMock, OPDSAtom, expOPDSConfig)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errOPDSSearchQueryMissing).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An OPDS search without a query didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An OPDS search without a query didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An OPDS search without a query didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
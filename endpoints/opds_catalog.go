@@ -0,0 +1,88 @@
+package endpoints
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OPDSCatalog is the start of the catalog, leading to the new and popular
+// books and the account's shelves.
+func OPDSCatalog(format OPDSFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderOPDS(w, format, OPDSConfig{}, opdsFeed{
+			id:    opdsID(""),
+			title: "E-Library",
+			navigation: []opdsNavigation{
+				{
+					id:          opdsID("/new"),
+					title:       "New arrivals",
+					summary:     "Books recently added to the library.",
+					path:        "/new",
+					rel:         "http://opds-spec.org/sort/new",
+					acquisition: true,
+				},
+				{
+					id:          opdsID("/popular"),
+					title:       "Popular",
+					summary:     "The most read books of the last 30 days.",
+					path:        "/popular",
+					rel:         "http://opds-spec.org/sort/popular",
+					acquisition: true,
+				},
+				{
+					id:      opdsID("/shelves"),
+					title:   "My shelves",
+					summary: "Your bookshelves and favorites.",
+					path:    "/shelves",
+					rel:     "subsection",
+				},
+			},
+		})
+	}
+}
+
+type openSearchDescription struct {
+	XMLName        xml.Name        `xml:"OpenSearchDescription"`
+	Xmlns          string          `xml:"xmlns,attr"`
+	ShortName      string          `xml:"ShortName"`
+	Description    string          `xml:"Description"`
+	InputEncoding  string          `xml:"InputEncoding"`
+	OutputEncoding string          `xml:"OutputEncoding"`
+	URLs           []openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+var opdsSearchDescription = openSearchDescription{
+	Xmlns:          "http://a9.com/-/spec/opensearch/1.1/",
+	ShortName:      "E-Library",
+	Description:    "Search the library by title, author or ISBN.",
+	InputEncoding:  "UTF-8",
+	OutputEncoding: "UTF-8",
+	URLs: []openSearchURL{
+		{Type: opdsAcquisitionType, Template: OPDSAtom.root() + "/search?query={searchTerms}"},
+		{Type: opdsJSONType, Template: OPDSJSON.root() + "/search?query={searchTerms}"},
+	},
+}
+
+// OPDSSearchDescription tells e-readers how to search the catalog, the same
+// way books are searched on /books.
+func OPDSSearchDescription() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := xml.Marshal(opdsSearchDescription)
+		if err != nil {
+			log.Ctx(r.Context()).Error().Err(err).Msg("Encoding the OpenSearch description failed")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("content-type", openSearchType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(append([]byte(xml.Header), body...))
+	}
+}
@@ -0,0 +1,97 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// atomTestFeed reads feeds back with their namespaces resolved, so a wrongly
+// declared prefix fails the tests.
+type atomTestFeed struct {
+	XMLName      xml.Name        `xml:"http://www.w3.org/2005/Atom feed"`
+	ID           string          `xml:"id"`
+	Title        string          `xml:"title"`
+	ItemsPerPage int             `xml:"http://a9.com/-/spec/opensearch/1.1/ itemsPerPage"`
+	Links        []atomLink      `xml:"link"`
+	Entries      []atomTestEntry `xml:"entry"`
+}
+
+type atomTestEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Authors    []atomAuthor   `xml:"author"`
+	Identifier string         `xml:"http://purl.org/dc/terms/ identifier"`
+	Language   string         `xml:"http://purl.org/dc/terms/ language"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary"`
+	Links      []atomLink     `xml:"link"`
+}
+
+func atomLinkTo(links []atomLink, rel string) *atomLink {
+	for _, link := range links {
+		if link.Rel == rel {
+			return &link
+		}
+	}
+	return nil
+}
+
+func jsonLinkTo(links []opdsJSONLink, rel string) *opdsJSONLink {
+	for _, link := range links {
+		if link.Rel == rel {
+			return &link
+		}
+	}
+	return nil
+}
+
+func TestSuccessfulOPDSCatalog(t *testing.T) {
+	w, r := mockRequest(t, "/opds", nil, true)
+	OPDSCatalog(OPDSAtom).ServeHTTP(w, r)
+
+	resp := &atomTestFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS catalog request didn't return the proper response code")
+	assert.Equal(t, opdsNavigationType, w.Header().Get("content-type"), "A successful OPDS catalog request didn't return a navigation feed")
+	if assert.Nil(t, xml.NewDecoder(w.Body).Decode(resp), "A successful OPDS catalog request didn't return a valid Atom feed") {
+		assert.Len(t, resp.Entries, 3, "A successful OPDS catalog request didn't list its sections")
+		assert.Equal(t, "/opds/new", resp.Entries[0].Links[0].Href, "A successful OPDS catalog request didn't link the new arrivals")
+		assert.Equal(t, opdsAcquisitionType, resp.Entries[0].Links[0].Type, "A successful OPDS catalog request didn't link an acquisition feed")
+		if search := atomLinkTo(resp.Links, "search"); assert.NotNil(t, search, "A successful OPDS catalog request didn't link the search") {
+			assert.Equal(t, "/opds/search.xml", search.Href, "A successful OPDS catalog request didn't link the OpenSearch description")
+		}
+	}
+
+	w, r = mockRequest(t, "/opds/v2", nil, true)
+	OPDSCatalog(OPDSJSON).ServeHTTP(w, r)
+
+	jsonResp := &opdsJSONFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS 2.0 catalog request didn't return the proper response code")
+	assert.Equal(t, opdsJSONType, w.Header().Get("content-type"), "A successful OPDS 2.0 catalog request didn't return an OPDS 2.0 feed")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(jsonResp), "A successful OPDS 2.0 catalog request didn't return a valid opdsJSONFeed object") {
+		assert.Len(t, jsonResp.Navigation, 3, "A successful OPDS 2.0 catalog request didn't list its sections")
+		assert.Equal(t, "/opds/v2/shelves", jsonResp.Navigation[2].Href, "A successful OPDS 2.0 catalog request didn't link the shelves")
+		assert.Nil(t, jsonResp.Publications, "A successful OPDS 2.0 catalog request listed publications")
+		if search := jsonLinkTo(jsonResp.Links, "search"); assert.NotNil(t, search, "A successful OPDS 2.0 catalog request didn't link the search") {
+			assert.True(t, search.Templated, "A successful OPDS 2.0 catalog request didn't link a search template")
+		}
+	}
+}
+
+func TestSuccessfulOPDSSearchDescription(t *testing.T) {
+	w, r := mockRequest(t, "/opds/search.xml", nil, false)
+	OPDSSearchDescription().ServeHTTP(w, r)
+
+	resp := &struct {
+		ShortName string          `xml:"ShortName"`
+		URLs      []openSearchURL `xml:"Url"`
+	}{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OpenSearch description request didn't return the proper response code")
+	assert.Equal(t, openSearchType, w.Header().Get("content-type"), "A successful OpenSearch description request didn't return the proper content type")
+	if assert.Nil(t, xml.NewDecoder(w.Body).Decode(resp), "A successful OpenSearch description request didn't return valid XML") {
+		assert.Equal(t, opdsSearchDescription.URLs, resp.URLs, "A successful OpenSearch description request didn't return the search templates")
+	}
+}
@@ -0,0 +1,443 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"ic-rhadi/e_library/coverhelper"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OPDSFormat is the flavour of OPDS a catalog tree is served in.
+type OPDSFormat int
+
+const (
+	// OPDSAtom serves OPDS 1.2 Atom feeds under /opds.
+	OPDSAtom OPDSFormat = iota
+	// OPDSJSON serves OPDS 2.0 JSON feeds under /opds/v2.
+	OPDSJSON
+)
+
+func (f OPDSFormat) root() string {
+	if f == OPDSJSON {
+		return "/opds/v2"
+	}
+	return "/opds"
+}
+
+// OPDSConfig tells e-readers where to download a book from. The library
+// doesn't store book files itself, so without an acquisition URL the feeds
+// only describe the books.
+type OPDSConfig struct {
+	// AcquisitionURL is a link template where {id} is replaced by the book id.
+	AcquisitionURL  string
+	AcquisitionType string
+}
+
+func (c OPDSConfig) acquisitionLink(bookID string) string {
+	if c.AcquisitionURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(c.AcquisitionURL, "{id}", bookID)
+}
+
+const opdsPageSize = 20
+
+const (
+	opdsNavigationType   = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	opdsAcquisitionType  = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	opdsJSONType         = "application/opds+json"
+	opdsAuthDocumentType = "application/opds-authentication+json"
+	openSearchType       = "application/opensearchdescription+xml"
+
+	opdsRelAcquisition = "http://opds-spec.org/acquisition"
+	opdsRelImage       = "http://opds-spec.org/image"
+	opdsRelThumbnail   = "http://opds-spec.org/image/thumbnail"
+	opdsRelAuthDoc     = "http://opds-spec.org/auth/document"
+)
+
+// opdsFeed is a catalog page before it's written in either format.
+type opdsFeed struct {
+	id    string
+	title string
+	// path is relative to the catalog root, query is what the page was
+	// requested with.
+	path        string
+	query       url.Values
+	acquisition bool
+	// paged feeds hold a page of opdsPageSize books, the others all of them.
+	paged      bool
+	page       int
+	navigation []opdsNavigation
+	books      []database.Book
+}
+
+type opdsNavigation struct {
+	id          string
+	title       string
+	summary     string
+	path        string
+	rel         string
+	acquisition bool
+}
+
+func (f opdsFeed) href(format OPDSFormat, page int) string {
+	href := format.root() + f.path
+	query := url.Values{}
+	for k, v := range f.query {
+		query[k] = v
+	}
+	query.Del("page")
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if encoded := query.Encode(); encoded != "" {
+		href += "?" + encoded
+	}
+	return href
+}
+
+func (f opdsFeed) hasNext() bool {
+	return f.paged && len(f.books) == opdsPageSize
+}
+
+func (f opdsFeed) hasPrevious() bool {
+	return f.paged && f.page > 0
+}
+
+// opdsPage reads the zero based page a feed is requested with.
+func opdsPage(r *http.Request) int {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 0 {
+		page = 0
+	}
+	return page
+}
+
+func renderOPDS(w http.ResponseWriter, format OPDSFormat, conf OPDSConfig, feed opdsFeed) {
+	var body []byte
+	var err error
+	if format == OPDSJSON {
+		w.Header().Set("content-type", opdsJSONType)
+		body, err = json.Marshal(opdsJSONFeedFrom(feed, conf))
+	} else {
+		kind := opdsNavigationType
+		if feed.acquisition {
+			kind = opdsAcquisitionType
+		}
+		w.Header().Set("content-type", kind)
+		body, err = xml.Marshal(atomFeedFrom(feed, conf, time.Now()))
+		body = append([]byte(xml.Header), body...)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("Encoding an OPDS feed failed")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+type atomFeed struct {
+	XMLName         xml.Name    `xml:"feed"`
+	Xmlns           string      `xml:"xmlns,attr"`
+	XmlnsDC         string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS       string      `xml:"xmlns:opds,attr"`
+	XmlnsOpenSearch string      `xml:"xmlns:opensearch,attr"`
+	ID              string      `xml:"id"`
+	Title           string      `xml:"title"`
+	Updated         string      `xml:"updated"`
+	Author          atomAuthor  `xml:"author"`
+	ItemsPerPage    int         `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex      int         `xml:"opensearch:startIndex,omitempty"`
+	Links           []atomLink  `xml:"link"`
+	Entries         []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Language   string         `xml:"dc:language,omitempty"`
+	Publisher  string         `xml:"dc:publisher,omitempty"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary"`
+	Content    *atomText      `xml:"content"`
+	Links      []atomLink     `xml:"link"`
+}
+
+func atomFeedFrom(feed opdsFeed, conf OPDSConfig, now time.Time) atomFeed {
+	updated := now.UTC().Format(time.RFC3339)
+	selfType := opdsNavigationType
+	if feed.acquisition {
+		selfType = opdsAcquisitionType
+	}
+
+	a := atomFeed{
+		Xmlns:           "http://www.w3.org/2005/Atom",
+		XmlnsDC:         "http://purl.org/dc/terms/",
+		XmlnsOPDS:       "http://opds-spec.org/2010/catalog",
+		XmlnsOpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		ID:              feed.id,
+		Title:           feed.title,
+		Updated:         updated,
+		Author:          atomAuthor{Name: "E-Library"},
+		Links: []atomLink{
+			{Rel: "self", Href: feed.href(OPDSAtom, feed.page), Type: selfType},
+			{Rel: "start", Href: OPDSAtom.root(), Type: opdsNavigationType},
+			{Rel: "search", Href: OPDSAtom.root() + "/search.xml", Type: openSearchType},
+			{Rel: opdsRelAuthDoc, Href: "/opds/auth", Type: opdsAuthDocumentType},
+		},
+	}
+	if feed.paged {
+		a.ItemsPerPage = opdsPageSize
+		a.StartIndex = feed.page*opdsPageSize + 1
+	}
+	if feed.hasNext() {
+		a.Links = append(a.Links, atomLink{Rel: "next", Href: feed.href(OPDSAtom, feed.page+1), Type: selfType})
+	}
+	if feed.hasPrevious() {
+		a.Links = append(a.Links, atomLink{Rel: "previous", Href: feed.href(OPDSAtom, feed.page-1), Type: selfType})
+	}
+
+	for _, n := range feed.navigation {
+		linkType := opdsNavigationType
+		if n.acquisition {
+			linkType = opdsAcquisitionType
+		}
+		a.Entries = append(a.Entries, atomEntry{
+			ID:      n.id,
+			Title:   n.title,
+			Updated: updated,
+			Content: &atomText{Type: "text", Text: n.summary},
+			Links: []atomLink{
+				{Rel: n.rel, Href: OPDSAtom.root() + n.path, Type: linkType},
+			},
+		})
+	}
+
+	for _, book := range feed.books {
+		b := BookFromDatabase(book)
+		entry := atomEntry{
+			ID:         "urn:uuid:" + b.ID,
+			Title:      b.Title,
+			Updated:    updated,
+			Identifier: opdsISBN(b.ISBN),
+			Language:   b.Language,
+			Publisher:  b.Publisher,
+			Issued:     b.PublishedOn,
+			Links: []atomLink{
+				{Rel: "alternate", Href: "/books/" + b.ID, Type: "application/json"},
+			},
+		}
+		for _, author := range opdsAuthors(b) {
+			entry.Authors = append(entry.Authors, atomAuthor{Name: author})
+		}
+		for _, genre := range b.Genres {
+			entry.Categories = append(entry.Categories, atomCategory{Term: genre.ID, Label: genre.Name})
+		}
+		if b.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Text: b.Summary}
+		}
+		for _, image := range opdsImages(b) {
+			entry.Links = append(entry.Links, atomLink{Rel: image.Rel, Href: image.Href, Type: image.Type})
+		}
+		if href := conf.acquisitionLink(b.ID); href != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: opdsRelAcquisition, Href: href, Type: conf.AcquisitionType})
+		}
+		a.Entries = append(a.Entries, entry)
+	}
+	return a
+}
+
+type opdsJSONFeed struct {
+	Metadata     opdsJSONMetadata      `json:"metadata"`
+	Links        []opdsJSONLink        `json:"links"`
+	Navigation   []opdsJSONLink        `json:"navigation,omitempty"`
+	Publications []opdsJSONPublication `json:"publications,omitempty"`
+}
+
+type opdsJSONMetadata struct {
+	Title        string `json:"title"`
+	ItemsPerPage int    `json:"itemsPerPage,omitempty"`
+	CurrentPage  int    `json:"currentPage,omitempty"`
+}
+
+type opdsJSONLink struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type opdsJSONContributor struct {
+	Name string `json:"name"`
+}
+
+type opdsJSONSubject struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+type opdsJSONPublication struct {
+	Metadata opdsJSONPublicationMetadata `json:"metadata"`
+	Links    []opdsJSONLink              `json:"links"`
+	Images   []opdsJSONLink              `json:"images,omitempty"`
+}
+
+type opdsJSONPublicationMetadata struct {
+	Type          string                `json:"@type"`
+	Identifier    string                `json:"identifier"`
+	Title         string                `json:"title"`
+	Author        []opdsJSONContributor `json:"author,omitempty"`
+	Language      string                `json:"language,omitempty"`
+	Publisher     string                `json:"publisher,omitempty"`
+	Published     string                `json:"published,omitempty"`
+	Description   string                `json:"description,omitempty"`
+	NumberOfPages int                   `json:"numberOfPages,omitempty"`
+	Subject       []opdsJSONSubject     `json:"subject,omitempty"`
+}
+
+func opdsJSONFeedFrom(feed opdsFeed, conf OPDSConfig) opdsJSONFeed {
+	j := opdsJSONFeed{
+		Metadata: opdsJSONMetadata{Title: feed.title},
+		Links: []opdsJSONLink{
+			{Rel: "self", Href: feed.href(OPDSJSON, feed.page), Type: opdsJSONType},
+			{Rel: "start", Href: OPDSJSON.root(), Type: opdsJSONType},
+			{Rel: "search", Href: OPDSJSON.root() + "/search{?query}", Type: opdsJSONType, Templated: true},
+			{Rel: opdsRelAuthDoc, Href: "/opds/auth", Type: opdsAuthDocumentType},
+		},
+	}
+	if feed.paged {
+		j.Metadata.ItemsPerPage = opdsPageSize
+		j.Metadata.CurrentPage = feed.page + 1
+	}
+	if feed.hasNext() {
+		j.Links = append(j.Links, opdsJSONLink{Rel: "next", Href: feed.href(OPDSJSON, feed.page+1), Type: opdsJSONType})
+	}
+	if feed.hasPrevious() {
+		j.Links = append(j.Links, opdsJSONLink{Rel: "previous", Href: feed.href(OPDSJSON, feed.page-1), Type: opdsJSONType})
+	}
+
+	for _, n := range feed.navigation {
+		j.Navigation = append(j.Navigation, opdsJSONLink{
+			Rel:   n.rel,
+			Href:  OPDSJSON.root() + n.path,
+			Type:  opdsJSONType,
+			Title: n.title,
+		})
+	}
+
+	for _, book := range feed.books {
+		b := BookFromDatabase(book)
+		identifier := opdsISBN(b.ISBN)
+		if identifier == "" {
+			identifier = "urn:uuid:" + b.ID
+		}
+		p := opdsJSONPublication{
+			Metadata: opdsJSONPublicationMetadata{
+				Type:          "http://schema.org/Book",
+				Identifier:    identifier,
+				Title:         b.Title,
+				Language:      b.Language,
+				Publisher:     b.Publisher,
+				Published:     b.PublishedOn,
+				Description:   b.Summary,
+				NumberOfPages: b.PageCount,
+			},
+			Links: []opdsJSONLink{
+				{Rel: "alternate", Href: "/books/" + b.ID, Type: "application/json"},
+			},
+			Images: opdsImages(b),
+		}
+		for _, author := range opdsAuthors(b) {
+			p.Metadata.Author = append(p.Metadata.Author, opdsJSONContributor{Name: author})
+		}
+		for _, genre := range b.Genres {
+			p.Metadata.Subject = append(p.Metadata.Subject, opdsJSONSubject{Name: genre.Name, Code: genre.ID})
+		}
+		if href := conf.acquisitionLink(b.ID); href != "" {
+			p.Links = append(p.Links, opdsJSONLink{Rel: opdsRelAcquisition, Href: href, Type: conf.AcquisitionType})
+		}
+		j.Publications = append(j.Publications, p)
+	}
+	return j
+}
+
+func opdsISBN(isbn string) string {
+	if isbn == "" {
+		return ""
+	}
+	return "urn:isbn:" + isbn
+}
+
+// opdsAuthors lists the book's writers, falling back on the author line books
+// had before credits were split up.
+func opdsAuthors(b BookResponse) []string {
+	var names []string
+	for _, author := range b.Authors {
+		if author.Role == database.CreditAuthor {
+			names = append(names, author.Name)
+		}
+	}
+	if len(names) == 0 && b.Author != "" {
+		names = append(names, b.Author)
+	}
+	return names
+}
+
+// opdsImages links the cover and its thumbnail. Generated covers are asked for
+// as JPEG, which every reader understands.
+func opdsImages(b BookResponse) []opdsJSONLink {
+	if b.Covers == nil {
+		if b.CoverURL == "" {
+			return nil
+		}
+		return []opdsJSONLink{
+			{Rel: opdsRelImage, Href: b.CoverURL},
+			{Rel: opdsRelThumbnail, Href: b.CoverURL},
+		}
+	}
+	jpeg := coverhelper.ContentType(coverhelper.FormatJPEG)
+	return []opdsJSONLink{
+		{Rel: opdsRelImage, Href: b.Covers["large"], Type: jpeg},
+		{Rel: opdsRelThumbnail, Href: b.Covers["small"], Type: jpeg},
+	}
+}
+
+// opdsID names a feed the same way in both formats.
+func opdsID(path string) string {
+	return "urn:e-library:opds" + strings.ReplaceAll(path, "/", ":")
+}
@@ -0,0 +1,108 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// OPDSShelves is the navigation feed of the account's shelves, the built-in
+// ones included.
+func OPDSShelves(
+	db database.ShelfInterface,
+	format OPDSFormat,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("OPDSShelves: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelves, err := db.GetShelves(ctx, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing shelves for OPDS")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		feed := opdsFeed{
+			id:    opdsID("/shelves"),
+			title: "My shelves",
+			path:  "/shelves",
+		}
+		for _, shelf := range shelves {
+			path := "/shelves/" + shelf.ID.String()
+			feed.navigation = append(feed.navigation, opdsNavigation{
+				id:          opdsID(path),
+				title:       shelf.Name,
+				summary:     shelf.Description,
+				path:        path,
+				rel:         "subsection",
+				acquisition: true,
+			})
+		}
+		renderOPDS(w, format, OPDSConfig{}, feed)
+	}
+}
+
+// OPDSShelf is the acquisition feed of one of the account's shelves, or a
+// public shelf of another account, in the shelf's order.
+func OPDSShelf(
+	db database.ShelfInterface,
+	format OPDSFormat,
+	conf OPDSConfig,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("OPDSShelf: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelfID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Getting an OPDS shelf with a malformed id")
+			render.Render(w, r, BadRequestError(errShelfIDMalformed))
+			return
+		}
+
+		shelf, err := db.GetShelf(ctx, shelfID, sch.Email)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting a shelf for OPDS")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		books, err := db.GetShelfBooks(ctx, shelfID, sch.Email)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while listing a shelf's books for OPDS")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		path := "/shelves/" + shelf.ID.String()
+		renderOPDS(w, format, conf, opdsFeed{
+			id:          opdsID(path),
+			title:       shelf.Name,
+			path:        path,
+			acquisition: true,
+			books:       books,
+		})
+	}
+}
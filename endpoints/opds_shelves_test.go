@@ -0,0 +1,91 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulOPDSShelves(t *testing.T) {
+	expDBShelves := []database.Shelf{
+		mockShelf(database.ShelfFavorites, "Favorites"),
+		mockShelf(database.ShelfCustom, "Summer reads"),
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelves", expID.Account).
+		Return(expDBShelves, nil).Once()
+
+	w, r := mockRequest(t, "/opds/v2/shelves", nil, true)
+	handler := OPDSShelves(dbMock, OPDSJSON)
+	handler.ServeHTTP(w, r)
+
+	resp := &opdsJSONFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS 2.0 shelves request didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful OPDS 2.0 shelves request didn't return a valid opdsJSONFeed object") {
+		if assert.Len(t, resp.Navigation, 2, "A successful OPDS 2.0 shelves request didn't list the shelves") {
+			assert.Equal(t, "Summer reads", resp.Navigation[1].Title, "A successful OPDS 2.0 shelves request didn't name the shelves")
+			assert.Equal(t, "/opds/v2/shelves/"+expDBShelves[1].ID.String(), resp.Navigation[1].Href, "A successful OPDS 2.0 shelves request didn't link the shelves")
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestSuccessfulOPDSShelf(t *testing.T) {
+	expDBShelf := mockShelf(database.ShelfCustom, "Summer reads")
+	expDBBooks := make([]database.Book, opdsPageSize)
+	for i := range expDBBooks {
+		expDBBooks[i] = mockOPDSBook()
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", expDBShelf.ID, expID.Account).
+		Return(&expDBShelf, nil).Once()
+	dbMock.On("GetShelfBooks", expDBShelf.ID, expID.Account).
+		Return(expDBBooks, nil).Once()
+
+	w, r := mockRequest(t, "/opds/shelves/"+expDBShelf.ID.String(), nil, true, param{"id", expDBShelf.ID.String()})
+	handler := OPDSShelf(dbMock, OPDSAtom, expOPDSConfig)
+	handler.ServeHTTP(w, r)
+
+	resp := &atomTestFeed{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful OPDS shelf request didn't return the proper response code")
+	if assert.Nil(t, xml.NewDecoder(w.Body).Decode(resp), "A successful OPDS shelf request didn't return a valid Atom feed") {
+		assert.Equal(t, expDBShelf.Name, resp.Title, "A successful OPDS shelf request didn't name the shelf")
+		assert.Len(t, resp.Entries, opdsPageSize, "A successful OPDS shelf request didn't list the books")
+		assert.Equal(t, expDBBooks[0].Title, resp.Entries[0].Title, "A successful OPDS shelf request didn't keep the shelf's order")
+		assert.Nil(t, atomLinkTo(resp.Links, "next"), "A whole OPDS shelf linked a next page")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedOPDSShelf(t *testing.T) {
+	expDBShelf := mockShelf(database.ShelfCustom, "Summer reads")
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", expDBShelf.ID, expID.Account).
+		Return(nil, database.ErrShelfNotFound).Once()
+
+	w, r := mockRequest(t, "/opds/shelves/"+expDBShelf.ID.String(), nil, true, param{"id", expDBShelf.ID.String()})
+	handler := OPDSShelf(dbMock, OPDSAtom, expOPDSConfig)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errShelfNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An OPDS request for a missing shelf didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An OPDS request for a missing shelf didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An OPDS request for a missing shelf didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+
+	w, r = mockRequest(t, "/opds/shelves/nope", nil, true, param{"id", "nope"})
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "An OPDS request for a malformed shelf id didn't return the proper response code")
+}
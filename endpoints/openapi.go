@@ -0,0 +1,829 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/coverhelper"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// apiAuth is how an operation is signed in to.
+type apiAuth int
+
+const (
+	apiPublic apiAuth = iota
+	apiBearer
+	apiAdmin
+	apiSupport
+	apiBasic
+)
+
+// apiBody is a request or response body. Its schema is read off value, a
+// zero value of the type the handler binds or renders; a nil value is an
+// opaque body of contentType.
+type apiBody struct {
+	contentType string
+	value       any
+}
+
+type apiResponse struct {
+	status      int
+	description string
+	body        *apiBody
+}
+
+type apiParam struct {
+	name        string
+	description string
+	schema      map[string]any
+	required    bool
+}
+
+// apiOperation documents one route. The OpenAPI document is built from
+// these, so a route added to main.go needs an entry here too.
+type apiOperation struct {
+	method    string
+	path      string
+	summary   string
+	tag       string
+	auth      apiAuth
+	query     []apiParam
+	request   *apiBody
+	responses []apiResponse
+}
+
+func jsonBody(value any) *apiBody {
+	return &apiBody{"application/json", value}
+}
+
+func rawBody(contentType string) *apiBody {
+	return &apiBody{contentType, nil}
+}
+
+func okResponse(description string, body *apiBody) apiResponse {
+	return apiResponse{http.StatusOK, description, body}
+}
+
+func createdResponse(description string, body *apiBody) apiResponse {
+	return apiResponse{http.StatusCreated, description, body}
+}
+
+func noContentResponse(description string) apiResponse {
+	return apiResponse{http.StatusNoContent, description, nil}
+}
+
+// failures documents the error responses an operation may send, all of them
+// in the ErrorResponse form.
+func failures(statuses ...int) []apiResponse {
+	var responses []apiResponse
+	for _, status := range statuses {
+		responses = append(responses, apiResponse{status, http.StatusText(status), jsonBody(ErrorResponse{})})
+	}
+	return responses
+}
+
+func respond(success apiResponse, statuses ...int) []apiResponse {
+	return append([]apiResponse{success}, failures(statuses...)...)
+}
+
+func queryParam(name string, description string) apiParam {
+	return apiParam{name: name, description: description, schema: map[string]any{"type": "string"}}
+}
+
+func enumParam(name string, description string, values ...string) apiParam {
+	return apiParam{name: name, description: description, schema: map[string]any{"type": "string", "enum": values}}
+}
+
+var pageParam = apiParam{
+	name:        "page",
+	description: "Zero based page number.",
+	schema:      map[string]any{"type": "integer", "minimum": 0, "default": 0},
+}
+
+var apiOperations = []apiOperation{
+	{
+		method: http.MethodPost, path: "/auth/login", tag: "auth",
+		summary:   "Sign in with email and password",
+		request:   jsonBody(loginPostRequest{}),
+		responses: respond(okResponse("Session and refresh tokens", jsonBody(tokenResponse{})), 400, 401, 422, 429, 500),
+	},
+	{
+		method: http.MethodPost, path: "/auth/google", tag: "auth",
+		summary:   "Sign in with a Google ID token",
+		request:   jsonBody(loginGoogleRequest{}),
+		responses: respond(okResponse("Session and refresh tokens", jsonBody(tokenResponse{})), 400, 401, 422, 500),
+	},
+	{
+		method: http.MethodPost, path: "/auth/refresh", tag: "auth",
+		summary:   "Trade a refresh token for new tokens",
+		request:   jsonBody(refreshTokenRequest{}),
+		responses: respond(okResponse("Session and refresh tokens", jsonBody(tokenResponse{})), 400, 401, 500),
+	},
+	{
+		method: http.MethodPost, path: "/auth/register", tag: "auth",
+		summary:   "Register with email and password",
+		request:   jsonBody(registerPostRequest{}),
+		responses: respond(createdResponse("Account registered, activation email sent", jsonBody(registerResponse{})), 400, 409, 500),
+	},
+	{
+		method: http.MethodPost, path: "/auth/register/google", tag: "auth",
+		summary:   "Register with a Google ID token",
+		request:   jsonBody(registerGoogleRequest{}),
+		responses: respond(createdResponse("Account registered, activation email sent", jsonBody(registerResponse{})), 400, 409, 422, 500),
+	},
+	{
+		method: http.MethodGet, path: "/auth/resend", tag: "auth",
+		summary: "Send the activation email again",
+		query: []apiParam{
+			{name: "email", schema: map[string]any{"type": "string", "format": "email"}, required: true},
+		},
+		responses: respond(okResponse("Activation email sent", jsonBody(resendResponse{})), 400, 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/auth/activate", tag: "auth",
+		summary: "Activate an account from its activation email",
+		query: []apiParam{
+			{name: "email", schema: map[string]any{"type": "string", "format": "email"}, required: true},
+			{name: "token", schema: map[string]any{"type": "string"}, required: true},
+		},
+		responses: respond(okResponse("Account activated", jsonBody(activatedResponse{})), 400, 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/books", tag: "books",
+		summary: "List or search books", auth: apiBearer,
+		query: []apiParam{
+			enumParam("criteria", "Which books to list.", "new", "popular", "newHomepage", "popularHomepage", "search"),
+			queryParam("query", "Title, author or ISBN, for criteria=search."),
+			enumParam("window", "Popularity window, 30d by default.", "7d", "30d", "all"),
+			{name: "genre", description: "Only books of this genre.", schema: map[string]any{"type": "string", "format": "uuid"}},
+			pageParam,
+		},
+		responses: respond(okResponse("Books", jsonBody(BooksResponse{})), 400, 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/books/{id}", tag: "books",
+		summary: "Get a book", auth: apiBearer,
+		responses: respond(okResponse("The book and the account's shelves it's on", jsonBody(BookDetailResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/books/{id}/similar", tag: "books",
+		summary: "List books similar to a book", auth: apiBearer,
+		responses: respond(okResponse("Similar books", jsonBody(BooksResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/books/{id}/reviews", tag: "reviews",
+		summary: "List a book's reviews", auth: apiBearer,
+		query:     []apiParam{enumParam("sort", "Most helpful first by default.", "helpful", "recent"), pageParam},
+		responses: respond(okResponse("Reviews", jsonBody(ReviewsResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodPut, path: "/books/{id}/review", tag: "reviews",
+		summary: "Rate and review a book", auth: apiBearer,
+		request:   jsonBody(saveReviewRequest{}),
+		responses: respond(okResponse("The saved review", jsonBody(ReviewResponse{})), 400, 401, 404, 422, 500),
+	},
+	{
+		method: http.MethodPut, path: "/reviews/{id}/vote", tag: "reviews",
+		summary: "Vote on whether a review is helpful", auth: apiBearer,
+		request:   jsonBody(voteReviewRequest{}),
+		responses: respond(noContentResponse("Vote saved"), 400, 401, 404, 409, 500),
+	},
+	{
+		method: http.MethodPost, path: "/reviews/{id}/report", tag: "reviews",
+		summary: "Report a review to the moderators", auth: apiBearer,
+		request:   jsonBody(reportReviewRequest{}),
+		responses: respond(noContentResponse("Review reported"), 400, 401, 404, 409, 422, 500),
+	},
+	{
+		method: http.MethodGet, path: "/books/{id}/annotations", tag: "annotations",
+		summary: "List the account's annotations on a book", auth: apiBearer,
+		query: []apiParam{
+			{name: "limit", schema: map[string]any{"type": "integer", "minimum": 1, "maximum": 100, "default": 50}},
+			queryParam("cursor", "next_cursor of the previous page."),
+		},
+		responses: respond(okResponse("Annotations", jsonBody(AnnotationsResponse{})), 400, 401, 500),
+	},
+	{
+		method: http.MethodPost, path: "/books/{id}/annotations", tag: "annotations",
+		summary: "Annotate a book", auth: apiBearer,
+		request:   jsonBody(createAnnotationRequest{}),
+		responses: respond(createdResponse("The new annotation", jsonBody(AnnotationResponse{})), 400, 401, 404, 422, 500),
+	},
+	{
+		method: http.MethodGet, path: "/books/{id}/annotations/export", tag: "annotations",
+		summary: "Download the account's annotations on a book", auth: apiBearer,
+		query:     []apiParam{enumParam("format", "Markdown by default.", "markdown", "json")},
+		responses: respond(okResponse("The annotations as a file, Markdown unless format=json", jsonBody(annotationExport{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/annotations/{id}", tag: "annotations",
+		summary: "Get an annotation", auth: apiBearer,
+		responses: respond(okResponse("The annotation", jsonBody(AnnotationResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodPatch, path: "/annotations/{id}", tag: "annotations",
+		summary: "Edit an annotation", auth: apiBearer,
+		request:   jsonBody(updateAnnotationRequest{}),
+		responses: respond(okResponse("The edited annotation", jsonBody(AnnotationResponse{})), 400, 401, 404, 422, 500),
+	},
+	{
+		method: http.MethodDelete, path: "/annotations/{id}", tag: "annotations",
+		summary: "Delete an annotation", auth: apiBearer,
+		responses: respond(noContentResponse("Annotation deleted"), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/me/language", tag: "account",
+		summary: "Get the language the account's messages are sent in", auth: apiBearer,
+		responses: respond(okResponse("The language, empty when it goes by the client's", jsonBody(LanguageResponse{})), 401, 404, 500),
+	},
+	{
+		method: http.MethodPut, path: "/me/language", tag: "account",
+		summary: "Set the language the account's messages are sent in", auth: apiBearer,
+		request:   jsonBody(setAccountLanguageRequest{}),
+		responses: respond(okResponse("The language saved", jsonBody(LanguageResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/me/recommendations", tag: "books",
+		summary: "List books recommended to the account", auth: apiBearer,
+		query:     []apiParam{pageParam},
+		responses: respond(okResponse("Recommended books", jsonBody(BooksResponse{})), 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/me/reading", tag: "reading",
+		summary: "List the books the account is reading and has finished", auth: apiBearer,
+		responses: respond(okResponse("Reading progress", jsonBody(ReadingResponse{})), 401, 500),
+	},
+	{
+		method: http.MethodPut, path: "/me/reading/{bookId}", tag: "reading",
+		summary: "Save reading progress from a device", auth: apiBearer,
+		request: jsonBody(readingProgressRequest{}),
+		responses: append(
+			respond(okResponse("The saved progress", jsonBody(ReadingProgressResponse{})), 400, 401, 404, 422, 500),
+			apiResponse{http.StatusConflict, "Newer progress was already saved, it's returned instead", jsonBody(ReadingProgressResponse{})},
+		),
+	},
+	{
+		method: http.MethodGet, path: "/me/shelves", tag: "shelves",
+		summary: "List the account's shelves", auth: apiBearer,
+		responses: respond(okResponse("Shelves", jsonBody(ShelvesResponse{})), 401, 500),
+	},
+	{
+		method: http.MethodPost, path: "/me/shelves", tag: "shelves",
+		summary: "Make a custom shelf", auth: apiBearer,
+		request:   jsonBody(createShelfRequest{}),
+		responses: respond(createdResponse("The new shelf", jsonBody(ShelfResponse{})), 400, 401, 409, 422, 500),
+	},
+	{
+		method: http.MethodGet, path: "/shelves/{id}", tag: "shelves",
+		summary: "Get a shelf with its books", auth: apiBearer,
+		responses: respond(okResponse("The shelf", jsonBody(ShelfBooksResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodPatch, path: "/shelves/{id}", tag: "shelves",
+		summary: "Rename, describe, move or share a shelf", auth: apiBearer,
+		request:   jsonBody(updateShelfRequest{}),
+		responses: respond(okResponse("The edited shelf", jsonBody(ShelfResponse{})), 400, 401, 404, 409, 422, 500),
+	},
+	{
+		method: http.MethodDelete, path: "/shelves/{id}", tag: "shelves",
+		summary: "Delete a custom shelf", auth: apiBearer,
+		responses: respond(noContentResponse("Shelf deleted"), 400, 401, 404, 409, 500),
+	},
+	{
+		method: http.MethodPut, path: "/shelves/{id}/books", tag: "shelves",
+		summary: "Reorder a shelf's books", auth: apiBearer,
+		request:   jsonBody(reorderShelfBooksRequest{}),
+		responses: respond(noContentResponse("Books reordered"), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodPut, path: "/shelves/{id}/books/{bookId}", tag: "shelves",
+		summary: "Put a book on a shelf", auth: apiBearer,
+		responses: respond(noContentResponse("Book shelved"), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodDelete, path: "/shelves/{id}/books/{bookId}", tag: "shelves",
+		summary: "Take a book off a shelf", auth: apiBearer,
+		responses: respond(noContentResponse("Book taken off"), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/shared/shelves/{id}", tag: "shelves",
+		summary:   "Get a public shelf with its books",
+		responses: respond(okResponse("The shelf", jsonBody(ShelfBooksResponse{})), 400, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/authors", tag: "authors",
+		summary: "List or search authors", auth: apiBearer,
+		query:     []apiParam{queryParam("query", "Part of the author's name."), pageParam},
+		responses: respond(okResponse("Authors", jsonBody(AuthorsResponse{})), 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/authors/{id}", tag: "authors",
+		summary: "Get an author with their books", auth: apiBearer,
+		responses: respond(okResponse("The author", jsonBody(AuthorResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/genres", tag: "genres",
+		summary: "List the genre tree", auth: apiBearer,
+		responses: respond(okResponse("Genres", jsonBody(GenresResponse{})), 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/genres/carousels", tag: "genres",
+		summary: "List a carousel of books for each top genre", auth: apiBearer,
+		responses: respond(okResponse("Carousels", jsonBody(GenreCarouselsResponse{})), 401, 500),
+	},
+	{
+		method: http.MethodGet, path: "/genres/{id}/books", tag: "genres",
+		summary: "List a genre's books", auth: apiBearer,
+		query:     []apiParam{pageParam},
+		responses: respond(okResponse("Books", jsonBody(BooksResponse{})), 400, 401, 404, 500),
+	},
+	{
+		method: http.MethodPut, path: "/books/{id}/cover", tag: "admin",
+		summary: "Upload a book's cover", auth: apiAdmin,
+		request:   rawBody("image/*"),
+		responses: respond(okResponse("The cover's sizes", jsonBody(bookCoverResponse{})), 400, 401, 403, 404, 413, 415, 500),
+	},
+	{
+		method: http.MethodPut, path: "/books/{id}/classification", tag: "admin",
+		summary: "Set a book's genres and tags", auth: apiAdmin,
+		request:   jsonBody(bookClassificationRequest{}),
+		responses: respond(noContentResponse("Classification saved"), 400, 401, 403, 404, 422, 500),
+	},
+	{
+		method: http.MethodPost, path: "/admin/genres", tag: "admin",
+		summary: "Add a genre", auth: apiAdmin,
+		request:   jsonBody(createGenreRequest{}),
+		responses: respond(createdResponse("The new genre", jsonBody(GenreResponse{})), 400, 401, 403, 409, 422, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/reviews", tag: "admin",
+		summary: "List reviews waiting for moderation", auth: apiAdmin,
+		query:     []apiParam{pageParam},
+		responses: respond(okResponse("Reviews with their flag and report reasons", jsonBody(ReviewsResponse{})), 401, 403, 500),
+	},
+	{
+		method: http.MethodPost, path: "/admin/reviews/{id}/moderation", tag: "admin",
+		summary: "Approve, reject or hide a review", auth: apiAdmin,
+		request:   jsonBody(moderateReviewRequest{}),
+		responses: respond(okResponse("The moderated review", jsonBody(ReviewResponse{})), 400, 401, 403, 404, 422, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/audit", tag: "admin",
+		summary: "List the audit log, or download it as CSV", auth: apiAdmin,
+		query: []apiParam{
+			queryParam("actor", "Email of the account that acted."),
+			queryParam("action", "Like login.failed or catalog.genre_created."),
+			{name: "from", description: "Earliest time, inclusive.", schema: map[string]any{"type": "string", "format": "date-time"}},
+			{name: "until", description: "Latest time, exclusive.", schema: map[string]any{"type": "string", "format": "date-time"}},
+			enumParam("format", "csv downloads every matching event, the oldest first.", "json", "csv"),
+			pageParam,
+		},
+		responses: respond(okResponse("Audit events, the newest first", jsonBody(AuditEventsResponse{})), 400, 401, 403, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/audit/verify", tag: "admin",
+		summary: "Check the hash chain of the audit log for tampering", auth: apiAdmin,
+		responses: respond(okResponse("Whether the chain is intact, and where it breaks", jsonBody(AuditVerificationResponse{})), 401, 403, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/users", tag: "admin",
+		summary: "Search accounts by email or name", auth: apiSupport,
+		query:     []apiParam{queryParam("query", "Part of the email or name; every account when empty."), pageParam},
+		responses: respond(okResponse("Accounts, by email", jsonBody(AccountsResponse{})), 401, 403, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/users/{email}", tag: "admin",
+		summary: "Get an account", auth: apiSupport,
+		responses: respond(okResponse("The account", jsonBody(AccountResponse{})), 401, 403, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/users/{email}/sessions", tag: "admin",
+		summary: "List the devices an account is signed in on", auth: apiSupport,
+		responses: respond(okResponse("Sessions, the last refreshed first", jsonBody(AccountSessionsResponse{})), 401, 403, 404, 500),
+	},
+	{
+		method: http.MethodDelete, path: "/admin/users/{email}/sessions", tag: "admin",
+		summary: "Sign an account out of every device", auth: apiSupport,
+		responses: respond(okResponse("How many refresh tokens were revoked", jsonBody(revokedSessionsResponse{})), 401, 403, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/users/{email}/loans", tag: "admin",
+		summary: "List the books an account has read", auth: apiSupport,
+		query:     []apiParam{pageParam},
+		responses: respond(okResponse("Books, the last read first", jsonBody(AccountLoansResponse{})), 401, 403, 500),
+	},
+	{
+		method: http.MethodPost, path: "/admin/users/{email}/activation", tag: "admin",
+		summary: "Activate an account", auth: apiSupport,
+		responses: respond(noContentResponse("Account activated"), 401, 403, 404, 409, 500),
+	},
+	{
+		method: http.MethodPost, path: "/admin/users/{email}/activation-email", tag: "admin",
+		summary: "Resend an account's activation email", auth: apiSupport,
+		responses: respond(okResponse("Email sent", jsonBody(resendResponse{})), 401, 403, 404, 409, 500),
+	},
+	{
+		method: http.MethodPut, path: "/admin/users/{email}/status", tag: "admin",
+		summary: "Disable, ban or reactivate an account", auth: apiAdmin,
+		request:   jsonBody(accountStatusRequest{}),
+		responses: respond(okResponse("The account", jsonBody(AccountResponse{})), 400, 401, 403, 404, 409, 422, 500),
+	},
+	{
+		method: http.MethodPost, path: "/admin/users/{email}/impersonation", tag: "admin",
+		summary: "Get a read-only session token of an account", auth: apiAdmin,
+		request:   jsonBody(impersonateRequest{}),
+		responses: respond(createdResponse("The impersonation token", jsonBody(impersonationResponse{})), 400, 401, 403, 404, 500),
+	},
+	{
+		method: http.MethodPost, path: "/admin/catalog/import", tag: "admin",
+		summary: "Import a CSV or ONIX catalog feed", auth: apiAdmin,
+		query: []apiParam{
+			enumParam("format", "", "csv", "onix"),
+			{name: "dry_run", description: "Only validate the feed.", schema: map[string]any{"type": "boolean"}},
+		},
+		request:   rawBody("text/csv"),
+		responses: respond(apiResponse{http.StatusAccepted, "The import job, running in the background", jsonBody(importJobResponse{})}, 400, 401, 403, 413, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/catalog/import/{id}", tag: "admin",
+		summary: "Get an import job", auth: apiAdmin,
+		responses: respond(okResponse("The import job", jsonBody(importJobResponse{})), 401, 403, 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/admin/catalog/export", tag: "admin",
+		summary: "Download the catalog", auth: apiAdmin,
+		query:     []apiParam{enumParam("format", "", "csv", "onix")},
+		responses: respond(okResponse("The catalog as a file", rawBody("text/csv")), 400, 401, 403),
+	},
+	{
+		method: http.MethodGet, path: "/covers/{id}/{size}", tag: "books",
+		summary:   "Get a cover, as WebP when accepted and JPEG otherwise",
+		responses: respond(okResponse("The cover", rawBody("image/jpeg")), 404, 500),
+	},
+	{
+		method: http.MethodGet, path: "/opds/auth", tag: "opds",
+		summary:   "Get the OPDS authentication document",
+		responses: []apiResponse{okResponse("How e-readers sign in", &apiBody{opdsAuthDocumentType, opdsAuthDocument{}})},
+	},
+	{
+		method: http.MethodGet, path: "/opds/search.xml", tag: "opds",
+		summary:   "Get the OpenSearch description of the catalog",
+		responses: []apiResponse{okResponse("OpenSearch description", rawBody(openSearchType))},
+	},
+	{
+		method: http.MethodGet, path: "/opds", tag: "opds",
+		summary: "Start of the OPDS 1.2 catalog", auth: apiBasic,
+		responses: opdsResponses(opdsNavigationType),
+	},
+	{
+		method: http.MethodGet, path: "/opds/new", tag: "opds",
+		summary: "OPDS 1.2 feed of new arrivals", auth: apiBasic,
+		query:     []apiParam{pageParam},
+		responses: opdsResponses(opdsAcquisitionType, 400),
+	},
+	{
+		method: http.MethodGet, path: "/opds/popular", tag: "opds",
+		summary: "OPDS 1.2 feed of popular books", auth: apiBasic,
+		query:     []apiParam{enumParam("window", "30d by default.", "7d", "30d", "all"), pageParam},
+		responses: opdsResponses(opdsAcquisitionType, 400),
+	},
+	{
+		method: http.MethodGet, path: "/opds/search", tag: "opds",
+		summary: "OPDS 1.2 feed of searched books", auth: apiBasic,
+		query:     []apiParam{{name: "query", schema: map[string]any{"type": "string"}, required: true}, pageParam},
+		responses: opdsResponses(opdsAcquisitionType, 400),
+	},
+	{
+		method: http.MethodGet, path: "/opds/shelves", tag: "opds",
+		summary: "OPDS 1.2 feed of the account's shelves", auth: apiBasic,
+		responses: opdsResponses(opdsNavigationType),
+	},
+	{
+		method: http.MethodGet, path: "/opds/shelves/{id}", tag: "opds",
+		summary: "OPDS 1.2 feed of a shelf's books", auth: apiBasic,
+		responses: opdsResponses(opdsAcquisitionType, 400, 404),
+	},
+	{
+		method: http.MethodGet, path: "/opds/v2", tag: "opds",
+		summary: "Start of the OPDS 2.0 catalog", auth: apiBasic,
+		responses: opdsResponses(opdsJSONType),
+	},
+	{
+		method: http.MethodGet, path: "/opds/v2/new", tag: "opds",
+		summary: "OPDS 2.0 feed of new arrivals", auth: apiBasic,
+		query:     []apiParam{pageParam},
+		responses: opdsResponses(opdsJSONType, 400),
+	},
+	{
+		method: http.MethodGet, path: "/opds/v2/popular", tag: "opds",
+		summary: "OPDS 2.0 feed of popular books", auth: apiBasic,
+		query:     []apiParam{enumParam("window", "30d by default.", "7d", "30d", "all"), pageParam},
+		responses: opdsResponses(opdsJSONType, 400),
+	},
+	{
+		method: http.MethodGet, path: "/opds/v2/search", tag: "opds",
+		summary: "OPDS 2.0 feed of searched books", auth: apiBasic,
+		query:     []apiParam{{name: "query", schema: map[string]any{"type": "string"}, required: true}, pageParam},
+		responses: opdsResponses(opdsJSONType, 400),
+	},
+	{
+		method: http.MethodGet, path: "/opds/v2/shelves", tag: "opds",
+		summary: "OPDS 2.0 feed of the account's shelves", auth: apiBasic,
+		responses: opdsResponses(opdsJSONType),
+	},
+	{
+		method: http.MethodGet, path: "/opds/v2/shelves/{id}", tag: "opds",
+		summary: "OPDS 2.0 feed of a shelf's books", auth: apiBasic,
+		responses: opdsResponses(opdsJSONType, 400, 404),
+	},
+	{
+		method: http.MethodGet, path: "/openapi.json", tag: "docs",
+		summary:   "Get this document",
+		responses: []apiResponse{okResponse("The OpenAPI document", rawBody("application/json"))},
+	},
+	{
+		method: http.MethodGet, path: "/docs", tag: "docs",
+		summary:   "Browse this document",
+		responses: []apiResponse{okResponse("The API reference page", rawBody("text/html"))},
+	},
+	{
+		method: http.MethodGet, path: "/healthz", tag: "health",
+		summary:   "Tell whether the process is alive",
+		responses: []apiResponse{okResponse("The process is serving", jsonBody(HealthResponse{}))},
+	},
+	{
+		method: http.MethodGet, path: "/readyz", tag: "health",
+		summary: "Tell whether the server can take requests, checking its dependencies",
+		responses: []apiResponse{
+			okResponse("Ready, possibly with failing optional checks", jsonBody(ReadinessResponse{})),
+			{http.StatusServiceUnavailable, "A check is failing or the server is shutting down", jsonBody(ReadinessResponse{})},
+		},
+	},
+}
+
+func opdsResponses(contentType string, statuses ...int) []apiResponse {
+	feed := rawBody(contentType)
+	if contentType == opdsJSONType {
+		feed = &apiBody{contentType, opdsJSONFeed{}}
+	}
+	return append(
+		respond(okResponse("The feed", feed), append(statuses, http.StatusInternalServerError)...),
+		apiResponse{http.StatusUnauthorized, "Credentials missing or wrong", &apiBody{opdsAuthDocumentType, opdsAuthDocument{}}},
+		apiResponse{http.StatusTooManyRequests, "Too many failed sign ins, retry after Retry-After seconds", nil},
+	)
+}
+
+var pathParamPattern = regexp.MustCompile(`{([^}]+)}`)
+
+// openAPIDocument builds the OpenAPI 3 document of operations.
+func openAPIDocument(operations []apiOperation) map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]any{}
+
+	for _, op := range operations {
+		operation := map[string]any{
+			"summary":     op.summary,
+			"tags":        []string{op.tag},
+			"operationId": operationID(op),
+		}
+
+		var params []any
+		for _, match := range pathParamPattern.FindAllStringSubmatch(op.path, -1) {
+			schema := map[string]any{"type": "string", "format": "uuid"}
+			switch match[1] {
+			case "size":
+				schema = map[string]any{"type": "string", "enum": coverhelper.CoverSizes()}
+			case "email":
+				schema = map[string]any{"type": "string", "format": "email"}
+			}
+			params = append(params, map[string]any{"name": match[1], "in": "path", "required": true, "schema": schema})
+		}
+		for _, q := range op.query {
+			param := map[string]any{"name": q.name, "in": "query", "required": q.required, "schema": q.schema}
+			if q.description != "" {
+				param["description"] = q.description
+			}
+			params = append(params, param)
+		}
+		if params != nil {
+			operation["parameters"] = params
+		}
+
+		switch op.auth {
+		case apiBearer:
+			operation["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+		case apiAdmin:
+			operation["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+			operation["description"] = "Only for admin accounts."
+		case apiSupport:
+			operation["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+			operation["description"] = "Only for support and admin accounts."
+		case apiBasic:
+			operation["security"] = []any{map[string]any{"basicAuth": []string{}}}
+		}
+
+		if op.request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  bodyContent(*op.request, schemas),
+			}
+		}
+
+		resps := map[string]any{}
+		for _, resp := range op.responses {
+			response := map[string]any{"description": resp.description}
+			if resp.body != nil {
+				response["content"] = bodyContent(*resp.body, schemas)
+			}
+			resps[strconv.Itoa(resp.status)] = response
+		}
+		operation["responses"] = resps
+
+		path, found := paths[op.path].(map[string]any)
+		if !found {
+			path = map[string]any{}
+			paths[op.path] = path
+		}
+		path[strings.ToLower(op.method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "E-Library API",
+			"version":     "1.0.0",
+			"description": "Generated from the endpoints package. Errors are sent as an ErrorResponse, or as RFC 7807 problem details to clients that accept application/problem+json.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"basicAuth":  map[string]any{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+}
+
+func operationID(op apiOperation) string {
+	id := strings.ToLower(op.method)
+	for _, part := range strings.Split(op.path, "/") {
+		part = strings.Trim(part, "{}")
+		part = strings.NewReplacer(".", "_", "_", "", "-", "").Replace(part)
+		if part == "" {
+			continue
+		}
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+func bodyContent(body apiBody, schemas map[string]any) map[string]any {
+	schema := map[string]any{"type": "string"}
+	if body.value != nil {
+		schema = schemaOf(reflect.TypeOf(body.value), schemas)
+	} else if !strings.HasPrefix(body.contentType, "text/") && body.contentType != "application/json" {
+		schema["format"] = "binary"
+	}
+	content := map[string]any{body.contentType: map[string]any{"schema": schema}}
+	if _, ok := body.value.(ErrorResponse); ok {
+		content[problemContentType] = map[string]any{"schema": schemaOf(reflect.TypeOf(problemDetails{}), schemas)}
+	}
+	return content
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf describes t the way encoding/json writes it. Named structs are
+// put in schemas and referred to.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case uuidType:
+		return map[string]any{"type": "string", "format": "uuid"}
+	case rawJSONType:
+		return map[string]any{"type": "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, found := schemas[t.Name()]; !found {
+			// Placeholder first, so a type referring to itself ends.
+			schemas[t.Name()] = map[string]any{}
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" {
+				addFields(field.Type)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaOf(field.Type, schemas)
+			if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// OpenAPIDocument serves the OpenAPI 3 document of the API, built from the
+// request and response types.
+func OpenAPIDocument() http.HandlerFunc {
+	body, err := json.MarshalIndent(openAPIDocument(apiOperations), "", "  ")
+	if err != nil {
+		log.Panic().Err(err).Msg("Encoding the OpenAPI document failed")
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}
+}
+
+const apiReferencePage = `<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>E-Library API</title>
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+	<redoc spec-url="/openapi.json"></redoc>
+	<script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// OpenAPIReference is a Redoc page for browsing /openapi.json.
+func OpenAPIReference() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(apiReferencePage))
+	}
+}
@@ -0,0 +1,81 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+type ReadingProgressResponse struct {
+	BookID     string  `json:"book_id"`
+	CFI        string  `json:"cfi,omitempty"`
+	Page       int     `json:"page,omitempty"`
+	Percentage float32 `json:"percentage"`
+	DeviceID   string  `json:"device_id"`
+	UpdatedAt  string  `json:"updated_at"`
+	ReceivedAt string  `json:"received_at"`
+	FinishedAt string  `json:"finished_at,omitempty"`
+
+	httpStatus int
+}
+
+func (p *ReadingProgressResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	status := p.httpStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	render.Status(r, status)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func ReadingProgressFromDatabase(dProgress database.ReadingProgress) ReadingProgressResponse {
+	var p ReadingProgressResponse
+
+	p.BookID = dProgress.BookID.String()
+	p.CFI = dProgress.CFI
+	p.Page = dProgress.Page
+	p.Percentage = dProgress.Percentage
+	p.DeviceID = dProgress.DeviceID
+	p.UpdatedAt = dProgress.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	p.ReceivedAt = dProgress.ReceivedAt.UTC().Format(time.RFC3339Nano)
+	if dProgress.FinishedAt != nil {
+		p.FinishedAt = dProgress.FinishedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	return p
+}
+
+type readingBookResponse struct {
+	Book     BookResponse            `json:"book"`
+	Progress ReadingProgressResponse `json:"progress"`
+}
+
+type ReadingResponse struct {
+	Data          []readingBookResponse `json:"data"`
+	FinishedCount int                   `json:"finished_count"`
+}
+
+func (re *ReadingResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func ReadingFromDatabase(dBooks []database.ReadingBook, finishedCount int) ReadingResponse {
+	re := ReadingResponse{
+		Data:          []readingBookResponse{},
+		FinishedCount: finishedCount,
+	}
+
+	for _, dBook := range dBooks {
+		re.Data = append(re.Data, readingBookResponse{
+			Book:     BookFromDatabase(dBook.Book),
+			Progress: ReadingProgressFromDatabase(dBook.Progress),
+		})
+	}
+
+	return re
+}
@@ -3,6 +3,7 @@ package endpoints
 import (
 	"errors"
 	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/metricshelper"
 	"ic-rhadi/e_library/sessiontoken"
 	"net/http"
 	"time"
@@ -29,8 +30,15 @@ var errRefreshTokenMalformed = errors.New("token missing from request body")
 var errRefreshTokenInvalid = errors.New("invalid refresh token")
 var errRefreshTokenExpired = errors.New("refresh token expired")
 
+// sessionAudit is the payload of refresh token audit events, naming the
+// refresh token that was reused.
+type sessionAudit struct {
+	Session string `json:"session"`
+}
+
 func RefreshToken(
 	db database.UserSessionInterface,
+	audit database.AuditInterface,
 	sessionAuth *jwtauth.JWTAuth,
 	sessionLength time.Duration,
 	tokenLength time.Duration,
@@ -40,21 +48,21 @@ func RefreshToken(
 
 		data := &refreshTokenRequest{}
 		if err := render.Bind(r, data); err != nil {
-			log.Debug().Err(err).Msg("Refresh token endpoint called with insufficient body")
+			log.Ctx(ctx).Debug().Err(err).Msg("Refresh token endpoint called with insufficient body")
 			render.Render(w, r, BadRequestError(errRefreshTokenMalformed))
 			return
 		}
 
 		currToken, err := sessionAuth.Decode(data.RefreshToken)
 		if err != nil {
-			log.Debug().Err(err).Msg("Trying to decode refresh token returned an error")
+			log.Ctx(ctx).Debug().Err(err).Msg("Trying to decode refresh token returned an error")
 			render.Render(w, r, UnauthorizedRequestError(errRefreshTokenInvalid))
 			return
 		}
 
 		var claims sessiontoken.RefreshClaimsSchema
 		if err := claims.FromToken(ctx, currToken); err != nil {
-			log.Debug().Err(err).Msg("Trying to get refresh token's claims returned an error")
+			log.Ctx(ctx).Debug().Err(err).Msg("Trying to get refresh token's claims returned an error")
 			render.Render(w, r, UnauthorizedRequestError(errRefreshTokenInvalid))
 			return
 		}
@@ -63,39 +71,42 @@ func RefreshToken(
 		tokenFamily, exhausted, expiresIn, err := db.GetSession(ctx, claims.Email, claims.Session, currTime)
 		if err != nil {
 			if err == database.ErrSessionNotFound {
-				log.Debug().Msg("")
+				log.Ctx(ctx).Debug().Msg("")
 				render.Render(w, r, UnauthorizedRequestError(errRefreshTokenInvalid))
 				return
 			}
-			log.Error().Err(err).Msg("Database error while trying to get ")
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while trying to get ")
 			render.Render(w, r, InternalServerError())
 			return
 		}
 
 		if expiresIn.Before(currTime) {
-			log.Debug().Msg("Refresh token has expired")
+			log.Ctx(ctx).Debug().Msg("Refresh token has expired")
 			render.Render(w, r, UnauthorizedRequestError(errRefreshTokenExpired))
 			return
 		}
 		if exhausted {
+			recordAudit(r, audit, database.AuditRefreshTokenReused, claims.Email, sessionAudit{claims.Session})
 			if err := db.InvaildateSession(ctx, claims.Email, claims.Session); err != nil {
-				log.Error().Err(err).Msg("Database error while invalidating session tokens")
+				log.Ctx(ctx).Error().Err(err).Msg("Database error while invalidating session tokens")
 				render.Render(w, r, InternalServerError())
 				return
 			}
-			log.Debug().Str("account", claims.Email).Msg("Double refresh of the same token. Token family invalidated")
+			log.Ctx(ctx).Debug().Str("account", claims.Email).Msg("Double refresh of the same token. Token family invalidated")
+			metricshelper.RefreshTokenReuses.Inc()
+			recordAudit(r, audit, database.AuditSessionFamilyRevoked, claims.Email, sessionAudit{claims.Session})
 			render.Render(w, r, UnauthorizedRequestError(errRefreshTokenExpired))
 			return
 		}
 
 		newSession, err := uuid.NewRandom()
 		if err != nil {
-			log.Error().Err(err).Msg("Unexpected error while creating new random UUID for token")
+			log.Ctx(ctx).Error().Err(err).Msg("Unexpected error while creating new random UUID for token")
 			render.Render(w, r, InternalServerError())
 			return
 		}
 		if err := db.AddNewSession(ctx, claims.Email, newSession.String(), tokenFamily, currTime.Add(sessionLength)); err != nil {
-			log.Error().Err(err).Msg("Database error while adding a new refresh token")
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while adding a new refresh token")
 			render.Render(w, r, InternalServerError())
 			return
 		}
@@ -108,7 +119,7 @@ func RefreshToken(
 			tokenLength,
 		)
 		if err != nil {
-			log.Error().Err(err).Msg("Error encoding new session token")
+			log.Ctx(ctx).Error().Err(err).Msg("Error encoding new session token")
 			render.Render(w, r, InternalServerError())
 			return
 		}
@@ -122,7 +133,7 @@ func RefreshToken(
 			sessionLength,
 		)
 		if err != nil {
-			log.Error().Err(err).Msg("Error encoding new refresh token")
+			log.Ctx(ctx).Error().Err(err).Msg("Error encoding new refresh token")
 			render.Render(w, r, InternalServerError())
 			return
 		}
@@ -29,6 +29,7 @@ var errGoogleTokenFailed = errors.New("google token validation failed")
 
 func RegisterGoogle(
 	db database.UserAccountInterface,
+	audit database.AuditInterface,
 	gValidator googlehelper.GTokenValidator,
 	email emailhelper.ActivationMailDriver,
 	activationDuration time.Duration,
@@ -38,31 +39,33 @@ func RegisterGoogle(
 
 		data := &registerGoogleRequest{}
 		if err := render.Bind(r, data); err != nil {
-			log.Debug().Err(err).Msg("Registering attempt failed")
+			log.Ctx(ctx).Debug().Err(err).Msg("Registering attempt failed")
 			render.Render(w, r, BadRequestError(err))
 			return
 		}
 
 		gClaims, err := gValidator.ValidateGToken(ctx, data.GoogleToken)
 		if err != nil {
-			log.Debug().Err(err).Str("token", data.GoogleToken).Msg("Google token validation failed")
+			log.Ctx(ctx).Debug().Err(err).Str("token", data.GoogleToken).Msg("Google token validation failed")
 			render.Render(w, r, ValidationFailedError(errGoogleTokenFailed))
 			return
 		}
 
 		activationToken, validUntil, err := db.RegisterGoogle(ctx, gClaims.Email, gClaims.AccountID, gClaims.FullName, activationDuration)
 		if err != nil {
-			if err == database.ErrAccountExisted {
-				render.Render(w, r, RequestConflictError(errAccountAlreadyRegistered))
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
 				return
 			}
-			log.Debug().Err(err).Str("email", gClaims.Email).Msg("Registering failed")
+			log.Ctx(ctx).Debug().Err(err).Str("email", gClaims.Email).Msg("Registering failed")
 			render.Render(w, r, InternalServerError())
 			return
 		}
 
-		if err := email.SendActivationEmail(gClaims.Email, activationToken, *validUntil); err != nil {
-			log.Error().Err(err).
+		recordAudit(r, audit, database.AuditAccountRegistered, gClaims.Email, registerAudit{Method: loginMethodGoogle})
+
+		if err := email.SendActivationEmail(ctx, gClaims.Email, activationToken, *validUntil); err != nil {
+			log.Ctx(ctx).Error().Err(err).
 				Str("email", gClaims.Email).
 				Str("Activation Token", activationToken).
 				Msg("Sending account activation email failed")
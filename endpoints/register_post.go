@@ -29,31 +29,33 @@ func (l *registerPostRequest) Bind(r *http.Request) error {
 		return errRegisterPostMalformed
 	}
 
+	// Every broken rule is reported at once, so the form can flag them all.
+	errs := fieldErrors{}
 	if len(l.Email) > 254 || !emailRegex.MatchString(l.Email) {
-		return errEmailMalformed
+		errs.add("email", errEmailMalformed)
 	}
 
 	pLen := utf8.RuneCountInString(l.Password)
 	if pLen < 8 {
-		return errPasswordTooShort
+		errs.add("password", errPasswordTooShort)
 	}
 	if pLen > 40 {
-		return errPasswordTooLong
+		errs.add("password", errPasswordTooLong)
 	}
 
 	if !passwordNumRegex.MatchString(l.Password) {
-		return errPasswordDontHaveNumber
+		errs.add("password", errPasswordDontHaveNumber)
 	}
 
 	if !passwordUpperRegex.MatchString(l.Password) {
-		return errPasswordDontHaveUppercase
+		errs.add("password", errPasswordDontHaveUppercase)
 	}
 
 	if !passwordSpecialRegex.MatchString(l.Password) {
-		return errPasswordDontHaveSpecials
+		errs.add("password", errPasswordDontHaveSpecials)
 	}
 
-	return nil
+	return errs.orNil()
 }
 
 type registerResponse struct {
@@ -75,8 +77,14 @@ var errPasswordDontHaveUppercase = errors.New("password don't have uppercase eng
 var errPasswordDontHaveSpecials = errors.New("password don't have special characters")
 var errAccountAlreadyRegistered = errors.New("this account is already registered")
 
+// registerAudit is the payload of registration audit events.
+type registerAudit struct {
+	Method string `json:"method"`
+}
+
 func RegisterPost(
 	db database.UserAccountInterface,
+	audit database.AuditInterface,
 	email emailhelper.ActivationMailDriver,
 	activationDuration time.Duration,
 ) http.HandlerFunc {
@@ -85,24 +93,26 @@ func RegisterPost(
 
 		data := &registerPostRequest{}
 		if err := render.Bind(r, data); err != nil {
-			log.Debug().Err(err).Str("email", data.Email).Str("name", data.Name).Msg("Registering attempt failed")
+			log.Ctx(ctx).Debug().Err(err).Str("email", data.Email).Str("name", data.Name).Msg("Registering attempt failed")
 			render.Render(w, r, BadRequestError(err))
 			return
 		}
 
 		activationToken, validUntil, err := db.Register(ctx, data.Email, data.Password, data.Name, activationDuration)
 		if err != nil {
-			if err == database.ErrAccountExisted {
-				render.Render(w, r, RequestConflictError(errAccountAlreadyRegistered))
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
 				return
 			}
-			log.Debug().Err(err).Str("email", data.Email).Msg("Registering failed")
+			log.Ctx(ctx).Debug().Err(err).Str("email", data.Email).Msg("Registering failed")
 			render.Render(w, r, InternalServerError())
 			return
 		}
 
-		if err := email.SendActivationEmail(data.Email, activationToken, *validUntil); err != nil {
-			log.Error().Err(err).
+		recordAudit(r, audit, database.AuditAccountRegistered, data.Email, registerAudit{Method: loginMethodPassword})
+
+		if err := email.SendActivationEmail(ctx, data.Email, activationToken, *validUntil); err != nil {
+			log.Ctx(ctx).Error().Err(err).
 				Str("email", data.Email).
 				Str("Activation Token", activationToken).
 				Msg("Sending account activation email failed")
@@ -33,7 +33,7 @@ func TestSuccessfulRegister(t *testing.T) {
 	expCode := http.StatusCreated
 
 	w, r := mockRequest(t, path, reg, false)
-	handler := RegisterPost(dbMock, mailMock, expDur)
+	handler := RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp := &registerResponse{}
@@ -58,10 +58,10 @@ func TestMalformedRegistered(t *testing.T) {
 
 	mailMock := activationMailDriverMock{&mock.Mock{}}
 
-	expResp, expCode := BadRequestError(errEmailMalformed).(*ErrorResponse).sentForm()
+	expResp, expCode := BadRequestError(fieldErrors{"email": {errEmailMalformed}}).(*ErrorResponse).sentForm()
 
 	w, r := mockRequest(t, path, reg, false)
-	handler := RegisterPost(dbMock, mailMock, expDur)
+	handler := RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
@@ -75,10 +75,10 @@ func TestMalformedRegistered(t *testing.T) {
 		Name:     "Joko",
 	}
 
-	expResp, expCode = BadRequestError(errPasswordDontHaveNumber).(*ErrorResponse).sentForm()
+	expResp, expCode = BadRequestError(fieldErrors{"password": {errPasswordDontHaveNumber}}).(*ErrorResponse).sentForm()
 
 	w, r = mockRequest(t, path, reg, false)
-	handler = RegisterPost(dbMock, mailMock, expDur)
+	handler = RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -92,10 +92,10 @@ func TestMalformedRegistered(t *testing.T) {
 		Name:     "Joko",
 	}
 
-	expResp, expCode = BadRequestError(errPasswordDontHaveUppercase).(*ErrorResponse).sentForm()
+	expResp, expCode = BadRequestError(fieldErrors{"password": {errPasswordDontHaveUppercase}}).(*ErrorResponse).sentForm()
 
 	w, r = mockRequest(t, path, reg, false)
-	handler = RegisterPost(dbMock, mailMock, expDur)
+	handler = RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -109,10 +109,10 @@ func TestMalformedRegistered(t *testing.T) {
 		Name:     "Joko",
 	}
 
-	expResp, expCode = BadRequestError(errPasswordDontHaveSpecials).(*ErrorResponse).sentForm()
+	expResp, expCode = BadRequestError(fieldErrors{"password": {errPasswordDontHaveSpecials}}).(*ErrorResponse).sentForm()
 
 	w, r = mockRequest(t, path, reg, false)
-	handler = RegisterPost(dbMock, mailMock, expDur)
+	handler = RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -126,10 +126,10 @@ func TestMalformedRegistered(t *testing.T) {
 		Name:     "Joko",
 	}
 
-	expResp, expCode = BadRequestError(errPasswordTooShort).(*ErrorResponse).sentForm()
+	expResp, expCode = BadRequestError(fieldErrors{"password": {errPasswordTooShort, errPasswordDontHaveSpecials}}).(*ErrorResponse).sentForm()
 
 	w, r = mockRequest(t, path, reg, false)
-	handler = RegisterPost(dbMock, mailMock, expDur)
+	handler = RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -143,10 +143,10 @@ func TestMalformedRegistered(t *testing.T) {
 		Name:     "Joko",
 	}
 
-	expResp, expCode = BadRequestError(errPasswordTooLong).(*ErrorResponse).sentForm()
+	expResp, expCode = BadRequestError(fieldErrors{"password": {errPasswordTooLong}}).(*ErrorResponse).sentForm()
 
 	w, r = mockRequest(t, path, reg, false)
-	handler = RegisterPost(dbMock, mailMock, expDur)
+	handler = RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp = &ErrorResponse{}
@@ -155,6 +155,40 @@ func TestMalformedRegistered(t *testing.T) {
 	assert.Equal(t, expResp, *resp, "An already-registered Post-Register didn't return a valid response")
 }
 
+func TestRegisterFieldErrors(t *testing.T) {
+	reg := registerPostRequest{
+		Email:    "username",
+		Password: "pass",
+		Name:     "Joko",
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	mailMock := activationMailDriverMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, "/auth/register", reg, false)
+	handler := RegisterPost(dbMock, newAuditMock(), mailMock, time.Minute)
+	handler.ServeHTTP(w, r)
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A Post-Register breaking several rules didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A Post-Register breaking several rules didn't return a valid errorResponse object") {
+		assert.Equal(t, codeFieldsInvalid, resp.Code, "A Post-Register breaking several rules didn't return the proper code")
+		assert.Equal(t, map[string][]FieldErrorResponse{
+			"email": {
+				{Code: "email_malformed", Message: errEmailMalformed.Error()},
+			},
+			"password": {
+				{Code: "password_too_short", Message: errPasswordTooShort.Error()},
+				{Code: "password_missing_number", Message: errPasswordDontHaveNumber.Error()},
+				{Code: "password_missing_uppercase", Message: errPasswordDontHaveUppercase.Error()},
+				{Code: "password_missing_special", Message: errPasswordDontHaveSpecials.Error()},
+			},
+		}, resp.Fields, "A Post-Register breaking several rules didn't report every rule")
+	}
+	dbMock.AssertExpectations(t)
+	mailMock.AssertExpectations(t)
+}
+
 func TestAlreadyRegistered(t *testing.T) {
 	path := "/auth/register"
 
@@ -174,7 +208,7 @@ func TestAlreadyRegistered(t *testing.T) {
 	expResp, expCode := RequestConflictError(errAccountAlreadyRegistered).(*ErrorResponse).sentForm()
 
 	w, r := mockRequest(t, path, reg, false)
-	handler := RegisterPost(dbMock, mailMock, expDur)
+	handler := RegisterPost(dbMock, newAuditMock(), mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
 	resp := &ErrorResponse{}
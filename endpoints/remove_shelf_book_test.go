@@ -0,0 +1,58 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) RemoveShelfBook(ctx context.Context, shelfID uuid.UUID, bookID uuid.UUID, accountID string) error {
+	args := db.Called(shelfID, bookID, accountID)
+	return args.Error(0)
+}
+
+func TestSuccessfulRemoveShelfBook(t *testing.T) {
+	shelfID := uuid.New()
+	bookID := uuid.New()
+	path := "/shelves/" + shelfID.String() + "/books/" + bookID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("RemoveShelfBook", shelfID, bookID, expID.Account).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", shelfID.String()}, param{"bookId", bookID.String()})
+	handler := RemoveShelfBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful shelf removal didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundRemoveShelfBook(t *testing.T) {
+	shelfID := uuid.New()
+	bookID := uuid.New()
+	path := "/shelves/" + shelfID.String() + "/books/" + bookID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("RemoveShelfBook", shelfID, bookID, expID.Account).
+		Return(database.ErrShelfNotFound).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", shelfID.String()}, param{"bookId", bookID.String()})
+	handler := RemoveShelfBook(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errShelfNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing shelf removal didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing shelf removal didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing shelf removal didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,75 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type reorderShelfBooksRequest struct {
+	BookIDs []uuid.UUID `json:"book_ids"`
+}
+
+func (o *reorderShelfBooksRequest) Bind(r *http.Request) error {
+	if len(o.BookIDs) == 0 {
+		return errReorderShelfMalformed
+	}
+	if len(o.BookIDs) > maxShelfReorder {
+		return errReorderShelfTooLong
+	}
+	return nil
+}
+
+const maxShelfReorder = 1000
+
+var errReorderShelfMalformed = errors.New("book_ids missing")
+var errReorderShelfTooLong = errors.New("can't order more than 1000 books at once")
+
+// ReorderShelfBooks puts the books of one of the account's shelves in the
+// order given. Books left out keep their place.
+func ReorderShelfBooks(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ReorderShelfBooks: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelfID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Ordering a shelf with a malformed id")
+			render.Render(w, r, BadRequestError(errShelfIDMalformed))
+			return
+		}
+
+		data := &reorderShelfBooksRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Ordering shelf attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		if err := db.ReorderShelfBooks(ctx, shelfID, data.BookIDs, sch.Email); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while ordering a shelf")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
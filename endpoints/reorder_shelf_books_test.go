@@ -0,0 +1,54 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) ReorderShelfBooks(ctx context.Context, shelfID uuid.UUID, bookIDs []uuid.UUID, accountID string) error {
+	args := db.Called(shelfID, bookIDs, accountID)
+	return args.Error(0)
+}
+
+func TestSuccessfulReorderShelfBooks(t *testing.T) {
+	shelfID := uuid.New()
+	path := "/shelves/" + shelfID.String() + "/books"
+	req := reorderShelfBooksRequest{BookIDs: []uuid.UUID{uuid.New(), uuid.New()}}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ReorderShelfBooks", shelfID, req.BookIDs, expID.Account).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", shelfID.String()})
+	handler := ReorderShelfBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful shelf ordering didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestMalformedReorderShelfBooks(t *testing.T) {
+	shelfID := uuid.New()
+	path := "/shelves/" + shelfID.String() + "/books"
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, reorderShelfBooksRequest{}, true, param{"id", shelfID.String()})
+	handler := ReorderShelfBooks(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errReorderShelfMalformed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed shelf ordering didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed shelf ordering didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A malformed shelf ordering didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,68 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type reportReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (rr *reportReviewRequest) Bind(r *http.Request) error {
+	if rr.Reason == "" {
+		return errReportReviewMalformed
+	}
+	return nil
+}
+
+var errReportReviewMalformed = errors.New("reason missing")
+
+// ReportReview sends another account's review to the moderation queue.
+func ReportReview(
+	db database.ReviewInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("ReportReview: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		reviewID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Reporting a review with a malformed id")
+			render.Render(w, r, BadRequestError(errReviewIDMalformed))
+			return
+		}
+
+		data := &reportReviewRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Reporting review attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		if err := db.ReportReview(ctx, reviewID, sch.Email, data.Reason); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while reporting a review")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,58 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) ReportReview(ctx context.Context, reviewID uuid.UUID, accountID string, reason string) error {
+	args := db.Called(reviewID, accountID, reason)
+	return args.Error(0)
+}
+
+func TestSuccessfulReportReview(t *testing.T) {
+	reviewID := uuid.New()
+	path := "/reviews/" + reviewID.String() + "/report"
+	req := reportReviewRequest{Reason: "Spoils the ending"}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ReportReview", reviewID, expID.Account, req.Reason).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", reviewID.String()})
+	handler := ReportReview(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful review report didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotFoundReportReview(t *testing.T) {
+	reviewID := uuid.New()
+	path := "/reviews/" + reviewID.String() + "/report"
+	req := reportReviewRequest{Reason: "Spam"}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("ReportReview", reviewID, expID.Account, req.Reason).
+		Return(database.ErrReviewNotFound).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", reviewID.String()})
+	handler := ReportReview(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errReviewNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A missing review report didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A missing review report didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A missing review report didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
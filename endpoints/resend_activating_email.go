@@ -1,6 +1,7 @@
 package endpoints
 
 import (
+	"context"
 	"errors"
 	"ic-rhadi/e_library/database"
 	"ic-rhadi/e_library/emailhelper"
@@ -33,43 +34,51 @@ func ResendActivationEmail(
 		ctx := r.Context()
 		accountEmail := r.URL.Query().Get("email")
 		if accountEmail == "" {
-			log.Debug().Msg("Resend activation email endpoint called with insufficient queries")
+			log.Ctx(ctx).Debug().Msg("Resend activation email endpoint called with insufficient queries")
 			render.Render(w, r, BadRequestError(errResendActivationEmailMalformed))
 			return
 		}
 
 		activated, _, _, err := db.GetActivationData(ctx, accountEmail)
 		if err == database.ErrAccountNotFound {
-			log.Debug().Err(err).Msg("Resending activating email on an account that couldn't be found")
+			log.Ctx(ctx).Debug().Err(err).Msg("Resending activating email on an account that couldn't be found")
 			render.Render(w, r, UnauthorizedRequestError(errAccountNotFound))
 			return
 		}
 		if activated {
-			log.Debug().Err(err).Msg("Resending activating email on an account that's already activated")
+			log.Ctx(ctx).Debug().Err(err).Msg("Resending activating email on an account that's already activated")
 			render.Render(w, r, UnauthorizedRequestError(errAccountAlreadyActivated))
 			return
 		}
 
-		activationToken, err := uuid.NewRandom()
-		if err != nil {
-			log.Error().Err(err).Msg("Trying to create random uuid returned an error")
+		if err := resendActivation(ctx, db, email, accountEmail, activationDuration); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Trying to resend activating email failed")
 			render.Render(w, r, InternalServerError())
 			return
 		}
-		expiresIn := time.Now().Add(activationDuration)
 
-		if err := db.RefreshActivation(ctx, accountEmail, activationToken.String(), expiresIn); err != nil {
-			log.Error().Err(err).Msg("Database error while trying to refresh activation token")
-			render.Render(w, r, InternalServerError())
-			return
-		}
-		if err := email.SendActivationEmail(accountEmail, activationToken.String(), expiresIn); err != nil {
-			log.Debug().Err(err).Msg("Trying to resend activating email failed")
-			render.Render(w, r, InternalServerError())
-			return
-		}
-
-		resp := resendResponse{"resend succesful"}
+		resp := resendResponse{localize(r, "activation_email_resent")}
 		render.Render(w, r, &resp)
 	}
 }
+
+// resendActivation replaces the account's activation token with a new one,
+// valid for activationDuration, and mails it.
+func resendActivation(
+	ctx context.Context,
+	db database.UserAccountInterface,
+	email emailhelper.ActivationMailDriver,
+	accountEmail string,
+	activationDuration time.Duration,
+) error {
+	activationToken, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	expiresIn := time.Now().Add(activationDuration)
+
+	if err := db.RefreshActivation(ctx, accountEmail, activationToken.String(), expiresIn); err != nil {
+		return err
+	}
+	return email.SendActivationEmail(ctx, accountEmail, activationToken.String(), expiresIn)
+}
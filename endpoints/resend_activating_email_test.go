@@ -42,7 +42,7 @@ func TestSuccessfulResendActivationEmail(t *testing.T) {
 	handler := ResendActivationEmail(dbMock, mailMock, expDur)
 	handler.ServeHTTP(w, r)
 
-	expResp := resendResponse{"resend succesful"}
+	expResp := resendResponse{"resend successful"}
 	expCode := http.StatusOK
 
 	resp := &resendResponse{}
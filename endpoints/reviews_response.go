@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+type ReviewResponse struct {
+	ID         string `json:"id"`
+	BookID     string `json:"book_id"`
+	Rating     int    `json:"rating"`
+	Body       string `json:"body,omitempty"`
+	Status     string `json:"status"`
+	AuthorName string `json:"author_name"`
+	IsOwn      bool   `json:"is_own"`
+	Helpful    int    `json:"helpful_count"`
+	NotHelpful int    `json:"not_helpful_count"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+
+	// Only moderators see why a review is in the queue.
+	FlagReason    string   `json:"flag_reason,omitempty"`
+	ReportReasons []string `json:"report_reasons,omitempty"`
+}
+
+func (rv *ReviewResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+// ReviewFromDatabase builds the response for the account in accountID, which
+// decides is_own.
+func ReviewFromDatabase(dReview database.Review, accountID string) ReviewResponse {
+	var rv ReviewResponse
+
+	rv.ID = dReview.ID.String()
+	rv.BookID = dReview.BookID.String()
+	rv.Rating = dReview.Rating
+	rv.Body = dReview.Body
+	rv.Status = dReview.Status
+	rv.AuthorName = dReview.AuthorName
+	rv.IsOwn = dReview.AccountID == accountID
+	rv.Helpful = dReview.Helpful
+	rv.NotHelpful = dReview.NotHelpful
+	rv.CreatedAt = dReview.CreatedAt.UTC().Format(time.RFC3339)
+	rv.UpdatedAt = dReview.UpdatedAt.UTC().Format(time.RFC3339)
+
+	return rv
+}
+
+// ModerationReviewFromDatabase adds what the moderation queue needs to the
+// review.
+func ModerationReviewFromDatabase(dReview database.Review, accountID string) ReviewResponse {
+	rv := ReviewFromDatabase(dReview, accountID)
+	rv.FlagReason = dReview.FlagReason
+	rv.ReportReasons = dReview.ReportReasons
+	return rv
+}
+
+type ReviewsResponse struct {
+	Data []ReviewResponse `json:"data"`
+}
+
+func (rv *ReviewsResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func ReviewsFromDatabase(dReviews []database.Review, accountID string) ReviewsResponse {
+	rv := ReviewsResponse{Data: []ReviewResponse{}}
+
+	for _, dReview := range dReviews {
+		rv.Data = append(rv.Data, ReviewFromDatabase(dReview, accountID))
+	}
+
+	return rv
+}
+
+var errReviewIDMalformed = errors.New("review id malformed")
+var errReviewNotFound = errors.New("review not found")
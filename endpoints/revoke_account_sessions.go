@@ -0,0 +1,47 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+type revokedSessionsAudit struct {
+	Account string `json:"account"`
+	Revoked int64  `json:"revoked"`
+}
+
+// RevokeAccountSessions signs an account out of every device. Its refresh
+// tokens stop working at once, its session tokens when they expire.
+func RevokeAccountSessions(
+	db database.AdminUserInterface,
+	audit database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		accountEmail := chi.URLParam(r, "email")
+
+		if _, err := db.GetAccount(ctx, accountEmail); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while getting an account")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		revoked, err := db.RevokeAccountSessions(ctx, accountEmail)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while revoking an account's sessions")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		recordSessionAudit(r, audit, database.AuditSessionsRevoked, revokedSessionsAudit{accountEmail, revoked})
+
+		render.Render(w, r, &revokedSessionsResponse{Revoked: revoked})
+	}
+}
@@ -0,0 +1,56 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulRevokeAccountSessions(t *testing.T) {
+	account := mockAccount()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccount", mockAccountEmail).
+		Return(&account, nil).Once()
+	dbMock.On("RevokeAccountSessions", mockAccountEmail).
+		Return(int64(3), nil).Once()
+	auditMock := dBMock{&mock.Mock{}}
+	auditMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditSessionsRevoked && string(e.Payload) == `{"account":"someone@example.com","revoked":3}`
+	})).Return(nil).Once()
+
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/sessions", nil, true, param{"email", mockAccountEmail})
+	handler := RevokeAccountSessions(dbMock, auditMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &revokedSessionsResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful sign out everywhere didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful sign out everywhere didn't return a valid revokedSessionsResponse object") {
+		assert.Equal(t, revokedSessionsResponse{Revoked: 3}, *resp, "A successful sign out everywhere didn't return how many sessions were revoked")
+	}
+	dbMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func TestNotFoundRevokeAccountSessions(t *testing.T) {
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAccount", mockAccountEmail).
+		Return(nil, database.ErrAccountNotFound).Once()
+
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/sessions", nil, true, param{"email", mockAccountEmail})
+	handler := RevokeAccountSessions(dbMock, dBMock{&mock.Mock{}})
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errAccountNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "Signing an unknown account out didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "Signing an unknown account out didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "Signing an unknown account out didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
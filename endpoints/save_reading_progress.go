@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type readingProgressRequest struct {
+	CFI        string     `json:"cfi"`
+	Page       int        `json:"page"`
+	Percentage *float32   `json:"percentage"`
+	DeviceID   string     `json:"device_id"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+func (p *readingProgressRequest) Bind(r *http.Request) error {
+	if p.Percentage == nil || p.UpdatedAt == nil {
+		return errReadingProgressMalformed
+	}
+	return nil
+}
+
+var errReadingProgressMalformed = errors.New("percentage or updated_at missing")
+
+// SaveReadingProgress syncs where the account is in a book. Progress made
+// earlier than the stored one is refused with 409, along with the stored
+// progress, so the device can jump to it.
+func SaveReadingProgress(
+	db database.ReadingInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("SaveReadingProgress: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "bookId"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Saving reading progress with a malformed book id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		data := &readingProgressRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Saving reading progress attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		progress, applied, err := db.SaveReadingProgress(ctx, sch.Email, database.ReadingProgress{
+			BookID:     bookID,
+			CFI:        data.CFI,
+			Page:       data.Page,
+			Percentage: *data.Percentage,
+			DeviceID:   data.DeviceID,
+			UpdatedAt:  *data.UpdatedAt,
+		})
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while saving reading progress")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ReadingProgressFromDatabase(*progress)
+		if !applied {
+			resp.httpStatus = http.StatusConflict
+		}
+		render.Render(w, r, &resp)
+	}
+}
@@ -0,0 +1,137 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SaveReadingProgress(ctx context.Context, accountID string, progress database.ReadingProgress) (*database.ReadingProgress, bool, error) {
+	args := db.Called(accountID, progress)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*database.ReadingProgress), args.Bool(1), args.Error(2)
+}
+
+func mockReadingProgress(bookID uuid.UUID) (readingProgressRequest, database.ReadingProgress) {
+	percentage := float32(42.5)
+	updatedAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+	req := readingProgressRequest{
+		CFI:        "epubcfi(/6/4[chap01ref]!/4[body01]/10[para05]/3:10)",
+		Percentage: &percentage,
+		DeviceID:   "tablet",
+		UpdatedAt:  &updatedAt,
+	}
+	progress := database.ReadingProgress{
+		BookID:     bookID,
+		CFI:        req.CFI,
+		Percentage: percentage,
+		DeviceID:   req.DeviceID,
+		UpdatedAt:  updatedAt,
+	}
+	return req, progress
+}
+
+func TestSuccessfulSaveReadingProgress(t *testing.T) {
+	bookID := uuid.New()
+	path := "/me/reading/" + bookID.String()
+
+	req, progress := mockReadingProgress(bookID)
+	stored := progress
+	stored.ReceivedAt = progress.UpdatedAt.Add(time.Second)
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SaveReadingProgress", expID.Account, progress).
+		Return(&stored, true, nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"bookId", bookID.String()})
+	handler := SaveReadingProgress(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ReadingProgressFromDatabase(stored)
+
+	resp := &ReadingProgressResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful reading progress save didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful reading progress save didn't return a valid ReadingProgressResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful reading progress save didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestConflictSaveReadingProgress(t *testing.T) {
+	bookID := uuid.New()
+	path := "/me/reading/" + bookID.String()
+
+	req, progress := mockReadingProgress(bookID)
+	stored := database.ReadingProgress{
+		BookID:     bookID,
+		Page:       120,
+		Percentage: 60,
+		DeviceID:   "phone",
+		UpdatedAt:  progress.UpdatedAt.Add(time.Hour),
+		ReceivedAt: progress.UpdatedAt.Add(time.Hour),
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SaveReadingProgress", expID.Account, progress).
+		Return(&stored, false, nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"bookId", bookID.String()})
+	handler := SaveReadingProgress(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ReadingProgressFromDatabase(stored)
+
+	resp := &ReadingProgressResponse{}
+	assert.Equal(t, http.StatusConflict, w.Code, "A stale reading progress save didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A stale reading progress save didn't return a valid ReadingProgressResponse object") {
+		assert.Equal(t, expResp, *resp, "A stale reading progress save didn't return the stored progress")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedSaveReadingProgress(t *testing.T) {
+	bookID := uuid.New()
+	path := "/me/reading/" + bookID.String()
+
+	req, progress := mockReadingProgress(bookID)
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SaveReadingProgress", expID.Account, progress).
+		Return(nil, false, database.ErrBookNotFound).Once()
+	dbMock.On("SaveReadingProgress", expID.Account, mock.Anything).
+		Return(nil, false, database.ErrReadingLocatorInvalid).Once()
+
+	tests := []struct {
+		name    string
+		body    interface{}
+		expResp *ErrorResponse
+	}{
+		{"missing book", req, NotFoundError(errBookNotFound).(*ErrorResponse)},
+		{"locator-less", readingProgressRequest{Percentage: req.Percentage, DeviceID: "tablet", UpdatedAt: req.UpdatedAt}, ValidationFailedError(database.ErrReadingLocatorInvalid).(*ErrorResponse)},
+		{"timeless", readingProgressRequest{CFI: req.CFI, Percentage: req.Percentage, DeviceID: "tablet"}, BadRequestError(errReadingProgressMalformed).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, path, test.body, true, param{"bookId", bookID.String()})
+		handler := SaveReadingProgress(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s reading progress save didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s reading progress save didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s reading progress save didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/moderationhelper"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type saveReviewRequest struct {
+	Rating int    `json:"rating"`
+	Body   string `json:"body"`
+}
+
+func (s *saveReviewRequest) Bind(r *http.Request) error {
+	if s.Rating == 0 {
+		return errSaveReviewMalformed
+	}
+	return nil
+}
+
+var errSaveReviewMalformed = errors.New("rating missing")
+
+// SaveReview rates a book for the account, with an optional review. Reviews
+// the content filter flags wait in the moderation queue, and so do the ones
+// the filter couldn't check.
+func SaveReview(
+	db database.ReviewInterface,
+	filter moderationhelper.ContentFilter,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("SaveReview: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Reviewing a book with a malformed id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		data := &saveReviewRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Saving review attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		review := database.Review{
+			AccountID: sch.Email,
+			BookID:    bookID,
+			Rating:    data.Rating,
+			Body:      data.Body,
+			Status:    database.ReviewApproved,
+		}
+		if err := review.Validate(); err != nil {
+			render.Render(w, r, ValidationFailedError(err))
+			return
+		}
+		if review.Body != "" {
+			verdict, err := filter.CheckContent(ctx, review.Body)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Content filter error while checking a review")
+				verdict = moderationhelper.Verdict{Flagged: true, Reason: "filter unavailable"}
+			}
+			if verdict.Flagged {
+				review.Status = database.ReviewPending
+				review.FlagReason = verdict.Reason
+			}
+		}
+
+		saved, err := db.SaveReview(ctx, review)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while saving a review")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ReviewFromDatabase(*saved, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
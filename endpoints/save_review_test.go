@@ -0,0 +1,149 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/moderationhelper"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SaveReview(ctx context.Context, review database.Review) (*database.Review, error) {
+	args := db.Called(review)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Review), args.Error(1)
+}
+
+func mockReview(bookID uuid.UUID, accountID string) database.Review {
+	createdAt := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+	return database.Review{
+		ID:         uuid.New(),
+		AccountID:  accountID,
+		AuthorName: "Reza",
+		BookID:     bookID,
+		Rating:     4,
+		Body:       "A slow start, but worth it.",
+		Status:     database.ReviewApproved,
+		Helpful:    3,
+		NotHelpful: 1,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+func TestSuccessfulSaveReview(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/review"
+
+	expDBReview := mockReview(bookID, expID.Account)
+	req := saveReviewRequest{Rating: expDBReview.Rating, Body: " " + expDBReview.Body}
+
+	filterMock := contentFilterMock{&mock.Mock{}}
+	filterMock.On("CheckContent", expDBReview.Body).
+		Return(moderationhelper.Verdict{}, nil).Once()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SaveReview", database.Review{
+		AccountID: expID.Account,
+		BookID:    bookID,
+		Rating:    req.Rating,
+		Body:      expDBReview.Body,
+		Status:    database.ReviewApproved,
+	}).Return(&expDBReview, nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", bookID.String()})
+	handler := SaveReview(dbMock, filterMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ReviewFromDatabase(expDBReview, expID.Account)
+
+	resp := &ReviewResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful review didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful review didn't return a valid ReviewResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful review didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+	filterMock.AssertExpectations(t)
+}
+
+func TestFlaggedSaveReview(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/review"
+
+	expDBReview := mockReview(bookID, expID.Account)
+	expDBReview.Body = "click here"
+	expDBReview.Status = database.ReviewPending
+
+	filterMock := contentFilterMock{&mock.Mock{}}
+	filterMock.On("CheckContent", "click here").
+		Return(moderationhelper.Verdict{Flagged: true, Reason: "listed phrase"}, nil).Once()
+	filterMock.On("CheckContent", "nice").
+		Return(moderationhelper.Verdict{}, errors.New("filter down")).Once()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SaveReview", mock.MatchedBy(func(rv database.Review) bool {
+		return rv.Status == database.ReviewPending && rv.FlagReason == "listed phrase"
+	})).Return(&expDBReview, nil).Once()
+	dbMock.On("SaveReview", mock.MatchedBy(func(rv database.Review) bool {
+		return rv.Status == database.ReviewPending && rv.FlagReason == "filter unavailable"
+	})).Return(&expDBReview, nil).Once()
+
+	for _, body := range []string{"click here", "nice"} {
+		w, r := mockRequest(t, path, saveReviewRequest{Rating: 2, Body: body}, true, param{"id", bookID.String()})
+		handler := SaveReview(dbMock, filterMock)
+		handler.ServeHTTP(w, r)
+
+		resp := &ReviewResponse{}
+		assert.Equal(t, http.StatusOK, w.Code, "A flagged review didn't return the proper response code")
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A flagged review didn't return a valid ReviewResponse object") {
+			assert.Equal(t, database.ReviewPending, resp.Status, "A flagged review wasn't held for moderation")
+		}
+	}
+	dbMock.AssertExpectations(t)
+	filterMock.AssertExpectations(t)
+}
+
+func TestFailedSaveReview(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/review"
+
+	filterMock := contentFilterMock{&mock.Mock{}}
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SaveReview", mock.MatchedBy(func(rv database.Review) bool { return rv.Rating == 3 })).
+		Return(nil, database.ErrBookNotFound).Once()
+
+	tests := []struct {
+		name    string
+		body    saveReviewRequest
+		expResp *ErrorResponse
+	}{
+		{"unrated", saveReviewRequest{}, BadRequestError(errSaveReviewMalformed).(*ErrorResponse)},
+		{"overrated", saveReviewRequest{Rating: 6}, ValidationFailedError(database.ErrReviewRatingInvalid).(*ErrorResponse)},
+		{"bookless", saveReviewRequest{Rating: 3}, NotFoundError(errBookNotFound).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, path, test.body, true, param{"id", bookID.String()})
+		handler := SaveReview(dbMock, filterMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s review didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s review didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s review didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+	filterMock.AssertExpectations(t)
+}
@@ -0,0 +1,71 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+type setAccountLanguageRequest struct {
+	Language *string `json:"language"`
+}
+
+var errAccountLanguageMalformed = errors.New("language missing")
+var errAccountLanguageUnsupported = errors.New("language isn't one of en, id or ja")
+
+// Bind turns the language into the catalog language closest to it, like
+// "ja" for "ja-JP". An empty language goes back to the client's.
+func (l *setAccountLanguageRequest) Bind(r *http.Request) error {
+	if l.Language == nil {
+		return errAccountLanguageMalformed
+	}
+	if *l.Language == "" {
+		return nil
+	}
+	tag, ok := supportedLanguage(*l.Language)
+	if !ok {
+		return fieldErrors{"language": {errAccountLanguageUnsupported}}
+	}
+	*l.Language = tag.String()
+	return nil
+}
+
+// SetAccountLanguage saves the language the account's messages are sent in,
+// on every device, over the language cookie and Accept-Language.
+func SetAccountLanguage(
+	db database.UserAccountInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("SetAccountLanguage: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		data := &setAccountLanguageRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Setting account language attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		if err := db.SetAccountLanguage(ctx, sch.Email, *data.Language); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while setting the account's language")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		render.Render(w, r, &LanguageResponse{Language: *data.Language})
+	}
+}
@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SetAccountLanguage(ctx context.Context, email string, lang string) error {
+	args := db.Called(email, lang)
+	return args.Error(0)
+}
+
+func TestSuccessfulSetAccountLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		expSaved string
+	}{
+		{"id", "id"},
+		{"ja-JP", "ja"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		dbMock := dBMock{&mock.Mock{}}
+		dbMock.On("SetAccountLanguage", expID.Account, test.expSaved).
+			Return(nil).Once()
+
+		language := test.language
+		w, r := mockRequest(t, "/me/language", setAccountLanguageRequest{Language: &language}, true)
+		handler := SetAccountLanguage(dbMock)
+		handler.ServeHTTP(w, r)
+
+		resp := &LanguageResponse{}
+		assert.Equal(t, http.StatusOK, w.Code, "Setting the account language to %q didn't return the proper response code", test.language)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "Setting the account language to %q didn't return a valid LanguageResponse object", test.language) {
+			assert.Equal(t, test.expSaved, resp.Language, "Setting the account language to %q didn't return the language saved", test.language)
+		}
+		dbMock.AssertExpectations(t)
+	}
+}
+
+func TestFailedSetAccountLanguage(t *testing.T) {
+	unsupported := "fr"
+	w, r := mockRequest(t, "/me/language", setAccountLanguageRequest{Language: &unsupported}, true)
+	SetAccountLanguage(dBMock{&mock.Mock{}}).ServeHTTP(w, r)
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, http.StatusBadRequest, w.Code, "An unsupported account language didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An unsupported account language didn't return a valid errorResponse object") {
+		assert.Equal(t, "language_unsupported", resp.Fields["language"][0].Code, "An unsupported account language didn't return the proper field error")
+	}
+
+	w, r = mockRequest(t, "/me/language", struct{}{}, true)
+	SetAccountLanguage(dBMock{&mock.Mock{}}).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A missing account language didn't return the proper response code")
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SetAccountLanguage", expID.Account, "id").
+		Return(database.ErrAccountNotFound).Once()
+
+	supported := "id"
+	w, r = mockRequest(t, "/me/language", setAccountLanguageRequest{Language: &supported}, true)
+	SetAccountLanguage(dbMock).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "Setting the language of a missing account didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog/log"
+)
+
+type accountStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func (a *accountStatusRequest) Bind(r *http.Request) error {
+	if a.Status == "" {
+		return errAccountStatusMalformed
+	}
+	return nil
+}
+
+type accountStatusAudit struct {
+	Account string `json:"account"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+var errAccountStatusMalformed = errors.New("status missing")
+var errAccountStatusOwn = errors.New("staff can't change the status of their own account")
+
+// SetAccountStatus disables, bans or reactivates an account. Disabled and
+// banned accounts can't sign in, and are signed out of every device.
+func SetAccountStatus(
+	db database.AdminUserInterface,
+	audit database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("SetAccountStatus: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		accountEmail := chi.URLParam(r, "email")
+		if accountEmail == sch.Email {
+			render.Render(w, r, RequestConflictError(errAccountStatusOwn))
+			return
+		}
+
+		data := &accountStatusRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Setting account status attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		account, err := db.SetAccountStatus(ctx, accountEmail, data.Status, data.Reason)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while setting an account's status")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		recordAudit(r, audit, database.AuditAccountStatusChanged, sch.Email, accountStatusAudit{
+			Account: accountEmail,
+			Status:  account.Status,
+			Reason:  account.StatusReason,
+		})
+
+		resp := AccountFromDatabase(*account)
+		render.Render(w, r, &resp)
+	}
+}
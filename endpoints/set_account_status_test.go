@@ -0,0 +1,78 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSuccessfulSetAccountStatus(t *testing.T) {
+	changedAt := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	expAccount := mockAccount()
+	expAccount.Status = database.AccountBanned
+	expAccount.StatusReason = "spam reviews"
+	expAccount.StatusChangedAt = &changedAt
+	expAccount.Sessions = 0
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SetAccountStatus", mockAccountEmail, database.AccountBanned, "spam reviews").
+		Return(&expAccount, nil).Once()
+	auditMock := dBMock{&mock.Mock{}}
+	auditMock.On("RecordAuditEvent", mock.MatchedBy(func(e database.AuditEvent) bool {
+		return e.Action == database.AuditAccountStatusChanged && e.Actor == expID.Account &&
+			string(e.Payload) == `{"account":"someone@example.com","status":"banned","reason":"spam reviews"}`
+	})).Return(nil).Once()
+
+	body := accountStatusRequest{Status: database.AccountBanned, Reason: "spam reviews"}
+	w, r := mockRequest(t, "/admin/users/"+mockAccountEmail+"/status", body, true, param{"email", mockAccountEmail})
+	handler := SetAccountStatus(dbMock, auditMock)
+	handler.ServeHTTP(w, r)
+
+	resp := &AccountResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful ban didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful ban didn't return a valid AccountResponse object") {
+		assert.Equal(t, AccountFromDatabase(expAccount), *resp, "A successful ban didn't return the account")
+		assert.Equal(t, "2022-03-04T05:06:07Z", resp.StatusChangedAt, "A successful ban didn't say when it happened")
+	}
+	dbMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func TestFailedSetAccountStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		email   string
+		body    accountStatusRequest
+		dbErr   error
+		expResp ErrorResponse
+	}{
+		{"missing status", mockAccountEmail, accountStatusRequest{Reason: "spam"}, nil, *BadRequestError(errAccountStatusMalformed).(*ErrorResponse)},
+		{"own account", expID.Account, accountStatusRequest{Status: database.AccountDisabled, Reason: "spam"}, nil, *RequestConflictError(errAccountStatusOwn).(*ErrorResponse)},
+		{"missing reason", mockAccountEmail, accountStatusRequest{Status: database.AccountDisabled}, database.ErrAccountStatusReasonMissing, *errorResponse(database.ErrAccountStatusReasonMissing).(*ErrorResponse)},
+		{"unknown account", mockAccountEmail, accountStatusRequest{Status: database.AccountActive}, database.ErrAccountNotFound, *errorResponse(database.ErrAccountNotFound).(*ErrorResponse)},
+	} {
+		dbMock := dBMock{&mock.Mock{}}
+		if tc.dbErr != nil {
+			dbMock.On("SetAccountStatus", tc.email, tc.body.Status, tc.body.Reason).
+				Return(nil, tc.dbErr).Once()
+		}
+
+		w, r := mockRequest(t, "/admin/users/"+tc.email+"/status", tc.body, true, param{"email", tc.email})
+		handler := SetAccountStatus(dbMock, dBMock{&mock.Mock{}})
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := tc.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "Setting an account's status with %s didn't return the proper response code", tc.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "Setting an account's status with %s didn't return a valid errorResponse object", tc.name) {
+			assert.Equal(t, expResp, *resp, "Setting an account's status with %s didn't return the proper error", tc.name)
+		}
+		dbMock.AssertExpectations(t)
+	}
+}
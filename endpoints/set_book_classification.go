@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type bookClassificationRequest struct {
+	GenreIDs []uuid.UUID `json:"genre_ids"`
+	Tags     []string    `json:"tags"`
+}
+
+func (b *bookClassificationRequest) Bind(r *http.Request) error {
+	if b.GenreIDs == nil && b.Tags == nil {
+		return errBookClassificationMalformed
+	}
+	return nil
+}
+
+type bookClassificationAudit struct {
+	Book     string      `json:"book_id"`
+	GenreIDs []uuid.UUID `json:"genre_ids"`
+	Tags     []string    `json:"tags"`
+}
+
+var errBookClassificationMalformed = errors.New("genre_ids or tags missing")
+
+// SetBookClassification replaces the genres and tags of a book. Leaving one
+// of the two out of the request clears it.
+func SetBookClassification(
+	db database.GenreInterface,
+	audit database.AuditInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Classifying book with a malformed book id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		data := &bookClassificationRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Classifying book attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		tags, err := database.NormalizeTags(data.Tags)
+		if err != nil {
+			render.Render(w, r, ValidationFailedError(err))
+			return
+		}
+
+		if err := db.SetBookClassification(ctx, bookID, data.GenreIDs, tags); err != nil {
+			// A missing genre is a bad field here rather than a missing page.
+			if err == database.ErrGenreNotFound {
+				render.Render(w, r, ValidationFailedError(errGenreNotFound))
+				return
+			}
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while classifying a book")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		recordSessionAudit(r, audit, database.AuditBookClassificationSet, bookClassificationAudit{
+			Book:     bookID.String(),
+			GenreIDs: data.GenreIDs,
+			Tags:     tags,
+		})
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
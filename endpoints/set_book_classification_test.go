@@ -0,0 +1,85 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SetBookClassification(ctx context.Context, bookID uuid.UUID, genreIDs []uuid.UUID, tags []string) error {
+	args := db.Called(bookID, genreIDs, tags)
+	return args.Error(0)
+}
+
+func TestSuccessfulSetBookClassification(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/classification"
+
+	req := bookClassificationRequest{
+		GenreIDs: []uuid.UUID{uuid.New(), uuid.New()},
+		Tags:     []string{"Sports ", "shonen", "sports"},
+	}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SetBookClassification", bookID, req.GenreIDs, []string{"shonen", "sports"}).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", bookID.String()})
+	handler := SetBookClassification(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful book classification didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestUnknownGenreSetBookClassification(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/classification"
+
+	req := bookClassificationRequest{GenreIDs: []uuid.UUID{uuid.New()}}
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SetBookClassification", bookID, req.GenreIDs, []string{}).
+		Return(database.ErrGenreNotFound).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", bookID.String()})
+	handler := SetBookClassification(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := ValidationFailedError(errGenreNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A book classification with an unknown genre didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A book classification with an unknown genre didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A book classification with an unknown genre didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestInvalidTagSetBookClassification(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/classification"
+
+	req := bookClassificationRequest{Tags: []string{" "}}
+
+	dbMock := dBMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, req, true, param{"id", bookID.String()})
+	handler := SetBookClassification(dbMock, newAuditMock())
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := ValidationFailedError(database.ErrTagInvalid).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A book classification with an empty tag didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A book classification with an empty tag didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A book classification with an empty tag didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+type ShelfResponse struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+	Position    int    `json:"position"`
+	BookCount   int    `json:"book_count"`
+	OwnerName   string `json:"owner_name"`
+	IsOwn       bool   `json:"is_own"`
+	SharePath   string `json:"share_path,omitempty"`
+	CreatedAt   string `json:"created_at"`
+
+	httpStatus int
+}
+
+func (s *ShelfResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	status := s.httpStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	render.Status(r, status)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+// ShelfFromDatabase builds the response for the account in accountID, which
+// decides is_own. Only public shelves get a share path.
+func ShelfFromDatabase(dShelf database.Shelf, accountID string) ShelfResponse {
+	var s ShelfResponse
+
+	s.ID = dShelf.ID.String()
+	s.Kind = dShelf.Kind
+	s.Name = dShelf.Name
+	s.Description = dShelf.Description
+	s.Visibility = dShelf.Visibility
+	s.Position = dShelf.Position
+	s.BookCount = dShelf.BookCount
+	s.OwnerName = dShelf.OwnerName
+	s.IsOwn = dShelf.AccountID == accountID
+	if dShelf.Visibility == database.ShelfPublic {
+		s.SharePath = "/shared/shelves/" + s.ID
+	}
+	s.CreatedAt = dShelf.CreatedAt.UTC().Format(time.RFC3339)
+
+	return s
+}
+
+type ShelvesResponse struct {
+	Data []ShelfResponse `json:"data"`
+}
+
+func (s *ShelvesResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+func ShelvesFromDatabase(dShelves []database.Shelf, accountID string) ShelvesResponse {
+	s := ShelvesResponse{Data: []ShelfResponse{}}
+
+	for _, dShelf := range dShelves {
+		s.Data = append(s.Data, ShelfFromDatabase(dShelf, accountID))
+	}
+
+	return s
+}
+
+// ShelfBooksResponse is a shelf with its books, in the owner's order.
+type ShelfBooksResponse struct {
+	ShelfResponse
+	Books []BookResponse `json:"books"`
+}
+
+func ShelfBooksFromDatabase(dShelf database.Shelf, dBooks []database.Book, accountID string) ShelfBooksResponse {
+	s := ShelfBooksResponse{
+		ShelfResponse: ShelfFromDatabase(dShelf, accountID),
+		Books:         []BookResponse{},
+	}
+
+	for _, dBook := range dBooks {
+		s.Books = append(s.Books, BookFromDatabase(dBook))
+	}
+
+	return s
+}
+
+type BookShelfResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+func BookShelvesFromDatabase(dShelves database.BookShelves) []BookShelfResponse {
+	shelves := make([]BookShelfResponse, 0, len(dShelves))
+	for _, dShelf := range dShelves {
+		shelves = append(shelves, BookShelfResponse{
+			ID:   dShelf.ID.String(),
+			Name: dShelf.Name,
+			Kind: dShelf.Kind,
+		})
+	}
+	return shelves
+}
+
+var errShelfIDMalformed = errors.New("shelf id malformed")
+var errShelfNotFound = errors.New("shelf not found")
@@ -0,0 +1,34 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/tracinghelper"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a span for every request, continuing the trace of
+// the W3C traceparent header when there is one, and names it after the route
+// that served the request. The request's context carries a logger with the
+// trace, span and request ids for log.Ctx.
+func TracingMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		traced := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logger := tracinghelper.Logger(ctx)
+			if reqID := middleware.GetReqID(ctx); reqID != "" {
+				logger = logger.With().Str("request_id", reqID).Logger()
+			}
+			next.ServeHTTP(w, r.WithContext(logger.WithContext(ctx)))
+
+			route := routePattern(r)
+			span := trace.SpanFromContext(ctx)
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		})
+		return otelhttp.NewHandler(traced, "http.request")
+	}
+}
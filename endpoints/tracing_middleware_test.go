@@ -0,0 +1,50 @@
+package endpoints
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var logs bytes.Buffer
+	globalLogger := log.Logger
+	log.Logger = zerolog.New(&logs)
+	defer func() { log.Logger = globalLogger }()
+
+	r := chi.NewRouter()
+	r.Use(TracingMiddleware())
+	r.Get("/test/{id}", func(w http.ResponseWriter, r *http.Request) {
+		log.Ctx(r.Context()).Info().Msg("handled")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1, "A request didn't get a span") {
+		span := spans[0]
+		assert.Equal(t, "GET /test/{id}", span.Name(), "A request's span wasn't named after its route")
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), "A request's span didn't continue the trace of its traceparent")
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String(), "A request's span didn't have its traceparent as parent")
+		assert.Contains(t, logs.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`, "A request's logs miss its trace id")
+		assert.Contains(t, logs.String(), `"span_id":"`+span.SpanContext().SpanID().String()+`"`, "A request's logs miss its span id")
+	}
+}
@@ -0,0 +1,84 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// updateAnnotationRequest leaves out what shouldn't change. An empty string
+// clears the color or the note.
+type updateAnnotationRequest struct {
+	Color   *string `json:"color"`
+	Note    *string `json:"note"`
+	Privacy *string `json:"privacy"`
+}
+
+func (u *updateAnnotationRequest) Bind(r *http.Request) error {
+	return nil
+}
+
+// UpdateAnnotation changes the color, note or privacy of one of the account's
+// annotations. Its anchor can't be moved, a new annotation is made instead.
+func UpdateAnnotation(
+	db database.AnnotationInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("UpdateAnnotation: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		annotationID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Updating an annotation with a malformed id")
+			render.Render(w, r, BadRequestError(errAnnotationIDMalformed))
+			return
+		}
+
+		data := &updateAnnotationRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Updating annotation attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		annotation, err := db.GetAnnotation(ctx, annotationID, sch.Email)
+		if err == nil && annotation.AccountID != sch.Email {
+			err = database.ErrAnnotationNotFound
+		}
+		if err == nil {
+			if data.Color != nil {
+				annotation.Color = *data.Color
+			}
+			if data.Note != nil {
+				annotation.Note = *data.Note
+			}
+			if data.Privacy != nil {
+				annotation.Privacy = *data.Privacy
+			}
+			annotation, err = db.UpdateAnnotation(ctx, *annotation)
+		}
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while updating an annotation")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := AnnotationFromDatabase(*annotation, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
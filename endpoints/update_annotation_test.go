@@ -0,0 +1,85 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) GetAnnotation(ctx context.Context, annotationID uuid.UUID, accountID string) (*database.Annotation, error) {
+	args := db.Called(annotationID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Annotation), args.Error(1)
+}
+
+func (db dBMock) UpdateAnnotation(ctx context.Context, annotation database.Annotation) (*database.Annotation, error) {
+	args := db.Called(annotation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Annotation), args.Error(1)
+}
+
+func TestSuccessfulUpdateAnnotation(t *testing.T) {
+	stored := mockAnnotation(uuid.New())
+	path := "/annotations/" + stored.ID.String()
+
+	note := "Foreshadowing"
+	privacy := database.AnnotationShared
+	req := updateAnnotationRequest{Note: &note, Privacy: &privacy}
+
+	updated := stored
+	updated.Note = note
+	updated.Privacy = privacy
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAnnotation", stored.ID, expID.Account).
+		Return(&stored, nil).Once()
+	dbMock.On("UpdateAnnotation", updated).
+		Return(&updated, nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", stored.ID.String()})
+	handler := UpdateAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := AnnotationFromDatabase(updated, expID.Account)
+
+	resp := &AnnotationResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful annotation update didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful annotation update didn't return a valid AnnotationResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful annotation update didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestNotOwnedUpdateAnnotation(t *testing.T) {
+	stored := mockAnnotation(uuid.New())
+	stored.AccountID = "friend@example.com"
+	stored.Privacy = database.AnnotationShared
+	path := "/annotations/" + stored.ID.String()
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetAnnotation", stored.ID, expID.Account).
+		Return(&stored, nil).Once()
+
+	w, r := mockRequest(t, path, updateAnnotationRequest{}, true, param{"id", stored.ID.String()})
+	handler := UpdateAnnotation(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errAnnotationNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "An update of someone else's annotation didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "An update of someone else's annotation didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "An update of someone else's annotation didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+}
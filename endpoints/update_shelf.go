@@ -0,0 +1,87 @@
+package endpoints
+
+import (
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// updateShelfRequest leaves out what shouldn't change.
+type updateShelfRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility"`
+	Position    *int    `json:"position"`
+}
+
+func (u *updateShelfRequest) Bind(r *http.Request) error {
+	return nil
+}
+
+// UpdateShelf renames, describes, moves or shares one of the account's
+// shelves. Built-in shelves can't be renamed.
+func UpdateShelf(
+	db database.ShelfInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("UpdateShelf: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		shelfID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Updating a shelf with a malformed id")
+			render.Render(w, r, BadRequestError(errShelfIDMalformed))
+			return
+		}
+
+		data := &updateShelfRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Updating shelf attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		shelf, err := db.GetShelf(ctx, shelfID, sch.Email)
+		if err == nil && shelf.AccountID != sch.Email {
+			err = database.ErrShelfNotFound
+		}
+		if err == nil {
+			if data.Name != nil {
+				shelf.Name = *data.Name
+			}
+			if data.Description != nil {
+				shelf.Description = *data.Description
+			}
+			if data.Visibility != nil {
+				shelf.Visibility = *data.Visibility
+			}
+			if data.Position != nil {
+				shelf.Position = *data.Position
+			}
+			shelf, err = db.UpdateShelf(ctx, *shelf)
+		}
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while updating a shelf")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		resp := ShelfFromDatabase(*shelf, sch.Email)
+		render.Render(w, r, &resp)
+	}
+}
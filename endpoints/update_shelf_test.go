@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) UpdateShelf(ctx context.Context, shelf database.Shelf) (*database.Shelf, error) {
+	args := db.Called(shelf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.Shelf), args.Error(1)
+}
+
+func TestSuccessfulUpdateShelf(t *testing.T) {
+	dbShelf := mockShelf(database.ShelfFinished, "Finished")
+	path := "/shelves/" + dbShelf.ID.String()
+
+	visibility := database.ShelfPublic
+	position := 7
+	req := updateShelfRequest{Visibility: &visibility, Position: &position}
+
+	expDBShelf := dbShelf
+	expDBShelf.Visibility = visibility
+	expDBShelf.Position = position
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", dbShelf.ID, expID.Account).
+		Return(&dbShelf, nil).Once()
+	dbMock.On("UpdateShelf", expDBShelf).
+		Return(&expDBShelf, nil).Once()
+
+	w, r := mockRequest(t, path, req, true, param{"id", dbShelf.ID.String()})
+	handler := UpdateShelf(dbMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := ShelfFromDatabase(expDBShelf, expID.Account)
+
+	resp := &ShelfResponse{}
+	assert.Equal(t, http.StatusOK, w.Code, "A successful shelf update didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful shelf update didn't return a valid ShelfResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful shelf update didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedUpdateShelf(t *testing.T) {
+	own := mockShelf(database.ShelfReading, "Currently reading")
+	others := mockShelf(database.ShelfCustom, "Classics")
+	others.AccountID = "someone@else.com"
+	others.Visibility = database.ShelfPublic
+	missingID := uuid.New()
+
+	name := "Reading now"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("GetShelf", own.ID, expID.Account).
+		Return(&own, nil).Once()
+	dbMock.On("UpdateShelf", mock.MatchedBy(func(s database.Shelf) bool { return s.ID == own.ID })).
+		Return(nil, database.ErrShelfBuiltIn).Once()
+	dbMock.On("GetShelf", others.ID, expID.Account).
+		Return(&others, nil).Once()
+	dbMock.On("GetShelf", missingID, expID.Account).
+		Return(nil, database.ErrShelfNotFound).Once()
+
+	tests := []struct {
+		name    string
+		shelfID string
+		expResp *ErrorResponse
+	}{
+		{"malformed", "abc", BadRequestError(errShelfIDMalformed).(*ErrorResponse)},
+		{"built-in", own.ID.String(), RequestConflictError(database.ErrShelfBuiltIn).(*ErrorResponse)},
+		{"not owned", others.ID.String(), NotFoundError(errShelfNotFound).(*ErrorResponse)},
+		{"missing", missingID.String(), NotFoundError(errShelfNotFound).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, "/shelves/"+test.shelfID, updateShelfRequest{Name: &name}, true, param{"id", test.shelfID})
+		handler := UpdateShelf(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s shelf update didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s shelf update didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s shelf update didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/coverhelper"
+	"ic-rhadi/e_library/database"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type bookCoverResponse struct {
+	ID     string            `json:"id"`
+	Covers map[string]string `json:"covers"`
+}
+
+func (b *bookCoverResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusOK)
+	w.Header().Set("content-type", "application/json")
+	return nil
+}
+
+var errBookIDMalformed = errors.New("book id malformed")
+var errBookNotFound = errors.New("book not found")
+
+type bookCoverAudit struct {
+	Book     string `json:"book_id"`
+	Cover    string `json:"cover_id"`
+	Replaced string `json:"replaced_cover_id,omitempty"`
+}
+
+func UploadBookCover(
+	db database.BookCoverInterface,
+	audit database.AuditInterface,
+	covers coverhelper.CoverStorage,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		bookID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Uploading cover with a malformed book id")
+			render.Render(w, r, BadRequestError(errBookIDMalformed))
+			return
+		}
+
+		coverID, err := covers.StoreCover(ctx, r.Body)
+		if err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Storing uploaded cover returned an error")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		oldCoverID, err := db.SetBookCover(ctx, bookID, coverID)
+		if err != nil {
+			if err := covers.DeleteCover(ctx, coverID); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("cover", coverID.String()).Msg("Removing unused cover failed")
+			}
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while setting book cover")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+		coverAudit := bookCoverAudit{Book: bookID.String(), Cover: coverID.String()}
+		if oldCoverID.Valid {
+			coverAudit.Replaced = oldCoverID.UUID.String()
+		}
+		recordSessionAudit(r, audit, database.AuditBookCoverUploaded, coverAudit)
+
+		if oldCoverID.Valid {
+			if err := covers.DeleteCover(ctx, oldCoverID.UUID); err != nil {
+				log.Ctx(ctx).Error().Err(err).Str("cover", oldCoverID.UUID.String()).Msg("Removing replaced cover failed")
+			}
+		}
+
+		render.Render(w, r, &bookCoverResponse{
+			ID:     coverID.String(),
+			Covers: CoverURLs(coverID),
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package endpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"ic-rhadi/e_library/coverhelper"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) SetBookCover(ctx context.Context, bookID uuid.UUID, coverID uuid.UUID) (uuid.NullUUID, error) {
+	args := db.Called(bookID, coverID)
+	return args.Get(0).(uuid.NullUUID), args.Error(1)
+}
+
+func TestSuccessfulUploadBookCover(t *testing.T) {
+	bookID := uuid.New()
+	coverID := uuid.New()
+	oldCoverID := uuid.New()
+	path := "/books/" + bookID.String() + "/cover"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SetBookCover", bookID, coverID).
+		Return(uuid.NullUUID{UUID: oldCoverID, Valid: true}, nil).Once()
+	coverMock := coverStorageMock{&mock.Mock{}}
+	coverMock.On("StoreCover").
+		Return(coverID, nil).Once()
+	coverMock.On("DeleteCover", oldCoverID).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := UploadBookCover(dbMock, newAuditMock(), coverMock)
+	handler.ServeHTTP(w, r)
+
+	expResp := bookCoverResponse{
+		ID:     coverID.String(),
+		Covers: CoverURLs(coverID),
+	}
+	expCode := http.StatusOK
+
+	resp := &bookCoverResponse{}
+	assert.Equal(t, expCode, w.Code, "A successful cover upload didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A successful cover upload didn't return a valid bookCoverResponse object") {
+		assert.Equal(t, expResp, *resp, "A successful cover upload didn't return a valid response")
+	}
+	dbMock.AssertExpectations(t)
+	coverMock.AssertExpectations(t)
+}
+
+func TestMalformedUploadBookCover(t *testing.T) {
+	path := "/books/abc/cover"
+
+	dbMock := dBMock{&mock.Mock{}}
+	coverMock := coverStorageMock{&mock.Mock{}}
+
+	w, r := mockRequest(t, path, nil, true, param{"id", "abc"})
+	handler := UploadBookCover(dbMock, newAuditMock(), coverMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := BadRequestError(errBookIDMalformed).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A malformed cover upload didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A malformed cover upload didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A malformed cover upload didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+	coverMock.AssertExpectations(t)
+}
+
+func TestRejectedUploadBookCover(t *testing.T) {
+	bookID := uuid.New()
+	path := "/books/" + bookID.String() + "/cover"
+
+	tests := []struct {
+		storeErr error
+		expResp  ErrorResponse
+	}{
+		{coverhelper.ErrCoverTooLarge, *PayloadTooLargeError(coverhelper.ErrCoverTooLarge).(*ErrorResponse)},
+		{coverhelper.ErrCoverTypeUnsupported, *UnsupportedMediaTypeError(coverhelper.ErrCoverTypeUnsupported).(*ErrorResponse)},
+		{sql.ErrConnDone, *InternalServerError().(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		dbMock := dBMock{&mock.Mock{}}
+		coverMock := coverStorageMock{&mock.Mock{}}
+		coverMock.On("StoreCover").
+			Return(uuid.Nil, test.storeErr).Once()
+
+		w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+		handler := UploadBookCover(dbMock, newAuditMock(), coverMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A rejected cover upload didn't return the proper response code")
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A rejected cover upload didn't return a valid errorResponse object") {
+			assert.Equal(t, expResp, *resp, "A rejected cover upload didn't return the proper error")
+		}
+		dbMock.AssertExpectations(t)
+		coverMock.AssertExpectations(t)
+	}
+}
+
+func TestNotFoundUploadBookCover(t *testing.T) {
+	bookID := uuid.New()
+	coverID := uuid.New()
+	path := "/books/" + bookID.String() + "/cover"
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("SetBookCover", bookID, coverID).
+		Return(uuid.NullUUID{}, database.ErrBookNotFound).Once()
+	coverMock := coverStorageMock{&mock.Mock{}}
+	coverMock.On("StoreCover").
+		Return(coverID, nil).Once()
+	coverMock.On("DeleteCover", coverID).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, nil, true, param{"id", bookID.String()})
+	handler := UploadBookCover(dbMock, newAuditMock(), coverMock)
+	handler.ServeHTTP(w, r)
+
+	expResp, expCode := NotFoundError(errBookNotFound).(*ErrorResponse).sentForm()
+
+	resp := &ErrorResponse{}
+	assert.Equal(t, expCode, w.Code, "A cover upload for a missing book didn't return the proper response code")
+	if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A cover upload for a missing book didn't return a valid errorResponse object") {
+		assert.Equal(t, expResp, *resp, "A cover upload for a missing book didn't return the proper error")
+	}
+	dbMock.AssertExpectations(t)
+	coverMock.AssertExpectations(t)
+}
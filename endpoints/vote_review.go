@@ -0,0 +1,69 @@
+package endpoints
+
+import (
+	"errors"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type voteReviewRequest struct {
+	Helpful *bool `json:"helpful"`
+}
+
+func (v *voteReviewRequest) Bind(r *http.Request) error {
+	if v.Helpful == nil {
+		return errVoteReviewMalformed
+	}
+	return nil
+}
+
+var errVoteReviewMalformed = errors.New("helpful missing")
+
+// VoteReview records whether the account found another account's review
+// helpful. Voting again changes the vote.
+func VoteReview(
+	db database.ReviewInterface,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		sch, err := sessiontoken.FromContext(ctx)
+		if err != nil || sch == nil {
+			log.Ctx(ctx).Error().Err(err).Msg("VoteReview: Getting account failed unexpectedly")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		reviewID, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Voting on a review with a malformed id")
+			render.Render(w, r, BadRequestError(errReviewIDMalformed))
+			return
+		}
+
+		data := &voteReviewRequest{}
+		if err := render.Bind(r, data); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("Voting on review attempt failed")
+			render.Render(w, r, BadRequestError(err))
+			return
+		}
+
+		if err := db.VoteReview(ctx, reviewID, sch.Email, *data.Helpful); err != nil {
+			if errResp := errorResponse(err); errResp != nil {
+				render.Render(w, r, errResp)
+				return
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("Database error while voting on a review")
+			render.Render(w, r, InternalServerError())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
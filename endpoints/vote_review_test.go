@@ -0,0 +1,73 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"ic-rhadi/e_library/database"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func (db dBMock) VoteReview(ctx context.Context, reviewID uuid.UUID, accountID string, helpful bool) error {
+	args := db.Called(reviewID, accountID, helpful)
+	return args.Error(0)
+}
+
+func TestSuccessfulVoteReview(t *testing.T) {
+	reviewID := uuid.New()
+	path := "/reviews/" + reviewID.String() + "/vote"
+	helpful := false
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VoteReview", reviewID, expID.Account, false).
+		Return(nil).Once()
+
+	w, r := mockRequest(t, path, voteReviewRequest{Helpful: &helpful}, true, param{"id", reviewID.String()})
+	handler := VoteReview(dbMock)
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code, "A successful review vote didn't return the proper response code")
+	dbMock.AssertExpectations(t)
+}
+
+func TestFailedVoteReview(t *testing.T) {
+	ownID := uuid.New()
+	missingID := uuid.New()
+	helpful := true
+
+	dbMock := dBMock{&mock.Mock{}}
+	dbMock.On("VoteReview", ownID, expID.Account, true).
+		Return(database.ErrReviewOwn).Once()
+	dbMock.On("VoteReview", missingID, expID.Account, true).
+		Return(database.ErrReviewNotFound).Once()
+
+	tests := []struct {
+		name     string
+		reviewID string
+		body     voteReviewRequest
+		expResp  *ErrorResponse
+	}{
+		{"blank", ownID.String(), voteReviewRequest{}, BadRequestError(errVoteReviewMalformed).(*ErrorResponse)},
+		{"own", ownID.String(), voteReviewRequest{Helpful: &helpful}, RequestConflictError(database.ErrReviewOwn).(*ErrorResponse)},
+		{"missing", missingID.String(), voteReviewRequest{Helpful: &helpful}, NotFoundError(errReviewNotFound).(*ErrorResponse)},
+	}
+
+	for _, test := range tests {
+		w, r := mockRequest(t, "/reviews/"+test.reviewID+"/vote", test.body, true, param{"id", test.reviewID})
+		handler := VoteReview(dbMock)
+		handler.ServeHTTP(w, r)
+
+		expResp, expCode := test.expResp.sentForm()
+
+		resp := &ErrorResponse{}
+		assert.Equal(t, expCode, w.Code, "A %s review vote didn't return the proper response code", test.name)
+		if assert.Nil(t, json.NewDecoder(w.Body).Decode(resp), "A %s review vote didn't return a valid errorResponse object", test.name) {
+			assert.Equal(t, expResp, *resp, "A %s review vote didn't return the proper error", test.name)
+		}
+	}
+	dbMock.AssertExpectations(t)
+}
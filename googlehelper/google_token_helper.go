@@ -5,6 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"ic-rhadi/e_library/tracinghelper"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"google.golang.org/api/idtoken"
 	"google.golang.org/api/option"
@@ -12,18 +18,25 @@ import (
 
 type GTokenValidator interface {
 	ValidateGToken(ctx context.Context, token string) (*GoogleClaimsSchema, error)
+	CheckJWKS(ctx context.Context) error
 }
 type gTokenValidatorImpl struct {
 	tokenValidator *idtoken.Validator
+	client         *http.Client
+
+	jwksMu      sync.Mutex
+	jwksExpires time.Time
 }
 
 func NewGValidator(ctx context.Context) (GTokenValidator, error) {
-	idTokenVal, err := idtoken.NewValidator(ctx, option.WithoutAuthentication())
+	client := &http.Client{Transport: tracinghelper.Transport(http.DefaultTransport)}
+	idTokenVal, err := idtoken.NewValidator(ctx, option.WithoutAuthentication(), option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("validator creation failed: %w", err)
 	}
 	return &gTokenValidatorImpl{
 		tokenValidator: idTokenVal,
+		client:         client,
 	}, nil
 }
 
@@ -36,14 +49,19 @@ type GoogleClaimsSchema struct {
 
 var ErrGoogleEmailUnverified = errors.New("google email unverified")
 
-func (v *gTokenValidatorImpl) ValidateGToken(ctx context.Context, token string) (*GoogleClaimsSchema, error) {
+var tracer = tracinghelper.Tracer("googlehelper")
+
+func (v *gTokenValidatorImpl) ValidateGToken(ctx context.Context, token string) (claims *GoogleClaimsSchema, err error) {
+	ctx, span := tracer.Start(ctx, "google.validate_token")
+	defer func() { tracinghelper.End(span, err) }()
+
 	tkn, err := v.tokenValidator.Validate(ctx, token, "")
 	if err != nil {
 		return nil, err
 	}
 
 	tknMap := tkn.Claims
-	claims := &GoogleClaimsSchema{}
+	claims = &GoogleClaimsSchema{}
 	js, err := json.Marshal(tknMap)
 	if err != nil {
 		return nil, err
@@ -58,3 +76,60 @@ func (v *gTokenValidatorImpl) ValidateGToken(ctx context.Context, token string)
 
 	return claims, nil
 }
+
+// googleJWKSURL serves the keys Google signs its ID tokens with.
+const googleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// jwksFallbackMaxAge is how long fetched keys count as fresh when Google
+// doesn't send a max-age.
+const jwksFallbackMaxAge = 5 * time.Minute
+
+var ErrGoogleJWKSEmpty = errors.New("google jwks has no keys")
+
+// CheckJWKS fails when a fresh copy of Google's signing keys can't be had. The
+// keys are fetched again only once the max-age of the last copy has passed.
+func (v *gTokenValidatorImpl) CheckJWKS(ctx context.Context) error {
+	v.jwksMu.Lock()
+	defer v.jwksMu.Unlock()
+	if time.Now().Before(v.jwksExpires) {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleJWKSURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("google jwks responded %s", resp.Status)
+	}
+
+	var jwks struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("google jwks malformed: %w", err)
+	}
+	if len(jwks.Keys) == 0 {
+		return ErrGoogleJWKSEmpty
+	}
+	v.jwksExpires = time.Now().Add(maxAge(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		value, found := strings.CutPrefix(strings.TrimSpace(directive), "max-age=")
+		if !found {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return jwksFallbackMaxAge
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// application runs the HTTP server next to the background workers. The first
+// of them to fail, or a cancelled parent context, stops all of the others.
+type application struct {
+	ctx   context.Context
+	group *errgroup.Group
+}
+
+func newApplication(ctx context.Context) *application {
+	group, ctx := errgroup.WithContext(ctx)
+	return &application{ctx, group}
+}
+
+// worker runs a long-lived background worker that returns once its context
+// is cancelled.
+func (app *application) worker(name string, run func(ctx context.Context) error) {
+	app.group.Go(func() error {
+		if err := run(app.ctx); err != nil {
+			log.Error().Err(err).Str("worker", name).Msg("Background worker stopped with error")
+			return err
+		}
+		log.Info().Str("worker", name).Msg("Background worker stopped")
+		return nil
+	})
+}
+
+// Go starts a job a request asked for, like a catalog import. The job is
+// cancelled on shutdown and waited for, but its failure doesn't stop the
+// application.
+func (app *application) Go(job func(ctx context.Context)) {
+	app.group.Go(func() error {
+		job(app.ctx)
+		return nil
+	})
+}
+
+// serve runs srv until the application stops. It then calls draining, still
+// serving for delay so load balancers notice the server going away, and gives
+// the requests in flight up to drain to finish before closing their
+// connections.
+func (app *application) serve(srv *http.Server, draining func(), delay time.Duration, drain time.Duration) {
+	app.group.Go(func() error {
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	app.group.Go(func() error {
+		<-app.ctx.Done()
+		if draining != nil {
+			draining()
+		}
+		log.Info().Dur("delay", delay).Dur("drain", drain).Msg("Server shutting down")
+		time.Sleep(delay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), drain)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+			return err
+		}
+		return nil
+	})
+}
+
+// wait blocks until the server and every worker and job have returned.
+func (app *application) wait() error {
+	return app.group.Wait()
+}
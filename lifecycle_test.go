@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	app := newApplication(ctx)
+
+	workerStopped := false
+	app.worker("test", func(ctx context.Context) error {
+		<-ctx.Done()
+		workerStopped = true
+		return nil
+	})
+	jobCancelled := false
+	app.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		jobCancelled = true
+	})
+	drained := false
+	app.serve(&http.Server{Addr: "127.0.0.1:0"}, func() { drained = true }, 0, time.Second)
+
+	cancel()
+	assert.Nil(t, app.wait(), "a cancelled application didn't stop cleanly")
+	assert.True(t, drained, "a cancelled application didn't start draining")
+	assert.True(t, workerStopped, "a cancelled application didn't wait for its workers")
+	assert.True(t, jobCancelled, "a cancelled application didn't wait for its jobs")
+}
+
+func TestApplicationWorkerFailure(t *testing.T) {
+	app := newApplication(context.Background())
+
+	expErr := errors.New("worker failed")
+	app.worker("failing", func(ctx context.Context) error {
+		return expErr
+	})
+	app.worker("healthy", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	app.serve(&http.Server{Addr: "127.0.0.1:0"}, nil, 0, time.Second)
+
+	assert.Equal(t, expErr, app.wait(), "a failing worker didn't stop the application")
+}
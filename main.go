@@ -2,81 +2,83 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"ic-rhadi/e_library/confighelper"
+	"ic-rhadi/e_library/coverhelper"
 	"ic-rhadi/e_library/database"
 	"ic-rhadi/e_library/emailhelper"
 	"ic-rhadi/e_library/endpoints"
 	"ic-rhadi/e_library/googlehelper"
+	"ic-rhadi/e_library/moderationhelper"
+	"ic-rhadi/e_library/tracinghelper"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/jwtauth"
-	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"github.com/sethvargo/go-envconfig"
 )
 
-type config struct {
-	JWTSecret    string       `env:"JWTSECRET"`
-	PgHost       string       `env:"PG_HOST"`
-	PgPort       int          `env:"PG_PORT"`
-	PgUser       string       `env:"PG_USER"`
-	PgPass       string       `env:"PG_PASS"`
-	PgDB         string       `env:"PG_DB"`
-	Port         int          `env:"PORT,required"`
-	LoginLengths loginLengths `env:""`
-}
-
-type loginLengths struct {
-	TokenLength           time.Duration `env:"TOKEN_DURATION"`
-	SessionLength         time.Duration `env:"SESSION_DURATION"`
-	ActivationLength      time.Duration `env:"ACTIVATION_DURATION"`
-	DatabaseCleanupLength time.Duration `env:"DB_CLEANUP_DURATION"`
-}
-
 func main() {
 	log.Logger = zerolog.New(zerolog.NewConsoleWriter()).With().Timestamp().Caller().Logger()
 	zerolog.TimeFieldFormat = time.RFC3339
+	// Requests carry their own logger, with their trace ids; anything else
+	// logging through log.Ctx gets the global one.
+	zerolog.DefaultContextLogger = &log.Logger
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatal().Err(err).Msg("Error loading .env file")
+	conf, err := confighelper.Load(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Loading configuration failed")
 	}
 
-	var conf config
-	if err := envconfig.Process(context.Background(), &conf); err != nil {
-		log.Fatal().Err(err).Msg("Required enviroment keys was not set up")
+	runServer(conf)
+}
+
+func connectDB(conf confighelper.Config) database.DB {
+	db, err := database.StartDB(context.Background(), conf.Database.DSN(), conf.Database.Options())
+	if err != nil {
+		log.Panic().Err(err).Str("dsn", database.RedactDSN(conf.Database.DSN())).Msg("Error starting database")
+	}
+	if err := db.InitDB(context.Background()); err != nil {
+		log.Panic().Err(err).Msg("Error initializing database")
 	}
+	return db
+}
 
+func runServer(conf confighelper.Config) {
 	var email emailhelper.ActivationMailDriver
 	email, err := emailhelper.NewActivationMailHelper(context.Background())
 	if err != nil {
 		log.Fatal().Err(err).Msg("Required enviroment keys was not set up")
 	}
 
-	sessionAuth := jwtauth.New("HS256", []byte(conf.JWTSecret), nil)
-	dbURL := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		conf.PgHost,
-		conf.PgPort,
-		conf.PgUser,
-		conf.PgPass,
-		conf.PgDB,
-	)
+	covers, err := coverhelper.NewCoverStorage(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Cover storage failed to initialize")
+	}
 
-	db, err := database.StartDB(dbURL)
+	contentFilter, err := moderationhelper.NewContentFilter(context.Background())
 	if err != nil {
-		log.Panic().Err(err).Str("database link", dbURL).Msg("Error starting database")
+		log.Fatal().Err(err).Msg("Content filter failed to initialize")
 	}
-	if err := db.InitDB(context.Background()); err != nil {
-		log.Panic().Err(err).Msg("Error initializing database")
+
+	sessionAuth := jwtauth.New("HS256", []byte(conf.JWTSecret), nil)
+
+	shutdownTracing, err := tracinghelper.StartTracing(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Tracing failed to initialize")
 	}
-	defer db.CloseDB()
-	stopDBJobs := db.SetConcurrentRoutineJobs(conf.LoginLengths.DatabaseCleanupLength)
 	defer func() {
-		stopDBJobs <- true
+		ctx, cancel := context.WithTimeout(context.Background(), conf.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error().Err(err).Msg("Flushing the remaining spans failed")
+		}
 	}()
 
 	gValidator, err := googlehelper.NewGValidator(context.Background())
@@ -85,35 +87,211 @@ func main() {
 		return
 	}
 
+	db := connectDB(conf)
+	defer db.CloseDB()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApplication(ctx)
+
+	app.worker("expired cleanup", func(ctx context.Context) error {
+		return db.RunExpiredCleanup(ctx, conf.LoginLengths.DatabaseCleanupLength)
+	})
+	app.worker("popularity refresh", func(ctx context.Context) error {
+		return db.RunPopularityRefresh(ctx, conf.Rankings.PopularityRefreshLength)
+	})
+	app.worker("recommendation refresh", func(ctx context.Context) error {
+		return db.RunRecommendationRefresh(ctx, conf.Rankings.RecommendationRefreshLength)
+	})
+
+	probe := endpoints.NewReadinessProbe(conf.Readiness.CheckTimeout, conf.Readiness.CacheDuration,
+		endpoints.ReadinessCheck{Name: "database", Check: db.PingContext},
+		endpoints.ReadinessCheck{Name: "statements", Check: func(ctx context.Context) error { return db.CheckStatements() }},
+		endpoints.ReadinessCheck{Name: "migration", Check: func(ctx context.Context) error { return db.CheckMigration() }},
+		endpoints.ReadinessCheck{Name: "smtp", Check: email.CheckConnection, Optional: true},
+		endpoints.ReadinessCheck{Name: "google_jwks", Check: gValidator.CheckJWKS, Optional: true},
+	)
+
+	r := newRouter(conf, services{
+		db:            db,
+		email:         email,
+		covers:        covers,
+		contentFilter: contentFilter,
+		gValidator:    gValidator,
+		sessionAuth:   sessionAuth,
+		jobs:          app,
+		readiness:     probe,
+	})
+
+	app.serve(&http.Server{
+		Addr:         ":" + strconv.Itoa(conf.Port),
+		Handler:      r,
+		ReadTimeout:  conf.Server.ReadTimeout,
+		WriteTimeout: conf.Server.WriteTimeout,
+		IdleTimeout:  conf.Server.IdleTimeout,
+	}, probe.Drain, conf.Server.DrainDelay, conf.Server.ShutdownTimeout)
+
+	// The metrics aren't signed in to, so they're kept off the public port.
+	app.serve(&http.Server{
+		Addr:         conf.MetricsAddr,
+		Handler:      newMetricsRouter(),
+		ReadTimeout:  conf.Server.ReadTimeout,
+		WriteTimeout: conf.Server.WriteTimeout,
+		IdleTimeout:  conf.Server.IdleTimeout,
+	}, nil, conf.Server.DrainDelay, conf.Server.ShutdownTimeout)
+
+	log.Info().Int("Server port", conf.Port).Str("Metrics address", conf.MetricsAddr).Msg("Server started")
+	if err := app.wait(); err != nil {
+		log.Error().Err(err).Msg("Server stopped with error")
+	} else {
+		log.Info().Msg("Server stopped normally")
+	}
+}
+
+// services are what the handlers are built with.
+type services struct {
+	db            database.DB
+	email         emailhelper.ActivationMailDriver
+	covers        coverhelper.CoverStorage
+	contentFilter moderationhelper.ContentFilter
+	gValidator    googlehelper.GTokenValidator
+	sessionAuth   *jwtauth.JWTAuth
+	jobs          endpoints.BackgroundJobs
+	readiness     *endpoints.ReadinessProbe
+}
+
+// newMetricsRouter serves /metrics on its own listener, for Prometheus.
+func newMetricsRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/metrics", endpoints.Metrics())
+	return r
+}
+
+func newRouter(conf confighelper.Config, s services) chi.Router {
 	sessionLength := conf.LoginLengths.SessionLength
 	tokenLength := conf.LoginLengths.TokenLength
+	loginLimiter := endpoints.NewLoginLimiter(conf.LoginLimits.MaxFailures, conf.LoginLimits.FailureWindow)
 	r := chi.NewRouter()
 
 	r.Use(middleware.CleanPath)
 	r.Use(middleware.RequestID)
+	r.Use(endpoints.TracingMiddleware())
+	r.Use(endpoints.MetricsMiddleware())
 	r.Use(middleware.Recoverer)
 
+	r.Get("/healthz", endpoints.Liveness())
+	r.Get("/readyz", endpoints.Readiness(s.readiness))
+
 	r.Route("/auth", func(r chi.Router) {
-		r.Post("/login", endpoints.LoginPost(db, sessionAuth, sessionLength, tokenLength))
-		r.Post("/google", endpoints.LoginGoogle(db, sessionAuth, gValidator, sessionLength, tokenLength))
-		r.Post("/refresh", endpoints.RefreshToken(db, sessionAuth, sessionLength, tokenLength))
-		r.Post("/register", endpoints.RegisterPost(db, email, conf.LoginLengths.ActivationLength))
-		r.Post("/register/google", endpoints.RegisterGoogle(db, gValidator, email, conf.LoginLengths.ActivationLength))
-		r.Get("/resend", endpoints.ResendActivationEmail(db, email, conf.LoginLengths.ActivationLength))
-		r.Get("/activate", endpoints.ActivateAccount(db))
+		r.Post("/login", endpoints.LoginPost(s.db, s.db, loginLimiter, s.sessionAuth, sessionLength, tokenLength))
+		r.Post("/google", endpoints.LoginGoogle(s.db, s.db, s.sessionAuth, s.gValidator, sessionLength, tokenLength))
+		r.Post("/refresh", endpoints.RefreshToken(s.db, s.db, s.sessionAuth, sessionLength, tokenLength))
+		r.Post("/register", endpoints.RegisterPost(s.db, s.db, s.email, conf.LoginLengths.ActivationLength))
+		r.Post("/register/google", endpoints.RegisterGoogle(s.db, s.db, s.gValidator, s.email, conf.LoginLengths.ActivationLength))
+		r.Get("/resend", endpoints.ResendActivationEmail(s.db, s.email, conf.LoginLengths.ActivationLength))
+		r.Get("/activate", endpoints.ActivateAccount(s.db, s.db))
 	})
 
 	r.Group(func(r chi.Router) {
-		r.Use(jwtauth.Verifier(sessionAuth))
+		r.Use(jwtauth.Verifier(s.sessionAuth))
 		r.Use(endpoints.SessionAuthenticatorMiddleware())
+		r.Use(endpoints.AccountLanguageMiddleware(s.db))
+
+		r.Get("/books", endpoints.ListBooks(s.db, conf.Rankings.NewArrivalWindow))
+		r.Get("/books/{id}", endpoints.GetBook(s.db))
+		r.Get("/books/{id}/similar", endpoints.ListSimilarBooks(s.db))
+		r.Get("/books/{id}/reviews", endpoints.ListReviews(s.db))
+		r.Put("/books/{id}/review", endpoints.SaveReview(s.db, s.contentFilter))
+		r.Put("/reviews/{id}/vote", endpoints.VoteReview(s.db))
+		r.Post("/reviews/{id}/report", endpoints.ReportReview(s.db))
+		r.Get("/books/{id}/annotations", endpoints.ListAnnotations(s.db))
+		r.Post("/books/{id}/annotations", endpoints.CreateAnnotation(s.db))
+		r.Get("/books/{id}/annotations/export", endpoints.ExportAnnotations(s.db, s.db))
+		r.Get("/annotations/{id}", endpoints.GetAnnotation(s.db))
+		r.Patch("/annotations/{id}", endpoints.UpdateAnnotation(s.db))
+		r.Delete("/annotations/{id}", endpoints.DeleteAnnotation(s.db))
+		r.Get("/me/language", endpoints.GetAccountLanguage(s.db))
+		r.Put("/me/language", endpoints.SetAccountLanguage(s.db))
+		r.Get("/me/recommendations", endpoints.ListRecommendations(s.db))
+		r.Get("/me/reading", endpoints.ListReading(s.db))
+		r.Put("/me/reading/{bookId}", endpoints.SaveReadingProgress(s.db))
+		r.Get("/me/shelves", endpoints.ListShelves(s.db))
+		r.Post("/me/shelves", endpoints.CreateShelf(s.db))
+		r.Get("/shelves/{id}", endpoints.GetShelf(s.db))
+		r.Patch("/shelves/{id}", endpoints.UpdateShelf(s.db))
+		r.Delete("/shelves/{id}", endpoints.DeleteShelf(s.db))
+		r.Put("/shelves/{id}/books", endpoints.ReorderShelfBooks(s.db))
+		r.Put("/shelves/{id}/books/{bookId}", endpoints.AddShelfBook(s.db))
+		r.Delete("/shelves/{id}/books/{bookId}", endpoints.RemoveShelfBook(s.db))
+		r.Get("/authors", endpoints.ListAuthors(s.db))
+		r.Get("/authors/{id}", endpoints.GetAuthor(s.db))
+		r.Get("/genres", endpoints.ListGenres(s.db))
+		r.Get("/genres/carousels", endpoints.ListGenreCarousels(s.db))
+		r.Get("/genres/{id}/books", endpoints.ListGenreBooks(s.db))
+
+		r.Group(func(r chi.Router) {
+			r.Use(endpoints.SupportAuthorizerMiddleware(s.db))
+
+			r.Get("/admin/users", endpoints.ListAccounts(s.db))
+			r.Get("/admin/users/{email}", endpoints.GetAccount(s.db))
+			r.Get("/admin/users/{email}/sessions", endpoints.ListAccountSessions(s.db))
+			r.Delete("/admin/users/{email}/sessions", endpoints.RevokeAccountSessions(s.db, s.db))
+			r.Get("/admin/users/{email}/loans", endpoints.ListAccountLoans(s.db))
+			r.Post("/admin/users/{email}/activation", endpoints.AdminActivateAccount(s.db, s.db))
+			r.Post("/admin/users/{email}/activation-email", endpoints.AdminResendActivation(s.db, s.db, s.email, conf.LoginLengths.ActivationLength))
+		})
 
-		r.Get("/books", endpoints.ListBooks(db))
+		r.Group(func(r chi.Router) {
+			r.Use(endpoints.AdminAuthorizerMiddleware(s.db))
+
+			r.Put("/books/{id}/cover", endpoints.UploadBookCover(s.db, s.db, s.covers))
+			r.Put("/books/{id}/classification", endpoints.SetBookClassification(s.db, s.db))
+			r.Post("/admin/genres", endpoints.CreateGenre(s.db, s.db))
+			r.Get("/admin/reviews", endpoints.ListModerationQueue(s.db))
+			r.Post("/admin/reviews/{id}/moderation", endpoints.ModerateReview(s.db, s.db))
+			r.Get("/admin/audit", endpoints.ListAuditEvents(s.db))
+			r.Get("/admin/audit/verify", endpoints.VerifyAuditLog(s.db))
+			r.Put("/admin/users/{email}/status", endpoints.SetAccountStatus(s.db, s.db))
+			r.Post("/admin/users/{email}/impersonation", endpoints.ImpersonateAccount(s.db, s.db, s.sessionAuth, conf.LoginLengths.ImpersonationLength))
+
+			r.Route("/admin/catalog", func(r chi.Router) {
+				r.Post("/import", endpoints.ImportCatalog(s.db, s.db, s.jobs, int64(conf.Catalog.ImportMaxSize)))
+				r.Get("/import/{id}", endpoints.GetImportJob(s.db))
+				r.Get("/export", endpoints.ExportCatalog(s.db))
+			})
+		})
 	})
 
-	log.Info().Int("Server port", conf.Port).Msg("Server started")
-	if err = http.ListenAndServe(":"+strconv.Itoa(conf.Port), r); err != http.ErrServerClosed {
-		log.Error().Err(err).Msg("Server stopped with error")
-	} else {
-		log.Info().Msg("Server stopped normally")
+	opdsConf := endpoints.OPDSConfig{
+		AcquisitionURL:  conf.OPDS.AcquisitionURL,
+		AcquisitionType: conf.OPDS.AcquisitionType,
+	}
+	opdsRoutes := func(format endpoints.OPDSFormat) func(r chi.Router) {
+		return func(r chi.Router) {
+			r.Get("/", endpoints.OPDSCatalog(format))
+			r.Get("/new", endpoints.OPDSNewBooks(s.db, format, opdsConf, conf.Rankings.NewArrivalWindow))
+			r.Get("/popular", endpoints.OPDSPopularBooks(s.db, format, opdsConf))
+			r.Get("/search", endpoints.OPDSSearchBooks(s.db, format, opdsConf))
+			r.Get("/shelves", endpoints.OPDSShelves(s.db, format))
+			r.Get("/shelves/{id}", endpoints.OPDSShelf(s.db, format, opdsConf))
+		}
 	}
+	r.Route("/opds", func(r chi.Router) {
+		r.Get("/auth", endpoints.OPDSAuthentication())
+		r.Get("/search.xml", endpoints.OPDSSearchDescription())
+
+		r.Group(func(r chi.Router) {
+			r.Use(endpoints.OPDSAuthenticatorMiddleware(s.db, s.db, loginLimiter, conf.OPDS.CredentialCacheLength))
+
+			r.Group(opdsRoutes(endpoints.OPDSAtom))
+			r.Route("/v2", opdsRoutes(endpoints.OPDSJSON))
+		})
+	})
+
+	r.Get("/covers/{id}/{size}", endpoints.GetCover(s.covers))
+	r.Get("/shared/shelves/{id}", endpoints.GetSharedShelf(s.db))
+	r.Get("/openapi.json", endpoints.OpenAPIDocument())
+	r.Get("/docs", endpoints.OpenAPIReference())
+
+	return r
 }
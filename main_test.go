@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"ic-rhadi/e_library/confighelper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOpenAPIRoutes fails when a route is added to or removed from the router
+// without the OpenAPI document following.
+func TestOpenAPIRoutes(t *testing.T) {
+	r := newRouter(confighelper.Config{}, services{
+		sessionAuth: jwtauth.New("HS256", []byte("secret"), nil),
+	})
+
+	var routes []string
+	err := chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		routes = append(routes, method+" "+route)
+		return nil
+	})
+	require.NoError(t, err, "walking the router failed")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code, "the OpenAPI document wasn't served")
+
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&doc), "the OpenAPI document isn't valid JSON")
+
+	var documented []string
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	assert.ElementsMatch(t, routes, documented, "the routes in main.go and the OpenAPI document differ")
+}
+
+// TestMetricsRouter keeps the metrics off the public router.
+func TestMetricsRouter(t *testing.T) {
+	r := newRouter(confighelper.Config{}, services{
+		sessionAuth: jwtauth.New("HS256", []byte("secret"), nil),
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "the metrics were served on the public router")
+
+	w = httptest.NewRecorder()
+	newMetricsRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "the metrics weren't served on their own router")
+	assert.Contains(t, w.Body.String(), "elibrary_", "the metrics router didn't serve the metrics")
+}
@@ -0,0 +1,102 @@
+package metricshelper
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "elibrary"
+
+// Registry holds every metric served on /metrics. The collectors below are
+// registered on it, next to the Go runtime and process ones.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests served, by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	StatementDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_statement_duration_seconds",
+		Help:      "Time taken to run prepared statements, by statement.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"statement"})
+
+	CatalogReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_catalog_reads_total",
+		Help:      "Book catalog reads, by route: replica, or primary when there's no replica, the account is pinned, the replicas lag or one failed.",
+	}, []string{"route"})
+
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts with well formed credentials, by method and result.",
+	}, []string{"method", "result"})
+
+	RefreshTokenReuses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "refresh_token_reuse_total",
+		Help:      "Refresh tokens used a second time, each invalidating its token family.",
+	})
+
+	Emails = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_total",
+		Help:      "Emails handed to the SMTP server, by kind and result.",
+	}, []string{"kind", "result"})
+
+	CleanupDeletedRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cleanup_deleted_rows_total",
+		Help:      "Rows deleted by the expired cleanup job, by what they held.",
+	}, []string{"target"})
+)
+
+// Label values shared by the callers.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	// ResultLimited is a login refused by the rate limit, without checking
+	// the credentials.
+	ResultLimited = "limited"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		StatementDuration,
+		CatalogReads,
+		Logins,
+		RefreshTokenReuses,
+		Emails,
+		CleanupDeletedRows,
+	)
+}
+
+// RegisterDBStats exposes the connection pool figures of db as gauges.
+func RegisterDBStats(db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,48 @@
+package moderationhelper
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sethvargo/go-envconfig"
+)
+
+// ContentFilter decides whether user written text needs a moderator's look
+// before it's published.
+type ContentFilter interface {
+	CheckContent(ctx context.Context, text string) (Verdict, error)
+}
+
+// Verdict is a filter's decision on a text. Reason says what was found when
+// the text is flagged.
+type Verdict struct {
+	Flagged bool
+	Reason  string
+}
+
+// NewContentFilter makes the local wordlist filter. Without a wordlist file
+// it uses the built-in list.
+func NewContentFilter(ctx context.Context) (ContentFilter, error) {
+	var config wordlistConfig
+
+	if err := envconfig.Process(ctx, &config); err != nil {
+		return nil, err
+	}
+
+	words := defaultWordlist
+	if config.WordlistPath != "" {
+		data, err := os.ReadFile(config.WordlistPath)
+		if err != nil {
+			return nil, fmt.Errorf("moderation wordlist reading failed: %w", err)
+		}
+		words = string(data)
+	}
+
+	return newWordlistFilter(words, config.MaxLinks), nil
+}
+
+type wordlistConfig struct {
+	WordlistPath string `env:"MODERATION_WORDLIST"`
+	MaxLinks     int    `env:"MODERATION_MAX_LINKS, default=2"`
+}
@@ -0,0 +1,102 @@
+package moderationhelper
+
+import (
+	"context"
+	_ "embed"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+//go:embed default_wordlist.txt
+var defaultWordlist string
+
+// leetReplacer undoes the usual letter swaps used to slip words past a
+// filter.
+var leetReplacer = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"@", "a",
+	"$", "s",
+)
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+const maxRepeatedRune = 10
+
+// wordlistFilter flags texts with a listed word or phrase, more links than
+// allowed, or a long run of one character.
+type wordlistFilter struct {
+	words    map[string]bool
+	phrases  []string
+	maxLinks int
+}
+
+// newWordlistFilter reads one entry per line. Blank lines and lines starting
+// with # are skipped, entries with spaces are matched as phrases.
+func newWordlistFilter(list string, maxLinks int) wordlistFilter {
+	f := wordlistFilter{words: map[string]bool{}, maxLinks: maxLinks}
+	for _, line := range strings.Split(list, "\n") {
+		entry := strings.Join(normalizedWords(line), " ")
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "#") || entry == "":
+		case strings.Contains(entry, " "):
+			f.phrases = append(f.phrases, " "+entry+" ")
+		default:
+			f.words[entry] = true
+		}
+	}
+	return f
+}
+
+func (f wordlistFilter) CheckContent(ctx context.Context, text string) (Verdict, error) {
+	if links := len(linkPattern.FindAllString(text, -1)); links > f.maxLinks {
+		return Verdict{Flagged: true, Reason: "too many links"}, nil
+	}
+	if hasRepeatedRune(text, maxRepeatedRune) {
+		return Verdict{Flagged: true, Reason: "repeated characters"}, nil
+	}
+
+	words := normalizedWords(linkPattern.ReplaceAllString(text, " "))
+	for _, word := range words {
+		if f.words[word] {
+			return Verdict{Flagged: true, Reason: "listed word"}, nil
+		}
+	}
+	joined := " " + strings.Join(words, " ") + " "
+	for _, phrase := range f.phrases {
+		if strings.Contains(joined, phrase) {
+			return Verdict{Flagged: true, Reason: "listed phrase"}, nil
+		}
+	}
+	return Verdict{}, nil
+}
+
+// normalizedWords lowercases the text, undoes letter swaps and splits it on
+// anything that isn't a letter.
+func normalizedWords(text string) []string {
+	text = leetReplacer.Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+func hasRepeatedRune(text string, limit int) bool {
+	var last rune
+	run := 0
+	for _, r := range text {
+		if r == last && !unicode.IsSpace(r) {
+			run++
+		} else {
+			last, run = r, 1
+		}
+		if run >= limit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package moderationhelper
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWordlist = `# a comment, not an entry
+spam
+scam
+
+Buy  Now
+`
+
+func TestWordlistFilter(t *testing.T) {
+	filter := newWordlistFilter(testWordlist, 2)
+
+	tests := []struct {
+		name      string
+		text      string
+		expReason string
+	}{
+		{"clean", "A gentle read, I liked it.", ""},
+		{"listed word", "This book is spam.", "listed word"},
+		{"any case", "SPAM, all of it", "listed word"},
+		{"letter swaps", "what a $c4m", "listed word"},
+		{"next to punctuation", "(spam)", "listed word"},
+		{"word containing a listed one", "the spammer and the antispam law", ""},
+		{"near miss", "scampi and spa menus", ""},
+		{"commented line", "a comment on the plot", ""},
+		{"phrase", "buy now while it lasts", "listed phrase"},
+		{"phrase across punctuation", "Buy... now!", "listed phrase"},
+		{"phrase words apart", "buy it now", ""},
+		{"phrase word extended", "the buyer knows now", ""},
+		{"links within limit", "see https://a.example and www.b.example", ""},
+		{"too many links", "https://a.example http://b.example www.c.example", "too many links"},
+		{"listed word in a link", "see https://spam.example.com/scam", ""},
+		{"repeated characters", "so good" + strings.Repeat("!", 10), "repeated characters"},
+		{"repeats under the limit", "so good" + strings.Repeat("!", 9), ""},
+		{"repeated spaces", "fine" + strings.Repeat(" ", 20) + "text", ""},
+	}
+
+	for _, test := range tests {
+		verdict, err := filter.CheckContent(context.Background(), test.text)
+		require.Nil(t, err)
+		assert.Equal(t, Verdict{Flagged: test.expReason != "", Reason: test.expReason}, verdict, "%s: unexpected verdict on %q", test.name, test.text)
+	}
+}
+
+func TestDefaultWordlist(t *testing.T) {
+	filter := newWordlistFilter(defaultWordlist, 2)
+	assert.NotEmpty(t, filter.words, "the built-in list has no words")
+	for word := range filter.words {
+		assert.NotContains(t, word, "#", "a comment was read as a word")
+	}
+}
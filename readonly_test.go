@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"ic-rhadi/e_library/confighelper"
+	"ic-rhadi/e_library/database"
+	"ic-rhadi/e_library/sessiontoken"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/jwtauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDriver is a database that has no rows and remembers every
+// statement run against it.
+type recordingDriver struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ran = append(d.ran, query)
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{c.d, query}, nil
+}
+func (c recordingConn) Close() error              { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.d.record(s.query)
+	return driver.RowsAffected(0), nil
+}
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.record(s.query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+var writeStatement = regexp.MustCompile(`(?i)\b(INSERT\s+INTO|DELETE\s+FROM|UPDATE\s+\w+\s+SET)\b`)
+
+var routeParam = regexp.MustCompile(`{([^}]+)}`)
+
+// TestReadOnlyImpersonation makes every GET route the router has with a
+// read-only impersonation token, checking none of them writes to the
+// impersonated account.
+func TestReadOnlyImpersonation(t *testing.T) {
+	ctx := context.Background()
+	rec := &recordingDriver{}
+	sql.Register("recording", rec)
+	d, err := sql.Open("recording", "")
+	require.Nil(t, err)
+	defer d.Close()
+	db := database.DBInstance{DB: d}
+	require.Nil(t, db.PrepareStatements(ctx))
+	rec.ran = nil
+
+	sessionAuth := jwtauth.New("HS256", []byte("secret"), nil)
+	r := newRouter(confighelper.Config{}, services{db: db, sessionAuth: sessionAuth})
+	_, token, err := sessiontoken.CreateNewSessionToken(sessionAuth, sessiontoken.AccessClaimsSchema{
+		Email:    "reader@example.com",
+		Actor:    &sessiontoken.ActorClaimsSchema{Email: "support@example.com"},
+		ReadOnly: true,
+	}, time.Minute)
+	require.Nil(t, err)
+
+	err = chi.Walk(r, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if method != http.MethodGet {
+			return nil
+		}
+		path := routeParam.ReplaceAllStringFunc(route, func(param string) string {
+			switch param {
+			case "{email}":
+				return "reader@example.com"
+			case "{size}":
+				return "small"
+			}
+			return "0b8f5a56-3c8e-4d0e-9d5e-2a7c1f1b6c11"
+		})
+		path = strings.TrimSuffix(path, "/*")
+
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		func() {
+			// Services the test doesn't set up, like the readiness probe,
+			// panic; only what ran before matters.
+			defer func() { recover() }()
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		for _, query := range rec.ran {
+			assert.False(t, writeStatement.MatchString(query), "GET %s wrote with a read-only token:\n%s", route, query)
+		}
+		rec.ran = nil
+		return nil
+	})
+	require.Nil(t, err)
+}
@@ -7,6 +7,20 @@ import (
 
 type AccessClaimsSchema struct {
 	Email string `json:"sub"`
+	// Actor is the staff account impersonating Email, like the act claim of
+	// RFC 8693. Impersonation tokens are always read-only.
+	Actor    *ActorClaimsSchema `json:"act,omitempty"`
+	ReadOnly bool               `json:"read_only,omitempty"`
+}
+
+type ActorClaimsSchema struct {
+	Email string `json:"sub"`
+}
+
+// Impersonated tells whether the token was given to staff acting as the
+// account rather than to the account itself.
+func (token AccessClaimsSchema) Impersonated() bool {
+	return token.Actor != nil
 }
 
 func (token AccessClaimsSchema) ToInterface() (inter map[string]interface{}, err error) {
@@ -41,5 +55,8 @@ func (token AccessClaimsSchema) CheckMalform() error {
 	if token.Email == "" {
 		return ErrAccessTokenMalformed
 	}
+	if token.Actor != nil && (token.Actor.Email == "" || !token.ReadOnly) {
+		return ErrAccessTokenMalformed
+	}
 	return nil
 }
@@ -0,0 +1,98 @@
+package tracinghelper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sethvargo/go-envconfig"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+)
+
+// tracingConfig picks where spans go. The OTLP exporter reads its endpoint,
+// headers and the like from the standard OTEL_EXPORTER_OTLP_* keys, and sends
+// to a collector on http://localhost:4318 by default.
+type tracingConfig struct {
+	Exporter    string  `env:"OTEL_TRACES_EXPORTER,default=none"`
+	ServiceName string  `env:"OTEL_SERVICE_NAME,default=e_library"`
+	SampleRatio float64 `env:"OTEL_TRACES_SAMPLE_RATIO,default=1"`
+}
+
+// StartTracing makes W3C trace context the propagated format and, with the
+// otlp exporter, installs the tracer provider that exports spans. The returned
+// shutdown flushes the spans still buffered.
+func StartTracing(ctx context.Context) (shutdown func(ctx context.Context) error, err error) {
+	var config tracingConfig
+	if err := envconfig.Process(ctx, &config); err != nil {
+		return nil, err
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	switch config.Exporter {
+	case ExporterNone:
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOTLP:
+	default:
+		return nil, fmt.Errorf("traces exporter %q unknown, expected %s or %s", config.Exporter, ExporterOTLP, ExporterNone)
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter creation failed: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer names the spans started by a package of this module.
+func Tracer(pkg string) trace.Tracer {
+	return otel.Tracer("ic-rhadi/e_library/" + pkg)
+}
+
+// End records err on span, when there is one, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transport traces the requests sent through base and passes the trace
+// context on to the server.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
+
+// Logger is log.Logger with the ids of the trace and span in ctx, if any.
+func Logger(ctx context.Context) zerolog.Logger {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return log.Logger
+	}
+	return log.Logger.With().
+		Str("trace_id", spanContext.TraceID().String()).
+		Str("span_id", spanContext.SpanID().String()).
+		Logger()
+}